@@ -0,0 +1,77 @@
+package goratelimit
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Algorithm names a rate limiting algorithm. Its values match
+// Description.Algorithm, so configuration files can select an algorithm by
+// the same name Describe reports.
+type Algorithm string
+
+const (
+	AlgorithmFixedWindow          Algorithm = "fixed_window"
+	AlgorithmSlidingWindow        Algorithm = "sliding_window"
+	AlgorithmSlidingWindowCounter Algorithm = "sliding_window_counter"
+	AlgorithmTokenBucket          Algorithm = "token_bucket"
+	AlgorithmLeakyBucket          Algorithm = "leaky_bucket"
+	AlgorithmGCRA                 Algorithm = "gcra"
+	AlgorithmCMS                  Algorithm = "cms"
+	AlgorithmApproxFixedWindow    Algorithm = "approx_fixed_window"
+	AlgorithmConcurrency          Algorithm = "concurrency"
+	AlgorithmMinInterval          Algorithm = "min_interval"
+	AlgorithmRollingCounter       Algorithm = "rolling_counter"
+	AlgorithmBalance              Algorithm = "balance"
+)
+
+var algorithms = []Algorithm{
+	AlgorithmFixedWindow,
+	AlgorithmSlidingWindow,
+	AlgorithmSlidingWindowCounter,
+	AlgorithmTokenBucket,
+	AlgorithmLeakyBucket,
+	AlgorithmGCRA,
+	AlgorithmCMS,
+	AlgorithmApproxFixedWindow,
+	AlgorithmConcurrency,
+	AlgorithmMinInterval,
+	AlgorithmRollingCounter,
+	AlgorithmBalance,
+}
+
+// UnmarshalText parses an algorithm name (case-insensitive), so an Algorithm
+// can be read from JSON, YAML, or flag values. Unknown names return an error
+// wrapping ErrUnknownAlgorithm.
+func (a *Algorithm) UnmarshalText(text []byte) error {
+	name := Algorithm(strings.ToLower(strings.TrimSpace(string(text))))
+	for _, known := range algorithms {
+		if name == known {
+			*a = name
+			return nil
+		}
+	}
+	names := make([]string, len(algorithms))
+	for i, known := range algorithms {
+		names[i] = string(known)
+	}
+	return algorithmErr(fmt.Sprintf("unknown algorithm %q", text),
+		"Use one of: "+strings.Join(names, ", ")+".")
+}
+
+// UnmarshalJSON parses a JSON string with the same rules as UnmarshalText.
+func (a *Algorithm) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONText(data, a)
+}
+
+// unmarshalJSONText decodes a JSON string and hands it to UnmarshalText.
+func unmarshalJSONText(data []byte, u encoding.TextUnmarshaler) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return validationErr(fmt.Sprintf("expected a JSON string, got %s", data),
+			"Quote the value in the config file.")
+	}
+	return u.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,76 @@
+package goratelimit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLeakyBucketMode_UnmarshalJSON(t *testing.T) {
+	for input, want := range map[string]LeakyBucketMode{
+		`"policing"`: Policing,
+		`"shaping"`:  Shaping,
+		`"Shaping"`:  Shaping,
+	} {
+		var mode LeakyBucketMode
+		require.NoError(t, json.Unmarshal([]byte(input), &mode), input)
+		assert.Equal(t, want, mode, input)
+	}
+
+	for _, input := range []string{`"throttling"`, `""`, `1`} {
+		var mode LeakyBucketMode
+		err := json.Unmarshal([]byte(input), &mode)
+		require.Error(t, err, input)
+		assert.ErrorIs(t, err, ErrInvalidParameter, input)
+		assert.Empty(t, mode, "mode should be unchanged on error")
+	}
+}
+
+func TestLeakyBucketMode_UnmarshalText(t *testing.T) {
+	var mode LeakyBucketMode
+	require.NoError(t, mode.UnmarshalText([]byte("policing")))
+	assert.Equal(t, Policing, mode)
+
+	err := mode.UnmarshalText([]byte("queueing"))
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Contains(t, err.Error(), `"queueing"`)
+	assert.Equal(t, Policing, mode, "mode should be unchanged on error")
+}
+
+func TestAlgorithm_UnmarshalJSON(t *testing.T) {
+	for _, want := range algorithms {
+		var algo Algorithm
+		require.NoError(t, json.Unmarshal([]byte(`"`+string(want)+`"`), &algo))
+		assert.Equal(t, want, algo)
+	}
+
+	var algo Algorithm
+	err := json.Unmarshal([]byte(`"round_robin"`), &algo)
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+	assert.Contains(t, err.Error(), "gcra", "error should list valid algorithms")
+	assert.Empty(t, algo)
+}
+
+func TestAlgorithm_MatchesDescribe(t *testing.T) {
+	l, err := NewGCRA(10, 20)
+	require.NoError(t, err)
+	var algo Algorithm
+	require.NoError(t, algo.UnmarshalText([]byte(Describe(l).Algorithm)))
+	assert.Equal(t, AlgorithmGCRA, algo)
+}
+
+func TestConfig_UnmarshalYAML(t *testing.T) {
+	var cfg struct {
+		Algorithm Algorithm       `yaml:"algorithm"`
+		Mode      LeakyBucketMode `yaml:"mode"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("algorithm: leaky_bucket\nmode: shaping\n"), &cfg))
+	assert.Equal(t, AlgorithmLeakyBucket, cfg.Algorithm)
+	assert.Equal(t, Shaping, cfg.Mode)
+
+	err := yaml.Unmarshal([]byte("algorithm: gcra\nmode: dropping\n"), &cfg)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
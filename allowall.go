@@ -0,0 +1,111 @@
+package goratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Refunder is implemented by limiters that can give back units consumed by
+// an allowed AllowN. The in-memory backends of Fixed Window, Sliding Window,
+// Sliding Window Counter, Token Bucket and GCRA implement it; Redis backends
+// and Leaky Bucket do not. The dry run, OnLimitExceeded, hot key and metrics
+// wrappers forward to the wrapped limiter, so use CanRefund rather than a
+// type assertion to learn whether a refund can succeed.
+//
+// Refund is meant to follow the charge it undoes closely: a refund after the
+// key's window has rolled over returns the units to the new window.
+type Refunder interface {
+	Refund(ctx context.Context, key string, n int) error
+}
+
+// Refund gives back n units charged to key by l. It returns an error
+// wrapping ErrInvalidParameter if l does not implement Refunder.
+func Refund(ctx context.Context, l Limiter, key string, n int) error {
+	r, err := refunder(l)
+	if err != nil {
+		return err
+	}
+	return r.Refund(ctx, key, n)
+}
+
+// CanRefund reports whether Refund on l can succeed. It looks through
+// wrappers that implement Unwrap() Limiter, such as those added by
+// WithDryRun, WithAllowList or the metrics package, to the limiter that
+// holds the budget.
+func CanRefund(l Limiter) bool {
+	_, ok := innermost(l).(Refunder)
+	return ok
+}
+
+// innermost follows Unwrap from l down to the limiter the wrappers sit on.
+func innermost(l Limiter) Limiter {
+	for {
+		u, ok := l.(interface{ Unwrap() Limiter })
+		if !ok {
+			return l
+		}
+		l = u.Unwrap()
+	}
+}
+
+func refunder(l Limiter) (Refunder, error) {
+	r, ok := l.(Refunder)
+	if !ok || !CanRefund(l) {
+		return nil, validationErr(fmt.Sprintf("%T does not support Refund", l),
+			"Use an in-memory Fixed Window, Sliding Window, Sliding Window Counter, Token Bucket or GCRA limiter.")
+	}
+	return r, nil
+}
+
+// AllowAll admits a request only if l allows every key, e.g. a user key and
+// its organisation's key. Each key is reserved in order by charging it the
+// cost set by WithContextCost, 1 by default; if a key is denied or errors,
+// the keys already reserved are refunded that cost, so a denied AllowAll
+// leaves every budget as it was. Concurrent callers may see a reservation
+// briefly before it is refunded.
+//
+// The Result is the denying key's, or on allow the one with the lowest
+// Remaining, with Components listing each key checked. CanRefund(l) must be
+// true; otherwise AllowAll returns an error wrapping ErrInvalidParameter
+// without charging anything. If a refund fails, its error is returned with
+// the denial.
+func AllowAll(ctx context.Context, l Limiter, keys ...string) (Result, error) {
+	r, err := refunder(l)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cost := CostFromContext(ctx)
+	combined := Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}
+	components := make([]ComponentResult, 0, len(keys))
+	for i, key := range keys {
+		res, err := l.AllowN(ctx, key, cost)
+		if err == nil {
+			components = append(components, ComponentResult{ID: key, Limit: res.Limit, Remaining: res.Remaining})
+		}
+		if err != nil || !res.Allowed {
+			if rerr := refundAll(ctx, r, keys[:i], cost); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+			res.Components = components
+			return res, err
+		}
+		if res.Remaining != Unlimited && (combined.Remaining == Unlimited || res.Remaining < combined.Remaining) {
+			combined = res
+		}
+	}
+	combined.Components = components
+	return combined, nil
+}
+
+// refundAll cancels the reservations of cost AllowAll made on keys.
+func refundAll(ctx context.Context, r Refunder, keys []string, cost int) error {
+	var errs []error
+	for _, key := range keys {
+		if err := r.Refund(ctx, key, cost); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
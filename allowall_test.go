@@ -0,0 +1,127 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowAll_DenialChargesNoKey(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	limiters := map[string]Limiter{
+		"fixed window":            must(NewFixedWindow(3, 60, WithClock(clock))),
+		"sliding window":          must(NewSlidingWindow(3, 60, WithClock(clock))),
+		"sliding window counter":  must(NewSlidingWindowCounter(3, 60, WithClock(clock))),
+		"token bucket":            must(NewTokenBucket(3, 1, WithClock(clock))),
+		"gcra":                    must(NewGCRA(1, 3, WithClock(clock))),
+		"OnLimitExceeded wrapper": must(NewFixedWindow(3, 60, WithClock(clock), WithOnLimitExceeded(func(context.Context, string, *Result) {}))),
+	}
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				res, err := l.Allow(ctx, "org")
+				require.NoError(t, err)
+				require.True(t, res.Allowed)
+			}
+
+			for i := 0; i < 5; i++ {
+				res, err := AllowAll(ctx, l, "user", "org")
+				require.NoError(t, err)
+				assert.False(t, res.Allowed)
+				assert.Equal(t, ReasonOverLimit, res.DenyReason)
+				require.Len(t, res.Components, 2)
+				assert.Equal(t, "org", res.Components[1].ID)
+			}
+
+			assert.Equal(t, 3, allowedCount(t, l, "user", 4), "denied AllowAll must leave user's budget untouched")
+		})
+	}
+}
+
+func TestAllowAll_ContextCost(t *testing.T) {
+	ctx := WithContextCost(context.Background(), 5)
+	l := must(NewFixedWindow(10, 60, WithClock(NewFakeClock())))
+	_, err := l.AllowN(context.Background(), "org", 8)
+	require.NoError(t, err)
+
+	res, err := AllowAll(ctx, l, "user", "org")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "org has 2 left, less than the context cost")
+
+	res, err = l.AllowN(context.Background(), "user", 10)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "the refund returned the whole context cost to user")
+
+	res, err = AllowAll(ctx, l, "other", "another")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, []ComponentResult{
+		{ID: "other", Limit: 10, Remaining: 5},
+		{ID: "another", Limit: 10, Remaining: 5},
+	}, res.Components, "each key is charged the context cost")
+}
+
+func TestAllowAll_ChargesEveryKeyOnAllow(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(3, 60, WithClock(NewFakeClock())))
+	_, err := l.Allow(ctx, "org")
+	require.NoError(t, err)
+
+	res, err := AllowAll(ctx, l, "user", "org")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining, "the tightest key is reported")
+	assert.Equal(t, []ComponentResult{
+		{ID: "user", Limit: 3, Remaining: 2},
+		{ID: "org", Limit: 3, Remaining: 1},
+	}, res.Components)
+
+	assert.Equal(t, 2, allowedCount(t, l, "user", 3))
+	assert.Equal(t, 1, allowedCount(t, l, "org", 2))
+}
+
+func TestAllowAll_Unsupported(t *testing.T) {
+	l := must(NewLeakyBucket(3, 1, Policing))
+	_, err := AllowAll(context.Background(), l, "user", "org")
+	require.ErrorIs(t, err, ErrInvalidParameter)
+
+	res, err := l.Allow(context.Background(), "user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Remaining, "nothing is charged when refunds are unsupported")
+}
+
+func TestAllowAll_WrappedUnsupported(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	l := must(NewFixedWindow(3, 60, WithRedis(client), WithDryRun(true)))
+
+	assert.False(t, CanRefund(l), "the dry run wrapper forwards to a Redis limiter")
+	_, err := AllowAll(context.Background(), l, "user", "org")
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+func TestCanRefund(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	tests := []struct {
+		name string
+		l    Limiter
+		want bool
+	}{
+		{"memory", must(NewTokenBucket(3, 1)), true},
+		{"memory wrapped", must(NewTokenBucket(3, 1, WithDryRun(true), WithKeyNormalizer(strings.ToLower))), true},
+		{"leaky bucket", must(NewLeakyBucket(3, 1, Policing)), false},
+		{"redis", must(NewGCRA(1, 3, WithRedis(client))), false},
+		{"redis wrapped", must(NewGCRA(1, 3, WithRedis(client), WithAllowList("admin"))), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CanRefund(tt.l))
+		})
+	}
+}
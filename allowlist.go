@@ -0,0 +1,109 @@
+package goratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// WithAllowList exempts keys from limiting, e.g. internal service accounts
+// and monitoring probes. Allow and AllowN admit a listed key at once with
+// Remaining equal to Limit, without touching backend state, so the exemption
+// holds for any caller of the limiter, not just HTTP middleware. Keys are
+// compared after WithKeyNormalizer. Combines with WithAllowListFunc: a key
+// is exempt if either matches. WithBlockList takes precedence. Ignored by
+// NewConcurrency.
+func WithAllowList(keys ...string) Option {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return WithAllowListFunc(func(_ context.Context, key string) bool {
+		_, ok := set[key]
+		return ok
+	})
+}
+
+// WithAllowListFunc exempts the keys for which fn returns true, as
+// WithAllowList does for a fixed set. fn runs on every Allow and AllowN
+// and must be safe for concurrent use.
+func WithAllowListFunc(fn func(ctx context.Context, key string) bool) Option {
+	return func(o *Options) {
+		if prev := o.AllowListFunc; prev != nil {
+			o.AllowListFunc = func(ctx context.Context, key string) bool {
+				return prev(ctx, key) || fn(ctx, key)
+			}
+			return
+		}
+		o.AllowListFunc = fn
+	}
+}
+
+// allowListLimiter admits the keys Options.AllowListFunc exempts without
+// consulting the inner limiter.
+type allowListLimiter struct {
+	inner  Limiter
+	exempt func(ctx context.Context, key string) bool
+	now    func() time.Time
+}
+
+func (l *allowListLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (l *allowListLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if l.exempt(ctx, key) {
+		d := Describe(l.inner)
+		return Result{Allowed: true, Remaining: d.Limit, Limit: d.Limit, Rate: d.Rate, Uncharged: true}, nil
+	}
+	return l.inner.AllowN(ctx, key, n)
+}
+
+func (l *allowListLimiter) Reset(ctx context.Context, key string) error {
+	return l.inner.Reset(ctx, key)
+}
+
+func (l *allowListLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	return ResetMany(ctx, l.inner, keys...)
+}
+
+func (l *allowListLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, l.inner, key)
+}
+
+func (l *allowListLimiter) Describe() Description {
+	return Describe(l.inner)
+}
+
+func (l *allowListLimiter) SetLimit(limit int64) error {
+	return SetLimit(l.inner, limit)
+}
+
+func (l *allowListLimiter) Inspect() []KeyState {
+	states, _ := Inspect(l.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (l *allowListLimiter) Unwrap() Limiter {
+	return l.inner
+}
+
+// Refund does nothing for an exempt key, which was never charged.
+func (l *allowListLimiter) Refund(ctx context.Context, key string, n int) error {
+	if l.exempt(ctx, key) {
+		return nil
+	}
+	return Refund(ctx, l.inner, key, n)
+}
+
+// ProjectN reports now for an exempt key, which is always allowed.
+func (l *allowListLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	if l.exempt(ctx, key) {
+		return l.now(), nil
+	}
+	return ProjectN(ctx, l.inner, key, n)
+}
+
+func (l *allowListLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	return Seed(ctx, l.inner, states)
+}
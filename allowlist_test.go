@@ -0,0 +1,74 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAllowList_NeverDeniesListedKeys(t *testing.T) {
+	ctx := context.Background()
+	builders := map[string]func(opts ...Option) (Limiter, error){
+		"fixed window": func(opts ...Option) (Limiter, error) { return NewFixedWindow(3, 60, opts...) },
+		"token bucket": func(opts ...Option) (Limiter, error) { return NewTokenBucket(3, 1, opts...) },
+		"gcra":         func(opts ...Option) (Limiter, error) { return NewGCRA(1, 3, opts...) },
+	}
+	for name, build := range builders {
+		t.Run(name, func(t *testing.T) {
+			l, err := build(WithAllowList("svc-monitor", "svc-billing"))
+			require.NoError(t, err)
+
+			for i := 0; i < 10; i++ {
+				res, err := l.AllowN(ctx, "svc-monitor", 2)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed, "request %d", i+1)
+				assert.Equal(t, ReasonNone, res.DenyReason)
+				assert.Equal(t, int64(3), res.Limit)
+				assert.Equal(t, res.Limit, res.Remaining)
+			}
+
+			for i := 0; i < 3; i++ {
+				res, err := l.Allow(ctx, "user")
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+			}
+			res, err := l.Allow(ctx, "user")
+			require.NoError(t, err)
+			assert.False(t, res.Allowed, "other keys are limited normally")
+
+			states, ok := Inspect(l)
+			require.True(t, ok)
+			for _, s := range states {
+				assert.NotEqual(t, "svc-monitor", s.Key, "an exempt key leaves no backend state")
+			}
+		})
+	}
+}
+
+func TestWithAllowListFunc_CombinesWithListAndNormalizer(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewFixedWindow(1, 60,
+		WithKeyNormalizer(LowerTrimNormalizer),
+		WithAllowList("svc-monitor"),
+		WithAllowListFunc(func(_ context.Context, key string) bool {
+			return strings.HasPrefix(key, "internal:")
+		}))
+	require.NoError(t, err)
+
+	for _, key := range []string{" SVC-Monitor", "internal:batch"} {
+		for i := 0; i < 3; i++ {
+			res, err := l.Allow(ctx, key)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "%q request %d", key, i+1)
+		}
+	}
+
+	_, err = l.Allow(ctx, "external")
+	require.NoError(t, err)
+	res, err := l.Allow(ctx, "external")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+}
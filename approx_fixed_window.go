@@ -0,0 +1,127 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// approxFixedWindow is a fixed window limiter that counts keys in a single
+// count-min sketch, cleared at each window boundary.
+type approxFixedWindow struct {
+	mu            sync.Mutex
+	sketch        *countMinSketch
+	windowSeconds int64
+	windowStart   time.Time
+	*baseLimit
+	opts *Options
+}
+
+// NewApproxFixedWindow creates a Fixed Window rate limiter that counts
+// requests in a count-min sketch of sketchDepth rows by sketchWidth columns
+// instead of per-key state, so memory stays at sketchWidth × sketchDepth × 8
+// bytes however many distinct keys are seen.
+//
+// Counts are approximate: keys that collide with heavier keys in every row
+// are over-counted, so a light key may occasionally be throttled early. A
+// key is never under-counted, so it is never allowed more than maxRequests
+// per window. Wider sketches reduce collisions; deeper ones make a collision
+// in every row less likely. Use it for DDoS-scale per-IP limiting where
+// exact per-key state would not fit in memory.
+//
+// This is an in-memory-only algorithm — WithRedis is ignored. Windows are
+// shared by all keys and start when the limiter is created.
+func NewApproxFixedWindow(maxRequests, windowSeconds int64, sketchWidth, sketchDepth int, opts ...Option) (Limiter, error) {
+	if maxRequests <= 0 || windowSeconds <= 0 {
+		return nil, validationErr("maxRequests and windowSeconds must be positive",
+			"Use positive integers, e.g. NewApproxFixedWindow(100, 60, 4096, 4).")
+	}
+	if sketchWidth <= 0 || sketchDepth <= 0 {
+		return nil, validationErr("sketchWidth and sketchDepth must be positive",
+			"Use e.g. a width of 4096 and a depth of 4 (128 KiB).")
+	}
+	if err := checkRateBounds(1, maxRequests); err != nil {
+		return nil, err
+	}
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewApproxFixedWindow", true, false); err != nil {
+		return nil, err
+	}
+	maxRequests = o.perShard(maxRequests)
+
+	return wrapOptions(&approxFixedWindow{
+		sketch:        newCountMinSketch(sketchWidth, sketchDepth),
+		windowSeconds: windowSeconds,
+		windowStart:   o.now(),
+		baseLimit:     newBaseLimit(maxRequests),
+		opts:          o,
+	}, o), nil
+}
+
+func (a *approxFixedWindow) Allow(ctx context.Context, key string) (Result, error) {
+	return a.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (a *approxFixedWindow) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, a.limit()); ok {
+		return res, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	maxReq, unlimited := a.opts.resolveLimit(ctx, key, a.limit())
+	if unlimited {
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
+	}
+
+	now := a.opts.now()
+	windowDuration := time.Duration(a.windowSeconds) * time.Second
+	if elapsed := now.Sub(a.windowStart); elapsed >= windowDuration {
+		a.windowStart = a.windowStart.Add(elapsed / windowDuration * windowDuration)
+		a.sketch.clear()
+	}
+	resetAt := a.windowStart.Add(windowDuration)
+
+	cost := int64(n)
+	if count := a.sketch.count(key); count+cost <= maxReq {
+		a.sketch.incrementBy(key, cost)
+		return Result{
+			Allowed:   true,
+			Remaining: max(0, maxReq-a.sketch.count(key)),
+			Limit:     maxReq,
+			ResetAt:   resetAt,
+		}, nil
+	}
+
+	return Result{
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      maxReq,
+		ResetAt:    resetAt,
+		RetryAfter: resetAt.Sub(now),
+	}, nil
+}
+
+// Reset is a no-op: the sketch cannot remove one key's count without
+// affecting the keys that share its cells. Counts clear at the next window.
+func (a *approxFixedWindow) Reset(_ context.Context, _ string) error {
+	return nil
+}
+
+// ResetMany is a no-op; see Reset.
+func (a *approxFixedWindow) ResetMany(_ context.Context, _ ...string) error {
+	return nil
+}
+
+// ResetExisted always reports false: the sketch holds no per-key state.
+func (a *approxFixedWindow) ResetExisted(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
+
+func (a *approxFixedWindow) Describe() Description {
+	return Description{Algorithm: "approx_fixed_window", Limit: a.limit(), Window: time.Duration(a.windowSeconds) * time.Second, Dynamic: a.opts.LimitFunc != nil}
+}
@@ -0,0 +1,217 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BalanceLimiter meters requests against a prepaid balance per key, e.g. API
+// credits, rather than a renewing window. Each allowed request of cost n
+// takes n from the key's balance, and a request that would take it below
+// zero is denied. Balances never refill on their own; AddBalance tops them up.
+//
+// A key that was never topped up has a balance of zero, and Reset drops a
+// key's balance back to zero.
+type BalanceLimiter interface {
+	Limiter
+
+	// AddBalance adds amount to key's balance and returns the new balance.
+	// amount must be positive.
+	AddBalance(ctx context.Context, key string, amount int64) (int64, error)
+}
+
+// NewBalance creates a limiter that charges requests to a prepaid balance
+// per key. An allowed result's Limit is the balance the request was checked
+// against and Remaining what is left after charging it; a denied one reports
+// the current balance as both, with no RetryAfter, since only a top-up
+// helps. AllowN with n <= 0 returns an error wrapping ErrInvalidParameter.
+// Pass WithRedis for distributed mode; omit for in-memory.
+//
+// Redis balances are never given a TTL, whatever WithNoExpire says, and
+// neither charges nor top-ups are retried under WithBackendRetry, since
+// either may have been applied before a network error.
+// WithLimitFunc and SetLimit do not apply, and as with NewConcurrency,
+// options that wrap a limiter, such as WithDryRun and OnLimitExceeded, are
+// not applied; WithStrictMode rejects them.
+func NewBalance(opts ...Option) (BalanceLimiter, error) {
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewBalance", false, true); err != nil {
+		return nil, err
+	}
+
+	if o.RedisClient != nil {
+		return &balanceRedis{redis: o.RedisClient, opts: o}, nil
+	}
+	return &balanceMemory{balances: make(map[string]int64), opts: o}, nil
+}
+
+// balanceResult describes a decision against balance, the key's balance
+// before the request.
+func balanceResult(allowed bool, balance, cost int64) Result {
+	if !allowed {
+		return Result{Allowed: false, DenyReason: ReasonOverLimit, Remaining: balance, Limit: balance}
+	}
+	return Result{Allowed: true, Remaining: balance - cost, Limit: balance}
+}
+
+func checkTopUp(amount int64) error {
+	if amount <= 0 {
+		return validationErr("amount must be positive",
+			"Top up with a positive amount, e.g. AddBalance(ctx, key, 1000).")
+	}
+	return nil
+}
+
+// ─── In-Memory ───────────────────────────────────────────────────────────────
+
+type balanceMemory struct {
+	mu       sync.Mutex
+	balances map[string]int64
+	opts     *Options
+}
+
+func (b *balanceMemory) Allow(ctx context.Context, key string) (Result, error) {
+	return b.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (b *balanceMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, 0); ok {
+		return res, nil
+	}
+	if err := nonPositiveCost(n); err != nil {
+		return Result{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	balance, cost := b.balances[key], int64(n)
+	if balance < cost {
+		return balanceResult(false, balance, cost), nil
+	}
+	b.balances[key] = balance - cost
+	return balanceResult(true, balance, cost), nil
+}
+
+func (b *balanceMemory) AddBalance(_ context.Context, key string, amount int64) (int64, error) {
+	if err := checkTopUp(amount); err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balances[key] += amount
+	return b.balances[key], nil
+}
+
+func (b *balanceMemory) Reset(_ context.Context, key string) error {
+	b.mu.Lock()
+	delete(b.balances, key)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *balanceMemory) ResetMany(_ context.Context, keys ...string) error {
+	b.mu.Lock()
+	for _, key := range keys {
+		delete(b.balances, key)
+	}
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *balanceMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	b.mu.Lock()
+	_, ok := b.balances[key]
+	delete(b.balances, key)
+	b.mu.Unlock()
+	return ok, nil
+}
+
+func (b *balanceMemory) Describe() Description {
+	return Description{Algorithm: "balance"}
+}
+
+// ─── Redis ────────────────────────────────────────────────────────────────────
+
+// balanceScript charges cost to the balance in key with DECRBY, unless that
+// would take it below zero, and returns { allowed, balance_before }. A cost
+// that is not positive is an error, since DECRBY would credit the key.
+var balanceScript = redis.NewScript(`
+local key = KEYS[1]
+local cost = tonumber(ARGV[1])
+
+if not cost or cost <= 0 then
+  return redis.error_reply('ERR cost must be positive')
+end
+
+local balance = tonumber(redis.call('GET', key) or '0')
+if balance < cost then
+  return { 0, balance }
+end
+redis.call('DECRBY', key, cost)
+return { 1, balance }
+`)
+
+type balanceRedis struct {
+	redis redis.UniversalClient
+	opts  *Options
+}
+
+func (b *balanceRedis) Allow(ctx context.Context, key string) (Result, error) {
+	return b.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+// AllowN is not retried under WithBackendRetry: the debit may have been
+// applied before a network error, and a retry would charge the key twice.
+func (b *balanceRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, 0); ok {
+		return res, nil
+	}
+	if err := nonPositiveCost(n); err != nil {
+		return Result{}, err
+	}
+	result, err := balanceScript.Run(ctx, b.redis, []string{b.opts.FormatKey(key)}, n).Int64Slice()
+	if err != nil {
+		if b.opts.FailOpen {
+			b.opts.failOpen(err)
+			return Result{Allowed: true, Remaining: 0, Limit: 0}, nil
+		}
+		return Result{Allowed: false, Remaining: 0, Limit: 0, DenyReason: ReasonBackendError}, redisErr(err, b.opts)
+	}
+	return balanceResult(result[0] == 1, result[1], int64(n)), nil
+}
+
+// AddBalance is not retried under WithBackendRetry: INCRBY may have been
+// applied before a network error, and a retry would credit the key twice.
+func (b *balanceRedis) AddBalance(ctx context.Context, key string, amount int64) (int64, error) {
+	if err := checkTopUp(amount); err != nil {
+		return 0, err
+	}
+	balance, err := b.redis.IncrBy(ctx, b.opts.FormatKey(key), amount).Result()
+	if err != nil {
+		return 0, redisErr(err, b.opts)
+	}
+	return balance, nil
+}
+
+func (b *balanceRedis) Reset(ctx context.Context, key string) error {
+	return b.redis.Del(ctx, b.opts.FormatKey(key)).Err()
+}
+
+func (b *balanceRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = b.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, b.redis, fullKeys)
+}
+
+func (b *balanceRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := b.redis.Del(ctx, b.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+func (b *balanceRedis) Describe() Description {
+	return Description{Algorithm: "balance"}
+}
@@ -0,0 +1,102 @@
+package goratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Check is one rate limit check in a BatchCheck.
+type Check struct {
+	Limiter Limiter
+	Key     string
+
+	// N is the cost charged to Key. Zero means the cost attached to the
+	// context, as Allow uses.
+	N int
+}
+
+// pipelinedLimiter is implemented by Redis limiters whose AllowN is a
+// single script call, so BatchCheck can pipeline it with others.
+type pipelinedLimiter interface {
+	redisClient() redis.UniversalClient
+	queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error)
+}
+
+// BatchCheck runs every check and returns their results in the order of
+// checks, e.g. a per-IP token bucket and a per-organisation fixed window for
+// one request. Unlike AllowAll, every check is charged independently: an
+// allowed check is not refunded when another is denied.
+//
+// Checks on Redis-backed Token Bucket, Leaky Bucket, GCRA, Fixed Window,
+// Sliding Window, Rolling Counter and Min Interval limiters that share a
+// Redis client are sent in one pipeline, so they cost a single round-trip
+// whatever their algorithms. Other checks, including those on limiters
+// wrapped by options such as WithDryRun or WithAllowList, run one by one
+// after the pipelines.
+//
+// Every result is non-nil, holding what AllowN returned even on error. The
+// error joins the errors of all failed checks, each naming its index and key.
+func BatchCheck(ctx context.Context, checks []Check) ([]*Result, error) {
+	finish := make([]func() (Result, error), len(checks))
+	pipes := make(map[redis.UniversalClient]redis.Pipeliner)
+	for i, c := range checks {
+		p, ok := c.Limiter.(pipelinedLimiter)
+		if !ok {
+			continue
+		}
+		client := p.redisClient()
+		pipe, ok := pipes[client]
+		if !ok {
+			pipe = client.Pipeline()
+			pipes[client] = pipe
+		}
+		finish[i] = p.queueAllowN(ctx, pipe, c.Key, checkCost(ctx, c))
+	}
+	for _, pipe := range pipes {
+		// Each check decodes its own command's error, applying its
+		// limiter's fail-open policy.
+		_, _ = pipe.Exec(ctx)
+	}
+
+	results := make([]*Result, len(checks))
+	var errs []error
+	for i, c := range checks {
+		var res Result
+		var err error
+		if finish[i] != nil {
+			res, err = finish[i]()
+		} else {
+			res, err = c.Limiter.AllowN(ctx, c.Key, checkCost(ctx, c))
+		}
+		results[i] = &res
+		if err != nil {
+			errs = append(errs, fmt.Errorf("check %d (key %q): %w", i, c.Key, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func checkCost(ctx context.Context, c Check) int {
+	if c.N == 0 {
+		return CostFromContext(ctx)
+	}
+	return c.N
+}
+
+// decided returns a queueAllowN result decided without Redis.
+func decided(res Result) func() (Result, error) {
+	return func() (Result, error) { return res, nil }
+}
+
+// runScript runs script on sc. Pipelines send the script body with EVAL,
+// since a NOSCRIPT reply to EVALSHA would only surface after Exec, too late
+// for Script.Run to fall back.
+func runScript(ctx context.Context, script *redis.Script, sc redis.Scripter, keys []string, args ...interface{}) *redis.Cmd {
+	if _, ok := sc.(redis.Pipeliner); ok {
+		return script.Eval(ctx, sc, keys, args...)
+	}
+	return script.Run(ctx, sc, keys, args...)
+}
@@ -3,10 +3,12 @@ package goratelimit
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // ─── Serial: allowed path ─────────────────────────────────────────────────────
@@ -36,7 +38,7 @@ func BenchmarkSlidingWindowCounter(b *testing.B) {
 }
 
 func BenchmarkTokenBucket(b *testing.B) {
-	l, err := NewTokenBucket(1<<62, 1<<62)
+	l, err := NewTokenBucket(MaxBurst, MaxRate)
 	if err != nil {
 		b.Fatalf("NewTokenBucket: %v", err)
 	}
@@ -60,7 +62,7 @@ func BenchmarkLeakyBucket_Shaping(b *testing.B) {
 }
 
 func BenchmarkGCRA(b *testing.B) {
-	l, err := NewGCRA(1<<62, 1<<62)
+	l, err := NewGCRA(MaxRate, MaxBurst)
 	if err != nil {
 		b.Fatalf("NewGCRA: %v", err)
 	}
@@ -80,7 +82,7 @@ func BenchmarkPreFilter(b *testing.B) {
 	if err != nil {
 		b.Fatalf("NewCMS: %v", err)
 	}
-	precise, err := NewGCRA(1<<62, 1<<62)
+	precise, err := NewGCRA(MaxRate, MaxBurst)
 	if err != nil {
 		b.Fatalf("NewGCRA: %v", err)
 	}
@@ -152,7 +154,7 @@ func BenchmarkSlidingWindowCounter_Parallel(b *testing.B) {
 }
 
 func BenchmarkTokenBucket_Parallel(b *testing.B) {
-	l, err := NewTokenBucket(1<<62, 1<<62)
+	l, err := NewTokenBucket(MaxBurst, MaxRate)
 	if err != nil {
 		b.Fatalf("NewTokenBucket: %v", err)
 	}
@@ -168,7 +170,7 @@ func BenchmarkLeakyBucket_Parallel(b *testing.B) {
 }
 
 func BenchmarkGCRA_Parallel(b *testing.B) {
-	l, err := NewGCRA(1<<62, 1<<62)
+	l, err := NewGCRA(MaxRate, MaxBurst)
 	if err != nil {
 		b.Fatalf("NewGCRA: %v", err)
 	}
@@ -188,7 +190,7 @@ func BenchmarkPreFilter_Parallel(b *testing.B) {
 	if err != nil {
 		b.Fatalf("NewCMS: %v", err)
 	}
-	precise, err := NewGCRA(1<<62, 1<<62)
+	precise, err := NewGCRA(MaxRate, MaxBurst)
 	if err != nil {
 		b.Fatalf("NewGCRA: %v", err)
 	}
@@ -254,7 +256,7 @@ func BenchmarkSlidingWindow_WindowSizes(b *testing.B) {
 func BenchmarkTokenBucket_AllowN(b *testing.B) {
 	for _, n := range []int{1, 5, 10} {
 		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
-			l, err := NewTokenBucket(1<<62, 1<<62)
+			l, err := NewTokenBucket(MaxBurst, MaxRate)
 			if err != nil {
 				b.Fatalf("NewTokenBucket: %v", err)
 			}
@@ -275,7 +277,7 @@ func BenchmarkPreFilter_CMSBlocks(b *testing.B) {
 	if err != nil {
 		b.Fatalf("NewCMS: %v", err)
 	}
-	precise, err := NewGCRA(1<<62, 1<<62)
+	precise, err := NewGCRA(MaxRate, MaxBurst)
 	if err != nil {
 		b.Fatalf("NewGCRA: %v", err)
 	}
@@ -386,6 +388,80 @@ func TestCorrectness_ExactAllowedCount(t *testing.T) {
 	}
 }
 
+// ─── Allocations ──────────────────────────────────────────────────────────────
+
+// Result is returned by value, so the in-memory hot path must not touch the
+// heap once a key's state exists. These guard against regressions such as
+// returning *Result or boxing through an interface.
+
+func BenchmarkTokenBucket_AllowAllocs(b *testing.B) {
+	l, err := NewTokenBucket(MaxBurst, MaxRate)
+	if err != nil {
+		b.Fatalf("NewTokenBucket: %v", err)
+	}
+	ctx := context.Background()
+	_, _ = l.Allow(ctx, "k") // create state outside the measured loop
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = l.Allow(ctx, "k")
+	}
+}
+
+func TestMemoryAllow_ZeroAllocs(t *testing.T) {
+	limiters := map[string]Limiter{
+		"fixed_window":           must(NewFixedWindow(1<<62, 3600)),
+		"sliding_window_counter": must(NewSlidingWindowCounter(1<<62, 3600)),
+		"token_bucket":           must(NewTokenBucket(MaxBurst, MaxRate)),
+		"leaky_bucket_policing":  must(NewLeakyBucket(1<<62, 1<<62, Policing)),
+		"leaky_bucket_shaping":   must(NewLeakyBucket(1<<62, 1<<62, Shaping)),
+		"gcra":                   must(NewGCRA(MaxRate, MaxBurst)),
+		"cms":                    must(NewCMS(1<<62, 3600, 0.01, 0.001)),
+	}
+	ctx := context.Background()
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			_, _ = l.Allow(ctx, "k")
+			allocs := testing.AllocsPerRun(1000, func() {
+				_, _ = l.Allow(ctx, "k")
+			})
+			if allocs != 0 {
+				t.Errorf("Allow allocated %.1f times per call, want 0", allocs)
+			}
+
+			var out Result
+			allocs = testing.AllocsPerRun(1000, func() {
+				_ = AllowInto(ctx, l, "k", &out)
+			})
+			if allocs != 0 {
+				t.Errorf("AllowInto allocated %.1f times per call, want 0", allocs)
+			}
+		})
+	}
+}
+
+func TestAllowInto_MatchesAllow(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	newLimiter := func() Limiter { return must(NewFixedWindow(2, 60, WithClock(clock))) }
+	viaAllow, viaInto := newLimiter(), newLimiter()
+
+	out := Result{Allowed: true, Remaining: 99, RetryAfter: time.Hour}
+	for i := 0; i < 3; i++ {
+		want, wantErr := viaAllow.Allow(ctx, "k")
+		err := AllowInto(ctx, viaInto, "k", &out)
+		if err != wantErr {
+			t.Fatalf("call %d: AllowInto error %v, Allow error %v", i+1, err, wantErr)
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("call %d: AllowInto filled %+v, Allow returned %+v", i+1, out, want)
+		}
+	}
+	if out.Allowed {
+		t.Error("the 3rd call should be denied")
+	}
+}
+
 // ─── Helpers ──────────────────────────────────────────────────────────────────
 
 func benchAllow(b *testing.B, l Limiter) {
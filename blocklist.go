@@ -0,0 +1,122 @@
+package goratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// WithBlockList hard-denies keys, e.g. known abusers. Allow and AllowN deny
+// a listed key at once with ReasonBlocked and the RetryAfter set by
+// WithBlockRetryAfter, without touching backend state. The block list is
+// checked before the allow list, so a key on both is blocked. Keys are
+// compared after WithKeyNormalizer. OnLimitExceeded is not called for
+// blocked keys. Combines with WithBlockListFunc: a key is blocked if either
+// matches. Ignored by NewConcurrency.
+func WithBlockList(keys ...string) Option {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return WithBlockListFunc(func(_ context.Context, key string) bool {
+		_, ok := set[key]
+		return ok
+	})
+}
+
+// WithBlockListFunc blocks the keys for which fn returns true, as
+// WithBlockList does for a fixed set. fn runs on every Allow and AllowN
+// and must be safe for concurrent use.
+func WithBlockListFunc(fn func(ctx context.Context, key string) bool) Option {
+	return func(o *Options) {
+		if prev := o.BlockListFunc; prev != nil {
+			o.BlockListFunc = func(ctx context.Context, key string) bool {
+				return prev(ctx, key) || fn(ctx, key)
+			}
+			return
+		}
+		o.BlockListFunc = fn
+	}
+}
+
+// WithBlockRetryAfter sets the RetryAfter reported to blocked keys, which
+// the HTTP middleware sends as Retry-After. Default: 0 (no Retry-After, as
+// the block does not lift on its own).
+func WithBlockRetryAfter(d time.Duration) Option {
+	return func(o *Options) { o.BlockRetryAfter = d }
+}
+
+// blockListLimiter denies the keys Options.BlockListFunc blocks without
+// consulting the inner limiter.
+type blockListLimiter struct {
+	inner Limiter
+	opts  *Options
+}
+
+func (l *blockListLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (l *blockListLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if !l.opts.BlockListFunc(ctx, key) {
+		return l.inner.AllowN(ctx, key, n)
+	}
+	d := Describe(l.inner)
+	res := Result{
+		Allowed:    false,
+		DenyReason: ReasonBlocked,
+		Remaining:  0,
+		Limit:      d.Limit,
+		Rate:       d.Rate,
+		RetryAfter: l.opts.BlockRetryAfter,
+	}
+	if res.RetryAfter > 0 {
+		res.ResetAt = l.opts.now().Add(res.RetryAfter)
+	}
+	return res, nil
+}
+
+func (l *blockListLimiter) Reset(ctx context.Context, key string) error {
+	return l.inner.Reset(ctx, key)
+}
+
+func (l *blockListLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	return ResetMany(ctx, l.inner, keys...)
+}
+
+func (l *blockListLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, l.inner, key)
+}
+
+func (l *blockListLimiter) Describe() Description {
+	return Describe(l.inner)
+}
+
+func (l *blockListLimiter) SetLimit(limit int64) error {
+	return SetLimit(l.inner, limit)
+}
+
+func (l *blockListLimiter) Inspect() []KeyState {
+	states, _ := Inspect(l.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (l *blockListLimiter) Unwrap() Limiter {
+	return l.inner
+}
+
+// Refund does nothing for a blocked key, which was never charged.
+func (l *blockListLimiter) Refund(ctx context.Context, key string, n int) error {
+	if l.opts.BlockListFunc(ctx, key) {
+		return nil
+	}
+	return Refund(ctx, l.inner, key, n)
+}
+
+func (l *blockListLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return ProjectN(ctx, l.inner, key, n)
+}
+
+func (l *blockListLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	return Seed(ctx, l.inner, states)
+}
@@ -0,0 +1,75 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBlockList_AlwaysDeniesListedKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClockAt(time.Unix(1_700_000_000, 0))
+	exceeded := 0
+	l, err := NewTokenBucket(5, 1,
+		WithClock(clock),
+		WithBlockList("abuser"),
+		WithBlockRetryAfter(time.Hour),
+		WithOnLimitExceeded(func(context.Context, string, *Result) { exceeded++ }))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "abuser")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, ReasonBlocked, res.DenyReason)
+		assert.Equal(t, time.Hour, res.RetryAfter)
+		assert.Equal(t, clock.Now().Add(time.Hour), res.ResetAt)
+		assert.Equal(t, int64(5), res.Limit)
+		assert.Equal(t, int64(0), res.Remaining)
+	}
+	assert.Zero(t, exceeded, "blocked keys are not reported as over the limit")
+
+	for i := 0; i < 5; i++ {
+		res, err := l.Allow(ctx, "user")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, int64(4-i), res.Remaining, "other keys follow the algorithm")
+	}
+	res, err := l.Allow(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonOverLimit, res.DenyReason)
+
+	states, ok := Inspect(l)
+	require.True(t, ok)
+	require.Len(t, states, 1, "a blocked key leaves no backend state")
+	assert.Equal(t, "user", states[0].Key)
+}
+
+func TestWithBlockList_TakesPrecedenceOverAllowList(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewFixedWindow(10, 60,
+		WithAllowList("svc", "svc-compromised"),
+		WithBlockList("svc-compromised"),
+		WithBlockListFunc(func(_ context.Context, key string) bool {
+			return strings.HasSuffix(key, ".tor")
+		}))
+	require.NoError(t, err)
+
+	res, err := l.Allow(ctx, "svc-compromised")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonBlocked, res.DenyReason)
+	assert.Zero(t, res.RetryAfter, "no RetryAfter by default")
+	assert.True(t, res.ResetAt.IsZero())
+
+	res, err = l.Allow(ctx, "exit.tor")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonBlocked, res.DenyReason)
+
+	res, err = l.Allow(ctx, "svc")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
@@ -2,6 +2,8 @@ package goratelimit
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -20,8 +22,81 @@ const (
 	algoLeakyBucket
 	algoGCRA
 	algoCMS
+
+	// numAlgorithms counts the algorithms above; keep it last. Every one of
+	// them needs an entry in algorithmSpecs.
+	numAlgorithms
 )
 
+// algorithmSpec wires an algorithm into Build and Summary.
+type algorithmSpec struct {
+	build    func(b *Builder) (Limiter, error)
+	describe func(b *Builder) Description
+}
+
+// algorithmSpecs holds the spec of every algorithm but algoNone.
+// TestBuilder_EveryAlgorithmWired fails for an algorithm missing here.
+var algorithmSpecs = map[algorithm]algorithmSpec{
+	algoFixedWindow: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewFixedWindow(b.maxRequests, b.windowSeconds, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "fixed_window", Limit: b.maxRequests, Window: seconds(b.windowSeconds)}
+		},
+	},
+	algoSlidingWindow: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewSlidingWindow(b.maxRequests, b.windowSeconds, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "sliding_window", Limit: b.maxRequests, Window: seconds(b.windowSeconds)}
+		},
+	},
+	algoSlidingWindowCounter: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewSlidingWindowCounter(b.maxRequests, b.windowSeconds, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "sliding_window_counter", Limit: b.maxRequests, Window: seconds(b.windowSeconds)}
+		},
+	},
+	algoTokenBucket: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewTokenBucket(b.tbCapacity, b.tbRefillRate, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "token_bucket", Limit: b.tbCapacity, Rate: b.tbRefillRate}
+		},
+	},
+	algoLeakyBucket: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewLeakyBucket(b.lbCapacity, b.lbLeakRate, b.lbMode, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "leaky_bucket", Limit: b.lbCapacity, Rate: b.lbLeakRate}
+		},
+	},
+	algoGCRA: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewGCRA(b.gcraRate, b.gcraBurst, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "gcra", Limit: b.gcraBurst, Rate: b.gcraRate}
+		},
+	},
+	algoCMS: {
+		build: func(b *Builder) (Limiter, error) {
+			return NewCMS(b.cmsLimit, b.cmsWindowSecs, b.cmsEpsilon, b.cmsDelta, b.opts...)
+		},
+		describe: func(b *Builder) Description {
+			return Description{Algorithm: "cms", Limit: b.cmsLimit, Window: seconds(b.cmsWindowSecs)}
+		},
+	},
+}
+
+func seconds(n int64) time.Duration { return time.Duration(n) * time.Second }
+
 // Builder provides a fluent API for constructing a Limiter.
 //
 //	limiter, err := goratelimit.NewBuilder().
@@ -37,6 +112,10 @@ type Builder struct {
 	maxRequests   int64
 	windowSeconds int64
 
+	// window as given to a window-based selector or CMS, before truncation
+	// to whole seconds
+	window time.Duration
+
 	// token bucket
 	tbCapacity   int64
 	tbRefillRate int64
@@ -70,6 +149,7 @@ func (b *Builder) FixedWindow(maxRequests int64, window time.Duration) *Builder
 	b.algo = algoFixedWindow
 	b.maxRequests = maxRequests
 	b.windowSeconds = int64(window.Seconds())
+	b.window = window
 	return b
 }
 
@@ -80,6 +160,7 @@ func (b *Builder) SlidingWindow(maxRequests int64, window time.Duration) *Builde
 	b.algo = algoSlidingWindow
 	b.maxRequests = maxRequests
 	b.windowSeconds = int64(window.Seconds())
+	b.window = window
 	return b
 }
 
@@ -90,6 +171,7 @@ func (b *Builder) SlidingWindowCounter(maxRequests int64, window time.Duration)
 	b.algo = algoSlidingWindowCounter
 	b.maxRequests = maxRequests
 	b.windowSeconds = int64(window.Seconds())
+	b.window = window
 	return b
 }
 
@@ -131,6 +213,7 @@ func (b *Builder) CMS(limit int64, window time.Duration, epsilon, delta float64)
 	b.algo = algoCMS
 	b.cmsLimit = limit
 	b.cmsWindowSecs = int64(window.Seconds())
+	b.window = window
 	b.cmsEpsilon = epsilon
 	b.cmsDelta = delta
 	return b
@@ -156,6 +239,19 @@ func (b *Builder) KeyPrefix(prefix string) *Builder {
 	return b
 }
 
+// PrefixVersion adds a version segment after the key prefix; see
+// WithPrefixVersion.
+func (b *Builder) PrefixVersion(v string) *Builder {
+	b.opts = append(b.opts, WithPrefixVersion(v))
+	return b
+}
+
+// KeySeparator sets the separator between the prefix, key and window suffix.
+func (b *Builder) KeySeparator(sep string) *Builder {
+	b.opts = append(b.opts, WithKeySeparator(sep))
+	return b
+}
+
 // HashTag enables Redis Cluster hash-tag wrapping on keys.
 func (b *Builder) HashTag() *Builder {
 	b.opts = append(b.opts, WithHashTag())
@@ -168,6 +264,13 @@ func (b *Builder) FailOpen(v bool) *Builder {
 	return b
 }
 
+// OnFailOpen sets a callback invoked with the backend error whenever a request
+// is allowed because the backend is unreachable.
+func (b *Builder) OnFailOpen(fn func(err error)) *Builder {
+	b.opts = append(b.opts, WithOnFailOpen(fn))
+	return b
+}
+
 // DryRun enables dry-run mode: never deny, log when a request would have been denied.
 func (b *Builder) DryRun(dryRun bool) *Builder {
 	b.opts = append(b.opts, WithDryRun(dryRun))
@@ -188,6 +291,24 @@ func (b *Builder) LimitFunc(fn func(ctx context.Context, key string) int64) *Bui
 	return b
 }
 
+// AllowList exempts keys from limiting; see WithAllowList.
+func (b *Builder) AllowList(keys ...string) *Builder {
+	b.opts = append(b.opts, WithAllowList(keys...))
+	return b
+}
+
+// BlockList hard-denies keys; see WithBlockList.
+func (b *Builder) BlockList(keys ...string) *Builder {
+	b.opts = append(b.opts, WithBlockList(keys...))
+	return b
+}
+
+// StrictMode rejects lossy configuration at Build; see WithStrictMode.
+func (b *Builder) StrictMode(strict bool) *Builder {
+	b.opts = append(b.opts, WithStrictMode(strict))
+	return b
+}
+
 // OnLimitExceeded sets a callback invoked when a request is denied due to rate limit.
 // Use for alerting, analytics, or logging. Not called on backend errors or when DryRun is true.
 func (b *Builder) OnLimitExceeded(fn func(ctx context.Context, key string, result *Result)) *Builder {
@@ -198,24 +319,76 @@ func (b *Builder) OnLimitExceeded(fn func(ctx context.Context, key string, resul
 // ─── Build ───────────────────────────────────────────────────────────────────
 
 // Build validates the configuration and returns the configured Limiter.
+// Setting both Redis and Store is an error wrapping ErrInvalidParameter,
+// rather than one backend silently winning. Windows are truncated to whole
+// seconds, or rejected in StrictMode.
 func (b *Builder) Build() (Limiter, error) {
-	switch b.algo {
-	case algoFixedWindow:
-		return NewFixedWindow(b.maxRequests, b.windowSeconds, b.opts...)
-	case algoSlidingWindow:
-		return NewSlidingWindow(b.maxRequests, b.windowSeconds, b.opts...)
-	case algoSlidingWindowCounter:
-		return NewSlidingWindowCounter(b.maxRequests, b.windowSeconds, b.opts...)
-	case algoTokenBucket:
-		return NewTokenBucket(b.tbCapacity, b.tbRefillRate, b.opts...)
-	case algoLeakyBucket:
-		return NewLeakyBucket(b.lbCapacity, b.lbLeakRate, b.lbMode, b.opts...)
-	case algoGCRA:
-		return NewGCRA(b.gcraRate, b.gcraBurst, b.opts...)
-	case algoCMS:
-		return NewCMS(b.cmsLimit, b.cmsWindowSecs, b.cmsEpsilon, b.cmsDelta, b.opts...)
-	default:
-		return nil, validationErr("no algorithm selected",
+	o := applyOptions(b.opts)
+	if o.Store != nil && o.RedisClient != nil {
+		return nil, validationErr("both Redis and Store are set",
+			"Configure a single backend: call Redis or Store, not both.")
+	}
+	if o.Strict && b.window%time.Second != 0 {
+		return nil, validationErr(fmt.Sprintf("window %s is not a whole number of seconds", b.window),
+			"Windows have one-second resolution: use e.g. 2*time.Second.")
+	}
+	if b.algo == algoNone {
+		return nil, algorithmErr("no algorithm selected",
 			"Call one of FixedWindow, SlidingWindow, SlidingWindowCounter, TokenBucket, LeakyBucket, GCRA, or CMS before Build().")
 	}
+	spec, ok := algorithmSpecs[b.algo]
+	if !ok {
+		return nil, algorithmErr(fmt.Sprintf("algorithm %d is not wired into Builder", b.algo),
+			"This is a bug in goratelimit: add the algorithm to algorithmSpecs.")
+	}
+	return spec.build(b)
+}
+
+// Summary returns a one-line description of the configured policy for
+// startup logs, e.g. "token bucket: 100 burst, 10/s refill, redis,
+// prefix=api". It reports the configuration as given, without validating
+// it; Build still does that.
+func (b *Builder) Summary() string {
+	d, ok := b.description()
+	if !ok {
+		return "no algorithm selected"
+	}
+	o := applyOptions(b.opts)
+	d.Dynamic = o.LimitFunc != nil
+	parts := []string{d.String()}
+	if b.algo == algoLeakyBucket {
+		parts = append(parts, string(b.lbMode))
+	}
+	switch {
+	case b.algo == algoCMS:
+		parts = append(parts, "memory")
+	case o.RedisClient != nil:
+		parts = append(parts, "redis")
+	case o.Store != nil:
+		parts = append(parts, "store")
+	default:
+		parts = append(parts, "memory")
+	}
+	if b.algo != algoCMS && (o.RedisClient != nil || o.Store != nil) {
+		parts = append(parts, "prefix="+o.prefix())
+		if o.HashTag {
+			parts = append(parts, "hash tag")
+		}
+		if !o.FailOpen {
+			parts = append(parts, "fail closed")
+		}
+	}
+	if o.DryRun {
+		parts = append(parts, "dry run")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// description returns the Description Build's limiter would report.
+func (b *Builder) description() (Description, bool) {
+	spec, ok := algorithmSpecs[b.algo]
+	if !ok {
+		return Description{}, false
+	}
+	return spec.describe(b), true
 }
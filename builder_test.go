@@ -5,13 +5,54 @@ import (
 	"testing"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/krishna-kudari/ratelimit/store/memory"
 )
 
 func TestBuilder_NoAlgorithm(t *testing.T) {
 	_, err := NewBuilder().Build()
 	require.Error(t, err, "expected error when no algorithm selected")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
+
+// TestBuilder_EveryAlgorithmWired catches an algorithm added to the enum but
+// not to algorithmSpecs, which Build would otherwise reject at runtime.
+func TestBuilder_EveryAlgorithmWired(t *testing.T) {
+	for algo := algoNone + 1; algo < numAlgorithms; algo++ {
+		spec, ok := algorithmSpecs[algo]
+		require.True(t, ok, "algorithm %d has no entry in algorithmSpecs", algo)
+
+		// Zero parameters reach the constructor, which rejects them.
+		_, err := (&Builder{algo: algo}).Build()
+		assert.ErrorIs(t, err, ErrInvalidParameter, "algorithm %d", algo)
+		assert.NotErrorIs(t, err, ErrUnknownAlgorithm, "algorithm %d", algo)
+
+		name := Algorithm(spec.describe(&Builder{}).Algorithm)
+		assert.Contains(t, algorithms, name, "algorithm %d should describe itself with a known name", algo)
+	}
+	assert.Len(t, algorithmSpecs, int(numAlgorithms-1), "algorithmSpecs should only hold enum values")
+
+	_, err := (&Builder{algo: numAlgorithms}).Build()
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+	assert.ErrorContains(t, err, "not wired into Builder")
+}
+
+func TestBuilder_RedisAndStoreConflict(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+
+	l, err := NewBuilder().
+		FixedWindow(10, time.Minute).
+		Redis(client).
+		Store(memory.New()).
+		Build()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Contains(t, err.Error(), "Redis and Store")
+	assert.Nil(t, l)
 }
 
 func TestBuilder_FixedWindow(t *testing.T) {
@@ -108,6 +149,7 @@ func TestBuilder_InvalidParams(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := tt.fn()
 			assert.Error(t, err, "expected error for invalid params")
+			assert.ErrorIs(t, err, ErrInvalidParameter)
 		})
 	}
 }
@@ -134,3 +176,32 @@ func TestBuilder_AlgorithmOverride(t *testing.T) {
 	res, _ := l.Allow(context.Background(), "k")
 	assert.Equal(t, int64(20), res.Limit)
 }
+
+func TestBuilder_Summary(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    string
+	}{
+		{"none", NewBuilder(), "no algorithm selected"},
+		{"fixed window", NewBuilder().FixedWindow(100, time.Minute), "fixed window: 100 per 60s, memory"},
+		{"sliding window", NewBuilder().SlidingWindow(50, 10*time.Second).DryRun(true), "sliding window: 50 per 10s, memory, dry run"},
+		{"sliding window counter", NewBuilder().SlidingWindowCounter(20, time.Hour).Redis(client).HashTag(),
+			"sliding window counter: 20 per 3600s, redis, prefix=ratelimit, hash tag"},
+		{"token bucket", NewBuilder().TokenBucket(100, 10).Redis(client).KeyPrefix("api"),
+			"token bucket: 100 burst, 10/s refill, redis, prefix=api"},
+		{"leaky bucket", NewBuilder().LeakyBucket(30, 5, Shaping).Store(memory.New()).FailOpen(false),
+			"leaky bucket: 30 capacity, 5/s leak, shaping, store, prefix=ratelimit, fail closed"},
+		{"gcra", NewBuilder().GCRA(10, 20).LimitFunc(func(context.Context, string) int64 { return 5 }),
+			"gcra: 10/s, 20 burst, dynamic limit, memory"},
+		{"cms", NewBuilder().CMS(1000, time.Minute, 0.01, 0.001).Redis(client), "cms: 1000 per 60s, memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.builder.Summary())
+		})
+	}
+}
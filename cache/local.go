@@ -26,10 +26,21 @@ import (
 type CacheOption func(*cacheConfig)
 
 type cacheConfig struct {
-	ttl     time.Duration
-	maxKeys int
+	ttl               time.Duration
+	maxKeys           int
+	backgroundRefresh bool
 }
 
+const (
+	// refreshMinHits is how many cache hits an entry needs since its last
+	// sync before it counts as hot enough to refresh in the background.
+	refreshMinHits = 2
+
+	// maxConcurrentRefreshes bounds in-flight background refreshes. Hits
+	// that would exceed it skip refreshing and sync on expiry as usual.
+	maxConcurrentRefreshes = 16
+)
+
 // WithTTL sets the cache entry TTL. After this duration, the next request
 // for that key will sync with the backend. Lower values = more accurate,
 // higher values = less Redis load. Default: 100ms.
@@ -43,6 +54,19 @@ func WithMaxKeys(maxKeys int) CacheOption {
 	return func(c *cacheConfig) { c.maxKeys = maxKeys }
 }
 
+// WithBackgroundRefresh re-syncs hot keys with the backend in a background
+// goroutine once an entry is past half its TTL, so requests for those keys
+// keep hitting a fresh entry instead of paying backend latency on the miss
+// after expiry. Only keys hit at least twice since their last sync are
+// refreshed, and at most 16 refreshes run at once.
+//
+// A refresh is charged to the backend as one request and credited to the
+// next local request for that key, so it does not consume extra quota
+// unless the key goes idle before that request arrives. Default: false.
+func WithBackgroundRefresh(enabled bool) CacheOption {
+	return func(c *cacheConfig) { c.backgroundRefresh = enabled }
+}
+
 // LocalCache is an L1 in-process cache that wraps any Limiter.
 // It implements goratelimit.Limiter so it can be used as a drop-in replacement.
 //
@@ -58,14 +82,21 @@ type LocalCache struct {
 	config  cacheConfig
 	mu      sync.Mutex
 	entries map[string]cacheEntry
+	resets  uint64 // bumped by every reset; see AllowN
 	closeCh chan struct{}
 	closed  bool
+	hits    uint64
+	misses  uint64
+
+	refreshSem chan struct{}
 }
 
 type cacheEntry struct {
-	result    goratelimit.Result
-	localUsed int64
-	fetchedAt time.Time
+	result     goratelimit.Result
+	localUsed  int64
+	fetchedAt  time.Time
+	hits       int
+	refreshing bool
 }
 
 // New wraps an existing Limiter with a local cache layer.
@@ -84,16 +115,21 @@ func New(inner goratelimit.Limiter, opts ...CacheOption) *LocalCache {
 		entries: make(map[string]cacheEntry),
 		closeCh: make(chan struct{}),
 	}
+	if cfg.backgroundRefresh {
+		lc.refreshSem = make(chan struct{}, maxConcurrentRefreshes)
+	}
 	go lc.evictionLoop()
 	return lc
 }
 
 // Allow checks whether a single request for key should be allowed.
 func (lc *LocalCache) Allow(ctx context.Context, key string) (goratelimit.Result, error) {
-	return lc.AllowN(ctx, key, 1)
+	return lc.AllowN(ctx, key, goratelimit.CostFromContext(ctx))
 }
 
-// AllowN checks whether n requests for key should be allowed.
+// AllowN checks whether n requests for key should be allowed. n requests
+// are served locally only if the cached quota covers all of them; otherwise
+// the backend decides, so a cached Remaining never goes negative.
 func (lc *LocalCache) AllowN(ctx context.Context, key string, n int) (goratelimit.Result, error) {
 	lc.mu.Lock()
 
@@ -101,6 +137,7 @@ func (lc *LocalCache) AllowN(ctx context.Context, key string, n int) (goratelimi
 	if ok && !lc.isExpired(&e) {
 		// Cached denial — don't hammer the backend
 		if !e.result.Allowed {
+			lc.hits++
 			lc.mu.Unlock()
 			return e.result, nil
 		}
@@ -114,6 +151,12 @@ func (lc *LocalCache) AllowN(ctx context.Context, key string, n int) (goratelimi
 				Remaining: e.result.Remaining - e.localUsed,
 				Limit:     e.result.Limit,
 				ResetAt:   e.result.ResetAt,
+				Rate:      e.result.Rate,
+			}
+			e.hits++
+			lc.hits++
+			if lc.shouldRefresh(&e) && lc.startRefresh(ctx, key, e.fetchedAt) {
+				e.refreshing = true
 			}
 			lc.entries[key] = e
 			lc.mu.Unlock()
@@ -121,6 +164,8 @@ func (lc *LocalCache) AllowN(ctx context.Context, key string, n int) (goratelimi
 		}
 		// Local quota exhausted — need to sync
 	}
+	lc.misses++
+	resets := lc.resets
 	lc.mu.Unlock()
 
 	// Cache miss, expired, or local quota exhausted → sync with backend
@@ -130,23 +175,67 @@ func (lc *LocalCache) AllowN(ctx context.Context, key string, n int) (goratelimi
 	}
 
 	lc.mu.Lock()
-	lc.entries[key] = cacheEntry{
-		result:    result,
-		localUsed: 0,
-		fetchedAt: time.Now(),
+	// A reset during the sync may have cleared the backend after it answered;
+	// caching that answer would resurrect the reset key's old state. A denied
+	// batch is not cached either: smaller requests may still fit, so they
+	// keep using the entry they had.
+	if lc.resets == resets && (result.Allowed || n <= 1) {
+		lc.entries[key] = cacheEntry{
+			result:    result,
+			localUsed: 0,
+			fetchedAt: time.Now(),
+		}
+		lc.evictIfOverCapacity()
 	}
-	lc.evictIfOverCapacity()
 	lc.mu.Unlock()
 
 	return result, nil
 }
 
-// Reset clears rate limit state for key in both cache and backend.
+// Reset clears rate limit state for key in both cache and backend. A backend
+// sync already in flight for any key is not cached, so it cannot restore
+// state from before the reset.
 func (lc *LocalCache) Reset(ctx context.Context, key string) error {
+	err := lc.inner.Reset(ctx, key)
+	lc.forget(key)
+	return err
+}
+
+// ResetMany clears rate limit state for all keys in both cache and backend.
+func (lc *LocalCache) ResetMany(ctx context.Context, keys ...string) error {
+	err := goratelimit.ResetMany(ctx, lc.inner, keys...)
+	lc.forget(keys...)
+	return err
+}
+
+// ResetExisted clears key in both cache and backend and reports whether the
+// backend held state for it.
+func (lc *LocalCache) ResetExisted(ctx context.Context, key string) (bool, error) {
+	existed, err := goratelimit.ResetExisted(ctx, lc.inner, key)
+	lc.forget(key)
+	return existed, err
+}
+
+// forget drops the cache entries for keys once the backend has been reset,
+// and stops syncs that started before the reset from caching their answers.
+func (lc *LocalCache) forget(keys ...string) {
 	lc.mu.Lock()
-	delete(lc.entries, key)
+	for _, key := range keys {
+		delete(lc.entries, key)
+	}
+	lc.resets++
 	lc.mu.Unlock()
-	return lc.inner.Reset(ctx, key)
+}
+
+// Describe forwards to the wrapped limiter.
+func (lc *LocalCache) Describe() goratelimit.Description {
+	return goratelimit.Describe(lc.inner)
+}
+
+// SetLimit forwards to the wrapped limiter. Cached decisions made under the
+// old limit are served until they expire.
+func (lc *LocalCache) SetLimit(limit int64) error {
+	return goratelimit.SetLimit(lc.inner, limit)
 }
 
 // Close stops the background eviction goroutine.
@@ -164,13 +253,20 @@ func (lc *LocalCache) Stats() CacheStats {
 	lc.mu.Lock()
 	defer lc.mu.Unlock()
 	return CacheStats{
-		Keys: len(lc.entries),
+		Keys:   len(lc.entries),
+		Hits:   lc.hits,
+		Misses: lc.misses,
 	}
 }
 
 // CacheStats holds cache statistics.
 type CacheStats struct {
 	Keys int
+
+	// Hits counts decisions served from the cache and Misses those that
+	// went to the wrapped limiter, since the cache was created.
+	Hits   uint64
+	Misses uint64
 }
 
 func (lc *LocalCache) isExpired(e *cacheEntry) bool {
@@ -185,6 +281,53 @@ func (lc *LocalCache) isExpired(e *cacheEntry) bool {
 	return time.Since(e.fetchedAt) >= ttl
 }
 
+// shouldRefresh reports whether a cached allow is hot and old enough to
+// refresh ahead of expiry.
+func (lc *LocalCache) shouldRefresh(e *cacheEntry) bool {
+	return lc.refreshSem != nil && !e.refreshing && e.hits >= refreshMinHits &&
+		time.Since(e.fetchedAt) >= lc.config.ttl/2
+}
+
+// startRefresh launches a background sync for key if a slot is free.
+// Called with lc.mu held.
+func (lc *LocalCache) startRefresh(ctx context.Context, key string, fetchedAt time.Time) bool {
+	select {
+	case lc.refreshSem <- struct{}{}:
+	default:
+		return false
+	}
+	// Keep request values (e.g. for LimitFunc) but not the request's deadline.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer func() { <-lc.refreshSem }()
+		ctx, cancel := context.WithTimeout(ctx, lc.config.ttl)
+		defer cancel()
+		result, err := lc.inner.AllowN(ctx, key, 1)
+
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+		e, ok := lc.entries[key]
+		// Drop the result if the entry was reset, evicted, or replaced by a
+		// synchronous sync while the refresh was in flight.
+		if !ok || !e.refreshing || !e.fetchedAt.Equal(fetchedAt) {
+			return
+		}
+		if err != nil {
+			e.refreshing = false
+			lc.entries[key] = e
+			return
+		}
+		next := cacheEntry{result: result, fetchedAt: time.Now()}
+		if result.Allowed {
+			// The refresh already charged one request; credit it to the
+			// next local request.
+			next.localUsed = -1
+		}
+		lc.entries[key] = next
+	}()
+	return true
+}
+
 func (lc *LocalCache) evictIfOverCapacity() {
 	if len(lc.entries) <= lc.config.maxKeys {
 		return
@@ -299,6 +299,47 @@ func TestLocalCache_Reset(t *testing.T) {
 	require.Equal(t, 2, mock.getCalls(), "expected 2 backend calls after reset")
 }
 
+func TestLocalCache_ResetDuringSyncIsNotUndone(t *testing.T) {
+	inSync := make(chan struct{})
+	release := make(chan struct{})
+	var blocked atomic.Bool
+	blocked.Store(true)
+	mock := &mockLimiter{
+		allowN: func(_ context.Context, _ string, _ int) (goratelimit.Result, error) {
+			if blocked.CompareAndSwap(true, false) {
+				close(inSync)
+				<-release
+			}
+			return goratelimit.Result{
+				Allowed:   true,
+				Remaining: 10,
+				Limit:     10,
+				ResetAt:   time.Now().Add(time.Minute),
+			}, nil
+		},
+	}
+
+	lc := New(mock, WithTTL(5*time.Second))
+	defer lc.Close()
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = lc.Allow(ctx, "k1")
+	}()
+
+	// Reset while the first sync is waiting on the backend.
+	<-inSync
+	require.NoError(t, lc.Reset(ctx, "k1"))
+	close(release)
+	<-done
+
+	assert.Equal(t, 0, lc.Stats().Keys, "the pre-reset answer should not be cached")
+	_, _ = lc.Allow(ctx, "k1")
+	assert.Equal(t, 2, mock.getCalls(), "the next request should sync with the reset backend")
+}
+
 func TestLocalCache_MultipleKeys(t *testing.T) {
 	mock := &mockLimiter{
 		allowN: func(_ context.Context, key string, _ int) (goratelimit.Result, error) {
@@ -420,4 +461,159 @@ func TestLocalCache_Stats(t *testing.T) {
 
 	stats = lc.Stats()
 	require.Equal(t, 2, stats.Keys, "expected 2 keys")
+	assert.Equal(t, uint64(2), stats.Misses, "first sight of each key goes to the backend")
+	assert.Equal(t, uint64(0), stats.Hits)
+
+	_, _ = lc.Allow(ctx, "k1")
+	stats = lc.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+}
+
+func slowBackend(latency time.Duration) *mockLimiter {
+	return &mockLimiter{
+		allowN: func(_ context.Context, _ string, _ int) (goratelimit.Result, error) {
+			time.Sleep(latency)
+			return goratelimit.Result{Allowed: true, Remaining: 1000, Limit: 1000}, nil
+		},
+	}
+}
+
+func TestLocalCache_BackgroundRefresh_HotKeyNeverWaits(t *testing.T) {
+	// Refreshes start at half the TTL, leaving 70ms of slack for a 30ms backend.
+	const latency = 30 * time.Millisecond
+	mock := slowBackend(latency)
+	lc := New(mock, WithTTL(200*time.Millisecond), WithBackgroundRefresh(true))
+	defer lc.Close()
+	ctx := context.Background()
+
+	// Warmup pays the backend latency once.
+	_, err := lc.Allow(ctx, "hot")
+	require.NoError(t, err)
+
+	var slowest time.Duration
+	deadline := time.Now().Add(800 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		r, err := lc.Allow(ctx, "hot")
+		require.NoError(t, err)
+		require.True(t, r.Allowed)
+		if d := time.Since(start); d > slowest {
+			slowest = d
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	assert.Less(t, slowest, latency, "hot key should never wait on the backend after warmup")
+	assert.Greater(t, mock.getCalls(), 3, "entry should have been refreshed across several TTLs")
+}
+
+func TestLocalCache_BackgroundRefresh_SkipsColdKeys(t *testing.T) {
+	mock := slowBackend(0)
+	lc := New(mock, WithTTL(50*time.Millisecond), WithBackgroundRefresh(true))
+	defer lc.Close()
+	ctx := context.Background()
+
+	_, err := lc.Allow(ctx, "cold")
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	_, err = lc.Allow(ctx, "cold") // one hit past half TTL: not hot yet
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, 1, mock.getCalls(), "cold key should not be refreshed in the background")
+}
+
+func TestLocalCache_BackgroundRefresh_CreditsPrechargedRequest(t *testing.T) {
+	var remaining atomic.Int64
+	remaining.Store(100)
+	mock := &mockLimiter{
+		allowN: func(_ context.Context, _ string, n int) (goratelimit.Result, error) {
+			return goratelimit.Result{Allowed: true, Remaining: remaining.Add(-int64(n)), Limit: 100}, nil
+		},
+	}
+	lc := New(mock, WithTTL(40*time.Millisecond), WithBackgroundRefresh(true))
+	defer lc.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := lc.Allow(ctx, "k")
+		require.NoError(t, err)
+	}
+	time.Sleep(25 * time.Millisecond)
+	_, err := lc.Allow(ctx, "k") // hot and past half TTL: refreshes
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+		return mock.getCalls() == 2 && !lc.entries["k"].refreshing
+	}, time.Second, time.Millisecond)
+
+	r, err := lc.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, remaining.Load(), r.Remaining, "first request after refresh uses the precharged unit")
+}
+
+// budgetBackend admits requests while its budget lasts and, like the
+// built-in algorithms, charges nothing for a denied one.
+func budgetBackend(budget int64) *mockLimiter {
+	var mu sync.Mutex
+	return &mockLimiter{
+		allowN: func(_ context.Context, _ string, n int) (goratelimit.Result, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if int64(n) > budget {
+				return goratelimit.Result{Allowed: false, Remaining: 0, Limit: 10, RetryAfter: time.Minute}, nil
+			}
+			budget -= int64(n)
+			return goratelimit.Result{Allowed: true, Remaining: budget, Limit: 10}, nil
+		},
+	}
+}
+
+func TestLocalCache_AllowN_ExceedingCachedRemaining_Syncs(t *testing.T) {
+	mock := budgetBackend(3)
+	lc := New(mock, WithTTL(time.Minute))
+	defer lc.Close()
+	ctx := context.Background()
+
+	r, err := lc.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.Remaining)
+
+	// 5 > cached remaining 2: not served locally, so the backend decides.
+	r, err = lc.AllowN(ctx, "k", 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, mock.getCalls(), "expected a backend sync")
+	assert.False(t, r.Allowed)
+	assert.Equal(t, int64(0), r.Remaining)
+
+	// The denied batch is not cached: a request that fits is still served
+	// locally.
+	r, err = lc.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, int64(1), r.Remaining)
+	assert.Equal(t, 2, mock.getCalls(), "expected a cache hit")
+
+	// 2 > local remaining 1: syncs rather than reporting -1.
+	r, err = lc.AllowN(ctx, "k", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, mock.getCalls(), "expected a backend sync")
+	assert.True(t, r.Allowed, "the backend still had 2")
+	assert.Equal(t, int64(0), r.Remaining)
+}
+
+func TestLocalCache_AllowN_RemainingNeverNegative(t *testing.T) {
+	mock := budgetBackend(1000)
+	lc := New(mock, WithTTL(time.Minute))
+	defer lc.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 500; i++ {
+		n := i%7 + 1
+		r, err := lc.AllowN(ctx, "k", n)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, r.Remaining, int64(0), "request %d with n=%d", i, n)
+	}
 }
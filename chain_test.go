@@ -0,0 +1,157 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chainUserKey struct{}
+
+func userFromContext(ctx context.Context, _ string) string {
+	user, _ := ctx.Value(chainUserKey{}).(string)
+	return user
+}
+
+func TestChain_MostConstrainingAndComponents(t *testing.T) {
+	perIP := must(NewFixedWindow(10, 60))
+	perUser := must(NewFixedWindow(3, 60))
+	l := NewChain(
+		ChainLink{ID: "ip", Limiter: perIP},
+		ChainLink{ID: "user", Limiter: perUser, Key: userFromContext},
+	)
+	ctx := context.WithValue(context.Background(), chainUserKey{}, "alice")
+
+	res, err := l.Allow(ctx, "1.2.3.4")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(2), res.Remaining, "top-level Remaining is the minimum")
+	assert.Equal(t, int64(3), res.Limit, "top-level Limit comes from the constraining link")
+	assert.Equal(t, []ComponentResult{
+		{ID: "ip", Limit: 10, Remaining: 9},
+		{ID: "user", Limit: 3, Remaining: 2},
+	}, res.Components)
+
+	// The user link received the derived key.
+	userRes, err := perUser.Allow(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userRes.Remaining)
+}
+
+func TestChain_DeniesWhenAnyLinkDenies(t *testing.T) {
+	perIP := must(NewFixedWindow(1, 60))
+	perUser := must(NewFixedWindow(10, 60))
+	l := NewChain(
+		ChainLink{ID: "ip", Limiter: perIP},
+		ChainLink{ID: "user", Limiter: perUser, Key: userFromContext},
+	)
+	ctx := context.WithValue(context.Background(), chainUserKey{}, "bob")
+
+	_, err := l.Allow(ctx, "1.2.3.4")
+	require.NoError(t, err)
+	res, err := l.Allow(ctx, "1.2.3.4")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, int64(0), res.Remaining)
+	assert.Positive(t, res.RetryAfter)
+	require.Len(t, res.Components, 1, "links after the denial are not checked")
+	assert.Equal(t, "ip", res.Components[0].ID)
+
+	userRes, err := perUser.Allow(context.Background(), "bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), userRes.Remaining, "user link charged only by the first request")
+}
+
+func TestChain_TopLevelTracksBindingLink(t *testing.T) {
+	clock := NewFakeClockAt(time.Unix(1_700_000_040, 0))
+	perIP := must(NewFixedWindow(5, 60, WithClock(clock)))
+	perUser := must(NewTokenBucket(8, 1, WithClock(clock)))
+	l := NewChain(
+		ChainLink{ID: "ip", Limiter: perIP},
+		ChainLink{ID: "user", Limiter: perUser, Key: userFromContext},
+	)
+	ctx := context.WithValue(context.Background(), chainUserKey{}, "dave")
+	allow := func() Result {
+		t.Helper()
+		res, err := l.Allow(ctx, "1.2.3.4")
+		require.NoError(t, err)
+		return res
+	}
+	spendUser := func(n int) {
+		t.Helper()
+		_, err := perUser.AllowN(context.Background(), "dave", n)
+		require.NoError(t, err)
+	}
+
+	res := allow()
+	assert.Equal(t, int64(5), res.Limit, "ip binds: 4 left of 5 vs 7 of 8")
+	assert.Equal(t, int64(4), res.Remaining)
+	assert.Zero(t, res.Rate)
+
+	// The user spends elsewhere, e.g. from another IP.
+	spendUser(5)
+	res = allow()
+	assert.Equal(t, int64(8), res.Limit, "user binds: 1 left of 8 vs 3 of 5")
+	assert.Equal(t, int64(1), res.Remaining)
+	assert.Equal(t, int64(1), res.Rate)
+	assert.Equal(t, []ComponentResult{
+		{ID: "ip", Limit: 5, Remaining: 3},
+		{ID: "user", Limit: 8, Remaining: 1},
+	}, res.Components)
+
+	require.NoError(t, perUser.Reset(context.Background(), "dave"))
+	res = allow()
+	assert.Equal(t, int64(5), res.Limit, "ip binds again once the user's budget is back")
+	assert.Equal(t, int64(2), res.Remaining)
+
+	spendUser(7)
+	res = allow()
+	assert.False(t, res.Allowed)
+	assert.Equal(t, ReasonOverLimit, res.DenyReason)
+	assert.Equal(t, int64(8), res.Limit, "the denying link binds")
+	assert.Equal(t, int64(0), res.Remaining)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, clock.Now().Add(time.Second), res.ResetAt)
+}
+
+func TestChain_TieGoesToEarlierLink(t *testing.T) {
+	clock := NewFakeClockAt(time.Unix(1_700_000_040, 0))
+	b := must(NewSlidingWindow(5, 60, WithClock(clock)))
+	l := NewChain(
+		ChainLink{ID: "a", Limiter: must(NewFixedWindow(4, 60, WithClock(clock)))},
+		ChainLink{ID: "b", Limiter: b},
+	)
+	_, err := b.Allow(context.Background(), "k")
+	require.NoError(t, err)
+
+	res, err := l.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Remaining, "both links have 3 left")
+	assert.Equal(t, int64(4), res.Limit)
+	assert.Equal(t, clock.Now().Add(time.Minute), res.ResetAt)
+}
+
+func TestChain_ResetAndDescribe(t *testing.T) {
+	perIP := must(NewFixedWindow(5, 60))
+	perUser := must(NewFixedWindow(2, 60))
+	l := NewChain(
+		ChainLink{ID: "ip", Limiter: perIP},
+		ChainLink{ID: "user", Limiter: perUser, Key: userFromContext},
+	)
+	ctx := context.WithValue(context.Background(), chainUserKey{}, "carol")
+
+	for i := 0; i < 2; i++ {
+		_, err := l.Allow(ctx, "1.2.3.4")
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Reset(ctx, "1.2.3.4"))
+	res, err := l.Allow(ctx, "1.2.3.4")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining)
+
+	assert.Equal(t, Description{Algorithm: "chain", Limit: 2}, Describe(l))
+}
@@ -47,6 +47,13 @@ func (c *countMinSketch) incrementBy(key string, n int64) {
 	}
 }
 
+// clear zeroes every cell in place.
+func (c *countMinSketch) clear() {
+	for _, row := range c.grid {
+		clear(row)
+	}
+}
+
 func (c *countMinSketch) count(key string) int64 {
 	min := int64(math.MaxInt64)
 	w := uint32(c.width)
@@ -67,10 +74,10 @@ type cmsLimiter struct {
 	previous      *countMinSketch
 	windowSeconds int64
 	windowStart   time.Time
-	limit         int64
-	width         int
-	depth         int
-	opts          *Options
+	*baseLimit
+	width int
+	depth int
+	opts  *Options
 }
 
 // NewCMS creates a Count-Min Sketch rate limiter that uses fixed memory
@@ -103,6 +110,10 @@ func NewCMS(limit, windowSeconds int64, epsilon, delta float64, opts ...Option)
 	}
 
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewCMS", true, false); err != nil {
+		return nil, err
+	}
+	limit = o.perShard(limit)
 	width := int(math.Ceil(math.E / epsilon))
 	depth := int(math.Ceil(math.Log(1 / delta)))
 
@@ -111,7 +122,7 @@ func NewCMS(limit, windowSeconds int64, epsilon, delta float64, opts ...Option)
 		previous:      newCountMinSketch(width, depth),
 		windowSeconds: windowSeconds,
 		windowStart:   o.now(),
-		limit:         limit,
+		baseLimit:     newBaseLimit(limit),
 		width:         width,
 		depth:         depth,
 		opts:          o,
@@ -128,16 +139,19 @@ func CMSMemoryBytes(epsilon, delta float64) int {
 }
 
 func (r *cmsLimiter) Allow(ctx context.Context, key string) (Result, error) {
-	return r.AllowN(ctx, key, 1)
+	return r.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (r *cmsLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, r.limit()); ok {
+		return res, nil
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	limit, unlimited := r.opts.resolveLimit(ctx, key, r.limit)
+	limit, unlimited := r.opts.resolveLimit(ctx, key, r.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
 	}
 	now := r.opts.now()
 	windowDuration := time.Duration(r.windowSeconds) * time.Second
@@ -180,6 +194,7 @@ func (r *cmsLimiter) AllowN(ctx context.Context, key string, n int) (Result, err
 	}
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      limit,
 		RetryAfter: retryAfter,
@@ -192,3 +207,17 @@ func (r *cmsLimiter) AllowN(ctx context.Context, key string, n int) (Result, err
 func (r *cmsLimiter) Reset(_ context.Context, _ string) error {
 	return nil
 }
+
+// ResetMany is a no-op for CMS; see Reset.
+func (r *cmsLimiter) ResetMany(_ context.Context, _ ...string) error {
+	return nil
+}
+
+// ResetExisted always reports false: the sketch holds no per-key state.
+func (r *cmsLimiter) ResetExisted(_ context.Context, _ string) (bool, error) {
+	return false, nil
+}
+
+func (r *cmsLimiter) Describe() Description {
+	return Description{Algorithm: "cms", Limit: r.limit(), Window: time.Duration(r.windowSeconds) * time.Second, Dynamic: r.opts.LimitFunc != nil}
+}
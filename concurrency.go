@@ -0,0 +1,382 @@
+package goratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ConcurrencyLimiter caps the number of in-flight requests per key rather
+// than the request rate. Each admitted request holds a lease until it is
+// released or the lease expires, so a client that crashes without calling
+// Release cannot hold its slot forever.
+//
+// Allow and AllowN acquire anonymous leases that are only freed by expiry
+// (or Reset). Use Acquire and Release to free a slot as soon as work completes.
+type ConcurrencyLimiter interface {
+	Limiter
+
+	// Acquire takes one slot for key. When the result is allowed, the returned
+	// Lease must be passed to Release once the work completes.
+	Acquire(ctx context.Context, key string) (Result, *Lease, error)
+
+	// Release frees the slot held by lease. Releasing an expired or
+	// already-released lease is a no-op.
+	Release(ctx context.Context, lease *Lease) error
+}
+
+// Lease is a concurrency slot held by an admitted request.
+type Lease struct {
+	Key       string
+	ID        string
+	ExpiresAt time.Time
+}
+
+// NewConcurrency creates a concurrency limiter that allows at most
+// maxInFlight concurrent leases per key. leaseTTL bounds how long an
+// unreleased lease holds its slot. AllowN with n <= 0 returns an error
+// wrapping ErrInvalidParameter.
+// Pass WithRedis for distributed mode; omit for in-memory.
+//
+// Options that wrap a limiter (WithKeyShards, WithHotKeyDetector,
+// WithOnLimitExceeded, WithDryRun, WithAllowList, WithBlockList and
+// WithKeyNormalizer) are not applied, since the wrappers would hide Acquire
+// and Release; WithStrictMode rejects them.
+func NewConcurrency(maxInFlight int64, leaseTTL time.Duration, opts ...Option) (ConcurrencyLimiter, error) {
+	if maxInFlight <= 0 || leaseTTL <= 0 {
+		return nil, validationErr("maxInFlight and leaseTTL must be positive",
+			"Use a positive limit and lease duration, e.g. NewConcurrency(10, 30*time.Second).")
+	}
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewConcurrency", false, true); err != nil {
+		return nil, err
+	}
+
+	if o.RedisClient != nil {
+		return &concurrencyRedis{
+			redis:     o.RedisClient,
+			baseLimit: newBaseLimit(maxInFlight),
+			leaseTTL:  leaseTTL,
+			opts:      o,
+		}, nil
+	}
+	return &concurrencyMemory{
+		states:    make(map[string]map[string]time.Time),
+		baseLimit: newBaseLimit(maxInFlight),
+		leaseTTL:  leaseTTL,
+		opts:      o,
+	}, nil
+}
+
+func newLeaseID(now time.Time) string {
+	return fmt.Sprintf("%d:%d", now.UnixNano(), rand.Int63())
+}
+
+// ─── In-Memory ───────────────────────────────────────────────────────────────
+
+type concurrencyMemory struct {
+	mu     sync.Mutex
+	states map[string]map[string]time.Time // key -> lease ID -> expiry
+	*baseLimit
+	leaseTTL  time.Duration
+	opts      *Options
+	lastSweep time.Time
+}
+
+func (c *concurrencyMemory) Allow(ctx context.Context, key string) (Result, error) {
+	return c.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (c *concurrencyMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	result, _, err := c.acquire(ctx, key, n)
+	return result, err
+}
+
+func (c *concurrencyMemory) Acquire(ctx context.Context, key string) (Result, *Lease, error) {
+	return c.acquire(ctx, key, 1)
+}
+
+func (c *concurrencyMemory) acquire(ctx context.Context, key string, n int) (Result, *Lease, error) {
+	if res, ok := forcedResult(ctx, c.limit()); ok {
+		return res, nil, nil
+	}
+	if err := nonPositiveCost(n); err != nil {
+		return Result{}, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit, unlimited := c.opts.resolveLimit(ctx, key, c.limit())
+	if unlimited {
+		return unlimitedResult(), nil, nil
+	}
+
+	now := c.opts.now()
+	c.sweep(now)
+	// Trim expired leases so crashed holders free their slots.
+	leases := c.states[key]
+	earliest := trimLeases(leases, now)
+
+	inFlight := int64(len(leases))
+	cost := int64(n)
+	if inFlight+cost <= limit {
+		if leases == nil {
+			leases = make(map[string]time.Time)
+			c.states[key] = leases
+		}
+		id := newLeaseID(now)
+		expiresAt := now.Add(c.leaseTTL)
+		if n == 1 {
+			leases[id] = expiresAt
+		} else {
+			for i := 0; i < n; i++ {
+				leases[id+":"+strconv.Itoa(i)] = expiresAt
+			}
+		}
+		return Result{
+			Allowed:   true,
+			Remaining: limit - inFlight - cost,
+			Limit:     limit,
+		}, &Lease{Key: key, ID: id, ExpiresAt: expiresAt}, nil
+	}
+
+	if len(leases) == 0 {
+		delete(c.states, key)
+	}
+	var retryAfter time.Duration
+	if !earliest.IsZero() {
+		retryAfter = earliest.Sub(now)
+	}
+	return Result{
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      limit,
+		ResetAt:    earliest,
+		RetryAfter: retryAfter,
+	}, nil, nil
+}
+
+// trimLeases deletes the leases expired at now and returns the earliest
+// expiry of those left, or zero if none are.
+func trimLeases(leases map[string]time.Time, now time.Time) time.Time {
+	var earliest time.Time
+	for id, exp := range leases {
+		if !exp.After(now) {
+			delete(leases, id)
+			continue
+		}
+		if earliest.IsZero() || exp.Before(earliest) {
+			earliest = exp
+		}
+	}
+	return earliest
+}
+
+// sweep trims every key's leases and drops the keys left without any, at
+// most once per leaseTTL, so keys that are no longer requested, or whose
+// anonymous leases from Allow are never released, do not hold memory.
+// Called with c.mu held.
+func (c *concurrencyMemory) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.leaseTTL {
+		return
+	}
+	c.lastSweep = now
+	for key, leases := range c.states {
+		trimLeases(leases, now)
+		if len(leases) == 0 {
+			delete(c.states, key)
+		}
+	}
+}
+
+func (c *concurrencyMemory) Release(_ context.Context, lease *Lease) error {
+	if lease == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if leases, ok := c.states[lease.Key]; ok {
+		delete(leases, lease.ID)
+		if len(leases) == 0 {
+			delete(c.states, lease.Key)
+		}
+	}
+	return nil
+}
+
+func (c *concurrencyMemory) Reset(_ context.Context, key string) error {
+	c.mu.Lock()
+	delete(c.states, key)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *concurrencyMemory) ResetMany(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.states, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *concurrencyMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	_, ok := c.states[key]
+	delete(c.states, key)
+	c.mu.Unlock()
+	return ok, nil
+}
+
+func (c *concurrencyMemory) Describe() Description {
+	return Description{Algorithm: "concurrency", Limit: c.limit(), Dynamic: c.opts.LimitFunc != nil}
+}
+
+// ─── Redis ────────────────────────────────────────────────────────────────────
+
+// concurrencyScript stores leases in a ZSET scored by their expiry time (ms).
+// Expired leases are trimmed before counting, so the admission decision and
+// the insert happen atomically.
+var concurrencyScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+local lease_ms = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local id = ARGV[5]
+local expire = tonumber(ARGV[6])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+local in_flight = redis.call('ZCARD', key)
+
+if in_flight + cost <= limit then
+  local expires = now + lease_ms
+  if cost == 1 then
+    redis.call('ZADD', key, expires, id)
+  else
+    for i = 0, cost - 1 do
+      redis.call('ZADD', key, expires, id .. ':' .. i)
+    end
+  end
+  if expire > 0 then
+    redis.call('PEXPIRE', key, math.ceil(lease_ms * expire))
+  end
+  return { 1, limit - in_flight - cost, 0 }
+end
+
+local retry_ms = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if #oldest > 0 then
+  retry_ms = tonumber(oldest[2]) - now
+end
+return { 0, 0, retry_ms }
+`)
+
+type concurrencyRedis struct {
+	redis redis.UniversalClient
+	*baseLimit
+	leaseTTL time.Duration
+	opts     *Options
+}
+
+func (c *concurrencyRedis) Allow(ctx context.Context, key string) (Result, error) {
+	return c.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (c *concurrencyRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	result, _, err := c.acquire(ctx, key, n)
+	return result, err
+}
+
+func (c *concurrencyRedis) Acquire(ctx context.Context, key string) (Result, *Lease, error) {
+	return c.acquire(ctx, key, 1)
+}
+
+func (c *concurrencyRedis) acquire(ctx context.Context, key string, n int) (Result, *Lease, error) {
+	if res, ok := forcedResult(ctx, c.limit()); ok {
+		return res, nil, nil
+	}
+	if err := nonPositiveCost(n); err != nil {
+		return Result{}, nil, err
+	}
+	limit, unlimited := c.opts.resolveLimit(ctx, key, c.limit())
+	if unlimited {
+		return unlimitedResult(), nil, nil
+	}
+	fullKey := c.opts.FormatKey(key)
+	now := c.opts.now()
+	id := newLeaseID(now)
+
+	keys := []string{fullKey}
+	args := []interface{}{
+		limit,
+		now.UnixMilli(),
+		c.leaseTTL.Milliseconds(),
+		n,
+		id,
+		c.opts.expireArg(),
+	}
+	cmd := concurrencyScript.Run(ctx, c.redis, keys, args...)
+	result, err := c.opts.scriptResult(ctx, cmd, c.redis, concurrencyScript, keys, args)
+	if err != nil {
+		if c.opts.FailOpen {
+			c.opts.failOpen(err)
+			return Result{Allowed: true, Remaining: limit - 1, Limit: limit}, nil, nil
+		}
+		return Result{Allowed: false, Remaining: 0, Limit: limit, DenyReason: ReasonBackendError}, nil, redisErr(err, c.opts)
+	}
+
+	if result[0] == 1 {
+		return Result{
+			Allowed:   true,
+			Remaining: result[1],
+			Limit:     limit,
+		}, &Lease{Key: key, ID: id, ExpiresAt: now.Add(c.leaseTTL)}, nil
+	}
+
+	retryAfter := time.Duration(result[2]) * time.Millisecond
+	return Result{
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      limit,
+		ResetAt:    now.Add(retryAfter),
+		RetryAfter: retryAfter,
+	}, nil, nil
+}
+
+func (c *concurrencyRedis) Release(ctx context.Context, lease *Lease) error {
+	if lease == nil {
+		return nil
+	}
+	fullKey := c.opts.FormatKey(lease.Key)
+	return c.redis.ZRem(ctx, fullKey, lease.ID).Err()
+}
+
+func (c *concurrencyRedis) Reset(ctx context.Context, key string) error {
+	fullKey := c.opts.FormatKey(key)
+	return c.redis.Del(ctx, fullKey).Err()
+}
+
+func (c *concurrencyRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = c.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, c.redis, fullKeys)
+}
+
+func (c *concurrencyRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := c.redis.Del(ctx, c.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+func (c *concurrencyRedis) Describe() Description {
+	return Description{Algorithm: "concurrency", Limit: c.limit(), Dynamic: c.opts.LimitFunc != nil}
+}
@@ -0,0 +1,62 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func concurrencyKeys(c *concurrencyMemory) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.states)
+}
+
+func TestConcurrencyMemory_DropsEmptyKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	l, err := NewConcurrency(1, time.Minute, WithClock(clock))
+	require.NoError(t, err)
+	c := l.(*concurrencyMemory)
+
+	// Allow takes an anonymous lease that is never released.
+	res, err := c.Allow(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 1, concurrencyKeys(c))
+
+	// A denial that trims the key's last lease drops the key.
+	clock.Advance(time.Minute)
+	res, err = c.AllowN(ctx, "a", 2)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, concurrencyKeys(c), "a denial leaves no empty lease map")
+
+	// Keys that are never requested again are swept once their leases expire.
+	for _, key := range []string{"b", "c", "d"} {
+		res, err := c.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	require.Equal(t, 3, concurrencyKeys(c))
+	clock.Advance(time.Minute)
+	res, err = c.Allow(ctx, "e")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	assert.Equal(t, 1, concurrencyKeys(c), "only the key with a live lease is kept")
+}
+
+func TestConcurrencyMemory_NonPositiveCost(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewConcurrency(2, time.Minute)
+	require.NoError(t, err)
+
+	for _, n := range []int{0, -1} {
+		_, err := l.AllowN(ctx, "k", n)
+		assert.ErrorIs(t, err, ErrInvalidParameter, "n=%d", n)
+	}
+	assert.Equal(t, 0, concurrencyKeys(l.(*concurrencyMemory)))
+}
@@ -0,0 +1,38 @@
+package goratelimit
+
+import "context"
+
+type contextCostKey struct{}
+
+type contextBypassKey struct{}
+
+// WithContextCost returns a copy of ctx that makes Allow charge n units, as
+// AllowN(ctx, key, n) would, so a deep call site can mark a request as
+// expensive without threading a cost through its callers. AllowN ignores it
+// and charges the n it is given. n below 1 is ignored.
+func WithContextCost(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, contextCostKey{}, n)
+}
+
+// CostFromContext returns the cost set by WithContextCost, or 1. Limiter
+// implementations outside this package call it from Allow to honor
+// WithContextCost.
+func CostFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(contextCostKey{}).(int); ok && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// WithContextBypass returns a copy of ctx for which Allow and AllowN admit
+// every request without touching limiter state, as under ForceAllow but for
+// a single call. It takes precedence over SetMode; the block list still
+// applies.
+func WithContextBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextBypassKey{}, true)
+}
+
+func contextBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(contextBypassKey{}).(bool)
+	return bypass
+}
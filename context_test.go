@@ -0,0 +1,74 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithContextCost_HonoredByAllow(t *testing.T) {
+	ctx := context.Background()
+	limiters := map[string]Limiter{
+		"fixed window":   must(NewFixedWindow(5, 60)),
+		"token bucket":   must(NewTokenBucket(5, 1)),
+		"wrapped":        must(NewGCRA(1, 5, WithKeyNormalizer(LowerTrimNormalizer), WithDryRun(true))),
+		"std rate keyed": FromStdRatePerKey(1, 5),
+	}
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			res, err := l.Allow(WithContextCost(ctx, 3), "k")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, int64(2), res.Remaining, "Allow charged the context cost")
+
+			res, err = l.Allow(WithContextCost(ctx, 3), "k")
+			require.NoError(t, err)
+			if name != "wrapped" { // dry run never denies
+				assert.False(t, res.Allowed, "3 more do not fit in the 2 left")
+			}
+		})
+	}
+}
+
+func TestWithContextCost_IgnoredByAllowNAndBelowOne(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(5, 60))
+
+	res, err := l.AllowN(WithContextCost(ctx, 4), "k", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.Remaining, "AllowN charges its own n")
+
+	res, err = l.Allow(WithContextCost(ctx, 0), "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Remaining, "a cost below 1 falls back to 1")
+	assert.Equal(t, 1, CostFromContext(ctx))
+}
+
+func TestWithContextBypass(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(1, 60, WithBlockList("abuser")))
+	bypass := WithContextBypass(ctx)
+
+	for i := 0; i < 5; i++ {
+		res, err := l.AllowN(bypass, "k", 1)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, int64(1), res.Remaining)
+		assert.Equal(t, int64(1), res.Limit)
+	}
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "bypassed calls left the key untouched")
+
+	SetMode(ForceDeny)
+	defer SetMode(Normal)
+	res, err = l.Allow(bypass, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "a bypass takes precedence over the mode")
+
+	res, err = l.Allow(bypass, "abuser")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonBlocked, res.DenyReason, "the block list still applies")
+}
@@ -3,6 +3,7 @@ package goratelimit
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -60,3 +61,65 @@ func TestCorrectness_NeverFlaps(t *testing.T) {
 		t.Run(fmt.Sprintf("run_%d", i), testCorrectnessExactlyN)
 	}
 }
+
+// TestCorrectness_AllowNMatchesSequentialAllow checks that AllowN(key, n)
+// leaves a key in the same state as n sequential Allow(key) calls, from an
+// unused key and from one with earlier usage.
+func TestCorrectness_AllowNMatchesSequentialAllow(t *testing.T) {
+	const limit = 10
+	constructors := map[string]func(opts ...Option) (Limiter, error){
+		"fixed_window": func(opts ...Option) (Limiter, error) {
+			return NewFixedWindow(limit, 60, opts...)
+		},
+		"sliding_window": func(opts ...Option) (Limiter, error) {
+			return NewSlidingWindow(limit, 60, opts...)
+		},
+		"sliding_window_counter": func(opts ...Option) (Limiter, error) {
+			return NewSlidingWindowCounter(limit, 60, opts...)
+		},
+		"token_bucket": func(opts ...Option) (Limiter, error) {
+			return NewTokenBucket(limit, 1, opts...)
+		},
+		"leaky_bucket": func(opts ...Option) (Limiter, error) {
+			return NewLeakyBucket(limit, 1, Policing, opts...)
+		},
+		"gcra": func(opts ...Option) (Limiter, error) {
+			return NewGCRA(1, limit, opts...)
+		},
+	}
+
+	ctx := context.Background()
+	for name, newLimiter := range constructors {
+		for _, tc := range []struct{ used, n int }{{0, 1}, {0, 3}, {0, limit}, {4, 1}, {4, 3}, {4, limit - 4}} {
+			used, n := tc.used, tc.n
+			t.Run(fmt.Sprintf("%s/used=%d/n=%d", name, used, n), func(t *testing.T) {
+				l := must(newLimiter(WithClock(NewFakeClock())))
+				for i := 0; i < used; i++ {
+					_, _ = l.Allow(ctx, "batch")
+					_, _ = l.Allow(ctx, "single")
+				}
+
+				batch, err := l.AllowN(ctx, "batch", n)
+				if err != nil {
+					t.Fatalf("AllowN: %v", err)
+				}
+				var single Result
+				for i := 0; i < n; i++ {
+					if single, err = l.Allow(ctx, "single"); err != nil {
+						t.Fatalf("Allow: %v", err)
+					}
+				}
+				if !reflect.DeepEqual(batch, single) {
+					t.Errorf("AllowN(%d) = %+v, last of %d Allow = %+v", n, batch, n, single)
+				}
+
+				// The next request must see identical state either way.
+				nextBatch, _ := l.Allow(ctx, "batch")
+				nextSingle, _ := l.Allow(ctx, "single")
+				if !reflect.DeepEqual(nextBatch, nextSingle) {
+					t.Errorf("after AllowN(%d): %+v, after %d Allow: %+v", n, nextBatch, n, nextSingle)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Chain link IDs of NewTokenBucketWithDailyCap, as reported in
+// Result.Components and as the scope of its JSON encoding.
+const (
+	DailyCapRateLink  = "rate"
+	DailyCapDailyLink = "daily"
+)
+
+// secondsPerDay is the window of the daily cap.
+const secondsPerDay = 24 * 60 * 60
+
+// NewTokenBucketWithDailyCap creates a limiter for the common "10 req/s,
+// burst 20, and 10,000 per day" policy: a Token Bucket with capacity and
+// refillRate chained (see NewChain) with a Fixed Window of dailyMax requests
+// per calendar day in UTC. Both backends align the day to midnight UTC, so
+// every key's daily quota resets at the same moment.
+//
+// A request is allowed only if both admit it; the bucket is checked first,
+// so a request it denies is not counted against the day. Once the day denies
+// a key, its tokens are refunded where the bucket supports Refund, and until
+// midnight the day is checked first, so retries do not drain the bucket and
+// keep reporting the daily RetryAfter. A denied Result is the denying
+// link's, with RetryAfter until the bucket refills or until midnight. Which
+// one denied is the ID of the last entry in Result.Components:
+// DailyCapRateLink or DailyCapDailyLink. opts apply to both links; in Redis
+// mode the daily counts are kept under "prefix:daily:key:<day>".
+func NewTokenBucketWithDailyCap(capacity, refillRate, dailyMax int64, opts ...Option) (Limiter, error) {
+	if dailyMax <= 0 {
+		return nil, validationErr("dailyMax must be positive",
+			"Use a positive integer, e.g. NewTokenBucketWithDailyCap(20, 10, 10000).")
+	}
+	rate, err := NewTokenBucket(capacity, refillRate, opts...)
+	if err != nil {
+		return nil, err
+	}
+	o := applyOptions(opts)
+	dailyOpts := append(opts[:len(opts):len(opts)],
+		WithKeyPrefix(o.KeyPrefix+o.separator()+DailyCapDailyLink),
+		func(o *Options) { o.alignWindows = true },
+	)
+	daily, err := NewFixedWindow(dailyMax, secondsPerDay, dailyOpts...)
+	if err != nil {
+		return nil, err
+	}
+	rateLink := ChainLink{ID: DailyCapRateLink, Limiter: rate}
+	dailyLink := ChainLink{ID: DailyCapDailyLink, Limiter: daily}
+	return &dailyCapLimiter{
+		chain:    &chain{links: []ChainLink{rateLink, dailyLink}},
+		dayFirst: &chain{links: []ChainLink{dailyLink, rateLink}},
+		rate:     rate,
+		now:      o.now,
+		usedUp:   make(map[string]time.Time),
+	}, nil
+}
+
+// dailyCapLimiter is the chain built by NewTokenBucketWithDailyCap. It
+// remembers the keys whose day is used up and checks those day first: a
+// Fixed Window takes nothing for a request it denies, whereas the bucket
+// would be charged before the day turned the request away.
+type dailyCapLimiter struct {
+	*chain
+	dayFirst *chain
+	rate     Limiter
+	now      func() time.Time
+
+	mu      sync.Mutex
+	usedUp  map[string]time.Time // key -> midnight its day resets
+	sweepAt time.Time
+}
+
+func (d *dailyCapLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return d.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (d *dailyCapLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if d.dayUsedUp(key) {
+		res, err := d.dayFirst.AllowN(ctx, key, n)
+		if err == nil && res.Allowed {
+			d.forget(key)
+		}
+		return res, err
+	}
+
+	res, err := d.chain.AllowN(ctx, key, n)
+	if err != nil || res.Allowed || res.Components[len(res.Components)-1].ID != DailyCapDailyLink {
+		return res, err
+	}
+	if CanRefund(d.rate) {
+		if err := Refund(ctx, d.rate, key, n); err != nil {
+			return res, err
+		}
+	}
+	d.markUsedUp(key, res.ResetAt)
+	return res, nil
+}
+
+func (d *dailyCapLimiter) dayUsedUp(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resetAt, ok := d.usedUp[key]
+	return ok && d.now().Before(resetAt)
+}
+
+// markUsedUp records that key's day is used up until resetAt, first
+// dropping the keys of days that have since reset.
+func (d *dailyCapLimiter) markUsedUp(key string, resetAt time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now := d.now(); !now.Before(d.sweepAt) {
+		for k, at := range d.usedUp {
+			if !now.Before(at) {
+				delete(d.usedUp, k)
+			}
+		}
+		d.sweepAt = resetAt
+	}
+	d.usedUp[key] = resetAt
+}
+
+func (d *dailyCapLimiter) forget(keys ...string) {
+	d.mu.Lock()
+	for _, key := range keys {
+		delete(d.usedUp, key)
+	}
+	d.mu.Unlock()
+}
+
+func (d *dailyCapLimiter) Reset(ctx context.Context, key string) error {
+	d.forget(key)
+	return d.chain.Reset(ctx, key)
+}
+
+func (d *dailyCapLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	d.forget(keys...)
+	return d.chain.ResetMany(ctx, keys...)
+}
+
+func (d *dailyCapLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	d.forget(key)
+	return d.chain.ResetExisted(ctx, key)
+}
@@ -0,0 +1,130 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lastComponent(res Result) string {
+	if len(res.Components) == 0 {
+		return ""
+	}
+	return res.Components[len(res.Components)-1].ID
+}
+
+func TestTokenBucketWithDailyCap_DailyCapWhileBucketHasTokens(t *testing.T) {
+	clock := NewFakeClockAt(time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC))
+	l := must(NewTokenBucketWithDailyCap(20, 10, 30, WithClock(clock)))
+	ctx := context.Background()
+
+	// Ten requests a second stay within the bucket; the day runs out first.
+	for i := 0; i < 30; i++ {
+		res, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+1)
+		if i%10 == 9 {
+			clock.Advance(time.Second)
+		}
+	}
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, ReasonOverLimit, res.DenyReason)
+	assert.Equal(t, DailyCapDailyLink, lastComponent(res))
+	assert.Equal(t, res.Components[0].ID, DailyCapRateLink)
+	assert.Positive(t, res.Components[0].Remaining, "the bucket still has tokens")
+	midnight := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	assert.True(t, midnight.Equal(res.ResetAt), "the day resets at midnight UTC, got %v", res.ResetAt)
+	assert.Equal(t, midnight.Sub(clock.Now()), res.RetryAfter)
+
+	// A new day restores the quota.
+	clock.Advance(res.RetryAfter)
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(29), res.Components[1].Remaining)
+}
+
+func TestTokenBucketWithDailyCap_BucketWhileDayHasQuota(t *testing.T) {
+	clock := NewFakeClock()
+	l := must(NewTokenBucketWithDailyCap(5, 1, 10000, WithClock(clock)))
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		res, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+1)
+	}
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, DailyCapRateLink, lastComponent(res))
+	assert.Len(t, res.Components, 1, "the day is not charged for a request the bucket denies")
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	clock.Advance(time.Second)
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(10000-6), res.Components[1].Remaining)
+}
+
+func TestTokenBucketWithDailyCap_RetriesAfterDayUsedUp(t *testing.T) {
+	clock := NewFakeClockAt(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	l := must(NewTokenBucketWithDailyCap(5, 1, 3, WithClock(clock)))
+	ctx := context.Background()
+	midnight := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+1)
+	}
+	// Retrying right away, as clients ignoring Retry-After do, keeps hitting
+	// the day rather than draining the bucket into rate denials.
+	for i := 0; i < 10; i++ {
+		res, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.False(t, res.Allowed, "retry %d", i+1)
+		assert.Equal(t, DailyCapDailyLink, lastComponent(res), "retry %d", i+1)
+		assert.Equal(t, midnight.Sub(clock.Now()), res.RetryAfter, "retry %d", i+1)
+	}
+
+	// The first denial was refunded and the retries never reached the
+	// bucket, so it holds what the three allowed requests left.
+	states, ok := Inspect(l.(*dailyCapLimiter).rate)
+	require.True(t, ok)
+	require.Len(t, states, 1)
+	assert.Equal(t, int64(2), states[0].Remaining)
+}
+
+func TestTokenBucketWithDailyCap_ResetForgetsUsedUpDay(t *testing.T) {
+	clock := NewFakeClock()
+	l := must(NewTokenBucketWithDailyCap(5, 1, 1, WithClock(clock)))
+	ctx := context.Background()
+
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	require.NoError(t, l.Reset(ctx, "k"))
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Len(t, res.Components, 2)
+	assert.Equal(t, DailyCapRateLink, res.Components[0].ID, "a fresh day checks the bucket first again")
+}
+
+func TestTokenBucketWithDailyCap_Validation(t *testing.T) {
+	_, err := NewTokenBucketWithDailyCap(5, 1, 0)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, err = NewTokenBucketWithDailyCap(0, 1, 100)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
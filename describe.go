@@ -0,0 +1,89 @@
+package goratelimit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Describer is implemented by limiters that can report their configuration.
+// All built-in algorithms implement it, as do the wrappers in this module
+// (dry run, PreFilter, cache, metrics), which forward to the wrapped limiter.
+type Describer interface {
+	Describe() Description
+}
+
+// Description summarizes a limiter's static configuration.
+type Description struct {
+	// Algorithm is the algorithm name, e.g. "token_bucket" or "gcra".
+	// Matches the algorithm label used by the metrics package.
+	Algorithm string
+
+	// Limit is the construction-time limit (maxRequests / capacity / burst).
+	// Dynamic limits from LimitFunc are not reflected here.
+	Limit int64
+
+	// Window is the window length of Fixed Window, Sliding Window, Sliding
+	// Window Counter, Rolling Counter and CMS limiters; zero for the others.
+	Window time.Duration
+
+	// Rate is the sustained requests per second of Token Bucket, Leaky
+	// Bucket and GCRA limiters, as passed to the constructor rather than
+	// derived from GCRA's emission interval; zero for the others.
+	Rate int64
+
+	// Dynamic reports whether the limit is resolved per request by a
+	// LimitFunc, so Result.Limit may differ from Limit and between keys.
+	Dynamic bool
+}
+
+// Describe returns the Description of l, or the zero Description if l does
+// not implement Describer.
+func Describe(l Limiter) Description {
+	if d, ok := l.(Describer); ok {
+		return d.Describe()
+	}
+	return Description{}
+}
+
+// String returns a one-line summary of d for logs, e.g.
+// "token bucket: 100 burst, 10/s refill" or "fixed window: 100 per 60s".
+func (d Description) String() string {
+	name := strings.ReplaceAll(d.Algorithm, "_", " ")
+	if name == "" {
+		name = "unknown"
+	}
+	var parts []string
+	switch d.Algorithm {
+	case "token_bucket":
+		parts = append(parts, fmt.Sprintf("%d burst", d.Limit), fmt.Sprintf("%d/s refill", d.Rate))
+	case "leaky_bucket":
+		parts = append(parts, fmt.Sprintf("%d capacity", d.Limit), fmt.Sprintf("%d/s leak", d.Rate))
+	case "gcra":
+		parts = append(parts, fmt.Sprintf("%d/s", d.Rate), fmt.Sprintf("%d burst", d.Limit))
+	default:
+		switch {
+		case d.Window > 0:
+			parts = append(parts, fmt.Sprintf("%d per %s", d.Limit, formatWindow(d.Window)))
+		case d.Rate > 0:
+			parts = append(parts, fmt.Sprintf("%d/s", d.Rate), fmt.Sprintf("%d burst", d.Limit))
+		case d.Limit > 0:
+			parts = append(parts, fmt.Sprintf("limit %d", d.Limit))
+		}
+	}
+	if d.Dynamic {
+		parts = append(parts, "dynamic limit")
+	}
+	if len(parts) == 0 {
+		return name
+	}
+	return name + ": " + strings.Join(parts, ", ")
+}
+
+// formatWindow writes whole-second windows as "60s" rather than "1m0s".
+func formatWindow(w time.Duration) string {
+	if w%time.Second == 0 {
+		return fmt.Sprintf("%ds", int64(w/time.Second))
+	}
+	return w.String()
+}
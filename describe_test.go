@@ -0,0 +1,126 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe_Algorithms(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter Limiter
+		algo    string
+		limit   int64
+	}{
+		{"fixed window", must(NewFixedWindow(10, 60)), "fixed_window", 10},
+		{"sliding window", must(NewSlidingWindow(11, 60)), "sliding_window", 11},
+		{"sliding window counter", must(NewSlidingWindowCounter(12, 60)), "sliding_window_counter", 12},
+		{"token bucket", must(NewTokenBucket(13, 1)), "token_bucket", 13},
+		{"leaky bucket", must(NewLeakyBucket(14, 1, Policing)), "leaky_bucket", 14},
+		{"gcra", must(NewGCRA(1, 15)), "gcra", 15},
+		{"cms", must(NewCMS(16, 60, 0.01, 0.01)), "cms", 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Describe(tt.limiter)
+			assert.Equal(t, tt.algo, d.Algorithm)
+			assert.Equal(t, tt.limit, d.Limit)
+		})
+	}
+}
+
+// TestDescribe_RoundTripsConstructorArgs checks that Describe reports the
+// arguments the limiter was built with, not values derived from them such as
+// GCRA's emission interval, on both backends.
+func TestDescribe_RoundTripsConstructorArgs(t *testing.T) {
+	backends := map[string][]Option{
+		"memory": nil,
+		"redis":  {WithRedis(redis.NewClient(&redis.Options{Addr: "localhost:0"}))},
+	}
+	for backend, opts := range backends {
+		tests := []struct {
+			name    string
+			limiter Limiter
+			want    Description
+		}{
+			{"fixed window", must(NewFixedWindow(10, 60, opts...)),
+				Description{Algorithm: "fixed_window", Limit: 10, Window: time.Minute}},
+			{"sliding window", must(NewSlidingWindow(11, 30, opts...)),
+				Description{Algorithm: "sliding_window", Limit: 11, Window: 30 * time.Second}},
+			{"sliding window counter", must(NewSlidingWindowCounter(12, 90, opts...)),
+				Description{Algorithm: "sliding_window_counter", Limit: 12, Window: 90 * time.Second}},
+			{"token bucket", must(NewTokenBucket(13, 7, opts...)),
+				Description{Algorithm: "token_bucket", Limit: 13, Rate: 7}},
+			{"leaky bucket", must(NewLeakyBucket(14, 3, Shaping, opts...)),
+				Description{Algorithm: "leaky_bucket", Limit: 14, Rate: 3}},
+			// 1s/3 is not a whole number of nanoseconds: the rate must not be
+			// recomputed from the emission interval.
+			{"gcra", must(NewGCRA(3, 15, opts...)),
+				Description{Algorithm: "gcra", Limit: 15, Rate: 3}},
+			{"gcra sharded", must(NewGCRA(40, 20, append(opts, WithKeyShards(4))...)),
+				Description{Algorithm: "gcra", Limit: 20, Rate: 40}},
+		}
+		for _, tt := range tests {
+			t.Run(backend+"/"+tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, Describe(tt.limiter))
+			})
+		}
+	}
+}
+
+func TestDescribe_ForwardedThroughWrappers(t *testing.T) {
+	l, err := NewGCRA(10, 5, WithDryRun(true),
+		WithOnLimitExceeded(func(context.Context, string, *Result) {}))
+	require.NoError(t, err)
+	assert.Equal(t, "gcra", Describe(l).Algorithm)
+
+	c, err := NewConcurrency(3, time.Second)
+	require.NoError(t, err)
+	pf := NewPreFilter(must(NewCMS(100, 60, 0.01, 0.01)), c)
+	assert.Equal(t, "concurrency", Describe(pf).Algorithm)
+}
+
+func TestDescribe_DynamicWithLimitFunc(t *testing.T) {
+	limitFunc := WithLimitFunc(func(context.Context, string) int64 { return 5 })
+
+	assert.False(t, Describe(must(NewTokenBucket(10, 1))).Dynamic)
+	dynamic := must(NewTokenBucket(10, 1, limitFunc))
+	assert.True(t, Describe(dynamic).Dynamic)
+
+	chain := NewChain(ChainLink{ID: "static", Limiter: must(NewFixedWindow(10, 60))}, ChainLink{ID: "dynamic", Limiter: dynamic})
+	assert.True(t, Describe(chain).Dynamic, "a chain is dynamic if any link is")
+}
+
+func must(l Limiter, err error) Limiter {
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func TestDescription_String(t *testing.T) {
+	tests := []struct {
+		limiter Limiter
+		want    string
+	}{
+		{must(NewFixedWindow(10, 60)), "fixed window: 10 per 60s"},
+		{must(NewTokenBucket(13, 2)), "token bucket: 13 burst, 2/s refill"},
+		{must(NewLeakyBucket(14, 3, Policing)), "leaky bucket: 14 capacity, 3/s leak"},
+		{must(NewGCRA(4, 15)), "gcra: 4/s, 15 burst"},
+		{must(NewMinInterval(1500 * time.Millisecond)), "min interval: 1 per 1.5s"},
+		{must(NewConcurrency(3, time.Second)), "concurrency: limit 3"},
+		{must(NewFixedWindow(10, 60, WithLimitFunc(func(context.Context, string) int64 { return 5 }))),
+			"fixed window: 10 per 60s, dynamic limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, Describe(tt.limiter).String())
+		})
+	}
+	assert.Equal(t, "unknown", Description{}.String())
+}
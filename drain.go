@@ -0,0 +1,142 @@
+package goratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Drainer wraps a Limiter so a node can wind down gracefully, e.g. during a
+// rolling deploy. After StartDraining the effective limit of every key is
+// scaled linearly from its current value down to zero over the drain window,
+// so new requests are increasingly denied while in-flight work finishes.
+//
+//	d := goratelimit.Drain(limiter)
+//	// on SIGTERM:
+//	d.StartDraining(30 * time.Second)
+//
+// Scaling is applied to the wrapped limiter's result: a request is admitted
+// only while the key's usage (Limit - Remaining) stays within the scaled
+// limit. A request denied that way is refunded to the wrapped limiter when
+// CanRefund reports it can be, so StopDraining finds budgets as draining
+// left them; otherwise its units stay spent. Once the window has elapsed
+// every request is denied without consulting the wrapped limiter, with a
+// zero RetryAfter so clients can retry against another node immediately.
+// Only WithClock is read from opts.
+type Drainer struct {
+	inner Limiter
+	opts  *Options
+
+	mu    sync.Mutex
+	start time.Time
+	over  time.Duration
+}
+
+// Drain returns a Drainer wrapping inner. It passes every request through
+// unchanged until StartDraining is called.
+func Drain(inner Limiter, opts ...Option) *Drainer {
+	return &Drainer{inner: inner, opts: applyOptions(opts)}
+}
+
+// StartDraining begins ramping the effective limit down to zero over the
+// given duration. A non-positive duration drains immediately. Calling it
+// again restarts the ramp from the current time.
+func (d *Drainer) StartDraining(over time.Duration) {
+	d.mu.Lock()
+	d.start = d.opts.now()
+	d.over = over
+	d.mu.Unlock()
+}
+
+// StopDraining cancels draining and restores the full limit.
+func (d *Drainer) StopDraining() {
+	d.mu.Lock()
+	d.start = time.Time{}
+	d.over = 0
+	d.mu.Unlock()
+}
+
+// Fraction returns the share of the limit currently in effect: 1 when not
+// draining, falling linearly to 0 at the end of the drain window.
+func (d *Drainer) Fraction() float64 {
+	return d.fraction(d.opts.now())
+}
+
+func (d *Drainer) fraction(now time.Time) float64 {
+	d.mu.Lock()
+	start, over := d.start, d.over
+	d.mu.Unlock()
+	if start.IsZero() {
+		return 1
+	}
+	if over <= 0 {
+		return 0
+	}
+	elapsed := now.Sub(start)
+	if elapsed >= over {
+		return 0
+	}
+	if elapsed <= 0 {
+		return 1
+	}
+	return 1 - float64(elapsed)/float64(over)
+}
+
+func (d *Drainer) Allow(ctx context.Context, key string) (Result, error) {
+	return d.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (d *Drainer) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	now := d.opts.now()
+	fraction := d.fraction(now)
+	if fraction <= 0 {
+		return Result{Allowed: false, Remaining: 0, Limit: 0, ResetAt: now, DenyReason: ReasonMaintenance}, nil
+	}
+
+	res, err := d.inner.AllowN(ctx, key, n)
+	if err != nil || !res.Allowed || fraction >= 1 || res.Limit <= 0 {
+		return res, err
+	}
+
+	// The epsilon keeps values like 0.7*10 from flooring to 6.
+	scaled := int64(math.Floor(float64(res.Limit)*fraction + 1e-9))
+	used := res.Limit - res.Remaining
+	if used > scaled {
+		res.Allowed = false
+		res.DenyReason = ReasonMaintenance
+		res.Remaining = 0
+		res.Limit = scaled
+		if res.ResetAt.After(now) {
+			res.RetryAfter = res.ResetAt.Sub(now)
+		}
+		if CanRefund(d.inner) {
+			// The wrapped limiter charged a request draining turned away.
+			err = Refund(ctx, d.inner, key, n)
+		}
+		return res, err
+	}
+	res.Remaining = scaled - used
+	res.Limit = scaled
+	return res, nil
+}
+
+func (d *Drainer) Reset(ctx context.Context, key string) error {
+	return d.inner.Reset(ctx, key)
+}
+
+func (d *Drainer) ResetMany(ctx context.Context, keys ...string) error {
+	return ResetMany(ctx, d.inner, keys...)
+}
+
+func (d *Drainer) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, d.inner, key)
+}
+
+func (d *Drainer) Describe() Description {
+	return Describe(d.inner)
+}
+
+func (d *Drainer) SetLimit(limit int64) error {
+	return SetLimit(d.inner, limit)
+}
@@ -0,0 +1,103 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowedInWindow sends attempts requests for key and counts the admitted ones.
+func allowedInWindow(t *testing.T, l Limiter, key string, attempts int) int {
+	t.Helper()
+	allowed := 0
+	for i := 0; i < attempts; i++ {
+		res, err := l.Allow(context.Background(), key)
+		require.NoError(t, err)
+		if res.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestDrain_PassesThroughUntilStarted(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewFixedWindow(10, 1, WithClock(clock))
+	require.NoError(t, err)
+	d := Drain(inner, WithClock(clock))
+
+	assert.Equal(t, float64(1), d.Fraction())
+	assert.Equal(t, 10, allowedInWindow(t, d, "k", 20))
+	assert.Equal(t, Describe(inner), Describe(d))
+}
+
+func TestDrain_AllowRateDecreasesToZero(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewFixedWindow(10, 1, WithClock(clock))
+	require.NoError(t, err)
+	d := Drain(inner, WithClock(clock))
+
+	d.StartDraining(10 * time.Second)
+	var rates []int
+	for i := 0; i <= 10; i++ {
+		rates = append(rates, allowedInWindow(t, d, "k", 20))
+		clock.Advance(time.Second)
+	}
+
+	assert.Equal(t, []int{10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}, rates)
+	for i := 1; i < len(rates); i++ {
+		assert.Less(t, rates[i], rates[i-1], "allow rate should fall every second")
+	}
+}
+
+func TestDrain_ResultReflectsScaledLimit(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	inner, err := NewFixedWindow(10, 1, WithClock(clock))
+	require.NoError(t, err)
+	d := Drain(inner, WithClock(clock))
+
+	d.StartDraining(10 * time.Second)
+	clock.Advance(5 * time.Second)
+	res, err := d.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(5), res.Limit)
+	assert.Equal(t, int64(4), res.Remaining)
+
+	clock.Advance(5 * time.Second)
+	res, err = d.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Duration(0), res.RetryAfter, "drained node should not ask clients to wait")
+}
+
+func TestDrain_StopDrainingRestoresLimit(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewFixedWindow(10, 1, WithClock(clock))
+	require.NoError(t, err)
+	d := Drain(inner, WithClock(clock))
+
+	d.StartDraining(0)
+	assert.Equal(t, 0, allowedInWindow(t, d, "k", 5))
+
+	d.StopDraining()
+	assert.Equal(t, 10, allowedInWindow(t, d, "k", 20))
+}
+
+func TestDrain_DenialLeavesInnerBudget(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewFixedWindow(10, 60, WithClock(clock))
+	require.NoError(t, err)
+	d := Drain(inner, WithClock(clock))
+
+	d.StartDraining(10 * time.Second)
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, 5, allowedInWindow(t, d, "k", 8), "half the limit while half drained")
+
+	d.StopDraining()
+	assert.Equal(t, 5, allowedInWindow(t, inner, "k", 10), "requests denied by draining are refunded")
+}
@@ -18,11 +18,13 @@ func TestDryRun_NeverDenies(t *testing.T) {
 		res, err := l.Allow(ctx, "key")
 		require.NoError(t, err)
 		assert.True(t, res.Allowed, "request %d", i+1)
+		assert.False(t, res.Uncharged, "request %d", i+1)
 	}
 	// Would be denied without dry run; with dry run still allowed
 	res, err := l.Allow(ctx, "key")
 	require.NoError(t, err)
 	assert.True(t, res.Allowed, "dry run should allow over limit")
+	assert.True(t, res.Uncharged, "the overridden denial took nothing")
 	assert.Equal(t, int64(0), res.Remaining)
 	assert.Equal(t, int64(2), res.Limit)
 }
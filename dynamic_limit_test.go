@@ -194,5 +194,46 @@ func TestDynamicLimit_Unlimited(t *testing.T) {
 		require.True(t, res.Allowed, "admin request %d", i+1)
 		assert.Equal(t, Unlimited, res.Limit)
 		assert.Equal(t, Unlimited, res.Remaining)
+		assert.True(t, res.Uncharged)
 	}
 }
+
+func TestResult_RemainingFraction(t *testing.T) {
+	tests := []struct {
+		name string
+		res  Result
+		want float64
+	}{
+		{"full", Result{Remaining: 10, Limit: 10}, 1},
+		{"typical", Result{Remaining: 25, Limit: 100}, 0.25},
+		{"empty", Result{Remaining: 0, Limit: 10}, 0},
+		{"zero limit", Result{Remaining: 5, Limit: 0}, 0},
+		{"zero value", Result{}, 0},
+		{"unlimited", Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, 1},
+		{"remaining exceeds limit", Result{Remaining: 15, Limit: 10}, 1},
+		{"negative remaining", Result{Remaining: -3, Limit: 10}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.res.RemainingFraction())
+		})
+	}
+}
+
+func TestResult_RemainingFraction_DynamicLimit(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewFixedWindow(10, 60, WithLimitFunc(limitByKey))
+	require.NoError(t, err)
+
+	res, err := l.Allow(ctx, "premium")
+	require.NoError(t, err)
+	assert.Equal(t, 0.999, res.RemainingFraction(), "fraction of the premium limit")
+
+	res, err = l.Allow(ctx, "free")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, res.RemainingFraction(), "fraction of the free limit")
+
+	res, err = l.Allow(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, res.RemainingFraction(), "fraction of the default limit")
+}
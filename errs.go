@@ -1,15 +1,67 @@
 package goratelimit
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
 const docBase = "https://pkg.go.dev/github.com/krishna-kudari/ratelimit"
 
+var (
+	// ErrInvalidParameter is wrapped by constructor errors for out-of-range
+	// parameters, e.g. a non-positive limit or window. Test with errors.Is.
+	ErrInvalidParameter = errors.New("goratelimit: invalid parameter")
+
+	// ErrUnknownAlgorithm is wrapped by errors for a missing or unrecognized
+	// algorithm selection. Test with errors.Is.
+	ErrUnknownAlgorithm = errors.New("goratelimit: unknown algorithm")
+
+	// ErrBackendUnavailable is wrapped by errors from a limiter's backend,
+	// e.g. a Redis timeout, returned when the limiter fails closed
+	// (WithFailOpen(false)). The backend's own error is wrapped as well.
+	// Test with errors.Is.
+	ErrBackendUnavailable = errors.New("goratelimit: backend unavailable")
+)
+
+// sentinelErr carries an actionable message while matching a sentinel
+// under errors.Is.
+type sentinelErr struct {
+	msg      string
+	sentinel error
+}
+
+func (e *sentinelErr) Error() string { return e.msg }
+func (e *sentinelErr) Unwrap() error { return e.sentinel }
+
 // validationErr returns an error with an actionable message and a doc link.
+// It wraps ErrInvalidParameter.
 func validationErr(msg, suggestion string) error {
-	return fmt.Errorf("goratelimit: %s. %s See %s", msg, suggestion, docBase)
+	return &sentinelErr{
+		msg:      fmt.Sprintf("goratelimit: %s. %s See %s", msg, suggestion, docBase),
+		sentinel: ErrInvalidParameter,
+	}
+}
+
+// algorithmErr is like validationErr but wraps ErrUnknownAlgorithm.
+func algorithmErr(msg, suggestion string) error {
+	return &sentinelErr{
+		msg:      fmt.Sprintf("goratelimit: %s. %s See %s", msg, suggestion, docBase),
+		sentinel: ErrUnknownAlgorithm,
+	}
+}
+
+// checkRateBounds rejects a rate or burst beyond MaxRate or MaxBurst.
+func checkRateBounds(rate, burst int64) error {
+	if rate > MaxRate {
+		return validationErr(fmt.Sprintf("rate %d exceeds MaxRate (%d per second)", rate, MaxRate),
+			"Use a rate of at most one request per nanosecond.")
+	}
+	if burst > MaxBurst {
+		return validationErr(fmt.Sprintf("burst %d exceeds MaxBurst (%d)", burst, MaxBurst),
+			"Use a burst or capacity of at most 2^53.")
+	}
+	return nil
 }
 
 // redisErr wraps a Redis backend error with a suggestion and optional Cluster hint.
@@ -25,5 +77,18 @@ func redisErr(err error, opts *Options) error {
 		(strings.Contains(err.Error(), "CROSSSLOT") || strings.Contains(err.Error(), "MOVED")) {
 		suggestion += " Using Redis Cluster? Enable WithHashTag(). See " + docBase + "#WithHashTag"
 	}
-	return fmt.Errorf("goratelimit: redis error: %w. %s", err, suggestion)
+	return &backendErr{
+		msg: fmt.Sprintf("goratelimit: redis error: %v. %s", err, suggestion),
+		err: err,
+	}
+}
+
+// backendErr matches both ErrBackendUnavailable and the backend's error
+// under errors.Is and errors.As.
+type backendErr struct {
+	msg string
+	err error
 }
+
+func (e *backendErr) Error() string   { return e.msg }
+func (e *backendErr) Unwrap() []error { return []error{ErrBackendUnavailable, e.err} }
@@ -0,0 +1,31 @@
+package goratelimit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrInvalidParameter_Is(t *testing.T) {
+	_, err := NewInMemory(Rate{})
+	assert.True(t, errors.Is(err, ErrInvalidParameter), "NewInMemory should wrap ErrInvalidParameter")
+	assert.False(t, errors.Is(err, ErrUnknownAlgorithm))
+	assert.Contains(t, err.Error(), "rate must have positive limit and window", "message should be preserved")
+	assert.Contains(t, err.Error(), docBase)
+}
+
+func TestErrUnknownAlgorithm_Is(t *testing.T) {
+	_, err := NewBuilder().Build()
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm), "Build without algorithm should wrap ErrUnknownAlgorithm")
+	assert.False(t, errors.Is(err, ErrInvalidParameter))
+}
+
+func TestErrBackendUnavailable_Is(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+	err := redisErr(cause, &Options{})
+	assert.True(t, errors.Is(err, ErrBackendUnavailable))
+	assert.True(t, errors.Is(err, cause), "the backend's error should still match")
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Contains(t, err.Error(), "Is Redis running?")
+}
@@ -68,7 +68,7 @@ func ExampleNewGCRA() {
 	limiter, _ := goratelimit.NewGCRA(5, 10)
 	result, _ := limiter.Allow(context.Background(), "user:123")
 	fmt.Printf("allowed=%v remaining=%d\n", result.Allowed, result.Remaining)
-	// Output: allowed=true remaining=8
+	// Output: allowed=true remaining=9
 }
 
 func ExampleLimiter_allowN() {
@@ -120,7 +120,7 @@ func ExampleNewPreFilter() {
 
 	result, _ := limiter.Allow(context.Background(), "user:123")
 	fmt.Printf("allowed=%v remaining=%d\n", result.Allowed, result.Remaining)
-	// Output: allowed=true remaining=8
+	// Output: allowed=true remaining=9
 }
 
 func ExampleCMSMemoryBytes() {
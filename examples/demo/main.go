@@ -248,7 +248,7 @@ func createLimiter(algo string, cfg map[string]interface{}) (goratelimit.Limiter
 			getInt64(cfg, "burst", 10),
 		)
 	}
-	return nil, fmt.Errorf("unknown algorithm: %s", algo)
+	return nil, fmt.Errorf("%w: %s", goratelimit.ErrUnknownAlgorithm, algo)
 }
 
 func getLimiter(sid, algo string, cfg map[string]interface{}) (goratelimit.Limiter, error) {
@@ -262,14 +262,44 @@ func getLimiter(sid, algo string, cfg map[string]interface{}) (goratelimit.Limit
 		return entry.limiter, nil
 	}
 
-	l, err := createLimiter(algo, cfg)
+	l, err := newLimiter(algo, cfg)
 	if err != nil {
 		return nil, err
 	}
+	if entry, ok := m[algo]; ok {
+		closeLimiter(entry.limiter)
+	}
 	m[algo] = &limiterEntry{limiter: l, configHash: hash}
 	return l, nil
 }
 
+// newLimiter builds the limiters getLimiter caches; tests replace it.
+var newLimiter = createLimiter
+
+// closeLimiter releases a limiter that is being dropped, such as a
+// cache.LocalCache with its eviction goroutine, if it has a Close method.
+func closeLimiter(l goratelimit.Limiter) {
+	switch c := l.(type) {
+	case interface{ Close() }:
+		c.Close()
+	case interface{ Close() error }:
+		if err := c.Close(); err != nil {
+			log.Printf("closing replaced limiter: %v", err)
+		}
+	}
+}
+
+// resetSession drops sid's limiters, closing each.
+func resetSession(sid string) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	if v, ok := sessions.LoadAndDelete(sid); ok {
+		for _, entry := range v.(map[string]*limiterEntry) {
+			closeLimiter(entry.limiter)
+		}
+	}
+}
+
 type apiResult struct {
 	Allowed    bool     `json:"allowed"`
 	Remaining  int64    `json:"remaining"`
@@ -351,9 +381,7 @@ func main() {
 		}
 
 		if r.Method == http.MethodPost && path == "reset" {
-			sessionsMu.Lock()
-			sessions.Delete(sid)
-			sessionsMu.Unlock()
+			resetSession(sid)
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 			return
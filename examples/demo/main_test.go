@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// closeCounter counts Close calls on a limiter built by getLimiter.
+type closeCounter struct {
+	goratelimit.Limiter
+	closed int
+}
+
+func (c *closeCounter) Close() { c.closed++ }
+
+func TestGetLimiter_ClosesReplacedLimiterOnce(t *testing.T) {
+	var built []*closeCounter
+	newLimiter = func(algo string, cfg map[string]interface{}) (goratelimit.Limiter, error) {
+		l, err := createLimiter(algo, cfg)
+		c := &closeCounter{Limiter: l}
+		built = append(built, c)
+		return c, err
+	}
+	defer func() { newLimiter = createLimiter }()
+
+	const sid = "test-session"
+	defer resetSession(sid)
+	for _, cfg := range []map[string]interface{}{
+		{"maxRequests": 5.0},
+		{"maxRequests": 5.0}, // unchanged: reused
+		{"maxRequests": 8.0},
+	} {
+		if _, err := getLimiter(sid, "fixed-window", cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(built) != 2 {
+		t.Fatalf("built %d limiters, want 2", len(built))
+	}
+	if built[0].closed != 1 || built[1].closed != 0 {
+		t.Fatalf("closed counts %d, %d; want 1, 0", built[0].closed, built[1].closed)
+	}
+
+	resetSession(sid)
+	if built[1].closed != 1 {
+		t.Fatalf("reset closed the current limiter %d times, want 1", built[1].closed)
+	}
+}
@@ -0,0 +1,90 @@
+package goratelimit
+
+import "time"
+
+// Factory creates limiters of any algorithm that share one set of options,
+// e.g. a gateway picking an algorithm per route while reusing one Redis
+// client, key prefix and error policy.
+//
+//	f := goratelimit.NewFactory(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix("gw"))
+//	search, _ := f.TokenBucket(100, 10)
+//	login, _ := f.FixedWindow(5, 60)
+//
+// Each method passes the factory's options first and its own after them, so
+// per-limiter options override shared ones. Limiters sharing a prefix share
+// a keyspace: give each its own WithKeyPrefix, or keep their keys distinct,
+// when two of them could see the same key.
+type Factory struct {
+	opts []Option
+}
+
+// NewFactory returns a Factory whose limiters all get opts.
+func NewFactory(opts ...Option) *Factory {
+	return &Factory{opts: opts}
+}
+
+// with returns the shared options followed by opts.
+func (f *Factory) with(opts []Option) []Option {
+	all := make([]Option, 0, len(f.opts)+len(opts))
+	return append(append(all, f.opts...), opts...)
+}
+
+// FixedWindow is NewFixedWindow with the factory's options.
+func (f *Factory) FixedWindow(maxRequests, windowSeconds int64, opts ...Option) (Limiter, error) {
+	return NewFixedWindow(maxRequests, windowSeconds, f.with(opts)...)
+}
+
+// SlidingWindow is NewSlidingWindow with the factory's options.
+func (f *Factory) SlidingWindow(maxRequests, windowSeconds int64, opts ...Option) (Limiter, error) {
+	return NewSlidingWindow(maxRequests, windowSeconds, f.with(opts)...)
+}
+
+// SlidingWindowCounter is NewSlidingWindowCounter with the factory's options.
+func (f *Factory) SlidingWindowCounter(maxRequests, windowSeconds int64, opts ...Option) (Limiter, error) {
+	return NewSlidingWindowCounter(maxRequests, windowSeconds, f.with(opts)...)
+}
+
+// TokenBucket is NewTokenBucket with the factory's options.
+func (f *Factory) TokenBucket(capacity, refillRate int64, opts ...Option) (Limiter, error) {
+	return NewTokenBucket(capacity, refillRate, f.with(opts)...)
+}
+
+// LeakyBucket is NewLeakyBucket with the factory's options.
+func (f *Factory) LeakyBucket(capacity, leakRate int64, mode LeakyBucketMode, opts ...Option) (Limiter, error) {
+	return NewLeakyBucket(capacity, leakRate, mode, f.with(opts)...)
+}
+
+// GCRA is NewGCRA with the factory's options.
+func (f *Factory) GCRA(rate, burst int64, opts ...Option) (Limiter, error) {
+	return NewGCRA(rate, burst, f.with(opts)...)
+}
+
+// CMS is NewCMS with the factory's options.
+func (f *Factory) CMS(limit, windowSeconds int64, epsilon, delta float64, opts ...Option) (Limiter, error) {
+	return NewCMS(limit, windowSeconds, epsilon, delta, f.with(opts)...)
+}
+
+// ApproxFixedWindow is NewApproxFixedWindow with the factory's options.
+func (f *Factory) ApproxFixedWindow(maxRequests, windowSeconds int64, sketchWidth, sketchDepth int, opts ...Option) (Limiter, error) {
+	return NewApproxFixedWindow(maxRequests, windowSeconds, sketchWidth, sketchDepth, f.with(opts)...)
+}
+
+// Concurrency is NewConcurrency with the factory's options.
+func (f *Factory) Concurrency(maxInFlight int64, leaseTTL time.Duration, opts ...Option) (ConcurrencyLimiter, error) {
+	return NewConcurrency(maxInFlight, leaseTTL, f.with(opts)...)
+}
+
+// MinInterval is NewMinInterval with the factory's options.
+func (f *Factory) MinInterval(interval time.Duration, opts ...Option) (Limiter, error) {
+	return NewMinInterval(interval, f.with(opts)...)
+}
+
+// RollingCounter is NewRollingCounter with the factory's options.
+func (f *Factory) RollingCounter(maxRequests int64, window, bucketSize time.Duration, opts ...Option) (Limiter, error) {
+	return NewRollingCounter(maxRequests, window, bucketSize, f.with(opts)...)
+}
+
+// Balance is NewBalance with the factory's options.
+func (f *Factory) Balance(opts ...Option) (BalanceLimiter, error) {
+	return NewBalance(f.with(opts)...)
+}
@@ -0,0 +1,47 @@
+package goratelimit
+
+import (
+	"context"
+	"log"
+)
+
+// failOpenLogSeconds is the minimum time between "failing open" warnings
+// logged by one limiter.
+const failOpenLogSeconds = 30
+
+// WithOnFailOpen sets a callback invoked with the backend error each time a
+// Redis limiter allows a request because its backend failed, as it does
+// under WithFailOpen(true). It runs on every such request, so keep it cheap,
+// e.g. increment a metric or trip an alert.
+//
+// Whether or not fn is set, the limiter also logs "rate limiter failing open,
+// backend unreachable" at most once every 30 seconds, so an outage that
+// silently disables rate limiting shows up in the logs without flooding them.
+func WithOnFailOpen(fn func(err error)) Option {
+	return func(o *Options) { o.OnFailOpen = fn }
+}
+
+// newFailOpenLog returns the fixed window that throttles fail-open warnings.
+// It is built directly rather than with NewFixedWindow, which would apply
+// options and build another one.
+func newFailOpenLog(clock Clock) Limiter {
+	return &fixedWindowMemory{
+		states:        make(map[string]*fixedWindowState),
+		baseLimit:     newBaseLimit(1),
+		windowSeconds: failOpenLogSeconds,
+		opts:          &Options{Clock: clock},
+	}
+}
+
+// failOpen reports a request allowed because the backend failed with err.
+func (o *Options) failOpen(err error) {
+	if o.OnFailOpen != nil {
+		o.OnFailOpen(err)
+	}
+	if o.failOpenLog == nil {
+		return
+	}
+	if res, _ := o.failOpenLog.Allow(context.Background(), ""); res.Allowed {
+		log.Printf("goratelimit: rate limiter failing open, backend unreachable: %v", err)
+	}
+}
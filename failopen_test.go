@@ -0,0 +1,69 @@
+package goratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableRedis returns a client whose every command fails without
+// reaching a server.
+func unreachableRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: "recorder:0", MaxRetries: -1})
+	client.AddHook(&keyRecorder{keys: make(map[string]bool)})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestWithOnFailOpen_ThrottlesLog(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(io.Discard)
+
+	ctx := context.Background()
+	clock := NewFakeClockAt(time.Now())
+	var errs []error
+	l, err := NewGCRA(10, 10, WithRedis(unreachableRedis(t)), WithClock(clock),
+		WithOnFailOpen(func(err error) { errs = append(errs, err) }))
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		res, err := l.Allow(ctx, "user")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d should fail open", i+1)
+		clock.Advance(100 * time.Millisecond)
+	}
+	assert.Len(t, errs, 50, "the callback should fire for every failed-open request")
+	assert.Error(t, errs[0])
+	assert.Equal(t, 1, strings.Count(buf.String(), "failing open"), "the warning should be logged once per interval")
+
+	clock.Advance(failOpenLogSeconds * time.Second)
+	_, _ = l.Allow(ctx, "user")
+	assert.Equal(t, 2, strings.Count(buf.String(), "failing open"), "the warning should repeat after the interval")
+}
+
+func TestWithOnFailOpen_NotCalledWhenFailingClosed(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(io.Discard)
+
+	called := false
+	l, err := NewFixedWindow(10, 60, WithRedis(unreachableRedis(t)), WithFailOpen(false),
+		WithOnFailOpen(func(error) { called = true }))
+	require.NoError(t, err)
+
+	res, err := l.Allow(context.Background(), "user")
+	require.Error(t, err)
+	assert.False(t, res.Allowed)
+	assert.False(t, called)
+	assert.NotContains(t, buf.String(), "failing open")
+}
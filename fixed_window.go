@@ -2,6 +2,7 @@ package goratelimit
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,25 +12,32 @@ import (
 // NewFixedWindow creates a Fixed Window rate limiter.
 // maxRequests is the maximum requests allowed per window.
 // windowSeconds is the window duration in seconds.
-// Pass WithRedis for distributed mode; omit for in-memory.
+// Pass WithRedis for distributed mode; omit for in-memory. In Redis mode
+// windows are aligned to multiples of windowSeconds since the Unix epoch, so
+// every instance reports the same ResetAt; in memory a key's window starts
+// with its first request.
 func NewFixedWindow(maxRequests, windowSeconds int64, opts ...Option) (Limiter, error) {
 	if maxRequests <= 0 || windowSeconds <= 0 {
 		return nil, validationErr("maxRequests and windowSeconds must be positive",
 			"Use positive integers, e.g. NewFixedWindow(10, 60).")
 	}
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewFixedWindow", false, false); err != nil {
+		return nil, err
+	}
+	maxRequests = o.perShard(maxRequests)
 
 	if o.RedisClient != nil {
 		return wrapOptions(&fixedWindowRedis{
 			redis:         o.RedisClient,
-			maxRequests:   maxRequests,
+			baseLimit:     newBaseLimit(maxRequests),
 			windowSeconds: windowSeconds,
 			opts:          o,
 		}, o), nil
 	}
 	return wrapOptions(&fixedWindowMemory{
 		states:        make(map[string]*fixedWindowState),
-		maxRequests:   maxRequests,
+		baseLimit:     newBaseLimit(maxRequests),
 		windowSeconds: windowSeconds,
 		opts:          o,
 	}, o), nil
@@ -39,52 +47,64 @@ func NewFixedWindow(maxRequests, windowSeconds int64, opts ...Option) (Limiter,
 
 type fixedWindowState struct {
 	requests    int64
+	carry       int64 // credit carried from the previous window
 	windowStart time.Time
 }
 
 type fixedWindowMemory struct {
-	mu            sync.Mutex
-	states        map[string]*fixedWindowState
-	maxRequests   int64
+	mu     sync.Mutex
+	states map[string]*fixedWindowState
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (f *fixedWindowMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return f.AllowN(ctx, key, 1)
+	return f.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (f *fixedWindowMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, f.limit()); ok {
+		return res, nil
+	}
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	maxReq, unlimited := f.opts.resolveLimit(ctx, key, f.maxRequests)
+	maxReq, unlimited := f.opts.resolveLimit(ctx, key, f.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
 	}
 
 	state, ok := f.states[key]
 	if !ok {
-		state = &fixedWindowState{windowStart: f.opts.now()}
+		state = &fixedWindowState{windowStart: f.windowStart(f.opts.now())}
 		f.states[key] = state
 	}
 
 	now := f.opts.now()
 	windowDuration := time.Duration(f.windowSeconds) * time.Second
-	if now.Sub(state.windowStart) >= windowDuration {
-		state.windowStart = now
+	if elapsed := now.Sub(state.windowStart); elapsed >= windowDuration {
+		state.carry = 0
+		if elapsed < 2*windowDuration {
+			state.carry = carryover(maxReq, state.requests, f.opts.Carryover)
+		}
+		state.windowStart = f.windowStart(now)
 		state.requests = 0
 	}
+	limit := maxReq + state.carry
 
 	cost := int64(n)
-	if state.requests+cost <= maxReq {
+	if state.requests+cost <= limit {
 		state.requests += cost
-		remaining := maxReq - state.requests
+		remaining := limit - state.requests
 		resetAt := state.windowStart.Add(windowDuration)
 		return Result{
 			Allowed:   true,
 			Remaining: remaining,
-			Limit:     maxReq,
+			Limit:     limit,
 			ResetAt:   resetAt,
 		}, nil
 	}
@@ -96,8 +116,9 @@ func (f *fixedWindowMemory) AllowN(ctx context.Context, key string, n int) (Resu
 	}
 	return Result{
 		Allowed:    false,
-		Remaining:  0,
-		Limit:      maxReq,
+		DenyReason: ReasonOverLimit,
+		Remaining:  max(0, limit-state.requests),
+		Limit:      limit,
 		ResetAt:    resetAt,
 		RetryAfter: retryAfter,
 	}, nil
@@ -110,87 +131,247 @@ func (f *fixedWindowMemory) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+func (f *fixedWindowMemory) ResetMany(_ context.Context, keys ...string) error {
+	f.mu.Lock()
+	for _, key := range keys {
+		delete(f.states, key)
+	}
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fixedWindowMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	f.mu.Lock()
+	_, ok := f.states[key]
+	delete(f.states, key)
+	f.mu.Unlock()
+	return ok, nil
+}
+
+func (f *fixedWindowMemory) Describe() Description {
+	return Description{Algorithm: "fixed_window", Limit: f.limit(), Window: time.Duration(f.windowSeconds) * time.Second, Dynamic: f.opts.LimitFunc != nil}
+}
+
+// windowStart returns the start of a window opened at now: now itself, or
+// with alignWindows the last multiple of the window since the Unix epoch.
+func (f *fixedWindowMemory) windowStart(now time.Time) time.Time {
+	if !f.opts.alignWindows {
+		return now
+	}
+	return time.Unix(now.Unix()-now.Unix()%f.windowSeconds, 0)
+}
+
+func (f *fixedWindowMemory) Inspect() []KeyState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.opts.now()
+	windowDuration := time.Duration(f.windowSeconds) * time.Second
+	states := make([]KeyState, 0, len(f.states))
+	for key, state := range f.states {
+		resetAt := state.windowStart.Add(windowDuration)
+		if !now.Before(resetAt) {
+			continue
+		}
+		states = append(states, KeyState{
+			Key:       key,
+			Remaining: max(0, f.limit()+state.carry-state.requests),
+			ResetAt:   resetAt,
+		})
+	}
+	return states
+}
+
+func (f *fixedWindowMemory) Refund(_ context.Context, key string, n int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if state, ok := f.states[key]; ok {
+		state.requests = max(0, state.requests-int64(n))
+	}
+	return nil
+}
+
+// carryover returns the credit a window with limit maxReq and used requests
+// passes to the next one, capped at maxCarry.
+func carryover(maxReq, used, maxCarry int64) int64 {
+	if maxCarry <= 0 {
+		return 0
+	}
+	return min(maxCarry, max(0, maxReq-used))
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
+// fixedWindowScript counts requests in an epoch-aligned window key
+// ("prefix:key:<window index>") that expires at the window boundary, so
+// ResetAt is computed from the boundary without reading the TTL. With
+// carryover, KEYS[2] is the previous window's key, kept for one extra window,
+// and its unused requests up to max_carry raise this window's limit.
+// It returns { allowed, remaining, limit }; a denied cost leaves remaining as
+// it was, so clients can see headroom too small for that cost.
 var fixedWindowScript = redis.NewScript(`
 local key = KEYS[1]
 local max_requests = tonumber(ARGV[1])
-local window_seconds = tonumber(ARGV[2])
-local cost = tonumber(ARGV[3])
-
-local count = redis.call('GET', key)
-if not count then
-  count = 0
-else
-  count = tonumber(count)
-end
+local cost = tonumber(ARGV[2])
+local ttl_ms = tonumber(ARGV[3])
+local expire = tonumber(ARGV[4])
+local max_carry = tonumber(ARGV[5])
 
-if count + cost <= max_requests then
-  local new_count = redis.call('INCRBY', key, cost)
-  if new_count == cost and count == 0 then
-    redis.call('EXPIRE', key, window_seconds)
+local limit = max_requests
+if max_carry > 0 then
+  local previous = redis.call('GET', KEYS[2])
+  if previous then
+    limit = limit + math.min(max_carry, math.max(0, max_requests - tonumber(previous)))
   end
-  local remaining = max_requests - new_count
-  local ttl = redis.call('TTL', key)
-  return { 1, remaining, ttl }
 end
 
-local ttl = redis.call('TTL', key)
-if ttl < 0 then
-  ttl = window_seconds
+local count = tonumber(redis.call('GET', key) or '0')
+if count + cost > limit then
+  return { 0, math.max(0, limit - count), limit }
+end
+
+local new_count = redis.call('INCRBY', key, cost)
+if expire > 0 and new_count == cost then
+  redis.call('PEXPIRE', key, math.ceil(ttl_ms * expire))
 end
-return { 0, 0, ttl }
+return { 1, limit - new_count, limit }
 `)
 
 type fixedWindowRedis struct {
-	redis         redis.UniversalClient
-	maxRequests   int64
+	redis redis.UniversalClient
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (f *fixedWindowRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return f.AllowN(ctx, key, 1)
+	return f.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (f *fixedWindowRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	maxReq, unlimited := f.opts.resolveLimit(ctx, key, f.maxRequests)
+	return f.queueAllowN(ctx, f.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (f *fixedWindowRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, f.limit()); ok {
+		return decided(res)
+	}
+	maxReq, unlimited := f.opts.resolveLimit(ctx, key, f.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return decided(unlimitedResult())
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return decided(res)
 	}
-	fullKey := f.opts.FormatKey(key)
-	result, err := fixedWindowScript.Run(ctx, f.redis, []string{fullKey},
+	now := f.opts.now()
+	window, resetAt := f.window(now)
+	keys := []string{f.windowKey(key, window)}
+	ttl := resetAt.Sub(now).Milliseconds() + 1
+	if f.opts.Carryover > 0 {
+		keys = append(keys, f.windowKey(key, window-1))
+		ttl += f.windowSeconds * 1000
+	}
+	args := []interface{}{
 		maxReq,
-		f.windowSeconds,
 		n,
-	).Int64Slice()
-	if err != nil {
-		if f.opts.FailOpen {
-			return Result{Allowed: true, Remaining: maxReq - 1, Limit: maxReq}, nil
-		}
-		return Result{Allowed: false, Remaining: 0, Limit: maxReq}, redisErr(err, f.opts)
+		ttl,
+		f.opts.expireArg(),
+		f.opts.Carryover,
 	}
+	cmd := runScript(ctx, fixedWindowScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := f.opts.scriptResult(ctx, cmd, f.redis, fixedWindowScript, keys, args)
+		if err != nil {
+			if f.opts.FailOpen {
+				f.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: maxReq - 1, Limit: maxReq}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: maxReq, DenyReason: ReasonBackendError}, redisErr(err, f.opts)
+		}
+
+		allowed := result[0] == 1
+		remaining := result[1]
 
-	allowed := result[0] == 1
-	remaining := result[1]
-	ttlSec := result[2]
+		var retryAfter time.Duration
+		if !allowed {
+			retryAfter = resetAt.Sub(now)
+		}
 
-	resetAt := f.opts.now().Add(time.Duration(ttlSec) * time.Second)
-	var retryAfter time.Duration
-	if !allowed {
-		retryAfter = time.Duration(ttlSec) * time.Second
+		return Result{
+			Allowed:    allowed,
+			DenyReason: denyReason(allowed),
+			Remaining:  remaining,
+			Limit:      result[2],
+			ResetAt:    resetAt,
+			RetryAfter: retryAfter,
+		}, nil
 	}
+}
 
-	return Result{
-		Allowed:    allowed,
-		Remaining:  remaining,
-		Limit:      maxReq,
-		ResetAt:    resetAt,
-		RetryAfter: retryAfter,
-	}, nil
+func (f *fixedWindowRedis) redisClient() redis.UniversalClient {
+	return f.redis
 }
 
 func (f *fixedWindowRedis) Reset(ctx context.Context, key string) error {
-	fullKey := f.opts.FormatKey(key)
-	return f.redis.Del(ctx, fullKey).Err()
+	return f.redis.Del(ctx, f.stateKeys(key)...).Err()
+}
+
+func (f *fixedWindowRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fullKeys = append(fullKeys, f.stateKeys(key)...)
+	}
+	return delPipelined(ctx, f.redis, fullKeys)
+}
+
+func (f *fixedWindowRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := f.redis.Del(ctx, f.stateKeys(key)...).Result()
+	return n > 0, err
+}
+
+// stateKeys returns the Redis keys holding key's state now: the current
+// window's and, with carryover, the previous window's, which would
+// otherwise still credit a Reset key.
+func (f *fixedWindowRedis) stateKeys(key string) []string {
+	window, _ := f.window(f.opts.now())
+	keys := []string{f.windowKey(key, window)}
+	if f.opts.Carryover > 0 {
+		keys = append(keys, f.windowKey(key, window-1))
+	}
+	return keys
+}
+
+// window returns the index of the epoch-aligned window containing now and
+// the boundary at which it ends.
+func (f *fixedWindowRedis) window(now time.Time) (int64, time.Time) {
+	windowMs := f.windowSeconds * 1000
+	index := now.UnixMilli() / windowMs
+	return index, time.UnixMilli((index + 1) * windowMs)
+}
+
+func (f *fixedWindowRedis) windowKey(key string, window int64) string {
+	return f.opts.FormatKeySuffix(key, strconv.FormatInt(window, 10))
+}
+
+// Seed writes each key's count for the current window, expiring at the
+// window boundary like AllowN's.
+func (f *fixedWindowRedis) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	maxReq := f.limit()
+	if err := checkSeed(states, maxReq); err != nil {
+		return err
+	}
+	now := f.opts.now()
+	window, resetAt := f.window(now)
+	ttl := f.opts.seedTTL(resetAt.Sub(now) + time.Millisecond)
+	pipe := f.redis.Pipeline()
+	for key, s := range states {
+		pipe.Set(ctx, f.windowKey(key, window), maxReq-s.Remaining, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (f *fixedWindowRedis) Describe() Description {
+	return Description{Algorithm: "fixed_window", Limit: f.limit(), Window: time.Duration(f.windowSeconds) * time.Second, Dynamic: f.opts.LimitFunc != nil}
 }
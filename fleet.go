@@ -0,0 +1,239 @@
+package goratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewFleet creates a limiter that holds a whole fleet of nodes to about
+// globalRate requests per second per key while deciding almost every request
+// locally. Each node admits requests from an in-memory token bucket whose
+// rate, its share, starts at globalRate/nodes. Every syncInterval a key's
+// next request also reports the node's usage to a shared Redis hash and
+// rebalances the share: each node keeps its part of what the fleet used, cut
+// back proportionally when the fleet ran over, plus an even split of any
+// unused budget among the nodes that reported. Busy nodes therefore grow
+// their shares while idle ones shrink, and the shares sum to globalRate.
+//
+// This trades precision for latency: between syncs the fleet can exceed
+// globalRate, e.g. when traffic shifts between nodes, and a node that cannot
+// reach Redis keeps its last share. nodes is only the initial estimate; the
+// actual number of reporting nodes is used once they sync. WithRedis is
+// required; KeyPrefix and KeySeparator name the shared hashes.
+func NewFleet(globalRate int64, nodes int, syncInterval time.Duration, opts ...Option) (Limiter, error) {
+	if globalRate <= 0 || nodes <= 0 {
+		return nil, validationErr("globalRate and nodes must be positive",
+			"Use positive integers, e.g. NewFleet(1000, 4, 5*time.Second, WithRedis(client)).")
+	}
+	if syncInterval < time.Millisecond {
+		return nil, validationErr("syncInterval must be at least 1ms",
+			"Reconcile every few seconds, e.g. 5*time.Second.")
+	}
+	if err := checkRateBounds(globalRate, globalRate); err != nil {
+		return nil, err
+	}
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewFleet", false, false); err != nil {
+		return nil, err
+	}
+	if o.RedisClient == nil {
+		return nil, validationErr("NewFleet requires WithRedis",
+			"Pass WithRedis(client); use NewTokenBucket for a single node.")
+	}
+	return wrapOptions(newFleet(globalRate, nodes, syncInterval, &fleetRedis{redis: o.RedisClient, opts: o}, o), o), nil
+}
+
+func newFleet(globalRate int64, nodes int, syncInterval time.Duration, backend fleetBackend, o *Options) *fleetLimiter {
+	return &fleetLimiter{
+		states:       make(map[string]*fleetState),
+		globalRate:   float64(globalRate),
+		initialShare: max(1, float64(globalRate)/float64(nodes)),
+		syncInterval: syncInterval,
+		node:         strconv.FormatInt(rand.Int63(), 36),
+		backend:      backend,
+		opts:         o,
+	}
+}
+
+// fleetBackend shares per-node usage between the nodes of a fleet.
+type fleetBackend interface {
+	// report records that node admitted used requests for key over the
+	// interval ending at now and returns the usage of every node that
+	// reported within the last two intervals, including this one.
+	report(ctx context.Context, key, node string, used int64, now time.Time, interval time.Duration) (map[string]int64, error)
+}
+
+type fleetState struct {
+	tokens   float64
+	share    float64 // local refill rate, requests per second
+	last     time.Time
+	used     int64 // admitted since the last sync
+	lastSync time.Time
+	syncing  bool
+}
+
+type fleetLimiter struct {
+	mu           sync.Mutex
+	states       map[string]*fleetState
+	globalRate   float64
+	initialShare float64
+	syncInterval time.Duration
+	node         string
+	backend      fleetBackend
+	opts         *Options
+}
+
+func (f *fleetLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return f.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (f *fleetLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, int64(f.globalRate)); ok {
+		return res, nil
+	}
+	now := f.opts.now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state := f.state(key, now)
+	if !state.syncing && now.Sub(state.lastSync) >= f.syncInterval {
+		// Sync outside the lock; other requests keep using the old share.
+		used, elapsed := state.used, now.Sub(state.lastSync)
+		state.used, state.lastSync, state.syncing = 0, now, true
+		f.mu.Unlock()
+		usage, err := f.backend.report(ctx, key, f.node, used, now, f.syncInterval)
+		f.mu.Lock()
+		state.syncing = false
+		if f.states[key] != state {
+			state = f.state(key, now) // reset during the sync
+		} else if err == nil {
+			state.share = f.rebalance(usage, used, elapsed)
+		}
+	}
+
+	capacity := max(1, state.share)
+	if res, ok := costTooLarge(n, int64(capacity), int64(state.share)); ok {
+		return res, nil
+	}
+	state.tokens = min(capacity, state.tokens+now.Sub(state.last).Seconds()*state.share)
+	state.last = now
+
+	cost := float64(n)
+	if state.tokens >= cost {
+		state.tokens -= cost
+		state.used += int64(n)
+		return Result{
+			Allowed:   true,
+			Remaining: int64(state.tokens),
+			Limit:     int64(capacity),
+			Rate:      int64(state.share),
+		}, nil
+	}
+	retryAfter := time.Duration((cost - state.tokens) / state.share * float64(time.Second))
+	return Result{
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      int64(capacity),
+		Rate:       int64(state.share),
+		RetryAfter: ceilSecond(retryAfter),
+	}, nil
+}
+
+// state returns key's state, creating it at the initial share. Called with
+// f.mu held.
+func (f *fleetLimiter) state(key string, now time.Time) *fleetState {
+	state, ok := f.states[key]
+	if !ok {
+		state = &fleetState{tokens: f.initialShare, share: f.initialShare, last: now, lastSync: now}
+		f.states[key] = state
+	}
+	return state
+}
+
+// rebalance returns this node's new share given every reporting node's usage
+// over the last interval, in which this node admitted used requests over
+// elapsed. The shares of all reporting nodes sum to globalRate.
+func (f *fleetLimiter) rebalance(usage map[string]int64, used int64, elapsed time.Duration) float64 {
+	if len(usage) == 0 || elapsed <= 0 {
+		return f.initialShare
+	}
+	var total int64
+	for _, u := range usage {
+		total += u
+	}
+	seconds := elapsed.Seconds()
+	budget := f.globalRate * seconds
+	mine := float64(used)
+	if float64(total) > budget {
+		mine *= budget / float64(total)
+	}
+	headroom := max(0, budget-float64(total))
+	return max(1, (mine+headroom/float64(len(usage)))/seconds)
+}
+
+// Reset clears this node's state for key. Other nodes' reports stay in Redis
+// until they expire.
+func (f *fleetLimiter) Reset(_ context.Context, key string) error {
+	f.mu.Lock()
+	delete(f.states, key)
+	f.mu.Unlock()
+	return nil
+}
+
+// ─── Redis ────────────────────────────────────────────────────────────────────
+
+// fleetScript stores each node's last report as "used:now_ms" in one hash
+// per key, drops reports older than two intervals, and returns the live ones
+// as a flat { node, used, ... } list.
+var fleetScript = redis.NewScript(`
+local key = KEYS[1]
+local node = ARGV[1]
+local used = ARGV[2]
+local now = tonumber(ARGV[3])
+local interval = tonumber(ARGV[4])
+
+redis.call('HSET', key, node, used .. ':' .. now)
+redis.call('PEXPIRE', key, 2 * interval)
+
+local live = {}
+local all = redis.call('HGETALL', key)
+for i = 1, #all, 2 do
+  local u, t = string.match(all[i + 1], '^(%d+):(%d+)$')
+  if t and now - tonumber(t) <= 2 * interval then
+    table.insert(live, all[i])
+    table.insert(live, u)
+  else
+    redis.call('HDEL', key, all[i])
+  end
+end
+return live
+`)
+
+type fleetRedis struct {
+	redis redis.UniversalClient
+	opts  *Options
+}
+
+func (f *fleetRedis) report(ctx context.Context, key, node string, used int64, now time.Time, interval time.Duration) (map[string]int64, error) {
+	reply, err := fleetScript.Run(ctx, f.redis, []string{f.opts.FormatKeySuffix(key, "fleet")},
+		node, used, now.UnixMilli(), interval.Milliseconds(),
+	).StringSlice()
+	if err != nil {
+		return nil, redisErr(err, f.opts)
+	}
+	usage := make(map[string]int64, len(reply)/2)
+	for i := 0; i+1 < len(reply); i += 2 {
+		u, err := strconv.ParseInt(reply[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("goratelimit: bad fleet usage %q: %w", reply[i+1], err)
+		}
+		usage[reply[i]] = u
+	}
+	return usage, nil
+}
@@ -0,0 +1,113 @@
+package goratelimit
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFleet is a fleetBackend whose other nodes' usage the test sets
+// directly.
+type fakeFleet struct {
+	usage map[string]int64
+	err   error
+}
+
+func (f *fakeFleet) report(_ context.Context, _, node string, used int64, _ time.Time, _ time.Duration) (map[string]int64, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.usage[node] = used
+	return maps.Clone(f.usage), nil
+}
+
+// driveFleet sends 20 requests every 100ms for one second, ending on the
+// request that triggers the next sync, and returns how many were allowed.
+func driveFleet(t *testing.T, f *fleetLimiter, clock *FakeClock) int {
+	t.Helper()
+	allowed := 0
+	for step := 0; step < 10; step++ {
+		for i := 0; i < 20; i++ {
+			res, err := f.Allow(context.Background(), "k")
+			require.NoError(t, err)
+			if res.Allowed {
+				allowed++
+			}
+		}
+		clock.Advance(100 * time.Millisecond)
+	}
+	_, err := f.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	return allowed
+}
+
+func TestFleet_ShareFollowsFleetUsage(t *testing.T) {
+	clock := NewFakeClockAt(time.Unix(1_700_000_000, 0))
+	backend := &fakeFleet{usage: map[string]int64{"other": 0}}
+	f := newFleet(100, 2, time.Second, backend, applyOptions([]Option{WithClock(clock)}))
+
+	assert.Equal(t, 50+45, driveFleet(t, f, clock), "a burst of globalRate/nodes, then that many per second")
+	assert.Greater(t, f.states["k"].share, 90.0, "the idle node's budget moves here")
+
+	backend.usage["other"] = 100
+	driveFleet(t, f, clock)
+	assert.Less(t, f.states["k"].share, 50.0, "the other node's traffic shrinks this share")
+
+	backend.usage["other"] = 0
+	driveFleet(t, f, clock)
+	assert.Greater(t, f.states["k"].share, 50.0, "the share grows back once the other node goes quiet")
+}
+
+func TestFleet_BackendErrorKeepsShare(t *testing.T) {
+	clock := NewFakeClockAt(time.Unix(1_700_000_000, 0))
+	backend := &fakeFleet{usage: map[string]int64{"other": 0}}
+	f := newFleet(100, 2, time.Second, backend, applyOptions([]Option{WithClock(clock)}))
+
+	driveFleet(t, f, clock)
+	share := f.states["k"].share
+
+	backend.err = errors.New("unreachable")
+	driveFleet(t, f, clock)
+	assert.Equal(t, share, f.states["k"].share)
+}
+
+func TestFleet_Reset(t *testing.T) {
+	clock := NewFakeClockAt(time.Unix(1_700_000_000, 0))
+	f := newFleet(10, 2, time.Second, &fakeFleet{usage: map[string]int64{}}, applyOptions([]Option{WithClock(clock)}))
+	ctx := context.Background()
+
+	res, err := f.AllowN(ctx, "k", 5)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	res, err = f.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	require.NoError(t, f.Reset(ctx, "k"))
+	res, err = f.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestNewFleet_Validation(t *testing.T) {
+	client := unreachableRedis(t)
+	cases := map[string]func() (Limiter, error){
+		"zero rate":      func() (Limiter, error) { return NewFleet(0, 2, time.Second, WithRedis(client)) },
+		"zero nodes":     func() (Limiter, error) { return NewFleet(100, 0, time.Second, WithRedis(client)) },
+		"short interval": func() (Limiter, error) { return NewFleet(100, 2, time.Microsecond, WithRedis(client)) },
+		"missing redis":  func() (Limiter, error) { return NewFleet(100, 2, time.Second) },
+	}
+	for name, build := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := build()
+			assert.ErrorIs(t, err, ErrInvalidParameter)
+		})
+	}
+	_, err := NewFleet(100, 2, time.Second, WithRedis(client))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultFlushBatchSize is the SCAN COUNT and delete batch FlushRedis uses
+// when batchSize is not positive.
+const DefaultFlushBatchSize = 1000
+
+// FlushRedis deletes every key under the key prefix, prefix version and
+// separator that opts configure ("ratelimit:*" by default), clearing all limiters sharing that
+// prefix. It walks the keyspace with SCAN and deletes each batch of
+// batchSize keys with UNLINK, so Redis frees memory in the background and is
+// never blocked the way KEYS and a large DEL would block it. Servers without
+// UNLINK (before Redis 4) get DEL instead. With a *redis.ClusterClient every
+// master is scanned. It returns the number of keys deleted.
+//
+// Keys written while FlushRedis runs may survive it; stop traffic first for
+// an exact clear.
+func FlushRedis(ctx context.Context, client redis.UniversalClient, batchSize int, opts ...Option) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultFlushBatchSize
+	}
+	o := applyOptions(opts)
+	pattern := escapeGlob(o.prefix()+o.separator()) + "*"
+
+	var deleted atomic.Int64
+	flush := func(ctx context.Context, c redis.UniversalClient) error {
+		n, err := scanUnlink(ctx, c, pattern, batchSize)
+		deleted.Add(n)
+		return err
+	}
+	var err error
+	if cc, ok := client.(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+			return flush(ctx, c)
+		})
+	} else {
+		err = flush(ctx, client)
+	}
+	return deleted.Load(), err
+}
+
+// scanUnlink deletes the keys on one node matching pattern, batchSize at a
+// time, and returns how many it deleted.
+func scanUnlink(ctx context.Context, client redis.UniversalClient, pattern string, batchSize int) (int64, error) {
+	var deleted int64
+	unlink := true
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, int64(batchSize)).Result()
+		if err != nil {
+			return deleted, err
+		}
+		for len(keys) > 0 {
+			batch := keys[:min(batchSize, len(keys))]
+			keys = keys[len(batch):]
+			n, err := deleteBatch(ctx, client, batch, unlink)
+			if err != nil && unlink && isUnknownCommand(err) {
+				unlink = false
+				n, err = deleteBatch(ctx, client, batch, false)
+			}
+			deleted += n
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// deleteBatch removes keys in one pipeline, one command per key like
+// delPipelined, with UNLINK or DEL.
+func deleteBatch(ctx context.Context, client redis.UniversalClient, keys []string, unlink bool) (int64, error) {
+	pipe := client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		if unlink {
+			cmds[i] = pipe.Unlink(ctx, key)
+		} else {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	var n int64
+	for _, cmd := range cmds {
+		if err == nil {
+			err = cmd.Err()
+		}
+		n += cmd.Val()
+	}
+	return n, err
+}
+
+func isUnknownCommand(err error) bool {
+	return strings.HasPrefix(err.Error(), "ERR unknown command")
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as wildcards.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
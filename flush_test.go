@@ -0,0 +1,183 @@
+package goratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeyspace is a go-redis hook serving SCAN, UNLINK and DEL from a map,
+// so FlushRedis can be tested without a server.
+type fakeKeyspace struct {
+	mu       sync.Mutex
+	keys     map[string]bool
+	order    []string // SCAN order; cursors index into it
+	noUnlink bool     // answer UNLINK like Redis 3
+	commands map[string]int
+}
+
+func newFakeKeyspace(keys ...string) *fakeKeyspace {
+	f := &fakeKeyspace{keys: make(map[string]bool), commands: make(map[string]int)}
+	for _, k := range keys {
+		f.keys[k] = true
+	}
+	f.order = append([]string(nil), keys...)
+	return f
+}
+
+func (f *fakeKeyspace) DialHook(redis.DialHook) redis.DialHook {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("fakeKeyspace: no server")
+	}
+}
+
+func (f *fakeKeyspace) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		f.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (f *fakeKeyspace) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		var err error
+		for _, cmd := range cmds {
+			f.process(cmd)
+			if err == nil {
+				err = cmd.Err()
+			}
+		}
+		return err
+	}
+}
+
+func (f *fakeKeyspace) process(cmd redis.Cmder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := cmd.Name()
+	f.commands[name]++
+	args := cmd.Args()
+	switch name {
+	case "scan":
+		cursor := int(args[1].(uint64))
+		pattern, count := "*", 10
+		for i := 2; i+1 < len(args); i += 2 {
+			switch args[i] {
+			case "match":
+				pattern = args[i+1].(string)
+			case "count":
+				count = int(args[i+1].(int64))
+			}
+		}
+		end := min(cursor+count, len(f.order))
+		var page []string
+		for _, k := range f.order[cursor:end] {
+			if ok, _ := path.Match(pattern, k); ok && f.keys[k] {
+				page = append(page, k)
+			}
+		}
+		next := uint64(end)
+		if end == len(f.order) {
+			next = 0
+		}
+		cmd.(*redis.ScanCmd).SetVal(page, next)
+	case "unlink", "del":
+		if name == "unlink" && f.noUnlink {
+			cmd.SetErr(errors.New("ERR unknown command 'unlink', with args beginning with: "))
+			return
+		}
+		var n int64
+		for _, k := range args[1:] {
+			if f.keys[k.(string)] {
+				delete(f.keys, k.(string))
+				n++
+			}
+		}
+		cmd.(*redis.IntCmd).SetVal(n)
+	default:
+		cmd.SetErr(fmt.Errorf("fakeKeyspace: unsupported command %s", name))
+	}
+}
+
+func (f *fakeKeyspace) client(t *testing.T) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: "fake:0", MaxRetries: -1})
+	client.AddHook(f)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (f *fakeKeyspace) remaining() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func manyKeys(prefix string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s:user-%d", prefix, i)
+	}
+	return keys
+}
+
+func TestFlushRedis_ClearsPrefixWithUnlink(t *testing.T) {
+	fake := newFakeKeyspace(append(manyKeys("ratelimit", 2500), "other:user-1", "ratelimitx:user-1")...)
+
+	n, err := FlushRedis(context.Background(), fake.client(t), 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500), n)
+	assert.Equal(t, []string{"other:user-1", "ratelimitx:user-1"}, fake.remaining(), "keys outside the prefix survive")
+	assert.Equal(t, 2500, fake.commands["unlink"])
+	assert.Zero(t, fake.commands["del"], "UNLINK should be used when available")
+	assert.Zero(t, fake.commands["keys"])
+}
+
+func TestFlushRedis_FallsBackToDel(t *testing.T) {
+	fake := newFakeKeyspace(manyKeys("ratelimit", 300)...)
+	fake.noUnlink = true
+
+	n, err := FlushRedis(context.Background(), fake.client(t), 50)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), n)
+	assert.Empty(t, fake.remaining())
+	assert.Equal(t, 50, fake.commands["unlink"], "UNLINK should be tried for the first batch only, then DEL used")
+	assert.Equal(t, 300, fake.commands["del"])
+}
+
+func TestFlushRedis_UsesConfiguredPrefixAndSeparator(t *testing.T) {
+	fake := newFakeKeyspace("api*|a", "api*|b", "apix|c", "ratelimit:d")
+
+	n, err := FlushRedis(context.Background(), fake.client(t), 0, WithKeyPrefix("api*"), WithKeySeparator("|"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []string{"apix|c", "ratelimit:d"}, fake.remaining(), "glob characters in the prefix are matched literally")
+}
+
+func TestFlushRedis_PrefixVersion(t *testing.T) {
+	fake := newFakeKeyspace("ratelimit:v1:a", "ratelimit:v1:b", "ratelimit:v2:a", "ratelimit:a")
+
+	n, err := FlushRedis(context.Background(), fake.client(t), 0, WithPrefixVersion("v1"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []string{"ratelimit:a", "ratelimit:v2:a"}, fake.remaining(), "other versions survive")
+}
+
+func TestEscapeGlob(t *testing.T) {
+	assert.Equal(t, `a\*b\?c\[d\]e\\f`, escapeGlob(`a*b?c[d]e\f`))
+	assert.Equal(t, "plain:", escapeGlob("plain:"))
+}
@@ -2,7 +2,10 @@ package goratelimit
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,30 +14,48 @@ import (
 
 // NewGCRA creates a GCRA (Generic Cell Rate Algorithm) rate limiter.
 // rate is the sustained request rate per second. burst is the maximum burst size.
+// rate may be at most MaxRate and burst at most MaxBurst. State is kept in
+// integer nanoseconds, so the interval between requests, one second over
+// rate, is rounded down; rates the rounding would raise by more than 0.1%
+// are rejected. Any rate up to 1,000,000 per second is accepted, and above
+// that a rate must be within 0.1% of dividing 1e9, e.g. 500,000,000.
 // Pass WithRedis for distributed mode; omit for in-memory.
 func NewGCRA(rate, burst int64, opts ...Option) (Limiter, error) {
 	if rate <= 0 || burst <= 0 {
 		return nil, validationErr("rate and burst must be positive",
 			"Use positive integers, e.g. NewGCRA(10, 5).")
 	}
+	if err := checkRateBounds(rate, burst); err != nil {
+		return nil, err
+	}
 	o := applyOptions(opts)
-	emissionInterval := 1.0 / float64(rate)
-	burstAllowance := float64(burst-1) * emissionInterval
+	if err := o.checkStrict("NewGCRA", false, false); err != nil {
+		return nil, err
+	}
+	rate = o.perShard(rate)
+	burst = o.perShard(burst)
+	if err := checkGCRARate(rate); err != nil {
+		return nil, err
+	}
+	emissionInterval := int64(time.Second) / rate
+	if err := checkGCRABurst(burst, emissionInterval); err != nil {
+		return nil, err
+	}
 
 	if o.RedisClient != nil {
 		return wrapOptions(&gcraRedis{
 			redis:            o.RedisClient,
 			emissionInterval: emissionInterval,
-			burstAllowance:   burstAllowance,
-			burst:            burst,
+			baseLimit:        newBaseLimit(burst),
+			rate:             rate,
 			opts:             o,
 		}, o), nil
 	}
 	return wrapOptions(&gcraMemory{
 		states:           make(map[string]*gcraState),
 		emissionInterval: emissionInterval,
-		burstAllowance:   burstAllowance,
-		burst:            burst,
+		baseLimit:        newBaseLimit(burst),
+		rate:             rate,
 		opts:             o,
 	}, o), nil
 }
@@ -42,31 +63,37 @@ func NewGCRA(rate, burst int64, opts ...Option) (Limiter, error) {
 // ─── In-Memory ───────────────────────────────────────────────────────────────
 
 type gcraState struct {
-	tat float64
+	tat int64 // theoretical arrival time, Unix nanoseconds
 }
 
 type gcraMemory struct {
 	mu               sync.Mutex
 	states           map[string]*gcraState
-	emissionInterval float64
-	burstAllowance   float64
-	burst            int64
-	opts             *Options
+	emissionInterval int64 // nanoseconds per request at the sustained rate
+	*baseLimit
+	rate int64
+	opts *Options
 }
 
 func (g *gcraMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return g.AllowN(ctx, key, 1)
+	return g.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (g *gcraMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, g.limit()); ok {
+		return res, nil
+	}
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	burst, unlimited := g.opts.resolveLimit(ctx, key, g.burst)
+	burst, unlimited := g.opts.resolveLimit(ctx, key, g.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, burst, g.rate); ok {
+		return res, nil
 	}
-	burstAllowance := float64(burst-1) * g.emissionInterval
+	burstAllowance := gcraSpan(burst-1, g.emissionInterval)
 
 	state, ok := g.states[key]
 	if !ok {
@@ -74,27 +101,33 @@ func (g *gcraMemory) AllowN(ctx context.Context, key string, n int) (Result, err
 		g.states[key] = state
 	}
 
-	now := float64(g.opts.now().UnixNano()) / 1e9
-	tat := math.Max(state.tat, now)
-	increment := g.emissionInterval * float64(n)
+	now := g.opts.now().UnixNano()
+	tat := max(state.tat, now)
+	increment := gcraSpan(int64(n), g.emissionInterval)
 	newTAT := tat + increment
 	diff := newTAT - now
 
 	if diff <= burstAllowance+g.emissionInterval {
 		state.tat = newTAT
-		remaining := int64(math.Floor((burstAllowance - diff + g.emissionInterval) / g.emissionInterval))
+		remaining := (burstAllowance - diff + g.emissionInterval) / g.emissionInterval
 		return Result{
 			Allowed:   true,
 			Remaining: remaining,
 			Limit:     burst,
+			Rate:      g.rate,
+			ResetAt:   time.Unix(0, newTAT),
 		}, nil
 	}
 
-	retryAfter := time.Duration(math.Ceil(diff-burstAllowance) * float64(time.Second))
+	// The request fits once diff has shrunk to burstAllowance+emissionInterval.
+	retryAfter := ceilSecond(time.Duration(diff - burstAllowance - g.emissionInterval))
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      burst,
+		Rate:       g.rate,
+		ResetAt:    time.Unix(0, now).Add(retryAfter),
 		RetryAfter: retryAfter,
 	}, nil
 }
@@ -106,80 +139,362 @@ func (g *gcraMemory) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+func (g *gcraMemory) ResetMany(_ context.Context, keys ...string) error {
+	g.mu.Lock()
+	for _, key := range keys {
+		delete(g.states, key)
+	}
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gcraMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	g.mu.Lock()
+	_, ok := g.states[key]
+	delete(g.states, key)
+	g.mu.Unlock()
+	return ok, nil
+}
+
+func (g *gcraMemory) Describe() Description {
+	return Description{Algorithm: "gcra", Limit: g.limit(), Rate: g.rate, Dynamic: g.opts.LimitFunc != nil}
+}
+
+func (g *gcraMemory) Inspect() []KeyState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.opts.now().UnixNano()
+	burst := g.limit()
+	burstAllowance := gcraSpan(burst-1, g.emissionInterval)
+	states := make([]KeyState, 0, len(g.states))
+	for key, state := range g.states {
+		ks := KeyState{Key: key, Remaining: burst}
+		if diff := state.tat - now; diff > 0 {
+			ks.Remaining = max(0, (burstAllowance-diff+g.emissionInterval)/g.emissionInterval)
+			ks.ResetAt = time.Unix(0, state.tat)
+		}
+		states = append(states, ks)
+	}
+	return states
+}
+
+func (g *gcraMemory) SetLimit(burst int64) error {
+	if err := checkGCRABurst(burst, g.emissionInterval); err != nil {
+		return err
+	}
+	return g.baseLimit.SetLimit(burst)
+}
+
+func (g *gcraMemory) Refund(_ context.Context, key string, n int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if state, ok := g.states[key]; ok {
+		state.tat -= gcraSpan(int64(n), g.emissionInterval)
+	}
+	return nil
+}
+
+// ProjectN reports when key is next allowed after n more requests now,
+// without touching its state.
+func (g *gcraMemory) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.opts.now().UnixNano()
+	var tat int64
+	if state, ok := g.states[key]; ok {
+		tat = state.tat
+	}
+	burst, unlimited := g.opts.resolveLimit(ctx, key, g.limit())
+	if unlimited {
+		return time.Unix(0, now), nil
+	}
+	return gcraProject(burst, g.emissionInterval, tat, now, n), nil
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
+// gcraScript works in integer nanoseconds. An absolute Unix time in
+// nanoseconds does not fit exactly in a Lua double, so now arrives as seconds
+// plus nanoseconds, the TAT is stored as "seconds:nanoseconds", and the math
+// runs on the TAT's offset from now, which stays exact for spans under about
+// 104 days. Fractional microsecond TATs written by earlier versions are still
+// read. An empty now means read it from Redis TIME.
 var gcraScript = redis.NewScript(`
 local key = KEYS[1]
 local emission_interval = tonumber(ARGV[1])
 local burst_allowance = tonumber(ARGV[2])
-local now = tonumber(ARGV[3])
-local increment = tonumber(ARGV[4])
+local now_s = tonumber(ARGV[3])
+local now_ns = tonumber(ARGV[4])
+local increment = tonumber(ARGV[5])
+local expire = tonumber(ARGV[6])
 
-local tat = tonumber(redis.call('GET', key)) or now
-tat = math.max(tat, now)
+if not now_s then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now_s = tonumber(t[1])
+  now_ns = tonumber(t[2]) * 1000
+end
+
+local offset = 0
+local stored = redis.call('GET', key)
+if stored then
+  local tat_s, tat_ns = string.match(stored, '^(%d+):(%d+)$')
+  if tat_s then
+    offset = (tonumber(tat_s) - now_s) * 1000000000 + (tonumber(tat_ns) - now_ns)
+  else
+    offset = (tonumber(stored) - now_s * 1000000) * 1000 - now_ns
+  end
+end
 
-local new_tat = tat + increment
-local diff = new_tat - now
+local diff = math.max(offset, 0) + increment
 
 if diff <= burst_allowance + emission_interval then
-    redis.call('SET', key, tostring(new_tat))
-    redis.call('EXPIRE', key, math.ceil(burst_allowance + emission_interval) + 1)
+    local tat = now_ns + diff
+    local carry = math.floor(tat / 1000000000)
+    redis.call('SET', key, string.format('%d:%09d', now_s + carry, tat - carry * 1000000000))
+    if expire > 0 then
+        redis.call('PEXPIRE', key, math.ceil((math.ceil((burst_allowance + emission_interval) / 1000000000) + 1) * 1000 * expire))
+    end
     local remaining = math.floor((burst_allowance - diff + emission_interval) / emission_interval)
     return { 1, remaining, 0 }
 else
-    local retry_after = math.ceil(diff - burst_allowance)
-    return { 0, 0, retry_after }
+    return { 0, 0, diff - burst_allowance - emission_interval }
 end
 `)
 
 type gcraRedis struct {
 	redis            redis.UniversalClient
-	emissionInterval float64
-	burstAllowance   float64
-	burst            int64
-	opts             *Options
+	emissionInterval int64 // nanoseconds per request at the sustained rate
+	*baseLimit
+	rate int64
+	opts *Options
 }
 
 func (g *gcraRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return g.AllowN(ctx, key, 1)
+	return g.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (g *gcraRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	burst, unlimited := g.opts.resolveLimit(ctx, key, g.burst)
+	return g.queueAllowN(ctx, g.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (g *gcraRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, g.limit()); ok {
+		return decided(res)
+	}
+	burst, unlimited := g.opts.resolveLimit(ctx, key, g.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return decided(unlimitedResult())
+	}
+	if res, ok := costTooLarge(n, burst, g.rate); ok {
+		return decided(res)
 	}
 	fullKey := g.opts.FormatKey(key)
-	burstAllowance := float64(burst-1) * g.emissionInterval
-	now := float64(g.opts.now().UnixNano()) / 1e9
-	increment := g.emissionInterval * float64(n)
+	burstAllowance := gcraSpan(burst-1, g.emissionInterval)
+	increment := gcraSpan(int64(n), g.emissionInterval)
 
-	result, err := gcraScript.Run(ctx, g.redis, []string{fullKey},
+	now := g.opts.now()
+	keys := []string{fullKey}
+	args := []interface{}{
 		g.emissionInterval,
 		burstAllowance,
-		now,
+		scriptNow(g.opts, now.Unix()),
+		now.Nanosecond(),
 		increment,
-	).Int64Slice()
-	if err != nil {
-		if g.opts.FailOpen {
-			return Result{Allowed: true, Remaining: burst - 1, Limit: burst}, nil
+		g.opts.expireArg(),
+	}
+	cmd := runScript(ctx, gcraScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := g.opts.scriptResult(ctx, cmd, g.redis, gcraScript, keys, args)
+		if err != nil {
+			if g.opts.FailOpen {
+				g.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: burst - 1, Limit: burst, Rate: g.rate}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: burst, Rate: g.rate, DenyReason: ReasonBackendError}, redisErr(err, g.opts)
+		}
+
+		allowed := result[0] == 1
+		remaining := result[1]
+		retryAfter := ceilSecond(time.Duration(result[2]))
+
+		// The script does not return the new TAT; remaining rounds it to within
+		// one emission interval.
+		resetAt := now.Add(time.Duration(gcraSpan(burst-remaining, g.emissionInterval)))
+		if !allowed {
+			resetAt = now.Add(retryAfter)
 		}
-		return Result{Allowed: false, Remaining: 0, Limit: burst}, redisErr(err, g.opts)
+		return Result{
+			Allowed:    allowed,
+			DenyReason: denyReason(allowed),
+			Remaining:  remaining,
+			Limit:      burst,
+			Rate:       g.rate,
+			ResetAt:    resetAt,
+			RetryAfter: retryAfter,
+		}, nil
 	}
+}
 
-	allowed := result[0] == 1
-	remaining := result[1]
-	retryAfterSec := result[2]
+func (g *gcraRedis) redisClient() redis.UniversalClient {
+	return g.redis
+}
 
-	return Result{
-		Allowed:    allowed,
-		Remaining:  remaining,
-		Limit:      burst,
-		RetryAfter: time.Duration(retryAfterSec) * time.Second,
-	}, nil
+// ProjectN reports when key is next allowed after n more requests now. It
+// only reads the key's TAT, and Redis TIME under WithServerTime.
+func (g *gcraRedis) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	now := g.opts.now()
+	if g.opts.ServerTime {
+		t, err := g.redis.Time(ctx).Result()
+		if err != nil {
+			return time.Time{}, redisErr(err, g.opts)
+		}
+		now = t
+	}
+	stored, err := g.redis.Get(ctx, g.opts.FormatKey(key)).Result()
+	if err != nil && err != redis.Nil {
+		return time.Time{}, redisErr(err, g.opts)
+	}
+	tat, err := parseGCRATAT(stored)
+	if err != nil {
+		return time.Time{}, err
+	}
+	burst, unlimited := g.opts.resolveLimit(ctx, key, g.limit())
+	if unlimited {
+		return now, nil
+	}
+	return gcraProject(burst, g.emissionInterval, tat, now.UnixNano(), n), nil
 }
 
 func (g *gcraRedis) Reset(ctx context.Context, key string) error {
 	fullKey := g.opts.FormatKey(key)
 	return g.redis.Del(ctx, fullKey).Err()
 }
+
+func (g *gcraRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = g.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, g.redis, fullKeys)
+}
+
+func (g *gcraRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := g.redis.Del(ctx, g.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+// Seed writes each key's TAT as of now: a key with Remaining r has its TAT
+// burst-r emission intervals ahead.
+func (g *gcraRedis) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	burst := g.limit()
+	if err := checkSeed(states, burst); err != nil {
+		return err
+	}
+	now := g.opts.now().UnixNano()
+	burstAllowance := gcraSpan(burst-1, g.emissionInterval)
+	ttl := g.opts.seedTTL(time.Duration(ceilDiv(burstAllowance+g.emissionInterval, int64(time.Second))+1) * time.Second)
+	pipe := g.redis.Pipeline()
+	for key, s := range states {
+		tat := now + gcraSpan(burst-s.Remaining, g.emissionInterval)
+		pipe.Set(ctx, g.opts.FormatKey(key), fmt.Sprintf("%d:%09d", tat/int64(time.Second), tat%int64(time.Second)), ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (g *gcraRedis) Describe() Description {
+	return Description{Algorithm: "gcra", Limit: g.limit(), Rate: g.rate, Dynamic: g.opts.LimitFunc != nil}
+}
+
+func (g *gcraRedis) SetLimit(burst int64) error {
+	if err := checkGCRABurst(burst, g.emissionInterval); err != nil {
+		return err
+	}
+	return g.baseLimit.SetLimit(burst)
+}
+
+// ─── Internals ───────────────────────────────────────────────────────────────
+
+// parseGCRATAT reads a TAT stored by gcraScript, "seconds:nanoseconds" or
+// the fractional microseconds of earlier versions, as Unix nanoseconds. An
+// empty value, a missing key, reads as 0.
+func parseGCRATAT(stored string) (int64, error) {
+	if stored == "" {
+		return 0, nil
+	}
+	if sec, nsec, ok := strings.Cut(stored, ":"); ok {
+		s, err1 := strconv.ParseInt(sec, 10, 64)
+		ns, err2 := strconv.ParseInt(nsec, 10, 64)
+		if err1 == nil && err2 == nil {
+			return s*int64(time.Second) + ns, nil
+		}
+	} else if us, err := strconv.ParseFloat(stored, 64); err == nil {
+		// Split off whole microseconds so their digits are kept exactly.
+		whole := math.Floor(us)
+		if w, err := strconv.ParseInt(strings.SplitN(stored, ".", 2)[0], 10, 64); err == nil {
+			return w*1000 + int64(math.Round((us-whole)*1000)), nil
+		}
+		return int64(us * 1000), nil
+	}
+	return 0, fmt.Errorf("goratelimit: bad GCRA state %q", stored)
+}
+
+// gcraProject returns the earliest time a single request is allowed after
+// AllowN with n at now against a TAT of tat, both in Unix nanoseconds,
+// applying the n only if AllowN would admit them. A request fits once the
+// TAT is at most burstAllowance ahead of its arrival.
+func gcraProject(burst, emissionInterval, tat, now int64, n int) time.Time {
+	tat = max(tat, now)
+	burstAllowance := gcraSpan(burst-1, emissionInterval)
+	if int64(n) <= burst {
+		if newTAT := tat + gcraSpan(int64(n), emissionInterval); newTAT-now <= burstAllowance+emissionInterval {
+			tat = newTAT
+		}
+	}
+	return time.Unix(0, max(now, tat-burstAllowance))
+}
+
+// maxGCRASpan bounds any GCRA time span so a TAT plus a span cannot overflow
+// int64 nanoseconds.
+const maxGCRASpan = math.MaxInt64 / 2
+
+// checkGCRABurst rejects a burst whose allowance would overflow gcraSpan.
+// checkGCRARate rejects a rate whose emission interval, rounded down to
+// whole nanoseconds, would admit more than 0.1% above it.
+func checkGCRARate(rate int64) error {
+	// Rounding raises the rate by rem/(1e9-rem).
+	rem := int64(time.Second) % rate
+	if rem*1000 > int64(time.Second)-rem {
+		return validationErr(fmt.Sprintf("rate %d is not within 0.1%% of a whole-nanosecond interval", rate),
+			"Use at most 1000000 per second, or a rate that divides 1000000000, e.g. 500000000.")
+	}
+	return nil
+}
+
+func checkGCRABurst(burst, emissionInterval int64) error {
+	if burst-1 > maxGCRASpan/emissionInterval {
+		return validationErr("burst is too large for rate",
+			"burst/rate must be under about 146 years; lower burst or raise rate.")
+	}
+	return nil
+}
+
+// gcraSpan returns count*emissionInterval, saturating at maxGCRASpan.
+func gcraSpan(count, emissionInterval int64) int64 {
+	if count > maxGCRASpan/emissionInterval {
+		return maxGCRASpan
+	}
+	return count * emissionInterval
+}
+
+// ceilSecond rounds d up to a whole second, matching the Retry-After
+// granularity reported by the other algorithms.
+func ceilSecond(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return (d + time.Second - 1) / time.Second * time.Second
+}
@@ -0,0 +1,137 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hotKeyLimiter counts denials per key over a rolling window and calls
+// OnHotKey once a key reaches HotKeyThreshold, at most once per window.
+type hotKeyLimiter struct {
+	inner Limiter
+	opts  *Options
+
+	mu        sync.Mutex
+	keys      map[string]*hotKeyState
+	lastSweep time.Time
+}
+
+type hotKeyState struct {
+	denials []time.Time // most recent denials, oldest first, at most threshold
+	firedAt time.Time
+}
+
+func newHotKeyLimiter(inner Limiter, opts *Options) *hotKeyLimiter {
+	return &hotKeyLimiter{inner: inner, opts: opts, keys: make(map[string]*hotKeyState)}
+}
+
+func (h *hotKeyLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return h.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (h *hotKeyLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	result, err := h.inner.AllowN(ctx, key, n)
+	if err == nil && !result.Allowed && h.recordDenial(key) {
+		h.opts.OnHotKey(key)
+	}
+	return result, err
+}
+
+// recordDenial records a denial for key and reports whether key just became hot.
+func (h *hotKeyLimiter) recordDenial(key string) bool {
+	now := h.opts.now()
+	window := h.opts.HotKeyWindow
+	threshold := h.opts.HotKeyThreshold
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sweep(now)
+
+	st, ok := h.keys[key]
+	if !ok {
+		st = &hotKeyState{denials: make([]time.Time, 0, threshold)}
+		h.keys[key] = st
+	}
+	if len(st.denials) == threshold {
+		st.denials = append(st.denials[:0], st.denials[1:]...)
+	}
+	st.denials = append(st.denials, now)
+
+	if len(st.denials) < threshold || now.Sub(st.denials[0]) >= window {
+		return false
+	}
+	if !st.firedAt.IsZero() && now.Sub(st.firedAt) < window {
+		return false
+	}
+	st.firedAt = now
+	return true
+}
+
+// sweep drops keys with no denials in the last window, at most once per
+// window. Called with h.mu held.
+func (h *hotKeyLimiter) sweep(now time.Time) {
+	window := h.opts.HotKeyWindow
+	if now.Sub(h.lastSweep) < window {
+		return
+	}
+	h.lastSweep = now
+	for key, st := range h.keys {
+		if now.Sub(st.denials[len(st.denials)-1]) >= window {
+			delete(h.keys, key)
+		}
+	}
+}
+
+func (h *hotKeyLimiter) forget(keys ...string) {
+	h.mu.Lock()
+	for _, key := range keys {
+		delete(h.keys, key)
+	}
+	h.mu.Unlock()
+}
+
+func (h *hotKeyLimiter) Reset(ctx context.Context, key string) error {
+	h.forget(key)
+	return h.inner.Reset(ctx, key)
+}
+
+func (h *hotKeyLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	h.forget(keys...)
+	return ResetMany(ctx, h.inner, keys...)
+}
+
+func (h *hotKeyLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	h.forget(key)
+	return ResetExisted(ctx, h.inner, key)
+}
+
+func (h *hotKeyLimiter) Describe() Description {
+	return Describe(h.inner)
+}
+
+func (h *hotKeyLimiter) SetLimit(limit int64) error {
+	return SetLimit(h.inner, limit)
+}
+
+func (h *hotKeyLimiter) Inspect() []KeyState {
+	states, _ := Inspect(h.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (h *hotKeyLimiter) Unwrap() Limiter {
+	return h.inner
+}
+
+func (h *hotKeyLimiter) Refund(ctx context.Context, key string, n int) error {
+	return Refund(ctx, h.inner, key, n)
+}
+
+func (h *hotKeyLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return ProjectN(ctx, h.inner, key, n)
+}
+
+func (h *hotKeyLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	return Seed(ctx, h.inner, states)
+}
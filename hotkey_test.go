@@ -0,0 +1,91 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hotKeyRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *hotKeyRecorder) record(key string) {
+	r.mu.Lock()
+	r.keys = append(r.keys, key)
+	r.mu.Unlock()
+}
+
+func (r *hotKeyRecorder) fired() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.keys...)
+}
+
+func TestHotKeyDetector_FiresForHotKeyOnly(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	rec := &hotKeyRecorder{}
+	l := must(NewFixedWindow(2, 60, WithClock(clock), WithHotKeyDetector(5, 10*time.Second, rec.record)))
+
+	for i := 0; i < 2+4; i++ {
+		_, err := l.Allow(ctx, "quiet")
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2+5; i++ {
+		_, err := l.Allow(ctx, "hot")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"hot"}, rec.fired(), "4 denials for quiet stay under the threshold")
+
+	for i := 0; i < 20; i++ {
+		_, err := l.Allow(ctx, "hot")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"hot"}, rec.fired(), "callback should be debounced within the window")
+
+	clock.Advance(10 * time.Second)
+	for i := 0; i < 5; i++ {
+		_, err := l.Allow(ctx, "hot")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"hot", "hot"}, rec.fired(), "a key that stays hot fires again next window")
+}
+
+func TestHotKeyDetector_RollingWindow(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	rec := &hotKeyRecorder{}
+	l := must(NewFixedWindow(1, 3600, WithClock(clock), WithHotKeyDetector(3, 10*time.Second, rec.record)))
+
+	_, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	for i := 0; i < 6; i++ {
+		_, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+		clock.Advance(6 * time.Second)
+	}
+	assert.Empty(t, rec.fired(), "denials spread wider than the window should not trigger")
+}
+
+func TestHotKeyDetector_ResetClearsCount(t *testing.T) {
+	ctx := context.Background()
+	rec := &hotKeyRecorder{}
+	l := must(NewFixedWindow(1, 60, WithHotKeyDetector(3, time.Minute, rec.record)))
+
+	for i := 0; i < 3; i++ {
+		_, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Reset(ctx, "k"))
+	for i := 0; i < 3; i++ {
+		_, err := l.Allow(ctx, "k")
+		require.NoError(t, err)
+	}
+	assert.Empty(t, rec.fired())
+}
@@ -0,0 +1,55 @@
+package goratelimit
+
+import (
+	"sort"
+	"time"
+)
+
+// KeyState is a snapshot of one tracked key, as reported by Inspector.
+type KeyState struct {
+	// Key is the rate limit key as passed to Allow.
+	Key string
+
+	// Remaining is what the key could consume right now against the
+	// construction-time limit. Dynamic limits from LimitFunc are not applied.
+	Remaining int64
+
+	// ResetAt is when the key is back to its full limit, or zero if it
+	// already is.
+	ResetAt time.Time
+}
+
+// Inspector is implemented by limiters that can list the keys they track.
+// The in-memory backends of Fixed Window, Sliding Window, Sliding Window
+// Counter, Token Bucket, Leaky Bucket and GCRA implement it. Redis backends
+// do not, since listing their keys would take a SCAN of the keyspace. Option
+// and metrics wrappers forward to the limiter they wrap, so use Inspect
+// rather than a type assertion to learn whether that one supports it.
+type Inspector interface {
+	Inspect() []KeyState
+}
+
+// Inspect returns the keys tracked by l sorted by key, and false if l, or
+// the limiter its wrappers sit on (see CanRefund), does not implement
+// Inspector. Keys with no remaining state may be omitted.
+func Inspect(l Limiter) ([]KeyState, bool) {
+	i, ok := l.(Inspector)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := innermost(l).(Inspector); !ok {
+		return nil, false
+	}
+	states := i.Inspect()
+	sort.Slice(states, func(a, b int) bool { return states[a].Key < states[b].Key })
+	return states, true
+}
+
+// untilFull returns now plus the time for deficit units to drain at rate
+// units per second, or zero if there is no deficit.
+func untilFull(now time.Time, deficit, rate float64) time.Time {
+	if deficit <= 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(deficit / rate * float64(time.Second)))
+}
@@ -0,0 +1,63 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_ReportsRemainingPerAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	for name, l := range map[string]Limiter{
+		"fixed window":           must(NewFixedWindow(10, 60, WithClock(clock))),
+		"sliding window":         must(NewSlidingWindow(10, 60, WithClock(clock))),
+		"sliding window counter": must(NewSlidingWindowCounter(10, 60, WithClock(clock))),
+		"token bucket":           must(NewTokenBucket(10, 1, WithClock(clock))),
+		"leaky bucket policing":  must(NewLeakyBucket(10, 1, Policing, WithClock(clock))),
+		"leaky bucket shaping":   must(NewLeakyBucket(10, 1, Shaping, WithClock(clock))),
+		"gcra":                   must(NewGCRA(1, 10, WithClock(clock))),
+		"dry run":                must(NewTokenBucket(10, 1, WithClock(clock), WithDryRun(true))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := l.AllowN(ctx, "b", 4)
+			require.NoError(t, err)
+			_, err = l.AllowN(ctx, "a", 1)
+			require.NoError(t, err)
+
+			states, ok := Inspect(l)
+			require.True(t, ok)
+			require.Len(t, states, 2)
+			assert.Equal(t, "a", states[0].Key)
+			assert.Equal(t, int64(9), states[0].Remaining)
+			assert.Equal(t, "b", states[1].Key)
+			assert.Equal(t, int64(6), states[1].Remaining)
+			assert.True(t, states[1].ResetAt.After(clock.Now()), "ResetAt should be in the future")
+		})
+	}
+}
+
+func TestInspect_ZeroResetAtWhenFull(t *testing.T) {
+	clock := NewFakeClock()
+	l := must(NewTokenBucket(10, 1, WithClock(clock)))
+	_, err := l.AllowN(context.Background(), "k", 3)
+	require.NoError(t, err)
+
+	clock.Advance(3 * time.Second)
+	states, ok := Inspect(l)
+	require.True(t, ok)
+	require.Len(t, states, 1)
+	assert.Equal(t, int64(10), states[0].Remaining)
+	assert.True(t, states[0].ResetAt.IsZero())
+}
+
+func TestInspect_RedisNotSupported(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+	_, ok := Inspect(must(NewFixedWindow(10, 60, WithRedis(client))))
+	assert.False(t, ok)
+}
@@ -2,7 +2,10 @@ package goratelimit
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +22,24 @@ const (
 	Shaping LeakyBucketMode = "shaping"
 )
 
+// UnmarshalText parses "policing" or "shaping" (case-insensitive), so a mode
+// can be read from JSON, YAML, or flag values. Other values return an error
+// wrapping ErrInvalidParameter.
+func (m *LeakyBucketMode) UnmarshalText(text []byte) error {
+	switch mode := LeakyBucketMode(strings.ToLower(strings.TrimSpace(string(text)))); mode {
+	case Policing, Shaping:
+		*m = mode
+		return nil
+	}
+	return validationErr(fmt.Sprintf("unknown leaky bucket mode %q", text),
+		`Use "policing" or "shaping".`)
+}
+
+// UnmarshalJSON parses a JSON string with the same rules as UnmarshalText.
+func (m *LeakyBucketMode) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONText(data, m)
+}
+
 // LeakyBucketResult extends Result with shaping-specific delay information.
 type LeakyBucketResult struct {
 	Result
@@ -35,23 +56,28 @@ func NewLeakyBucket(capacity, leakRate int64, mode LeakyBucketMode, opts ...Opti
 			"Use positive integers, e.g. NewLeakyBucket(10, 2, goratelimit.Policing).")
 	}
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewLeakyBucket", false, false); err != nil {
+		return nil, err
+	}
+	capacity = o.perShard(capacity)
+	leakRate = o.perShard(leakRate)
 
 	if o.RedisClient != nil {
 		return wrapOptions(&leakyBucketRedis{
-			redis:    o.RedisClient,
-			capacity: capacity,
-			leakRate: leakRate,
-			mode:     mode,
-			opts:     o,
+			redis:     o.RedisClient,
+			baseLimit: newBaseLimit(capacity),
+			leakRate:  leakRate,
+			mode:      mode,
+			opts:      o,
 		}, o), nil
 	}
 	return wrapOptions(&leakyBucketMemory{
-		states:   make(map[string]*leakyBucketState),
-		capacity: float64(capacity),
-		leakRate: float64(leakRate),
-		limit:    capacity,
-		mode:     mode,
-		opts:     o,
+		states:    make(map[string]*leakyBucketState),
+		baseLimit: newBaseLimit(capacity),
+		leakRate:  float64(leakRate),
+		rate:      leakRate,
+		mode:      mode,
+		opts:      o,
 	}, o), nil
 }
 
@@ -66,11 +92,11 @@ type leakyBucketState struct {
 }
 
 type leakyBucketMemory struct {
-	mu       sync.Mutex
-	states   map[string]*leakyBucketState
-	capacity float64
+	mu     sync.Mutex
+	states map[string]*leakyBucketState
+	*baseLimit
 	leakRate float64
-	limit    int64
+	rate     int64
 	mode     LeakyBucketMode
 	opts     *Options
 }
@@ -86,16 +112,22 @@ func (l *leakyBucketMemory) getState(key string) *leakyBucketState {
 }
 
 func (l *leakyBucketMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return l.AllowN(ctx, key, 1)
+	return l.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (l *leakyBucketMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, l.limit()); ok {
+		return res, nil
+	}
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	limit, unlimited := l.opts.resolveLimit(ctx, key, l.limit)
+	limit, unlimited := l.opts.resolveLimit(ctx, key, l.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, limit, l.rate); ok {
+		return res, nil
 	}
 	cap := float64(limit)
 
@@ -123,14 +155,21 @@ func (l *leakyBucketMemory) allowPolicing(key string, n int, cap float64) (Resul
 			Allowed:   true,
 			Remaining: remaining,
 			Limit:     limit,
+			Rate:      l.rate,
+			ResetAt:   untilFull(now, state.level, l.leakRate),
 		}, nil
 	}
 
-	retryAfter := time.Duration(math.Ceil(cost/l.leakRate) * float64(time.Second))
+	// Wait until enough has leaked to fit cost; the level can exceed cap
+	// when a LimitFunc lowers the limit.
+	retryAfter := time.Duration(math.Ceil((state.level + cost - cap) / l.leakRate * float64(time.Second)))
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      limit,
+		Rate:       l.rate,
+		ResetAt:    now.Add(retryAfter),
 		RetryAfter: retryAfter,
 	}, nil
 }
@@ -157,14 +196,22 @@ func (l *leakyBucketMemory) allowShaping(key string, n int, cap float64) (Result
 			Allowed:    true,
 			Remaining:  remaining,
 			Limit:      limit,
+			Rate:       l.rate,
+			ResetAt:    state.nextFree,
 			RetryAfter: delay,
 		}, nil
 	}
 
+	// Wait until the queue has drained enough to fit cost.
+	retryAfter := time.Duration(math.Ceil((queueDepth + cost - cap) / l.leakRate * float64(time.Second)))
 	return Result{
-		Allowed:   false,
-		Remaining: 0,
-		Limit:     limit,
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      limit,
+		Rate:       l.rate,
+		ResetAt:    now.Add(retryAfter),
+		RetryAfter: retryAfter,
 	}, nil
 }
 
@@ -175,14 +222,64 @@ func (l *leakyBucketMemory) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+func (l *leakyBucketMemory) ResetMany(_ context.Context, keys ...string) error {
+	l.mu.Lock()
+	for _, key := range keys {
+		delete(l.states, key)
+	}
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *leakyBucketMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	_, ok := l.states[key]
+	delete(l.states, key)
+	l.mu.Unlock()
+	return ok, nil
+}
+
+func (l *leakyBucketMemory) Describe() Description {
+	return Description{Algorithm: "leaky_bucket", Limit: l.limit(), Rate: l.rate, Dynamic: l.opts.LimitFunc != nil}
+}
+
+func (l *leakyBucketMemory) Inspect() []KeyState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.opts.now()
+	states := make([]KeyState, 0, len(l.states))
+	for key, state := range l.states {
+		level := math.Max(0, state.level-now.Sub(state.lastLeak).Seconds()*l.leakRate)
+		if l.mode == Shaping {
+			level = math.Max(0, state.nextFree.Sub(now).Seconds()*l.leakRate)
+		}
+		states = append(states, KeyState{
+			Key:       key,
+			Remaining: int64(math.Max(0, math.Floor(float64(l.limit())-level))),
+			ResetAt:   untilFull(now, level, l.leakRate),
+		})
+	}
+	return states
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
+// luaPolicing and luaShaping take now in seconds. An empty now means read it
+// from Redis TIME. Both return { allowed, remaining, ms }: the retry delay of
+// a denied request, or the queue delay of an allowed Shaping one.
 var luaPolicing = redis.NewScript(`
 local key = KEYS[1]
 local capacity = tonumber(ARGV[1])
 local leak_rate = tonumber(ARGV[2])
 local now = tonumber(ARGV[3])
 local cost = tonumber(ARGV[4])
+local expire = tonumber(ARGV[5])
+
+if not now then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+end
 
 local data = redis.call('HGETALL', key)
 local level = 0
@@ -203,20 +300,22 @@ level = math.max(0, level - leaked)
 
 local allowed = 0
 local remaining = math.max(0, math.floor(capacity - level))
-local retry_after = 0
+local retry_ms = 0
 
 if level + cost <= capacity then
   level = level + cost
   remaining = math.max(0, math.floor(capacity - level))
   allowed = 1
 else
-  retry_after = math.ceil(cost / leak_rate)
+  retry_ms = math.ceil((level + cost - capacity) / leak_rate * 1000)
 end
 
 redis.call('HSET', key, 'level', tostring(level), 'last_leak', tostring(now))
-redis.call('EXPIRE', key, math.ceil(capacity / leak_rate) + 1)
+if expire > 0 then
+  redis.call('PEXPIRE', key, math.ceil((math.ceil(capacity / leak_rate) + 1) * 1000 * expire))
+end
 
-return { allowed, remaining, retry_after }
+return { allowed, remaining, retry_ms }
 `)
 
 var luaShaping = redis.NewScript(`
@@ -225,6 +324,13 @@ local capacity = tonumber(ARGV[1])
 local leak_rate = tonumber(ARGV[2])
 local now = tonumber(ARGV[3])
 local cost = tonumber(ARGV[4])
+local expire = tonumber(ARGV[5])
+
+if not now then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+end
 
 local data = redis.call('HGETALL', key)
 local next_free = now
@@ -254,74 +360,150 @@ if queue_depth + cost <= capacity then
   allowed = 1
   queue_depth = queue_depth + cost
   remaining = math.max(0, math.floor(capacity - queue_depth))
+else
+  delay_ms = math.ceil((queue_depth + cost - capacity) / leak_rate * 1000)
 end
 
 redis.call('HSET', key, 'next_free', tostring(next_free))
-redis.call('EXPIRE', key, math.ceil(capacity / leak_rate) + 1)
+if expire > 0 then
+  redis.call('PEXPIRE', key, math.ceil((math.ceil(capacity / leak_rate) + 1) * 1000 * expire))
+end
 
 return { allowed, remaining, delay_ms }
 `)
 
 type leakyBucketRedis struct {
-	redis    redis.UniversalClient
-	capacity int64
+	redis redis.UniversalClient
+	*baseLimit
 	leakRate int64
 	mode     LeakyBucketMode
 	opts     *Options
 }
 
 func (l *leakyBucketRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return l.AllowN(ctx, key, 1)
+	return l.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (l *leakyBucketRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	cap, unlimited := l.opts.resolveLimit(ctx, key, l.capacity)
+	return l.queueAllowN(ctx, l.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (l *leakyBucketRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, l.limit()); ok {
+		return decided(res)
+	}
+	cap, unlimited := l.opts.resolveLimit(ctx, key, l.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return decided(unlimitedResult())
+	}
+	if res, ok := costTooLarge(n, cap, l.leakRate); ok {
+		return decided(res)
 	}
 	fullKey := l.opts.FormatKey(key)
-	now := float64(l.opts.now().UnixNano()) / 1e9
+	now := l.opts.now()
 
 	script := luaPolicing
 	if l.mode == Shaping {
 		script = luaShaping
 	}
 
-	result, err := script.Run(ctx, l.redis, []string{fullKey},
+	keys := []string{fullKey}
+	args := []interface{}{
 		cap,
 		l.leakRate,
-		now,
+		scriptNow(l.opts, float64(now.UnixNano())/1e9),
 		n,
-	).Int64Slice()
-	if err != nil {
-		if l.opts.FailOpen {
-			return Result{Allowed: true, Remaining: cap - 1, Limit: cap}, nil
-		}
-		return Result{Allowed: false, Remaining: 0, Limit: cap}, redisErr(err, l.opts)
+		l.opts.expireArg(),
 	}
+	cmd := runScript(ctx, script, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := l.opts.scriptResult(ctx, cmd, l.redis, script, keys, args)
+		if err != nil {
+			if l.opts.FailOpen {
+				l.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: cap - 1, Limit: cap, Rate: l.leakRate}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: cap, Rate: l.leakRate, DenyReason: ReasonBackendError}, redisErr(err, l.opts)
+		}
 
-	allowed := result[0] == 1
-	remaining := result[1]
+		allowed := result[0] == 1
+		remaining := result[1]
 
-	r := Result{
-		Allowed:   allowed,
-		Remaining: remaining,
-		Limit:     cap,
-	}
+		r := Result{
+			Allowed:    allowed,
+			DenyReason: denyReason(allowed),
+			Remaining:  remaining,
+			Limit:      cap,
+			Rate:       l.leakRate,
+		}
 
-	if l.mode == Policing && !allowed {
-		retryAfterSec := result[2]
-		r.RetryAfter = time.Duration(retryAfterSec) * time.Second
-	}
-	if l.mode == Shaping && allowed {
-		delayMs := result[2]
-		r.RetryAfter = time.Duration(delayMs) * time.Millisecond
+		// result[2] is the retry delay of a denied request, or the queue delay
+		// of an allowed Shaping one. Remaining is rounded down, so an allowed
+		// ResetAt may be up to one request's leak late.
+		r.RetryAfter = time.Duration(result[2]) * time.Millisecond
+		switch {
+		case !allowed:
+			r.ResetAt = now.Add(r.RetryAfter)
+		case l.mode == Shaping:
+			r.ResetAt = now.Add(r.RetryAfter + time.Duration(float64(n)/float64(l.leakRate)*float64(time.Second)))
+		default:
+			r.ResetAt = untilFull(now, float64(cap-remaining), float64(l.leakRate))
+		}
+		return r, nil
 	}
+}
 
-	return r, nil
+func (l *leakyBucketRedis) redisClient() redis.UniversalClient {
+	return l.redis
 }
 
 func (l *leakyBucketRedis) Reset(ctx context.Context, key string) error {
 	fullKey := l.opts.FormatKey(key)
 	return l.redis.Del(ctx, fullKey).Err()
 }
+
+func (l *leakyBucketRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = l.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, l.redis, fullKeys)
+}
+
+func (l *leakyBucketRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := l.redis.Del(ctx, l.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+// Seed writes each key's level (Policing) or next free slot (Shaping) as of
+// now, in the scripts' format.
+func (l *leakyBucketRedis) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	capacity := l.limit()
+	if err := checkSeed(states, capacity); err != nil {
+		return err
+	}
+	now := float64(l.opts.now().UnixNano()) / 1e9
+	ttl := l.opts.seedTTL(time.Duration(ceilDiv(capacity, l.leakRate)+1) * time.Second)
+	pipe := l.redis.Pipeline()
+	for key, s := range states {
+		fullKey := l.opts.FormatKey(key)
+		level := float64(capacity - s.Remaining)
+		pipe.Del(ctx, fullKey)
+		if l.mode == Shaping {
+			pipe.HSet(ctx, fullKey, "next_free", strconv.FormatFloat(now+level/float64(l.leakRate), 'f', -1, 64))
+		} else {
+			pipe.HSet(ctx, fullKey, "level", strconv.FormatFloat(level, 'f', -1, 64), "last_leak", strconv.FormatFloat(now, 'f', -1, 64))
+		}
+		if ttl > 0 {
+			pipe.PExpire(ctx, fullKey, ttl)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (l *leakyBucketRedis) Describe() Description {
+	return Description{Algorithm: "leaky_bucket", Limit: l.limit(), Rate: l.leakRate, Dynamic: l.opts.LimitFunc != nil}
+}
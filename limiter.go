@@ -3,6 +3,7 @@ package goratelimit
 import (
 	"context"
 	"log"
+	"math/rand"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,33 +15,113 @@ import (
 // to allow the key without consuming quota (e.g. trusted users, internal services).
 const Unlimited int64 = -1
 
+// MaxRate is the largest rate, in requests per second, accepted by NewGCRA
+// and NewTokenBucket. GCRA tracks time in integer nanoseconds, so a higher
+// rate would round the per-request interval down to zero; below MaxRate,
+// NewGCRA also rejects rates that rounding would raise by more than 0.1%.
+const MaxRate int64 = 1_000_000_000
+
+// MaxBurst is the largest burst or capacity accepted by NewGCRA and
+// NewTokenBucket: 2^53, beyond which token counts held as float64 can no
+// longer represent every integer exactly.
+const MaxBurst int64 = 1 << 53
+
 // Limiter is the core interface for all rate limiting algorithms.
 // All implementations (in-memory and Redis-backed) satisfy this interface,
-// making algorithms swappable without changing caller code.
+// making algorithms swappable without changing caller code. Results are
+// returned by value, so the in-memory limiters allocate nothing per call;
+// AllowInto reuses a caller-owned Result in hot loops.
 type Limiter interface {
-	// Allow checks whether a single request identified by key should be allowed.
+	// Allow checks whether a single request identified by key should be
+	// allowed. It charges the cost set by WithContextCost, if any, as AllowN
+	// would.
 	Allow(ctx context.Context, key string) (Result, error)
 
 	// AllowN checks whether n requests identified by key should be allowed.
+	// The n units are admitted or denied together; when admitted, the key is
+	// left in the same state as after n sequential Allow calls.
 	AllowN(ctx context.Context, key string, n int) (Result, error)
 
-	// Reset clears all rate limit state for the given key.
+	// Reset clears all rate limit state for the given key. Reset is ordered
+	// against concurrent calls for the same key: an AllowN that finishes
+	// before Reset is cleared by it, and one that starts after sees a fresh
+	// key. The in-memory limiters get this by looking up and updating a
+	// key's state under the same lock Reset takes, so an AllowN never
+	// updates state that Reset has already dropped.
 	Reset(ctx context.Context, key string) error
 }
 
+// AllowInto runs l.Allow for key and stores the result in out, which the
+// caller may reuse across calls: with an in-memory limiter the check
+// allocates nothing. out is written even when an error is returned, as
+// Allow's Result would be.
+func AllowInto(ctx context.Context, l Limiter, key string, out *Result) error {
+	var err error
+	*out, err = l.Allow(ctx, key)
+	return err
+}
+
 // Result holds the outcome of a rate limit check.
 type Result struct {
-	Allowed    bool
-	Remaining  int64
-	Limit      int64
-	ResetAt    time.Time
+	Allowed   bool
+	Remaining int64
+	Limit     int64
+
+	// ResetAt is when the key's budget is expected to be fully restored on
+	// an allowed result, and when the request may be retried on a denied
+	// one, i.e. about now+RetryAfter. Zero if unknown.
+	ResetAt time.Time
+
+	// RetryAfter is how long to wait before retrying a denied request, and
+	// zero on an allowed one, except for a Shaping Leaky Bucket, where it is
+	// the delay to apply before serving the request.
 	RetryAfter time.Duration
+
+	// Rate is the sustained rate in requests per second for rate-defined
+	// algorithms (Token Bucket refillRate, Leaky Bucket leakRate, GCRA rate).
+	// Limit is then the burst size or capacity. Zero for window algorithms.
+	Rate int64
+
+	// Components holds per-link results for limiters built with NewChain.
+	// Nil for single limiters.
+	Components []ComponentResult
+
+	// DenyReason says why the request was denied; ReasonNone when allowed.
+	DenyReason DenyReason
+
+	// Uncharged is true when the decision took nothing from the key's
+	// budget: under SetMode or WithContextBypass, for an Unlimited limit or
+	// an allow-listed key, and when WithDryRun admits a request the limiter
+	// denied. Such a request must not be refunded.
+	Uncharged bool
+}
+
+// RemainingFraction returns Remaining/Limit clamped to [0, 1], e.g. for a
+// quota gauge. It reads the result's own Limit, so it stays right when
+// WithLimitFunc gives each key or call a different limit. An Unlimited
+// result returns 1, and any other result without a positive Limit returns 0.
+func (r Result) RemainingFraction() float64 {
+	if r.Limit <= 0 {
+		if r.Limit == Unlimited {
+			return 1
+		}
+		return 0
+	}
+	f := float64(r.Remaining) / float64(r.Limit)
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	}
+	return f
 }
 
 // Options configures behavior shared across all algorithm implementations.
 type Options struct {
-	// Store is the pluggable backend for rate limit state.
-	// Takes precedence over RedisClient if both are set.
+	// Store is the pluggable backend for rate limit state. The built-in
+	// algorithms do not read it yet; they keep state in RedisClient or in
+	// memory. Builder.Build rejects setting both Store and RedisClient.
 	Store store.Store
 
 	// RedisClient is a Redis connection for distributed rate limiting.
@@ -52,11 +133,38 @@ type Options struct {
 	// Default: "ratelimit".
 	KeyPrefix string
 
+	// PrefixVersion, when set, is appended to KeyPrefix as its own segment,
+	// "prefix:version:key". See WithPrefixVersion.
+	PrefixVersion string
+
+	// KeySeparator joins the prefix, user key and any window suffix in
+	// storage keys. See WithKeySeparator.
+	// Default: ":".
+	KeySeparator string
+
 	// FailOpen controls behavior when the backend is unreachable.
 	// If true (default), requests are allowed on errors.
 	// If false, requests are denied on errors.
 	FailOpen bool
 
+	// OnFailOpen is called with the backend error whenever a request is
+	// allowed because the backend failed. See WithOnFailOpen.
+	OnFailOpen func(err error)
+
+	// BackendRetries and BackendRetryBackoff retry Redis operations that
+	// fail with a network error before FailOpen applies. See
+	// WithBackendRetry. Default: 0 (no retries).
+	BackendRetries      int
+	BackendRetryBackoff time.Duration
+
+	// failOpenLog throttles the "failing open" warning for Redis limiters.
+	failOpenLog Limiter
+
+	// alignWindows makes in-memory Fixed Windows start at multiples of the
+	// window since the Unix epoch, as Redis ones do, instead of at a key's
+	// first request. Set by NewTokenBucketWithDailyCap for calendar days.
+	alignWindows bool
+
 	// HashTag enables Redis Cluster hash-tag wrapping of user keys.
 	// When true, keys are formatted as "prefix:{key}" instead of "prefix:key",
 	// ensuring all keys for the same logical entity route to the same slot.
@@ -64,6 +172,18 @@ type Options struct {
 	// for any Redis Cluster deployment.
 	HashTag bool
 
+	// NoExpire stops Redis limiters from setting a TTL on their keys.
+	// See WithNoExpire.
+	NoExpire bool
+
+	// TTLJitter is the largest fraction by which Redis key TTLs are
+	// randomly lengthened. See WithTTLJitter.
+	TTLJitter float64
+
+	// ServerTime makes Redis scripts read the current time with Redis TIME
+	// instead of using the client clock. See WithServerTime.
+	ServerTime bool
+
 	// LimitFunc dynamically resolves the rate limit for each key.
 	// Called with the request context (e.g. from middleware) so limits can depend on
 	// user plan, JWT claims, or other context values. Returns the effective limit
@@ -88,13 +208,54 @@ type Options struct {
 	// OnLimitExceeded is called when a request is denied due to rate limit.
 	// Use for alerting, analytics, or logging. Not called on backend errors or in dry-run.
 	OnLimitExceeded func(ctx context.Context, key string, result *Result)
+
+	// EstimateRounding controls how the Sliding Window Counter rounds its
+	// weighted estimate before comparing it to the limit.
+	// Default: no rounding. Ignored by other algorithms.
+	EstimateRounding EstimateRounding
+
+	// Carryover caps how many unused requests a Fixed Window key carries into
+	// the next window. See WithCarryover. Default: 0 (no carryover). Ignored
+	// by other algorithms.
+	Carryover int64
+
+	// KeyNormalizer rewrites keys before they are used. See WithKeyNormalizer.
+	// Default: nil (keys are used as given).
+	KeyNormalizer func(key string) string
+
+	// AllowListFunc reports keys exempt from limiting. See WithAllowList.
+	// Default: nil (no key is exempt).
+	AllowListFunc func(ctx context.Context, key string) bool
+
+	// BlockListFunc reports keys denied outright. See WithBlockList.
+	// Default: nil (no key is blocked).
+	BlockListFunc func(ctx context.Context, key string) bool
+
+	// BlockRetryAfter is the RetryAfter reported to blocked keys. See
+	// WithBlockRetryAfter. Default: 0.
+	BlockRetryAfter time.Duration
+
+	// KeyShards splits each logical key across this many physical keys.
+	// See WithKeyShards. Default: 1 (no sharding).
+	KeyShards int
+
+	// Strict makes constructors reject lossy configuration instead of
+	// silently accepting it. See WithStrictMode. Default: false.
+	Strict bool
+
+	// HotKeyThreshold, HotKeyWindow and OnHotKey configure hot-key detection.
+	// See WithHotKeyDetector. Detection is off unless all three are set.
+	HotKeyThreshold int
+	HotKeyWindow    time.Duration
+	OnHotKey        func(key string)
 }
 
 // Option is a functional option for configuring a Limiter.
 type Option func(*Options)
 
 // WithStore configures the limiter to use a custom store.Store backend.
-// This takes precedence over WithRedis if both are set.
+// It cannot be combined with WithRedis: Builder.Build returns an error
+// wrapping ErrInvalidParameter if both are set.
 func WithStore(s store.Store) Option {
 	return func(o *Options) { o.Store = s }
 }
@@ -113,6 +274,29 @@ func WithKeyPrefix(prefix string) Option {
 	return func(o *Options) { o.KeyPrefix = prefix }
 }
 
+// WithPrefixVersion adds a version segment after the key prefix:
+// "ratelimit:v2:key". Bumping it, e.g. at deploy after a policy change,
+// invalidates all state at once without scanning Redis: limiters on the new
+// version never see keys written under the old one, which age out on their
+// own TTLs (not at all under WithNoExpire; FlushRedis with the old version
+// removes them). Limiters sharing state must use the same version.
+func WithPrefixVersion(v string) Option {
+	return func(o *Options) { o.PrefixVersion = v }
+}
+
+// WithKeySeparator sets the separator joining the key prefix, the user key
+// and, for Fixed Window and Sliding Window Counter, the window suffix:
+// "prefix<sep>key<sep>window". Default: ":".
+//
+// With the default, user keys that themselves contain ":" can be ambiguous:
+// "user:1" in window 42 and "user:1:42" both format as "ratelimit:user:1:42".
+// Pick a separator that never appears in your keys, such as "|", or enable
+// WithHashTag, which brackets the user key. Changing the separator moves
+// every key, so existing state is not seen by limiters using the new one.
+func WithKeySeparator(sep string) Option {
+	return func(o *Options) { o.KeySeparator = sep }
+}
+
 // WithFailOpen controls behavior when the backend is unreachable.
 // If true (default), requests are allowed on errors.
 // If false, requests are denied on errors.
@@ -128,6 +312,48 @@ func WithHashTag() Option {
 	return func(o *Options) { o.HashTag = true }
 }
 
+// WithNoExpire stops Redis-backed limiters from setting EXPIRE/PEXPIRE on
+// their keys, for deployments that manage key lifetime externally, e.g. a
+// reaper for persisted daily quotas. Keys then live until deleted, so Redis
+// memory grows with every distinct key ever seen (and, for Fixed Window and
+// Sliding Window Counter, with every window). Ignored without WithRedis.
+func WithNoExpire(noExpire bool) Option {
+	return func(o *Options) { o.NoExpire = noExpire }
+}
+
+// expireArg tells a Redis script how to set key TTLs: 0 for none, otherwise
+// the factor to stretch them by, 1 plus a random jitter under WithTTLJitter.
+func (o *Options) expireArg() float64 {
+	if o.NoExpire {
+		return 0
+	}
+	if o.TTLJitter <= 0 {
+		return 1
+	}
+	return 1 + rand.Float64()*o.TTLJitter
+}
+
+// WithTTLJitter lengthens each Redis key TTL by a random share of up to
+// fraction, e.g. 0.1 for up to 10%, so keys created together in a traffic
+// spike do not all expire together and send a wave of cold reads a window
+// later. TTLs are only ever lengthened: a key expiring early would forget
+// requests its window still counts. The extra lifetime costs Redis memory,
+// not accuracy, since the algorithms ignore state that is out of date.
+// Ignored under WithNoExpire and without WithRedis. Default: 0 (no jitter).
+func WithTTLJitter(fraction float64) Option {
+	return func(o *Options) { o.TTLJitter = fraction }
+}
+
+// WithServerTime makes the Redis Token Bucket, GCRA, Leaky Bucket, Sliding
+// Window and Min Interval limiters take "now" from Redis TIME inside their
+// Lua scripts rather than from the client clock, so clock skew between app
+// servers cannot make nodes disagree about the same key. Clock is then
+// ignored for those decisions. Fixed Window and Sliding Window Counter still
+// derive their window keys from the client clock. Ignored without WithRedis.
+func WithServerTime(enabled bool) Option {
+	return func(o *Options) { o.ServerTime = enabled }
+}
+
 // WithLimitFunc sets a dynamic limit resolver. The function is called on
 // every Allow/AllowN with the request context and key. Use context for plan-based
 // limits (e.g. ctx.Value("plan")). Return the effective limit, Unlimited for
@@ -162,10 +388,29 @@ func WithOnLimitExceeded(fn func(ctx context.Context, key string, result *Result
 	return func(o *Options) { o.OnLimitExceeded = fn }
 }
 
+// WithCarryover lets Fixed Window credit up to maxCarry of a key's unused
+// requests from one window into the next, smoothing usage for clients that
+// stay under their quota. Credit only comes from the immediately preceding
+// window; a key idle for a whole window starts fresh. Result.Limit reports
+// the window's limit including its credit. In Redis mode the previous
+// window's key is read too, so Redis Cluster needs WithHashTag.
+// Ignored by other algorithms.
+func WithCarryover(maxCarry int64) Option {
+	return func(o *Options) { o.Carryover = maxCarry }
+}
+
+// WithEstimateRounding sets how the Sliding Window Counter rounds its weighted
+// estimate: Conservative (ceil) never over-grants, Permissive (floor) may
+// admit slightly more than the nominal rate at window boundaries.
+func WithEstimateRounding(r EstimateRounding) Option {
+	return func(o *Options) { o.EstimateRounding = r }
+}
+
 func defaultOptions() *Options {
 	return &Options{
-		KeyPrefix: "ratelimit",
-		FailOpen:  true,
+		KeyPrefix:    "ratelimit",
+		KeySeparator: ":",
+		FailOpen:     true,
 	}
 }
 
@@ -174,6 +419,9 @@ func applyOptions(opts []Option) *Options {
 	for _, opt := range opts {
 		opt(o)
 	}
+	if o.RedisClient != nil && o.FailOpen {
+		o.failOpenLog = newFailOpenLog(o.Clock)
+	}
 	return o
 }
 
@@ -185,6 +433,51 @@ func (o *Options) now() time.Time {
 	return time.Now()
 }
 
+// scriptNow returns the now argument for a Redis script: the client time now,
+// or "" under ServerTime so the script reads Redis TIME instead.
+func scriptNow[T int64 | float64](o *Options, now T) any {
+	if o.ServerTime {
+		return ""
+	}
+	return now
+}
+
+// WithKeyShards spreads each logical key across n physical keys ("key#0" …
+// "key#n-1") to smooth hot-key load on Redis. Requests rotate round-robin
+// over the shards, and the limit and rate passed to the constructor (and any
+// LimitFunc result) are divided by n, never below 1. Result.Limit and
+// Result.Remaining are scaled back up by n.
+//
+// Enforcement is looser than a single key: limits not divisible by n round
+// down per shard, and a burst can land unevenly when many keys interleave.
+// Ignored by NewConcurrency.
+func WithKeyShards(n int) Option {
+	return func(o *Options) { o.KeyShards = n }
+}
+
+// WithHotKeyDetector calls onHot when a key is denied at least threshold
+// times within window, e.g. to alert on abusive clients or add the key to a
+// block list. Denials are counted per key over a rolling window, and the
+// callback fires at most once per window for a key while it stays hot.
+//
+// onHot runs synchronously on the request path; hand off slow work to a
+// goroutine. Backend errors are not counted as denials.
+func WithHotKeyDetector(threshold int, window time.Duration, onHot func(key string)) Option {
+	return func(o *Options) {
+		o.HotKeyThreshold = threshold
+		o.HotKeyWindow = window
+		o.OnHotKey = onHot
+	}
+}
+
+// perShard divides a limit or rate across KeyShards, never below 1.
+func (o *Options) perShard(v int64) int64 {
+	if o.KeyShards <= 1 {
+		return v
+	}
+	return max(1, v/int64(o.KeyShards))
+}
+
 // resolveLimit returns the dynamic limit for key and whether the key is unlimited.
 // When unlimited is true, the caller should allow without updating state.
 func (o *Options) resolveLimit(ctx context.Context, key string, defaultLimit int64) (limit int64, unlimited bool) {
@@ -194,7 +487,7 @@ func (o *Options) resolveLimit(ctx context.Context, key string, defaultLimit int
 			return 0, true
 		}
 		if v > 0 {
-			return v, false
+			return o.perShard(v), false
 		}
 	}
 	return defaultLimit, false
@@ -202,21 +495,34 @@ func (o *Options) resolveLimit(ctx context.Context, key string, defaultLimit int
 
 // FormatKey builds a storage key. With HashTag enabled the user key is
 // wrapped in {}: "prefix:{key}" so all derived keys for the same user
-// land on the same Redis Cluster slot.
+// land on the same Redis Cluster slot. ":" stands for KeySeparator.
 func (o *Options) FormatKey(key string) string {
 	if o.HashTag {
-		return o.KeyPrefix + ":{" + key + "}"
+		return o.prefix() + o.separator() + "{" + key + "}"
 	}
-	return o.KeyPrefix + ":" + key
+	return o.prefix() + o.separator() + key
 }
 
 // FormatKeySuffix builds a storage key with an additional suffix.
 // "prefix:{key}:suffix" (hash-tag) or "prefix:key:suffix" (plain).
 func (o *Options) FormatKeySuffix(key, suffix string) string {
-	if o.HashTag {
-		return o.KeyPrefix + ":{" + key + "}:" + suffix
+	return o.FormatKey(key) + o.separator() + suffix
+}
+
+// prefix returns KeyPrefix followed by the PrefixVersion segment, if any.
+func (o *Options) prefix() string {
+	if o.PrefixVersion == "" {
+		return o.KeyPrefix
 	}
-	return o.KeyPrefix + ":" + key + ":" + suffix
+	return o.KeyPrefix + o.separator() + o.PrefixVersion
+}
+
+// separator returns KeySeparator, or ":" if it is empty.
+func (o *Options) separator() string {
+	if o.KeySeparator == "" {
+		return ":"
+	}
+	return o.KeySeparator
 }
 
 // dryRunLimiter wraps a Limiter and converts denials into allows when DryRun is true,
@@ -227,7 +533,7 @@ type dryRunLimiter struct {
 }
 
 func (d *dryRunLimiter) Allow(ctx context.Context, key string) (Result, error) {
-	return d.allowN(ctx, key, 1)
+	return d.allowN(ctx, key, CostFromContext(ctx))
 }
 
 func (d *dryRunLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
@@ -249,10 +555,13 @@ func (d *dryRunLimiter) allowN(ctx context.Context, key string, n int) (Result,
 			key, result.Limit, result.Remaining, result.RetryAfter)
 	}
 	return Result{
-		Allowed:   true,
-		Remaining: result.Remaining,
-		Limit:     result.Limit,
-		ResetAt:   result.ResetAt,
+		Allowed:    true,
+		Remaining:  result.Remaining,
+		Limit:      result.Limit,
+		ResetAt:    result.ResetAt,
+		Rate:       result.Rate,
+		Components: result.Components,
+		Uncharged:  true,
 	}, nil
 }
 
@@ -260,6 +569,44 @@ func (d *dryRunLimiter) Reset(ctx context.Context, key string) error {
 	return d.inner.Reset(ctx, key)
 }
 
+func (d *dryRunLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	return ResetMany(ctx, d.inner, keys...)
+}
+
+func (d *dryRunLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, d.inner, key)
+}
+
+func (d *dryRunLimiter) Describe() Description {
+	return Describe(d.inner)
+}
+
+func (d *dryRunLimiter) SetLimit(limit int64) error {
+	return SetLimit(d.inner, limit)
+}
+
+func (d *dryRunLimiter) Inspect() []KeyState {
+	states, _ := Inspect(d.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (d *dryRunLimiter) Unwrap() Limiter {
+	return d.inner
+}
+
+func (d *dryRunLimiter) Refund(ctx context.Context, key string, n int) error {
+	return Refund(ctx, d.inner, key, n)
+}
+
+func (d *dryRunLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return ProjectN(ctx, d.inner, key, n)
+}
+
+func (d *dryRunLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	return Seed(ctx, d.inner, states)
+}
+
 // onLimitExceededLimiter invokes OnLimitExceeded when the inner limiter denies.
 type onLimitExceededLimiter struct {
 	inner Limiter
@@ -267,13 +614,13 @@ type onLimitExceededLimiter struct {
 }
 
 func (o *onLimitExceededLimiter) Allow(ctx context.Context, key string) (Result, error) {
-	return o.AllowN(ctx, key, 1)
+	return o.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (o *onLimitExceededLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
 	result, err := o.inner.AllowN(ctx, key, n)
 	if err != nil {
-		return Result{}, err
+		return result, err
 	}
 	if !result.Allowed && o.opts.OnLimitExceeded != nil {
 		o.opts.OnLimitExceeded(ctx, key, &result)
@@ -285,13 +632,69 @@ func (o *onLimitExceededLimiter) Reset(ctx context.Context, key string) error {
 	return o.inner.Reset(ctx, key)
 }
 
-// wrapOptions applies OnLimitExceeded (when set, and not in DryRun) and DryRun (when set) around the inner limiter.
+func (o *onLimitExceededLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	return ResetMany(ctx, o.inner, keys...)
+}
+
+func (o *onLimitExceededLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, o.inner, key)
+}
+
+func (o *onLimitExceededLimiter) Describe() Description {
+	return Describe(o.inner)
+}
+
+func (o *onLimitExceededLimiter) SetLimit(limit int64) error {
+	return SetLimit(o.inner, limit)
+}
+
+func (o *onLimitExceededLimiter) Inspect() []KeyState {
+	states, _ := Inspect(o.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (o *onLimitExceededLimiter) Unwrap() Limiter {
+	return o.inner
+}
+
+func (o *onLimitExceededLimiter) Refund(ctx context.Context, key string, n int) error {
+	return Refund(ctx, o.inner, key, n)
+}
+
+func (o *onLimitExceededLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return ProjectN(ctx, o.inner, key, n)
+}
+
+func (o *onLimitExceededLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	return Seed(ctx, o.inner, states)
+}
+
+// wrapOptions applies OnLimitExceeded (when set, and not in DryRun), DryRun
+// (when set), the allow list and the block list, which takes precedence,
+// around the inner limiter, with KeyNormalizer outermost so every layer sees
+// the normalized key.
 func wrapOptions(inner Limiter, opts *Options) Limiter {
+	if opts != nil && opts.KeyShards > 1 {
+		inner = &shardedLimiter{inner: inner, shards: opts.KeyShards}
+	}
+	if opts != nil && opts.OnHotKey != nil && opts.HotKeyThreshold > 0 && opts.HotKeyWindow > 0 {
+		inner = newHotKeyLimiter(inner, opts)
+	}
 	if opts != nil && opts.OnLimitExceeded != nil && !opts.DryRun {
 		inner = &onLimitExceededLimiter{inner: inner, opts: opts}
 	}
 	if opts != nil && opts.DryRun {
-		return &dryRunLimiter{inner: inner, opts: opts}
+		inner = &dryRunLimiter{inner: inner, opts: opts}
+	}
+	if opts != nil && opts.AllowListFunc != nil {
+		inner = &allowListLimiter{inner: inner, exempt: opts.AllowListFunc, now: opts.now}
+	}
+	if opts != nil && opts.BlockListFunc != nil {
+		inner = &blockListLimiter{inner: inner, opts: opts}
+	}
+	if opts != nil && opts.KeyNormalizer != nil {
+		inner = &normalizedLimiter{inner: inner, normalize: opts.KeyNormalizer}
 	}
 	return inner
 }
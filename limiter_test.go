@@ -1,12 +1,21 @@
 package goratelimit
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log"
+	"net"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -45,6 +54,21 @@ func TestFormatKeySuffix_HashTag(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestFormatKey_PrefixVersion(t *testing.T) {
+	v1 := applyOptions([]Option{WithPrefixVersion("v1"), WithKeyPrefix("api")})
+	v2 := applyOptions([]Option{WithKeyPrefix("api"), WithPrefixVersion("v2"), WithHashTag()})
+
+	assert.Equal(t, "api:v1:user:123", v1.FormatKey("user:123"))
+	assert.Equal(t, "api:v1:user:123:42", v1.FormatKeySuffix("user:123", "42"))
+	assert.Equal(t, "api:v2:{user:123}", v2.FormatKey("user:123"))
+
+	v2.HashTag = false
+	for _, key := range []string{"k", "user:1", "v1", ""} {
+		assert.NotEqual(t, v1.FormatKey(key), v2.FormatKey(key), "key %q", key)
+		assert.NotContains(t, v2.FormatKey(key), "api:v1:")
+	}
+}
+
 func TestFormatKeySuffix_HashTag_SlotConsistency(t *testing.T) {
 	o := defaultOptions()
 	o.HashTag = true
@@ -73,6 +97,139 @@ func TestFormatKey_CustomPrefix_HashTag(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestFormatKey_Separator(t *testing.T) {
+	o := applyOptions([]Option{WithKeySeparator("|")})
+	assert.Equal(t, "ratelimit|user:123", o.FormatKey("user:123"))
+	assert.Equal(t, "ratelimit|user:123|42", o.FormatKeySuffix("user:123", "42"))
+
+	o.HashTag = true
+	assert.Equal(t, "ratelimit|{user:123}", o.FormatKey("user:123"))
+	assert.Equal(t, "ratelimit|{user:123}|42", o.FormatKeySuffix("user:123", "42"))
+}
+
+func TestFormatKey_EmptySeparatorUsesDefault(t *testing.T) {
+	o := &Options{KeyPrefix: "ratelimit"}
+	assert.Equal(t, "ratelimit:user", o.FormatKey("user"))
+	assert.Equal(t, "ratelimit:user:42", o.FormatKeySuffix("user", "42"))
+}
+
+// keyRecorder is a go-redis hook that records the keys each command names
+// and fails the command without reaching a server. GET reports a missing key
+// so multi-command limiters carry on to their writes.
+type keyRecorder struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (r *keyRecorder) DialHook(next redis.DialHook) redis.DialHook {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("keyRecorder: no server")
+	}
+}
+
+func (r *keyRecorder) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		var keys []any
+		switch strings.ToLower(cmd.Name()) {
+		case "evalsha", "eval":
+			n, _ := args[2].(int)
+			keys = args[3 : 3+n]
+		case "get":
+			keys = args[1:2]
+		case "del":
+			keys = args[1:]
+		}
+		r.mu.Lock()
+		for _, k := range keys {
+			r.keys[k.(string)] = true
+		}
+		r.mu.Unlock()
+		if cmd.Name() == "get" {
+			cmd.SetErr(redis.Nil) // a missing key lets the caller go on
+		} else {
+			cmd.SetErr(errors.New("keyRecorder: recorded"))
+		}
+		return cmd.Err()
+	}
+}
+
+func (r *keyRecorder) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			_ = r.ProcessHook(nil)(ctx, cmd)
+		}
+		return nil
+	}
+}
+
+// take returns the recorded keys, sorted, and clears them.
+func (r *keyRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.keys))
+	for k := range r.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	r.keys = make(map[string]bool)
+	return keys
+}
+
+func TestWithKeySeparator_AllowAndResetUseSameKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	constructors := map[string]func(opts ...Option) (Limiter, error){
+		"fixed_window": func(opts ...Option) (Limiter, error) {
+			return NewFixedWindow(5, 60, opts...)
+		},
+		"sliding_window": func(opts ...Option) (Limiter, error) {
+			return NewSlidingWindow(5, 60, opts...)
+		},
+		"sliding_window_counter": func(opts ...Option) (Limiter, error) {
+			return NewSlidingWindowCounter(5, 60, opts...)
+		},
+		"token_bucket": func(opts ...Option) (Limiter, error) {
+			return NewTokenBucket(5, 1, opts...)
+		},
+		"leaky_bucket": func(opts ...Option) (Limiter, error) {
+			return NewLeakyBucket(5, 1, Policing, opts...)
+		},
+		"gcra": func(opts ...Option) (Limiter, error) {
+			return NewGCRA(5, 5, opts...)
+		},
+		"min_interval": func(opts ...Option) (Limiter, error) {
+			return NewMinInterval(time.Second, opts...)
+		},
+	}
+
+	for name, newLimiter := range constructors {
+		t.Run(name, func(t *testing.T) {
+			rec := &keyRecorder{keys: make(map[string]bool)}
+			client := redis.NewClient(&redis.Options{Addr: "recorder:0", MaxRetries: -1})
+			client.AddHook(rec)
+			defer client.Close()
+
+			l, err := newLimiter(WithRedis(client), WithKeySeparator("|"), WithClock(clock))
+			require.NoError(t, err)
+
+			_, _ = l.Allow(ctx, "user:123")
+			allowed := rec.take()
+			require.NotEmpty(t, allowed)
+			for _, k := range allowed {
+				assert.True(t, strings.HasPrefix(k, "ratelimit|user:123"), "key %q should use the separator", k)
+				assert.NotContains(t, strings.TrimPrefix(k, "ratelimit|user:123"), ":", "suffix of %q should use the separator", k)
+			}
+
+			_ = l.Reset(ctx, "user:123")
+			reset := rec.take()
+			for _, k := range allowed {
+				assert.Contains(t, reset, k, "Reset should clear the key Allow used")
+			}
+		})
+	}
+}
+
 // extractHashTag returns the content between the first { and the next }.
 func extractHashTag(key string) string {
 	start := -1
@@ -0,0 +1,630 @@
+package goratelimit
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/krishna-kudari/ratelimit/store"
+)
+
+// luaStore is a test-only store.Store that runs Lua scripts through
+// gopher-lua, so the Redis algorithms' scripts can be checked without
+// Redis. redis.call supports the commands the scripts use on strings,
+// hashes and sorted sets, and the Go methods share the same dispatcher, so
+// they see what scripts wrote. Expiry follows now, e.g. a FakeClock.
+type luaStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	strs    map[string]string
+	hashes  map[string]map[string]string
+	zsets   map[string]map[string]float64
+	expires map[string]time.Time
+	scripts map[string]string // SHA1 -> source
+}
+
+// statusReply is a Redis status reply such as OK.
+type statusReply string
+
+var errNoScript = errors.New("NOSCRIPT No matching script. Please use EVAL.")
+
+func newLuaStore(now func() time.Time) *luaStore {
+	return &luaStore{
+		now:     now,
+		strs:    make(map[string]string),
+		hashes:  make(map[string]map[string]string),
+		zsets:   make(map[string]map[string]float64),
+		expires: make(map[string]time.Time),
+		scripts: make(map[string]string),
+	}
+}
+
+var _ store.Store = (*luaStore)(nil)
+
+func (s *luaStore) Eval(_ context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[scriptSHA(script)] = script
+	return s.run(script, keys, args)
+}
+
+func (s *luaStore) EvalSha(_ context.Context, sha string, keys []string, args ...interface{}) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	script, ok := s.scripts[sha]
+	if !ok {
+		return nil, errNoScript
+	}
+	return s.run(script, keys, args)
+}
+
+func (s *luaStore) ScriptLoad(_ context.Context, script string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sha := scriptSHA(script)
+	s.scripts[sha] = script
+	return sha, nil
+}
+
+func scriptSHA(script string) string {
+	sum := sha1.Sum([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// run executes script with s.mu held, converting values the way Redis does:
+// numbers are truncated to integers, false becomes nil and tables become
+// arrays up to their first nil.
+func (s *luaStore) run(script string, keys []string, args []interface{}) (interface{}, error) {
+	L := lua.NewState()
+	defer L.Close()
+
+	keyTable := L.NewTable()
+	for _, k := range keys {
+		keyTable.Append(lua.LString(k))
+	}
+	argTable := L.NewTable()
+	for _, a := range args {
+		argTable.Append(lua.LString(argString(a)))
+	}
+	L.SetGlobal("KEYS", keyTable)
+	L.SetGlobal("ARGV", argTable)
+
+	redisTable := L.NewTable()
+	L.SetField(redisTable, "call", L.NewFunction(func(L *lua.LState) int {
+		cmd := make([]string, L.GetTop())
+		for i := range cmd {
+			cmd[i] = lua.LVAsString(L.CheckAny(i + 1))
+		}
+		reply, err := s.do(cmd)
+		if err != nil {
+			L.RaiseError("%s", err)
+			return 0
+		}
+		L.Push(toLua(L, reply))
+		return 1
+	}))
+	L.SetField(redisTable, "replicate_commands", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LTrue)
+		return 1
+	}))
+	L.SetGlobal("redis", redisTable)
+
+	fn, err := L.LoadString(script)
+	if err != nil {
+		return nil, err
+	}
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		return nil, err
+	}
+	return fromLua(L.Get(-1))
+}
+
+func argString(a interface{}) string {
+	switch v := a.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toLua(L *lua.LState, reply interface{}) lua.LValue {
+	switch v := reply.(type) {
+	case nil:
+		return lua.LFalse
+	case int64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case statusReply:
+		t := L.NewTable()
+		L.SetField(t, "ok", lua.LString(v))
+		return t
+	case []interface{}:
+		t := L.NewTable()
+		for _, e := range v {
+			t.Append(toLua(L, e))
+		}
+		return t
+	}
+	panic(fmt.Sprintf("luaStore: unexpected reply %T", reply))
+}
+
+func fromLua(v lua.LValue) (interface{}, error) {
+	switch v := v.(type) {
+	case lua.LNumber:
+		return int64(v), nil
+	case lua.LString:
+		return string(v), nil
+	case lua.LBool:
+		if v {
+			return int64(1), nil
+		}
+		return nil, nil
+	case *lua.LTable:
+		if msg, ok := v.RawGetString("err").(lua.LString); ok {
+			return nil, errors.New(string(msg))
+		}
+		if status, ok := v.RawGetString("ok").(lua.LString); ok {
+			return string(status), nil
+		}
+		var out []interface{}
+		for i := 1; ; i++ {
+			e := v.RawGetInt(i)
+			if e == lua.LNil {
+				return out, nil
+			}
+			conv, err := fromLua(e)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, conv)
+		}
+	}
+	return nil, nil
+}
+
+// do runs one command with s.mu held.
+func (s *luaStore) do(cmd []string) (interface{}, error) {
+	if len(cmd) == 0 {
+		return nil, errors.New("ERR wrong number of arguments")
+	}
+	args := cmd[1:]
+	if len(args) > 0 {
+		s.expire(args[0])
+	}
+	switch strings.ToUpper(cmd[0]) {
+	case "GET":
+		if v, ok := s.strs[args[0]]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "SET":
+		s.del(args[0])
+		s.strs[args[0]] = args[1]
+		if len(args) == 4 {
+			ttl, err := strconv.ParseInt(args[3], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			unit := time.Millisecond
+			if strings.EqualFold(args[2], "EX") {
+				unit = time.Second
+			}
+			s.expires[args[0]] = s.now().Add(time.Duration(ttl) * unit)
+		}
+		return statusReply("OK"), nil
+	case "DEL":
+		var n int64
+		for _, k := range args {
+			s.expire(k)
+			if s.exists(k) {
+				s.del(k)
+				n++
+			}
+		}
+		return n, nil
+	case "INCRBY", "DECRBY":
+		delta, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return nil, errors.New("ERR value is not an integer or out of range")
+		}
+		if strings.EqualFold(cmd[0], "DECRBY") {
+			delta = -delta
+		}
+		var cur int64
+		if v, ok := s.strs[args[0]]; ok {
+			if cur, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return nil, errors.New("ERR value is not an integer or out of range")
+			}
+		}
+		cur += delta
+		s.strs[args[0]] = strconv.FormatInt(cur, 10)
+		return cur, nil
+	case "EXPIRE", "PEXPIRE":
+		ttl, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if !s.exists(args[0]) {
+			return int64(0), nil
+		}
+		unit := time.Millisecond
+		if strings.EqualFold(cmd[0], "EXPIRE") {
+			unit = time.Second
+		}
+		s.expires[args[0]] = s.now().Add(time.Duration(ttl) * unit)
+		return int64(1), nil
+	case "PTTL":
+		if !s.exists(args[0]) {
+			return int64(-2), nil
+		}
+		at, ok := s.expires[args[0]]
+		if !ok {
+			return int64(-1), nil
+		}
+		return at.Sub(s.now()).Milliseconds(), nil
+	case "HGET":
+		if v, ok := s.hashes[args[0]][args[1]]; ok {
+			return v, nil
+		}
+		return nil, nil
+	case "HGETALL":
+		h := s.hashes[args[0]]
+		fields := make([]string, 0, len(h))
+		for f := range h {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		out := []interface{}{}
+		for _, f := range fields {
+			out = append(out, f, h[f])
+		}
+		return out, nil
+	case "HSET":
+		h, ok := s.hashes[args[0]]
+		if !ok {
+			h = make(map[string]string)
+			s.hashes[args[0]] = h
+		}
+		var added int64
+		for i := 1; i+1 < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				added++
+			}
+			h[args[i]] = args[i+1]
+		}
+		return added, nil
+	case "ZADD":
+		z, ok := s.zsets[args[0]]
+		if !ok {
+			z = make(map[string]float64)
+			s.zsets[args[0]] = z
+		}
+		var added int64
+		for i := 1; i+1 < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return nil, errors.New("ERR value is not a valid float")
+			}
+			if _, ok := z[args[i+1]]; !ok {
+				added++
+			}
+			z[args[i+1]] = score
+		}
+		return added, nil
+	case "ZCARD":
+		return int64(len(s.zsets[args[0]])), nil
+	case "ZREMRANGEBYSCORE":
+		lo, hi := parseScore(args[1]), parseScore(args[2])
+		var n int64
+		for m, score := range s.zsets[args[0]] {
+			if score >= lo && score <= hi {
+				delete(s.zsets[args[0]], m)
+				n++
+			}
+		}
+		return n, nil
+	case "ZRANGE":
+		entries := s.zrange(args[0], args[1], args[2])
+		withScores := len(args) > 3 && strings.EqualFold(args[3], "WITHSCORES")
+		out := []interface{}{}
+		for _, e := range entries {
+			out = append(out, e.Member)
+			if withScores {
+				out = append(out, strconv.FormatFloat(e.Score, 'f', -1, 64))
+			}
+		}
+		return out, nil
+	case "TIME":
+		now := s.now()
+		return []interface{}{
+			strconv.FormatInt(now.Unix(), 10),
+			strconv.FormatInt(int64(now.Nanosecond()/1000), 10),
+		}, nil
+	}
+	return nil, fmt.Errorf("ERR unknown command '%s'", cmd[0])
+}
+
+func parseScore(s string) float64 {
+	switch s {
+	case "-inf":
+		return math.Inf(-1)
+	case "+inf", "inf":
+		return math.Inf(1)
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func (s *luaStore) zrange(key, start, stop string) []store.ZEntry {
+	var entries []store.ZEntry
+	for m, score := range s.zsets[key] {
+		entries = append(entries, store.ZEntry{Score: score, Member: m})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score < entries[j].Score
+		}
+		return entries[i].Member < entries[j].Member
+	})
+	lo, _ := strconv.Atoi(start)
+	hi, _ := strconv.Atoi(stop)
+	n := len(entries)
+	if lo < 0 {
+		lo += n
+	}
+	if hi < 0 {
+		hi += n
+	}
+	lo = max(lo, 0)
+	hi = min(hi, n-1)
+	if lo > hi {
+		return nil
+	}
+	return entries[lo : hi+1]
+}
+
+func (s *luaStore) exists(key string) bool {
+	_, str := s.strs[key]
+	_, hash := s.hashes[key]
+	return str || hash || len(s.zsets[key]) > 0
+}
+
+func (s *luaStore) del(key string) {
+	delete(s.strs, key)
+	delete(s.hashes, key)
+	delete(s.zsets, key)
+	delete(s.expires, key)
+}
+
+// expire drops key if its TTL has passed.
+func (s *luaStore) expire(key string) {
+	if at, ok := s.expires[key]; ok && !s.now().Before(at) {
+		s.del(key)
+	}
+}
+
+// call runs one command from Go.
+func (s *luaStore) call(cmd ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.do(cmd)
+}
+
+func (s *luaStore) Get(_ context.Context, key string) (string, error) {
+	v, err := s.call("GET", key)
+	if err != nil {
+		return "", err
+	}
+	if v == nil {
+		return "", &store.ErrKeyNotFound{Key: key}
+	}
+	return v.(string), nil
+}
+
+func (s *luaStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	if ttl > 0 {
+		_, err := s.call("SET", key, value, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+		return err
+	}
+	_, err := s.call("SET", key, value)
+	return err
+}
+
+func (s *luaStore) Del(_ context.Context, keys ...string) error {
+	_, err := s.call(append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+func (s *luaStore) IncrBy(_ context.Context, key string, n int64) (int64, error) {
+	v, err := s.call("INCRBY", key, strconv.FormatInt(n, 10))
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+func (s *luaStore) Expire(_ context.Context, key string, ttl time.Duration) error {
+	_, err := s.call("PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (s *luaStore) TTL(_ context.Context, key string) (time.Duration, error) {
+	v, err := s.call("PTTL", key)
+	if err != nil {
+		return 0, err
+	}
+	switch ms := v.(int64); ms {
+	case -1, -2:
+		return time.Duration(ms) * time.Second, nil
+	default:
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+}
+
+func (s *luaStore) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	v, err := s.call("HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	pairs := v.([]interface{})
+	out := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out[pairs[i].(string)] = pairs[i+1].(string)
+	}
+	return out, nil
+}
+
+func (s *luaStore) HSet(_ context.Context, key string, values ...interface{}) error {
+	cmd := []string{"HSET", key}
+	for _, v := range values {
+		cmd = append(cmd, argString(v))
+	}
+	_, err := s.call(cmd...)
+	return err
+}
+
+func (s *luaStore) ZAdd(_ context.Context, key string, score float64, member string) error {
+	_, err := s.call("ZADD", key, strconv.FormatFloat(score, 'f', -1, 64), member)
+	return err
+}
+
+func (s *luaStore) ZCard(_ context.Context, key string) (int64, error) {
+	v, err := s.call("ZCARD", key)
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+func (s *luaStore) ZRemRangeByScore(_ context.Context, key, min, max string) error {
+	_, err := s.call("ZREMRANGEBYSCORE", key, min, max)
+	return err
+}
+
+func (s *luaStore) ZRangeWithScores(_ context.Context, key string, start, stop int64) ([]store.ZEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(key)
+	return s.zrange(key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10)), nil
+}
+
+func (s *luaStore) Pipeline() store.Pipeline {
+	return &luaPipeline{s: s}
+}
+
+func (s *luaStore) Close() error {
+	return nil
+}
+
+// luaPipeline queues commands and runs them on Exec.
+type luaPipeline struct {
+	s    *luaStore
+	cmds [][]string
+}
+
+func (p *luaPipeline) ZAdd(_ context.Context, key string, score float64, member string) {
+	p.cmds = append(p.cmds, []string{"ZADD", key, strconv.FormatFloat(score, 'f', -1, 64), member})
+}
+
+func (p *luaPipeline) Expire(_ context.Context, key string, ttl time.Duration) {
+	p.cmds = append(p.cmds, []string{"PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10)})
+}
+
+func (p *luaPipeline) Exec(_ context.Context) error {
+	for _, cmd := range p.cmds {
+		if _, err := p.s.call(cmd...); err != nil {
+			return err
+		}
+	}
+	p.cmds = nil
+	return nil
+}
+
+func TestLuaStore_TokenBucketMatchesMemory(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClockAt(time.Unix(1_700_000_000, 250_000_000))
+	mem, err := NewTokenBucket(5, 2, WithClock(clock))
+	require.NoError(t, err)
+	ls := newLuaStore(clock.Now)
+
+	steps := []struct {
+		advance time.Duration
+		n       int
+	}{
+		{0, 1}, {0, 3}, {0, 2}, {100 * time.Millisecond, 1},
+		{400 * time.Millisecond, 1}, {0, 1}, {1250 * time.Millisecond, 3},
+		{0, 5}, {10 * time.Second, 5}, {0, 1}, {333 * time.Millisecond, 1},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		want, err := mem.AllowN(ctx, "k", step.n)
+		require.NoError(t, err)
+
+		reply, err := ls.Eval(ctx, tokenBucketLua, []string{"k"},
+			int64(5), int64(2), clock.Now().UnixMicro(), step.n, float64(1))
+		require.NoError(t, err, "step %d", i)
+		got := reply.([]interface{})
+		assert.Equal(t, boolInt(want.Allowed), got[0], "step %d allowed", i)
+		if want.Allowed {
+			// On a denial the memory backend reports Remaining 0 and the
+			// script the whole tokens left, as the Redis backend does.
+			assert.Equal(t, want.Remaining, got[1], "step %d remaining", i)
+		}
+		assert.Equal(t, int64(want.RetryAfter/time.Second), got[2], "step %d retry after", i)
+	}
+
+	ttl, err := ls.TTL(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Second, ttl, "the script sets the bucket's TTL")
+	clock.Advance(ttl)
+	state, err := ls.HGetAll(ctx, "k")
+	require.NoError(t, err)
+	assert.Empty(t, state, "the bucket expires on the store's clock")
+}
+
+func TestLuaStore_EvalSha(t *testing.T) {
+	ctx := context.Background()
+	ls := newLuaStore(time.Now)
+
+	_, err := ls.EvalSha(ctx, scriptSHA("return 1"), nil)
+	assert.ErrorIs(t, err, errNoScript)
+
+	sha, err := ls.ScriptLoad(ctx, "return redis.call('INCRBY', KEYS[1], ARGV[1])")
+	require.NoError(t, err)
+	for _, want := range []int64{3, 6} {
+		got, err := ls.EvalSha(ctx, sha, []string{"n"}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	v, err := ls.Get(ctx, "n")
+	require.NoError(t, err)
+	assert.Equal(t, "6", v, "Go methods see what scripts wrote")
+
+	_, err = ls.Eval(ctx, "return redis.call('NOPE')", nil)
+	assert.ErrorContains(t, err, "unknown command")
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
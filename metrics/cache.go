@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheStats is a snapshot of an L1 cache's counters, such as those of
+// cache.LocalCache.
+type CacheStats struct {
+	Hits   uint64 // decisions served from the cache
+	Misses uint64 // decisions that went to the backend
+	Keys   int    // keys currently cached
+}
+
+// RegisterCache exports the counters stats returns, read on every scrape:
+//   - {namespace}_cache_hits_total    counter
+//   - {namespace}_cache_misses_total  counter
+//   - {namespace}_cache_keys          gauge
+//
+// The metrics are registered with the Collector's registry the first time
+// RegisterCache is called and sum over every cache registered with c. The
+// returned function unregisters the cache; its counts then drop out of the
+// totals, which Prometheus treats as a counter reset. For a
+// cache.LocalCache:
+//
+//	unregister := collector.RegisterCache(func() metrics.CacheStats {
+//		s := lc.Stats()
+//		return metrics.CacheStats{Hits: s.Hits, Misses: s.Misses, Keys: s.Keys}
+//	})
+//	defer unregister()
+func (c *Collector) RegisterCache(stats func() CacheStats) (unregister func()) {
+	cc := c.cacheCollector()
+	cc.mu.Lock()
+	id := cc.next
+	cc.next++
+	cc.sources[id] = stats
+	cc.mu.Unlock()
+	return func() {
+		cc.mu.Lock()
+		delete(cc.sources, id)
+		cc.mu.Unlock()
+	}
+}
+
+func (c *Collector) cacheCollector() *cacheCollector {
+	c.cacheOnce.Do(func() {
+		name := func(n string) string {
+			return prometheus.BuildFQName(c.config.namespace, c.config.subsystem, n)
+		}
+		c.caches = &cacheCollector{
+			sources: make(map[int]func() CacheStats),
+			hits:    prometheus.NewDesc(name("cache_hits_total"), "Rate limit decisions served from the local cache.", nil, nil),
+			misses:  prometheus.NewDesc(name("cache_misses_total"), "Rate limit decisions the local cache sent to the backend.", nil, nil),
+			keys:    prometheus.NewDesc(name("cache_keys"), "Keys held in the local cache.", nil, nil),
+		}
+		c.config.registry.MustRegister(c.caches)
+	})
+	return c.caches
+}
+
+// cacheCollector sums the stats of the registered caches at scrape time.
+type cacheCollector struct {
+	mu      sync.Mutex
+	sources map[int]func() CacheStats
+	next    int
+
+	hits, misses, keys *prometheus.Desc
+}
+
+func (cc *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cc.hits
+	ch <- cc.misses
+	ch <- cc.keys
+}
+
+func (cc *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	cc.mu.Lock()
+	var total CacheStats
+	for _, stats := range cc.sources {
+		s := stats()
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		total.Keys += s.Keys
+	}
+	cc.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(cc.hits, prometheus.CounterValue, float64(total.Hits))
+	ch <- prometheus.MustNewConstMetric(cc.misses, prometheus.CounterValue, float64(total.Misses))
+	ch <- prometheus.MustNewConstMetric(cc.keys, prometheus.GaugeValue, float64(total.Keys))
+}
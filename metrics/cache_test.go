@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/cache"
+	"github.com/krishna-kudari/ratelimit/metrics"
+)
+
+func TestRegisterCache_LocalCache(t *testing.T) {
+	inner, err := goratelimit.NewFixedWindow(3, 60)
+	require.NoError(t, err)
+	lc := cache.New(inner, cache.WithTTL(time.Minute))
+	defer lc.Close()
+
+	reg := prometheus.NewRegistry()
+	collector := metrics.NewCollector(metrics.WithRegistry(reg))
+	unregister := collector.RegisterCache(func() metrics.CacheStats {
+		s := lc.Stats()
+		return metrics.CacheStats{Hits: s.Hits, Misses: s.Misses, Keys: s.Keys}
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, _ = lc.Allow(ctx, "a")
+	}
+	_, _ = lc.Allow(ctx, "b")
+
+	stats := lc.Stats()
+	require.NotZero(t, stats.Hits)
+	assert.Equal(t, map[string]float64{
+		"ratelimit_cache_hits_total":   float64(stats.Hits),
+		"ratelimit_cache_misses_total": float64(stats.Misses),
+		"ratelimit_cache_keys":         float64(stats.Keys),
+	}, gatherCacheMetrics(t, reg))
+
+	unregister()
+	assert.Equal(t, map[string]float64{
+		"ratelimit_cache_hits_total":   0,
+		"ratelimit_cache_misses_total": 0,
+		"ratelimit_cache_keys":         0,
+	}, gatherCacheMetrics(t, reg), "an unregistered cache drops out of the totals")
+}
+
+func gatherCacheMetrics(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	values := make(map[string]float64)
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			switch {
+			case m.GetCounter() != nil:
+				values[mf.GetName()] = m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				values[mf.GetName()] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
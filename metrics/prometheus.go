@@ -13,6 +13,7 @@ package metrics
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -35,6 +36,10 @@ type Collector struct {
 	requests *prometheus.CounterVec
 	duration *prometheus.HistogramVec
 	errors   *prometheus.CounterVec
+
+	config    *collectorConfig
+	cacheOnce sync.Once
+	caches    *cacheCollector // registered by the first RegisterCache
 }
 
 type collectorConfig struct {
@@ -77,6 +82,8 @@ var defaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25
 //   - {namespace}_request_duration_seconds  histogram (algorithm)
 //   - {namespace}_errors_total          counter   (algorithm)
 //
+// RegisterCache adds the cache metrics on first use.
+//
 // Default namespace is "ratelimit".
 func NewCollector(opts ...CollectorOption) *Collector {
 	cfg := &collectorConfig{
@@ -116,6 +123,7 @@ func NewCollector(opts ...CollectorOption) *Collector {
 		requests: requests,
 		duration: duration,
 		errors:   errors,
+		config:   cfg,
 	}
 }
 
@@ -136,7 +144,7 @@ type instrumentedLimiter struct {
 }
 
 func (l *instrumentedLimiter) Allow(ctx context.Context, key string) (goratelimit.Result, error) {
-	return l.AllowN(ctx, key, 1)
+	return l.AllowN(ctx, key, goratelimit.CostFromContext(ctx))
 }
 
 func (l *instrumentedLimiter) AllowN(ctx context.Context, key string, n int) (goratelimit.Result, error) {
@@ -157,6 +165,44 @@ func (l *instrumentedLimiter) Reset(ctx context.Context, key string) error {
 	return l.inner.Reset(ctx, key)
 }
 
+func (l *instrumentedLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	return goratelimit.ResetMany(ctx, l.inner, keys...)
+}
+
+func (l *instrumentedLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return goratelimit.ResetExisted(ctx, l.inner, key)
+}
+
+func (l *instrumentedLimiter) Describe() goratelimit.Description {
+	return goratelimit.Describe(l.inner)
+}
+
+func (l *instrumentedLimiter) SetLimit(limit int64) error {
+	return goratelimit.SetLimit(l.inner, limit)
+}
+
+func (l *instrumentedLimiter) Inspect() []goratelimit.KeyState {
+	states, _ := goratelimit.Inspect(l.inner)
+	return states
+}
+
+// Unwrap returns the instrumented limiter.
+func (l *instrumentedLimiter) Unwrap() goratelimit.Limiter {
+	return l.inner
+}
+
+func (l *instrumentedLimiter) Refund(ctx context.Context, key string, n int) error {
+	return goratelimit.Refund(ctx, l.inner, key, n)
+}
+
+func (l *instrumentedLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return goratelimit.ProjectN(ctx, l.inner, key, n)
+}
+
+func (l *instrumentedLimiter) Seed(ctx context.Context, states map[string]goratelimit.StateSnapshot) error {
+	return goratelimit.Seed(ctx, l.inner, states)
+}
+
 func (l *instrumentedLimiter) recordDecision(result *goratelimit.Result) {
 	decision := "denied"
 	if result.Allowed {
@@ -97,6 +97,18 @@ func TestWrap_Reset(t *testing.T) {
 	require.True(t, result.Allowed, "expected allowed after reset")
 }
 
+func TestWrap_CanRefund(t *testing.T) {
+	collector := metrics.NewCollector(metrics.WithRegistry(prometheus.NewRegistry()))
+
+	memory, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+	assert.True(t, goratelimit.CanRefund(metrics.Wrap(memory, metrics.FixedWindow, collector)))
+
+	leaky, err := goratelimit.NewLeakyBucket(1, 1, goratelimit.Policing)
+	require.NoError(t, err)
+	assert.False(t, goratelimit.CanRefund(metrics.Wrap(leaky, metrics.LeakyBucket, collector)))
+}
+
 func TestCollectorOptions(t *testing.T) {
 	reg := prometheus.NewRegistry()
 	collector := metrics.NewCollector(
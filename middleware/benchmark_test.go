@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+// discardWriter is a reusable ResponseWriter so the benchmarks measure the
+// middleware rather than httptest.ResponseRecorder.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func benchmarkRateLimit(b *testing.B, limiter goratelimit.Limiter) {
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: limiter,
+		KeyFunc: func(*http.Request) string { return "bench" },
+		DeniedHandler: func(w http.ResponseWriter, _ *http.Request, _ *goratelimit.Result) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+	})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest("GET", "/", nil)
+	w := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRateLimit_Allowed(b *testing.B) {
+	benchmarkRateLimit(b, mustLimiter(goratelimit.NewTokenBucket(goratelimit.MaxBurst, goratelimit.MaxRate)))
+}
+
+func BenchmarkRateLimit_Denied(b *testing.B) {
+	benchmarkRateLimit(b, mustLimiter(goratelimit.NewFixedWindow(1, 3600)))
+}
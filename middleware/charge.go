@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// ChargeConfig configures ChargeByResponseSizeWithConfig.
+type ChargeConfig struct {
+	// Limiter is charged one unit per response byte (required). A Token
+	// Bucket or GCRA sized in bytes suits bandwidth quotas.
+	Limiter goratelimit.Limiter
+
+	// KeyFunc extracts the rate limit key from the request (required).
+	KeyFunc KeyFunc
+
+	// DeniedHandler responds to requests for a key whose last charge was
+	// denied, until that denial's RetryAfter has elapsed.
+	// Default: responds with 429, like Config.DeniedHandler.
+	DeniedHandler DeniedHandler
+
+	// ChargeErrorHandler is called when charging the limiter fails. The
+	// response has already been written, so it can only log or record.
+	// Default: errors are ignored.
+	ChargeErrorHandler func(r *http.Request, err error)
+}
+
+// ChargeByResponseSize creates HTTP middleware that charges limiter for
+// bandwidth after the fact: once the handler returns, it calls
+// AllowN(ctx, key, bytesWritten).
+//
+//	quota, _ := goratelimit.NewTokenBucket(100<<20, 1<<20) // 100 MiB burst, 1 MiB/s
+//	mux.Handle("/download/", middleware.ChargeByResponseSize(quota, middleware.KeyByAPIKey)(handler))
+//
+// The request being charged is never blocked. When a charge is denied, the
+// key's later requests are rejected until the denial's RetryAfter elapses.
+// Because AllowN admits all or nothing, a denied charge consumes no quota;
+// the block stands in for it. Blocks are tracked in this process only.
+func ChargeByResponseSize(limiter goratelimit.Limiter, keyFunc KeyFunc) func(http.Handler) http.Handler {
+	return ChargeByResponseSizeWithConfig(ChargeConfig{
+		Limiter: limiter,
+		KeyFunc: keyFunc,
+	})
+}
+
+// ChargeByResponseSizeWithConfig is ChargeByResponseSize with full
+// configuration control.
+func ChargeByResponseSizeWithConfig(cfg ChargeConfig) func(http.Handler) http.Handler {
+	if cfg.Limiter == nil {
+		panic("goratelimit/middleware: Limiter is required")
+	}
+	if cfg.KeyFunc == nil {
+		panic("goratelimit/middleware: KeyFunc is required")
+	}
+	if cfg.DeniedHandler == nil {
+		cfg.DeniedHandler = defaultDeniedHandler("", 0, nil)
+	}
+	blocks := &chargeBlocks{until: make(map[string]goratelimit.Result)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := cfg.KeyFunc(r)
+			if result, blocked := blocks.get(key, time.Now()); blocked {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(result.RetryAfter.Seconds()+0.5), 10))
+				cfg.DeniedHandler(w, r, &result)
+				return
+			}
+
+			r = r.WithContext(ContextWithKey(r.Context(), key))
+			cw := &countingWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			if cw.written == 0 {
+				return
+			}
+
+			result, err := cfg.Limiter.AllowN(context.WithoutCancel(r.Context()), key, int(cw.written))
+			if err != nil {
+				if cfg.ChargeErrorHandler != nil {
+					cfg.ChargeErrorHandler(r, err)
+				}
+				return
+			}
+			if !result.Allowed && result.RetryAfter > 0 {
+				blocks.set(key, result, time.Now())
+			}
+		})
+	}
+}
+
+// chargeBlocks remembers keys whose last charge was denied, keyed to the
+// denying Result with ResetAt set to the end of the block.
+type chargeBlocks struct {
+	mu        sync.Mutex
+	until     map[string]goratelimit.Result
+	lastSweep time.Time
+}
+
+// get returns the denial for key with RetryAfter set to the time left, or
+// false if key is not blocked.
+func (b *chargeBlocks) get(key string, now time.Time) (goratelimit.Result, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result, ok := b.until[key]
+	if !ok {
+		return goratelimit.Result{}, false
+	}
+	if !now.Before(result.ResetAt) {
+		delete(b.until, key)
+		return goratelimit.Result{}, false
+	}
+	result.RetryAfter = result.ResetAt.Sub(now)
+	return result, true
+}
+
+func (b *chargeBlocks) set(key string, result goratelimit.Result, now time.Time) {
+	result.ResetAt = now.Add(result.RetryAfter)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.until[key] = result
+	// Drop expired blocks for keys that never came back, at most once a minute.
+	if now.Sub(b.lastSweep) >= time.Minute {
+		b.lastSweep = now
+		for k, r := range b.until {
+			if !now.Before(r.ResetAt) {
+				delete(b.until, k)
+			}
+		}
+	}
+}
+
+// countingWriter counts the body bytes written through it.
+type countingWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+func payloadHandler(size int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", size)))
+	})
+}
+
+func TestChargeByResponseSize_ChargesBytesWritten(t *testing.T) {
+	clock := goratelimit.NewFakeClock()
+	limiter := mustLimiter(goratelimit.NewTokenBucket(1000, 1, goratelimit.WithClock(clock)))
+	handler := middleware.ChargeByResponseSize(limiter, middleware.KeyByIP)(payloadHandler(300))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "8.8.8.8:1111"
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 300, rr.Body.Len())
+	}
+
+	res, err := limiter.AllowN(context.Background(), "8.8.8.8", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000-600-1), res.Remaining, "two 300-byte responses should consume 600 tokens")
+}
+
+func TestChargeByResponseSize_DeniedChargeBlocksLaterRequests(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewTokenBucket(500, 1))
+	handler := middleware.ChargeByResponseSize(limiter, middleware.KeyByIP)(payloadHandler(300))
+	serve := func(ip string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1111"
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, serve("9.9.9.9").Code)
+	assert.Equal(t, http.StatusOK, serve("9.9.9.9").Code, "over-limit charge must not block the response it charges")
+
+	rr := serve("9.9.9.9")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("9.9.9.10").Code, "other keys are unaffected")
+}
+
+func TestChargeByResponseSize_KeyInContext(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewTokenBucket(1000, 1))
+	var got string
+	handler := middleware.ChargeByResponseSize(limiter, middleware.KeyByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = middleware.KeyFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "8.8.4.4:1111"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "8.8.4.4", got)
+}
@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+// newClientCert returns a self-signed client certificate for cn.
+func newClientCert(t *testing.T, cn string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// newMTLSServer starts a TLS server that requests (but does not require)
+// client certificates and responds with the KeyByClientCert key.
+func newMTLSServer(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(h)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func clientWithCert(srv *httptest.Server, cert *tls.Certificate) *http.Client {
+	client := srv.Client()
+	transport := client.Transport.(*http.Transport).Clone()
+	if cert != nil {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+	}
+	return &http.Client{Transport: transport}
+}
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestKeyByClientCert_Fingerprint(t *testing.T) {
+	srv := newMTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, middleware.KeyByClientCert(r))
+	}))
+
+	certA := newClientCert(t, "client-a")
+	certB := newClientCert(t, "client-b")
+	clientA := clientWithCert(srv, &certA)
+
+	_, first := get(t, clientA, srv.URL)
+	_, second := get(t, clientA, srv.URL)
+	assert.Equal(t, middleware.CertFingerprint(certA.Leaf), first)
+	assert.Len(t, first, 64, "expected hex-encoded SHA-256")
+	assert.Equal(t, first, second, "fingerprint should be stable across requests")
+
+	// A fresh connection with the same certificate yields the same key.
+	_, fresh := get(t, clientWithCert(srv, &certA), srv.URL)
+	assert.Equal(t, first, fresh)
+
+	_, other := get(t, clientWithCert(srv, &certB), srv.URL)
+	assert.NotEqual(t, first, other, "different certificates should yield different keys")
+}
+
+func TestKeyByClientCert_FallsBackToIP(t *testing.T) {
+	srv := newMTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, middleware.KeyByClientCert(r))
+	}))
+
+	_, key := get(t, clientWithCert(srv, nil), srv.URL)
+	assert.Equal(t, "127.0.0.1", key)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.7:4321"
+	assert.Equal(t, "192.0.2.7", middleware.KeyByClientCert(req), "plain HTTP should use the client IP")
+}
+
+func TestRateLimit_KeyByClientCert(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(2, 60)
+	require.NoError(t, err)
+	srv := newMTLSServer(t, middleware.RateLimit(limiter, middleware.KeyByClientCert)(okHandler()))
+
+	certA := newClientCert(t, "client-a")
+	certB := newClientCert(t, "client-b")
+	clientA := clientWithCert(srv, &certA)
+
+	for i := 0; i < 2; i++ {
+		code, _ := get(t, clientA, srv.URL)
+		require.Equal(t, http.StatusOK, code, "request %d", i+1)
+	}
+	code, _ := get(t, clientA, srv.URL)
+	assert.Equal(t, http.StatusTooManyRequests, code, "client-a should be limited")
+
+	// Same source IP, different certificate: a separate bucket.
+	code, _ = get(t, clientWithCert(srv, &certB), srv.URL)
+	assert.Equal(t, http.StatusOK, code, "client-b should have its own limit")
+}
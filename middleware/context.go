@@ -0,0 +1,21 @@
+package middleware
+
+import "context"
+
+type keyCtxKey struct{}
+
+// ContextWithKey returns a copy of ctx carrying the resolved rate limit key.
+// The middlewares in this module call it once the key is extracted, before
+// the limiter is consulted.
+func ContextWithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyCtxKey{}, key)
+}
+
+// KeyFromContext returns the rate limit key a middleware resolved for the
+// request, e.g. to correlate logs with rate limit decisions. ok is false if
+// no rate limit middleware has run. With stacked middlewares it returns the
+// innermost one's key.
+func KeyFromContext(ctx context.Context) (key string, ok bool) {
+	key, ok = ctx.Value(keyCtxKey{}).(string)
+	return key, ok
+}
@@ -0,0 +1,288 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// Adapter connects Core to one request in a web framework. Core asks it for
+// the key, writes rate limit headers through SetHeader, and finishes the
+// request with exactly one call to Next or Deny.
+type Adapter interface {
+	// Key returns the rate limit key, with any framework-specific fallback
+	// for an empty key already applied.
+	Key() string
+
+	// SetHeader sets a response header.
+	SetHeader(key, value string)
+
+	// Deny writes the rejection for a denied request.
+	Deny(result *goratelimit.Result) error
+
+	// Next passes the request on to the wrapped handler.
+	Next() error
+}
+
+// PathAdapter is implemented by adapters that know the request path, so
+// Config.ExcludePaths applies to them.
+type PathAdapter interface {
+	Path() string
+}
+
+// CostAdapter is implemented by adapters that charge a request more than
+// one unit. Cost is called after Key.
+type CostAdapter interface {
+	Cost() int
+}
+
+// StatusAdapter is implemented by adapters that can report the status the
+// handler responded with, so Config.ChargeOnStatus applies to them.
+type StatusAdapter interface {
+	// NextStatus is Next, also returning the response status.
+	NextStatus() (int, error)
+}
+
+// Core is the framework-agnostic decision flow behind the middlewares in
+// this module: path exclusion, empty keys, charging the limiter, error
+// fallback, shaping and deny delays, and the X-RateLimit-* or RateLimit-*,
+// Retry-After, X-RateLimit-Delay, Link, X-RateLimit-Backoff and
+// Surrogate-Control headers. Build it once per middleware with NewCore and
+// call Run for each request.
+//
+// Core reads the framework-neutral fields of Config: Limiter,
+// EmptyKeyPolicy, FallbackOnError, ExcludePaths, Headers, HeaderStyle,
+// ApplyShapingDelay, DenyDelay, DenyDelayFunc, ChargeOnStatus,
+// ExposeAlgorithm, ComponentHeaders, DocumentationURL, PenaltyBox and
+// SurrogateControl. The request-typed fields (KeyFunc, Cost, handlers,
+// bypass rules) belong to the adapter.
+type Core struct {
+	cfg         Config
+	sendHeaders bool
+	policy      string
+	dynamic     bool
+	algorithm   string
+	dedup       bool
+}
+
+// NewCore prepares cfg for Run. It panics if cfg.Limiter is nil, or if
+// cfg.ChargeOnStatus is set and goratelimit.CanRefund(cfg.Limiter) is false.
+func NewCore(cfg Config) *Core {
+	if cfg.Limiter == nil {
+		panic("goratelimit/middleware: Limiter is required")
+	}
+	if cfg.ChargeOnStatus != nil && !goratelimit.CanRefund(cfg.Limiter) {
+		panic("goratelimit/middleware: ChargeOnStatus requires a Limiter that can refund (see goratelimit.CanRefund)")
+	}
+	description := goratelimit.Describe(cfg.Limiter)
+	c := &Core{
+		cfg:         cfg,
+		sendHeaders: cfg.Headers == nil || *cfg.Headers,
+		dynamic:     description.Dynamic,
+		dedup:       dedupable(cfg.Limiter),
+	}
+	if cfg.ExposeAlgorithm != nil && *cfg.ExposeAlgorithm {
+		c.algorithm = description.Algorithm
+	}
+	if cfg.HeaderStyle != HeaderStyleLegacy {
+		c.policy = ratePolicy(description)
+	}
+	return c
+}
+
+// Run is shorthand for NewCore(cfg).Run(ctx, a). Middlewares should build
+// their Core once instead.
+func Run(ctx context.Context, cfg Config, a Adapter) error {
+	return NewCore(cfg).Run(ctx, a)
+}
+
+// Run decides one request and finishes it through a. ctx is the request
+// context; the limiter sees it with the key attached (see ContextWithKey).
+// Run returns the error from Next or Deny, or the limiter's error when
+// FallbackOnError is FallbackNone, leaving the response to the caller.
+func (c *Core) Run(ctx context.Context, a Adapter) error {
+	if p, ok := a.(PathAdapter); ok && c.cfg.ExcludePaths != nil && c.cfg.ExcludePaths[p.Path()] {
+		return a.Next()
+	}
+
+	key := a.Key()
+	if key == "" {
+		switch c.cfg.EmptyKeyPolicy {
+		case EmptyKeyAllow:
+			return a.Next()
+		case EmptyKeyDeny:
+			return a.Deny(&goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+		}
+	}
+	ctx = ContextWithKey(ctx, key)
+	cost := 1
+	if ca, ok := a.(CostAdapter); ok {
+		cost = ca.Cost()
+	}
+
+	// result is pooled and recycled once Run returns, so it only feeds the
+	// headers; Deny and the denial callbacks get their own copy to keep.
+	result := resultPool.Get().(*goratelimit.Result)
+	defer releaseResult(result)
+	var err error
+	charged := true
+	if ledger := ledgerFromContext(ctx); c.dedup && ledger != nil {
+		*result, charged, err = ledger.allow(ctx, c.cfg.Limiter, key, cost)
+	} else {
+		*result, err = c.cfg.Limiter.AllowN(ctx, key, cost)
+	}
+	if err != nil {
+		switch c.cfg.FallbackOnError {
+		case FallbackAllow:
+			return a.Next()
+		case FallbackDeny:
+			denied := *result
+			denied.Allowed = false
+			denied.DenyReason = goratelimit.ReasonBackendError
+			return a.Deny(&denied)
+		}
+		return err
+	}
+
+	if c.sendHeaders {
+		c.setRateLimitHeaders(a, result)
+		if c.dynamic {
+			a.SetHeader("X-RateLimit-Limit-Policy", "dynamic")
+		}
+		if c.cfg.ComponentHeaders {
+			setComponentHeaders(a, result)
+		}
+	}
+	if c.algorithm != "" {
+		a.SetHeader("X-RateLimit-Algorithm", c.algorithm)
+	}
+
+	if !result.Allowed {
+		denied := *result
+		if result.RetryAfter > 0 {
+			a.SetHeader("Retry-After", strconv.FormatInt(int64(result.RetryAfter.Seconds()+0.5), 10))
+		}
+		if c.cfg.DocumentationURL != "" {
+			a.SetHeader("Link", "<"+c.cfg.DocumentationURL+`>; rel="help"`)
+		}
+		if c.cfg.PenaltyBox != nil {
+			setBackoffHeader(a, c.cfg.PenaltyBox, key)
+		}
+		if c.cfg.SurrogateControl != nil {
+			if v := c.cfg.SurrogateControl(&denied); v != "" {
+				a.SetHeader("Surrogate-Control", v)
+			}
+		}
+		if d := c.denyDelay(key, &denied); d > 0 {
+			if err := sleep(ctx, d); err != nil {
+				return err
+			}
+		}
+		return a.Deny(&denied)
+	}
+	if result.RetryAfter > 0 {
+		// An allowed request with a delay is being shaped.
+		if c.cfg.ApplyShapingDelay {
+			if err := sleep(ctx, result.RetryAfter); err != nil {
+				return err
+			}
+		} else if c.sendHeaders {
+			a.SetHeader("X-RateLimit-Delay", strconv.FormatInt(result.RetryAfter.Milliseconds(), 10))
+		}
+	}
+	// Only a request that took units can give them back: not a repeat for
+	// a pair the ledger already charged, nor a result the limiter marks
+	// Uncharged (a bypass, an Unlimited limit, a dry-run denial).
+	if sa, ok := a.(StatusAdapter); ok && c.cfg.ChargeOnStatus != nil && charged && !result.Uncharged {
+		status, err := sa.NextStatus()
+		if !c.cfg.ChargeOnStatus(status) {
+			// The handler did no billable work: cancel the reservation.
+			_ = goratelimit.Refund(context.WithoutCancel(ctx), c.cfg.Limiter, key, cost)
+		}
+		return err
+	}
+	return a.Next()
+}
+
+// denyDelay returns how long to hold the request denied with result.
+func (c *Core) denyDelay(key string, result *goratelimit.Result) time.Duration {
+	if c.cfg.DenyDelayFunc != nil {
+		return c.cfg.DenyDelayFunc(key, result)
+	}
+	return c.cfg.DenyDelay
+}
+
+// PenaltyDenyDelay returns a Config.DenyDelayFunc holding denied requests
+// for base times the key's penalty factor in pb, capped at maxDelay, so the hold
+// grows with repeated violations as pb's cooldown does. pb should wrap the
+// middleware's Limiter.
+func PenaltyDenyDelay(pb *goratelimit.PenaltyBox, base, maxDelay time.Duration) func(key string, result *goratelimit.Result) time.Duration {
+	return func(key string, _ *goratelimit.Result) time.Duration {
+		d := time.Duration(float64(base) * pb.Factor(key))
+		if d > maxDelay || d < 0 {
+			return maxDelay
+		}
+		return d
+	}
+}
+
+// sleep waits for d or until ctx is done, returning ctx's error in that case.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var resultPool = sync.Pool{New: func() any { return new(goratelimit.Result) }}
+
+func releaseResult(result *goratelimit.Result) {
+	*result = goratelimit.Result{}
+	resultPool.Put(result)
+}
+
+// ─── Headers ─────────────────────────────────────────────────────────────────
+
+func (c *Core) setRateLimitHeaders(a Adapter, result *goratelimit.Result) {
+	if c.cfg.HeaderStyle != HeaderStyleDraft {
+		a.SetHeader("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		a.SetHeader("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		if !result.ResetAt.IsZero() {
+			a.SetHeader("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+		}
+	}
+	if c.cfg.HeaderStyle != HeaderStyleLegacy {
+		a.SetHeader("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		a.SetHeader("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		if reset, ok := draftReset(result); ok {
+			a.SetHeader("RateLimit-Reset", strconv.FormatInt(reset, 10))
+		}
+		if c.policy != "" {
+			a.SetHeader("RateLimit-Policy", c.policy)
+		}
+	}
+}
+
+func setBackoffHeader(a Adapter, pb *goratelimit.PenaltyBox, key string) {
+	b, ok := pb.Backoff(key)
+	if !ok {
+		return
+	}
+	a.SetHeader("X-RateLimit-Backoff", fmt.Sprintf("base=%d; max=%d; attempt=%d",
+		int64(b.Base.Seconds()+0.5), int64(b.Max.Seconds()+0.5), b.Attempt))
+}
+
+func setComponentHeaders(a Adapter, result *goratelimit.Result) {
+	for _, c := range result.Components {
+		a.SetHeader("X-RateLimit-Limit-"+c.ID, strconv.FormatInt(c.Limit, 10))
+		a.SetHeader("X-RateLimit-Remaining-"+c.ID, strconv.FormatInt(c.Remaining, 10))
+	}
+}
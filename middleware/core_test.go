@@ -0,0 +1,197 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+	"github.com/krishna-kudari/ratelimit/ratelimittest"
+)
+
+// fakeAdapter records what Core does with one request.
+type fakeAdapter struct {
+	key     string
+	path    string
+	headers map[string]string
+	denied  *goratelimit.Result
+	nexted  bool
+}
+
+func newFakeAdapter(key string) *fakeAdapter {
+	return &fakeAdapter{key: key, path: "/api", headers: map[string]string{}}
+}
+
+func (a *fakeAdapter) Key() string                 { return a.key }
+func (a *fakeAdapter) Path() string                { return a.path }
+func (a *fakeAdapter) SetHeader(key, value string) { a.headers[key] = value }
+func (a *fakeAdapter) Next() error                 { a.nexted = true; return nil }
+
+func (a *fakeAdapter) Deny(result *goratelimit.Result) error {
+	a.denied = result
+	return nil
+}
+
+func TestCore_AllowsAndDenies(t *testing.T) {
+	core := middleware.NewCore(middleware.Config{
+		Limiter: mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+	})
+	ctx := context.Background()
+
+	a := newFakeAdapter("k")
+	require.NoError(t, core.Run(ctx, a))
+	assert.True(t, a.nexted)
+	assert.Nil(t, a.denied)
+	assert.Equal(t, "1", a.headers["X-RateLimit-Limit"])
+	assert.Equal(t, "0", a.headers["X-RateLimit-Remaining"])
+
+	a = newFakeAdapter("k")
+	require.NoError(t, core.Run(ctx, a))
+	assert.False(t, a.nexted)
+	require.NotNil(t, a.denied)
+	assert.Equal(t, goratelimit.ReasonOverLimit, a.denied.DenyReason)
+	assert.NotEmpty(t, a.headers["Retry-After"])
+}
+
+func TestCore_Headers(t *testing.T) {
+	off := false
+	core := middleware.NewCore(middleware.Config{
+		Limiter:          ratelimittest.AlwaysDeny(30 * time.Second),
+		Headers:          &off,
+		DocumentationURL: "https://example.com/limits",
+	})
+	a := newFakeAdapter("k")
+	require.NoError(t, core.Run(context.Background(), a))
+	assert.NotContains(t, a.headers, "X-RateLimit-Limit")
+	assert.Equal(t, "30", a.headers["Retry-After"])
+	assert.Equal(t, `<https://example.com/limits>; rel="help"`, a.headers["Link"])
+}
+
+func TestCore_ExcludePaths(t *testing.T) {
+	limiter := ratelimittest.AlwaysDeny(time.Second)
+	core := middleware.NewCore(middleware.Config{
+		Limiter:      limiter,
+		ExcludePaths: map[string]bool{"/health": true},
+	})
+	a := newFakeAdapter("k")
+	a.path = "/health"
+	require.NoError(t, core.Run(context.Background(), a))
+	assert.True(t, a.nexted)
+	assert.Empty(t, a.headers)
+	assert.Zero(t, limiter.CallCount())
+}
+
+func TestCore_EmptyKeyPolicy(t *testing.T) {
+	limiter := ratelimittest.AlwaysAllow()
+	ctx := context.Background()
+
+	a := newFakeAdapter("")
+	require.NoError(t, middleware.Run(ctx, middleware.Config{Limiter: limiter, EmptyKeyPolicy: middleware.EmptyKeyAllow}, a))
+	assert.True(t, a.nexted)
+
+	a = newFakeAdapter("")
+	require.NoError(t, middleware.Run(ctx, middleware.Config{Limiter: limiter, EmptyKeyPolicy: middleware.EmptyKeyDeny}, a))
+	require.NotNil(t, a.denied)
+	assert.Equal(t, goratelimit.ReasonMissingKey, a.denied.DenyReason)
+	assert.Zero(t, limiter.CallCount())
+}
+
+func TestCore_LimiterError(t *testing.T) {
+	limiter := ratelimittest.AlwaysAllow()
+	boom := errors.New("boom")
+	limiter.SetError(boom)
+	ctx := context.Background()
+
+	a := newFakeAdapter("k")
+	err := middleware.Run(ctx, middleware.Config{Limiter: limiter}, a)
+	require.ErrorIs(t, err, boom, "FallbackNone leaves the error to the caller")
+	assert.False(t, a.nexted)
+	assert.Nil(t, a.denied)
+
+	a = newFakeAdapter("k")
+	require.NoError(t, middleware.Run(ctx, middleware.Config{Limiter: limiter, FallbackOnError: middleware.FallbackDeny}, a))
+	require.NotNil(t, a.denied)
+	assert.Equal(t, goratelimit.ReasonBackendError, a.denied.DenyReason)
+}
+
+func TestRateLimit_ConcurrentResultsStayIsolated(t *testing.T) {
+	// Each key's limit is its length, so every response can be checked
+	// against the key that produced it.
+	limiter := mustLimiter(goratelimit.NewFixedWindow(100, 60,
+		goratelimit.WithLimitFunc(func(_ context.Context, key string) int64 { return int64(len(key)) })))
+	var mismatches atomic.Int64
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: limiter,
+		KeyFunc: middleware.KeyByHeader("X-Key"),
+		DeniedHandler: func(w http.ResponseWriter, r *http.Request, result *goratelimit.Result) {
+			if result.Limit != int64(len(r.Header.Get("X-Key"))) {
+				mismatches.Add(1)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+	})(okHandler())
+
+	var wg sync.WaitGroup
+	for g := 1; g <= 16; g++ {
+		key := strings.Repeat("k", g)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("X-Key", key)
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if rr.Header().Get("X-RateLimit-Limit") != strconv.Itoa(len(key)) {
+					mismatches.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Zero(t, mismatches.Load())
+}
+
+func TestRateLimit_DeniedHandlerMayKeepResult(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(100, 60,
+		goratelimit.WithLimitFunc(func(_ context.Context, key string) int64 { return int64(len(key)) })))
+	var mu sync.Mutex
+	kept := map[string][]*goratelimit.Result{}
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: limiter,
+		KeyFunc: middleware.KeyByHeader("X-Key"),
+		DeniedHandler: func(w http.ResponseWriter, r *http.Request, result *goratelimit.Result) {
+			// e.g. handed to an async logger
+			mu.Lock()
+			kept[r.Header.Get("X-Key")] = append(kept[r.Header.Get("X-Key")], result)
+			mu.Unlock()
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+	})(okHandler())
+
+	for i := 0; i < 20; i++ {
+		for g := 1; g <= 4; g++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-Key", strings.Repeat("k", g))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	}
+	require.Len(t, kept, 4)
+	for key, results := range kept {
+		for _, result := range results {
+			assert.False(t, result.Allowed, "key %q", key)
+			assert.Equal(t, int64(len(key)), result.Limit, "key %q", key)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import "net/http"
+
+// CostByContentLength returns a CostFunc that charges one unit per
+// bytesPerToken bytes of the request's declared Content-Length, rounded up,
+// so oversized uploads are rejected before the body is read. Requests of
+// unknown length (chunked, ContentLength -1) cost defaultCost. Requests
+// without a body cost 0.
+//
+//	bandwidth, _ := goratelimit.NewTokenBucket(100<<10, 10<<10) // KiB: 100 MiB burst, 10 MiB/s
+//	mux.Handle("/upload", middleware.RateLimitWithConfig(middleware.Config{
+//		Limiter: bandwidth,
+//		KeyFunc: middleware.KeyByAPIKey,
+//		Cost:    middleware.CostByContentLength(1024, 1024),
+//	})(handler))
+//
+// The declared length is not verified against the body; pair it with
+// http.MaxBytesReader if clients may under-declare. bytesPerToken must be
+// positive.
+func CostByContentLength(bytesPerToken int, defaultCost int) CostFunc {
+	if bytesPerToken <= 0 {
+		panic("goratelimit/middleware: bytesPerToken must be positive")
+	}
+	per := int64(bytesPerToken)
+	return func(r *http.Request) int {
+		if r.ContentLength < 0 {
+			return defaultCost
+		}
+		return int((r.ContentLength + per - 1) / per)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+	"github.com/krishna-kudari/ratelimit/ratelimittest"
+)
+
+func TestCostByContentLength(t *testing.T) {
+	cost := middleware.CostByContentLength(1024, 7)
+	tests := []struct {
+		name          string
+		contentLength int64
+		want          int
+	}{
+		{"no body", 0, 0},
+		{"one byte", 1, 1},
+		{"exact multiple", 4096, 4},
+		{"rounds up", 4097, 5},
+		{"unknown length", -1, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/upload", nil)
+			req.ContentLength = tt.contentLength
+			assert.Equal(t, tt.want, cost(req))
+		})
+	}
+}
+
+func TestRateLimit_CostChargesAllowN(t *testing.T) {
+	fake := ratelimittest.AlwaysAllow()
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: fake,
+		KeyFunc: middleware.KeyByIP,
+		Cost:    middleware.CostByContentLength(100, 3),
+	})(okHandler())
+
+	for _, size := range []int{250, 100} {
+		req := httptest.NewRequest("POST", "/upload", strings.NewReader(strings.Repeat("x", size)))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	chunked := httptest.NewRequest("POST", "/upload", strings.NewReader("xyz"))
+	chunked.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), chunked)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, 3, calls[0].N, "250 bytes at 100 bytes per token")
+	assert.Equal(t, 1, calls[1].N)
+	assert.Equal(t, 3, calls[2].N, "unknown length uses defaultCost")
+}
+
+func TestRateLimit_CostRejectsOversizedUpload(t *testing.T) {
+	bandwidth := mustLimiter(goratelimit.NewTokenBucket(10, 1))
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: bandwidth,
+		KeyFunc: middleware.KeyByIP,
+		Cost:    middleware.CostByContentLength(1024, 1),
+	})(okHandler())
+
+	serve := func(size int) int {
+		req := httptest.NewRequest("POST", "/upload", strings.NewReader(strings.Repeat("x", size)))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, serve(8*1024))
+	assert.Equal(t, http.StatusTooManyRequests, serve(4*1024), "only 2 KiB of budget left")
+	assert.Equal(t, http.StatusOK, serve(2*1024))
+}
+
+func TestRateLimit_DefaultCostIsOne(t *testing.T) {
+	fake := ratelimittest.AlwaysAllow()
+	handler := middleware.RateLimit(fake, middleware.KeyByIP)(okHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("body")))
+	require.Len(t, fake.Calls(), 1)
+	assert.Equal(t, 1, fake.Calls()[0].N)
+}
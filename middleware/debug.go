@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// DebugHandler returns an http.Handler that renders limiter's configuration
+// and the state of every key it tracks as JSON, for live inspection:
+//
+//	mux.Handle("/debug/ratelimit", middleware.DebugHandler(limiter))
+//
+// Nothing is registered automatically; mount it where you choose, behind
+// the same access control as net/http/pprof, since keys are usually client
+// IPs or API keys. The limiter must implement goratelimit.Inspector (the
+// in-memory backends do); otherwise the handler responds with 501.
+func DebugHandler(limiter goratelimit.Limiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		states, ok := goratelimit.Inspect(limiter)
+		if !ok {
+			w.WriteHeader(http.StatusNotImplemented)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "limiter does not support inspection"})
+			return
+		}
+
+		description := goratelimit.Describe(limiter)
+		body := debugBody{
+			Algorithm: description.Algorithm,
+			Limit:     description.Limit,
+			Dynamic:   description.Dynamic,
+			KeyCount:  len(states),
+			Keys:      make([]debugKey, len(states)),
+		}
+		for i, s := range states {
+			body.Keys[i] = debugKey{Key: s.Key, Remaining: s.Remaining}
+			if !s.ResetAt.IsZero() {
+				body.Keys[i].ResetAt = s.ResetAt.UTC().Format(time.RFC3339)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+type debugBody struct {
+	Algorithm string     `json:"algorithm,omitempty"`
+	Limit     int64      `json:"limit"`
+	Dynamic   bool       `json:"dynamic"`
+	KeyCount  int        `json:"key_count"`
+	Keys      []debugKey `json:"keys"`
+}
+
+type debugKey struct {
+	Key       string `json:"key"`
+	Remaining int64  `json:"remaining"`
+	ResetAt   string `json:"reset_at,omitempty"`
+}
@@ -0,0 +1,82 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+type debugResponse struct {
+	Algorithm string `json:"algorithm"`
+	Limit     int64  `json:"limit"`
+	KeyCount  int    `json:"key_count"`
+	Keys      []struct {
+		Key       string `json:"key"`
+		Remaining int64  `json:"remaining"`
+		ResetAt   string `json:"reset_at"`
+	} `json:"keys"`
+}
+
+func TestDebugHandler_ListsTrackedKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := goratelimit.NewFakeClock()
+	limiter := mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithClock(clock)))
+	_, _ = limiter.AllowN(ctx, "alice", 2)
+	_, _ = limiter.AllowN(ctx, "bob", 5)
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/ratelimit", middleware.DebugHandler(limiter))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/debug/ratelimit", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var body debugResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "fixed_window", body.Algorithm)
+	assert.Equal(t, int64(5), body.Limit)
+	require.Equal(t, 2, body.KeyCount)
+	require.Len(t, body.Keys, 2)
+
+	resetAt := clock.Now().Add(60 * time.Second).UTC().Format(time.RFC3339)
+	assert.Equal(t, "alice", body.Keys[0].Key)
+	assert.Equal(t, int64(3), body.Keys[0].Remaining)
+	assert.Equal(t, resetAt, body.Keys[0].ResetAt)
+	assert.Equal(t, "bob", body.Keys[1].Key)
+	assert.Equal(t, int64(0), body.Keys[1].Remaining)
+
+	clock.Advance(61 * time.Second)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/debug/ratelimit", nil))
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, 0, body.KeyCount, "expired windows are not listed")
+}
+
+func TestDebugHandler_NotImplementedForRedis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+
+	for name, opt := range map[string]goratelimit.Option{
+		"plain":      goratelimit.WithKeyPrefix("debug"),
+		"dry run":    goratelimit.WithDryRun(true),
+		"allow list": goratelimit.WithAllowList("10.0.0.1"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			limiter := mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithRedis(client), opt))
+
+			rr := httptest.NewRecorder()
+			middleware.DebugHandler(limiter).ServeHTTP(rr, httptest.NewRequest("GET", "/debug/ratelimit", nil))
+			assert.Equal(t, http.StatusNotImplemented, rr.Code, "wrappers must not hide that Redis cannot be inspected")
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// chargeLedger records the (limiter, key) pairs already charged for one
+// request, so stacked middlewares sharing a limiter and key (e.g. a global
+// policy and a per-route policy, both by IP) charge the request only once.
+type chargeLedger struct {
+	mu      sync.Mutex
+	results map[chargeKey]goratelimit.Result
+}
+
+type chargeKey struct {
+	limiter goratelimit.Limiter
+	key     string
+}
+
+type ledgerCtxKey struct{}
+
+// withLedger returns the request's ledger, attaching a new one to r's context
+// if this is the first rate limit middleware in the chain.
+func withLedger(r *http.Request) (*chargeLedger, *http.Request) {
+	if l := ledgerFromContext(r.Context()); l != nil {
+		return l, r
+	}
+	l := &chargeLedger{results: make(map[chargeKey]goratelimit.Result)}
+	return l, r.WithContext(context.WithValue(r.Context(), ledgerCtxKey{}, l))
+}
+
+// ledgerFromContext returns the ledger attached by withLedger, or nil.
+func ledgerFromContext(ctx context.Context) *chargeLedger {
+	l, _ := ctx.Value(ledgerCtxKey{}).(*chargeLedger)
+	return l
+}
+
+// allow charges limiter n units for key unless the pair was already charged
+// for this request, in which case the earlier result is returned and charged
+// is false.
+func (l *chargeLedger) allow(ctx context.Context, limiter goratelimit.Limiter, key string, n int) (res goratelimit.Result, charged bool, err error) {
+	ck := chargeKey{limiter: limiter, key: key}
+	l.mu.Lock()
+	if res, ok := l.results[ck]; ok {
+		l.mu.Unlock()
+		return res, false, nil
+	}
+	l.mu.Unlock()
+
+	res, err = limiter.AllowN(ctx, key, n)
+	if err != nil {
+		return res, true, err
+	}
+	l.mu.Lock()
+	l.results[ck] = res
+	l.mu.Unlock()
+	return res, true, nil
+}
+
+// dedupable reports whether limiter can be used as a map key. Limiters are
+// normally pointers; a non-comparable value type is charged every time.
+func dedupable(limiter goratelimit.Limiter) bool {
+	return reflect.TypeOf(limiter).Comparable()
+}
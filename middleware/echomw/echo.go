@@ -39,12 +39,21 @@ type Config struct {
 	// KeyFunc extracts the rate limit key (required).
 	KeyFunc KeyFunc
 
-	// DeniedHandler is called on denial. Default: 429 JSON.
+	// DeniedHandler is called on denial. Default: 429 JSON with
+	// Cache-Control: no-store (see middleware.SetDeniedCacheHeaders).
 	DeniedHandler DeniedHandler
 
 	// ErrorHandler is called on limiter error. Default: pass-through (fail open).
 	ErrorHandler ErrorHandler
 
+	// EmptyKeyPolicy controls how requests are handled when KeyFunc returns "".
+	// Default: middleware.EmptyKeyShared.
+	EmptyKeyPolicy middleware.EmptyKeyPolicy
+
+	// EmptyKeyFallback resolves the key when KeyFunc returns "" and
+	// EmptyKeyPolicy is middleware.EmptyKeyFallback (required in that case).
+	EmptyKeyFallback KeyFunc
+
 	// ExcludePaths are request paths that bypass rate limiting.
 	ExcludePaths map[string]bool
 
@@ -75,6 +84,9 @@ func RateLimitWithConfig(cfg Config) echo.MiddlewareFunc {
 	if cfg.KeyFunc == nil {
 		panic("echomw: KeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == middleware.EmptyKeyFallback && cfg.EmptyKeyFallback == nil {
+		panic("echomw: EmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
 	if cfg.DeniedHandler == nil {
 		cfg.DeniedHandler = defaultDeniedHandler
 	}
@@ -97,6 +109,17 @@ func RateLimitWithConfig(cfg Config) echo.MiddlewareFunc {
 			}
 
 			key := cfg.KeyFunc(c)
+			if key == "" {
+				switch cfg.EmptyKeyPolicy {
+				case middleware.EmptyKeyAllow:
+					return next(c)
+				case middleware.EmptyKeyDeny:
+					return cfg.DeniedHandler(c, &goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+				case middleware.EmptyKeyFallback:
+					key = cfg.EmptyKeyFallback(c)
+				}
+			}
+			c.SetRequest(c.Request().WithContext(middleware.ContextWithKey(c.Request().Context(), key)))
 			result, err := cfg.Limiter.Allow(c.Request().Context(), key)
 			if err != nil {
 				return cfg.ErrorHandler(c, err)
@@ -183,6 +206,7 @@ func setHeaders(c echo.Context, result *goratelimit.Result) {
 }
 
 func defaultDeniedHandler(c echo.Context, result *goratelimit.Result) error {
+	middleware.SetDeniedCacheHeaders(c.Response().Header())
 	return c.JSON(http.StatusTooManyRequests, map[string]interface{}{
 		"error":       "rate limit exceeded",
 		"limit":       result.Limit,
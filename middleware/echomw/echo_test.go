@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
 	"github.com/krishna-kudari/ratelimit/middleware/echomw"
 )
 
@@ -241,3 +242,21 @@ func must(l goratelimit.Limiter, err error) goratelimit.Limiter {
 	}
 	return l
 }
+
+func TestRateLimit_KeyInContext(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(5, 60))
+	e := echo.New()
+	e.Use(echomw.RateLimit(limiter, echomw.KeyByHeader("X-API-Key")))
+	var got string
+	e.GET("/api/data", func(c echo.Context) error {
+		got, _ = middleware.KeyFromContext(c.Request().Context())
+		return c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-API-Key", "key-123")
+	e.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "key-123", got)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+// EmptyKeyPolicy controls what middleware does when KeyFunc returns an empty
+// key (e.g. a missing API key header). Without a policy every such request
+// shares the single "" bucket, so anonymous callers throttle each other.
+// Shared by the net/http, Gin, Echo, Fiber, and gRPC middleware.
+type EmptyKeyPolicy int
+
+const (
+	// EmptyKeyShared rate limits all empty-key requests together under the
+	// "" key. This is the default and matches the historical behavior.
+	EmptyKeyShared EmptyKeyPolicy = iota
+
+	// EmptyKeyDeny rejects empty-key requests through the DeniedHandler
+	// without consulting the limiter, with a Result whose DenyReason is
+	// goratelimit.ReasonMissingKey.
+	EmptyKeyDeny
+
+	// EmptyKeyAllow lets empty-key requests through without rate limiting.
+	EmptyKeyAllow
+
+	// EmptyKeyFallback resolves the key with the config's EmptyKeyFallback
+	// extractor instead (e.g. client IP). If the fallback also returns "",
+	// the request is limited under the shared "" key.
+	EmptyKeyFallback
+)
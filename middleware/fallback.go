@@ -0,0 +1,19 @@
+package middleware
+
+// ErrorFallback controls what middleware does when the limiter returns an
+// error and no ErrorHandler is configured.
+type ErrorFallback int
+
+const (
+	// FallbackNone responds with 500 Internal Server Error. This is the
+	// default and matches the historical behavior.
+	FallbackNone ErrorFallback = iota
+
+	// FallbackAllow lets the request through without rate limit headers,
+	// failing open like WithFailOpen(true).
+	FallbackAllow
+
+	// FallbackDeny rejects the request through the DeniedHandler with
+	// Result.DenyReason set to ReasonBackendError, failing closed.
+	FallbackDeny
+)
@@ -0,0 +1,148 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+// unreachableLimiter fails closed against a Redis that is not listening, so
+// every call returns an error wrapping goratelimit.ErrBackendUnavailable.
+func unreachableLimiter(t *testing.T) goratelimit.Limiter {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 50 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	t.Cleanup(func() { _ = client.Close() })
+	return mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithRedis(client), goratelimit.WithFailOpen(false)))
+}
+
+func TestRateLimit_FallbackOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback middleware.ErrorFallback
+		wantCode int
+	}{
+		{"none responds 500", middleware.FallbackNone, http.StatusInternalServerError},
+		{"allow serves the request", middleware.FallbackAllow, http.StatusOK},
+		{"deny responds 429", middleware.FallbackDeny, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason goratelimit.DenyReason
+			cfg := middleware.Config{
+				Limiter:         unreachableLimiter(t),
+				KeyFunc:         middleware.KeyByIP,
+				FallbackOnError: tt.fallback,
+			}
+			if tt.fallback == middleware.FallbackDeny {
+				cfg.DeniedHandler = func(w http.ResponseWriter, _ *http.Request, result *goratelimit.Result) {
+					reason = result.DenyReason
+					w.WriteHeader(http.StatusTooManyRequests)
+				}
+			}
+			handler := middleware.RateLimitWithConfig(cfg)(okHandler())
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.fallback == middleware.FallbackDeny {
+				assert.Equal(t, goratelimit.ReasonBackendError, reason)
+			}
+		})
+	}
+}
+
+func TestRateLimit_FallbackDenyDefaultBody(t *testing.T) {
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:         unreachableLimiter(t),
+		KeyFunc:         middleware.KeyByIP,
+		FallbackOnError: middleware.FallbackDeny,
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, "rate limit exceeded", body["error"])
+}
+
+func TestRateLimit_ErrorHandlerOverridesFallback(t *testing.T) {
+	var got error
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:         unreachableLimiter(t),
+		KeyFunc:         middleware.KeyByIP,
+		FallbackOnError: middleware.FallbackAllow,
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			got = err
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.True(t, errors.Is(got, goratelimit.ErrBackendUnavailable))
+}
+
+func TestRateLimit_StatusForReason(t *testing.T) {
+	statuses := map[goratelimit.DenyReason]int{
+		goratelimit.ReasonOverLimit:    http.StatusTooManyRequests,
+		goratelimit.ReasonBackendError: http.StatusServiceUnavailable,
+		goratelimit.ReasonMaintenance:  http.StatusServiceUnavailable,
+	}
+
+	t.Run("backend failure responds 503", func(t *testing.T) {
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:         unreachableLimiter(t),
+			KeyFunc:         middleware.KeyByIP,
+			FallbackOnError: middleware.FallbackDeny,
+			StatusForReason: statuses,
+		})(okHandler())
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+
+	t.Run("over limit responds 429", func(t *testing.T) {
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:         mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+			KeyFunc:         middleware.KeyByIP,
+			StatusCode:      http.StatusForbidden,
+			StatusForReason: statuses,
+		})(okHandler())
+
+		for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+			assert.Equal(t, want, rr.Code, "request %d", i+1)
+		}
+	})
+
+	t.Run("unmapped reasons use StatusCode", func(t *testing.T) {
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:         unreachableLimiter(t),
+			KeyFunc:         middleware.KeyByIP,
+			FallbackOnError: middleware.FallbackDeny,
+			StatusForReason: map[goratelimit.DenyReason]int{goratelimit.ReasonMaintenance: http.StatusServiceUnavailable},
+		})(okHandler())
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	})
+}
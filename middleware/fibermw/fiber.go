@@ -39,12 +39,21 @@ type Config struct {
 	// KeyFunc extracts the rate limit key (required).
 	KeyFunc KeyFunc
 
-	// DeniedHandler is called on denial. Default: 429 JSON.
+	// DeniedHandler is called on denial. Default: 429 JSON with
+	// Cache-Control: no-store (see middleware.SetDeniedCacheHeaders).
 	DeniedHandler DeniedHandler
 
 	// ErrorHandler is called on limiter error. Default: pass-through (fail open).
 	ErrorHandler ErrorHandler
 
+	// EmptyKeyPolicy controls how requests are handled when KeyFunc returns "".
+	// Default: middleware.EmptyKeyShared.
+	EmptyKeyPolicy middleware.EmptyKeyPolicy
+
+	// EmptyKeyFallback resolves the key when KeyFunc returns "" and
+	// EmptyKeyPolicy is middleware.EmptyKeyFallback (required in that case).
+	EmptyKeyFallback KeyFunc
+
 	// ExcludePaths are request paths that bypass rate limiting.
 	ExcludePaths map[string]bool
 
@@ -75,6 +84,9 @@ func RateLimitWithConfig(cfg Config) fiber.Handler {
 	if cfg.KeyFunc == nil {
 		panic("fibermw: KeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == middleware.EmptyKeyFallback && cfg.EmptyKeyFallback == nil {
+		panic("fibermw: EmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
 	if cfg.DeniedHandler == nil {
 		cfg.DeniedHandler = defaultDeniedHandler
 	}
@@ -96,6 +108,17 @@ func RateLimitWithConfig(cfg Config) fiber.Handler {
 		}
 
 		key := cfg.KeyFunc(c)
+		if key == "" {
+			switch cfg.EmptyKeyPolicy {
+			case middleware.EmptyKeyAllow:
+				return c.Next()
+			case middleware.EmptyKeyDeny:
+				return cfg.DeniedHandler(c, &goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+			case middleware.EmptyKeyFallback:
+				key = cfg.EmptyKeyFallback(c)
+			}
+		}
+		c.SetUserContext(middleware.ContextWithKey(c.UserContext(), key))
 		result, err := cfg.Limiter.Allow(c.UserContext(), key)
 		if err != nil {
 			return cfg.ErrorHandler(c, err)
@@ -179,6 +202,8 @@ func setHeaders(c *fiber.Ctx, result *goratelimit.Result) {
 }
 
 func defaultDeniedHandler(c *fiber.Ctx, result *goratelimit.Result) error {
+	c.Set(fiber.HeaderCacheControl, middleware.DeniedCacheControl)
+	c.Set(fiber.HeaderVary, middleware.DeniedVary)
 	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 		"error":       "rate limit exceeded",
 		"limit":       result.Limit,
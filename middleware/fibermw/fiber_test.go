@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
 	"github.com/krishna-kudari/ratelimit/middleware/fibermw"
 )
 
@@ -184,3 +185,30 @@ func must(l goratelimit.Limiter, err error) goratelimit.Limiter {
 	}
 	return l
 }
+
+func TestRateLimit_DeniedResponseNotCacheable(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(1, 60))
+	app := newApp(fibermw.RateLimit(limiter, fibermw.KeyByIP))
+
+	doReq(app, "GET", "/api/data", nil)
+	resp := doReq(app, "GET", "/api/data", nil)
+
+	require.Equal(t, 429, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, "*", resp.Header.Get("Vary"))
+}
+
+func TestRateLimit_KeyInContext(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(5, 60))
+	app := fiber.New()
+	app.Use(fibermw.RateLimit(limiter, fibermw.KeyByHeader("X-API-Key")))
+	var got string
+	app.Get("/api/data", func(c *fiber.Ctx) error {
+		got, _ = middleware.KeyFromContext(c.UserContext())
+		return c.SendString("ok")
+	})
+
+	resp := doReq(app, "GET", "/api/data", map[string]string{"X-API-Key": "key-123"})
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "key-123", got)
+}
@@ -15,6 +15,7 @@
 // Key extractors:
 //
 //	ginmw.KeyByClientIP          — Gin's ClientIP() with trusted proxy support
+//	ginmw.KeyByIPNet(24)         — ClientIP()'s /24 (IPv6: /64)
 //	ginmw.KeyByHeader("X-API-Key") — value from request header
 //	ginmw.KeyByParam(":id")     — value from URL parameter
 //	ginmw.KeyByPathAndIP        — path + client IP for per-endpoint limits
@@ -12,6 +12,7 @@ package ginmw
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -39,12 +40,21 @@ type Config struct {
 	// KeyFunc extracts the rate limit key (required).
 	KeyFunc KeyFunc
 
-	// DeniedHandler is called on denial. Default: 429 JSON.
+	// DeniedHandler is called on denial. Default: 429 JSON with
+	// Cache-Control: no-store (see middleware.SetDeniedCacheHeaders).
 	DeniedHandler DeniedHandler
 
 	// ErrorHandler is called on limiter error. Default: pass-through (fail open).
 	ErrorHandler ErrorHandler
 
+	// EmptyKeyPolicy controls how requests are handled when KeyFunc returns "".
+	// Default: middleware.EmptyKeyShared.
+	EmptyKeyPolicy middleware.EmptyKeyPolicy
+
+	// EmptyKeyFallback resolves the key when KeyFunc returns "" and
+	// EmptyKeyPolicy is middleware.EmptyKeyFallback (required in that case).
+	EmptyKeyFallback KeyFunc
+
 	// ExcludePaths are request paths that bypass rate limiting.
 	ExcludePaths map[string]bool
 
@@ -75,6 +85,9 @@ func RateLimitWithConfig(cfg Config) gin.HandlerFunc {
 	if cfg.KeyFunc == nil {
 		panic("ginmw: KeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == middleware.EmptyKeyFallback && cfg.EmptyKeyFallback == nil {
+		panic("ginmw: EmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
 	if cfg.DeniedHandler == nil {
 		cfg.DeniedHandler = defaultDeniedHandler
 	}
@@ -99,6 +112,19 @@ func RateLimitWithConfig(cfg Config) gin.HandlerFunc {
 		}
 
 		key := cfg.KeyFunc(c)
+		if key == "" {
+			switch cfg.EmptyKeyPolicy {
+			case middleware.EmptyKeyAllow:
+				c.Next()
+				return
+			case middleware.EmptyKeyDeny:
+				cfg.DeniedHandler(c, &goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+				return
+			case middleware.EmptyKeyFallback:
+				key = cfg.EmptyKeyFallback(c)
+			}
+		}
+		c.Request = c.Request.WithContext(middleware.ContextWithKey(c.Request.Context(), key))
 		result, err := cfg.Limiter.Allow(c.Request.Context(), key)
 		if err != nil {
 			cfg.ErrorHandler(c, err)
@@ -123,11 +149,39 @@ func RateLimitWithConfig(cfg Config) gin.HandlerFunc {
 
 // ─── Built-in Key Extractors ─────────────────────────────────────────────────
 
-// KeyByClientIP uses Gin's ClientIP() which respects trusted proxies.
+// KeyByClientIP uses Gin's ClientIP(), which reads X-Forwarded-For and
+// X-Real-IP only when the peer is a trusted proxy and otherwise falls back to
+// the remote address, so a client cannot pick its own key by spoofing those
+// headers. Gin trusts every proxy by default: call engine.SetTrustedProxies
+// with your proxies' addresses, or nil when there are none.
 func KeyByClientIP(c *gin.Context) string {
 	return c.ClientIP()
 }
 
+// KeyByIPNet returns a KeyFunc that keys by the network of Gin's ClientIP(),
+// so that clients rotating addresses within one allocation share a budget.
+// IPv4 addresses are masked to their first bits bits; IPv6 addresses to
+// their /64, the usual size of a single subscriber's allocation. The key is
+// the network in CIDR notation, e.g. "203.0.113.0/24". A ClientIP() that
+// does not parse is used unmasked.
+func KeyByIPNet(bits int) KeyFunc {
+	if bits < 0 || bits > 32 {
+		panic("goratelimit/ginmw: KeyByIPNet bits must be between 0 and 32")
+	}
+	v4 := net.CIDRMask(bits, 32)
+	v6 := net.CIDRMask(64, 128)
+	return func(c *gin.Context) string {
+		ip := net.ParseIP(c.ClientIP())
+		if ip == nil {
+			return c.ClientIP()
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return (&net.IPNet{IP: ip4.Mask(v4), Mask: v4}).String()
+		}
+		return (&net.IPNet{IP: ip.Mask(v6), Mask: v6}).String()
+	}
+}
+
 // KeyByHeader returns a KeyFunc that extracts from a request header.
 func KeyByHeader(header string) KeyFunc {
 	return func(c *gin.Context) string {
@@ -184,6 +238,7 @@ func setHeaders(c *gin.Context, result *goratelimit.Result) {
 }
 
 func defaultDeniedHandler(c *gin.Context, result *goratelimit.Result) {
+	middleware.SetDeniedCacheHeaders(c.Writer.Header())
 	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 		"error":       "rate limit exceeded",
 		"limit":       result.Limit,
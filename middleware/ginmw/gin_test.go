@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
 	"github.com/krishna-kudari/ratelimit/middleware/ginmw"
 )
 
@@ -242,9 +243,165 @@ func TestKeyByUser(t *testing.T) {
 	require.Equal(t, 429, w.Code)
 }
 
+func TestRateLimit_EmptyKeyPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy middleware.EmptyKeyPolicy
+		codes  []int // status per request from two different client IPs, alternating
+	}{
+		{"shared", middleware.EmptyKeyShared, []int{200, 429}},
+		{"deny", middleware.EmptyKeyDeny, []int{429, 429}},
+		{"allow", middleware.EmptyKeyAllow, []int{200, 200}},
+		{"fallback", middleware.EmptyKeyFallback, []int{200, 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := must(goratelimit.NewFixedWindow(1, 60))
+			router := newRouter(ginmw.RateLimitWithConfig(ginmw.Config{
+				Limiter:          limiter,
+				KeyFunc:          ginmw.KeyByHeader("X-API-Key"),
+				EmptyKeyPolicy:   tt.policy,
+				EmptyKeyFallback: ginmw.KeyByClientIP,
+			}))
+
+			ips := []string{"10.1.1.1:1234", "10.1.1.2:1234"}
+			for i, want := range tt.codes {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest("GET", "/api/data", nil)
+				req.RemoteAddr = ips[i%len(ips)]
+				router.ServeHTTP(w, req)
+				assert.Equal(t, want, w.Code, "request %d", i+1)
+			}
+		})
+	}
+}
+
 func must(l goratelimit.Limiter, err error) goratelimit.Limiter {
 	if err != nil {
 		panic(err)
 	}
 	return l
 }
+
+func TestRateLimit_DeniedResponseNotCacheable(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(1, 60))
+	router := newRouter(ginmw.RateLimit(limiter, ginmw.KeyByClientIP))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "100.0.0.2:1234"
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = "100.0.0.2:1234"
+	router.ServeHTTP(w, req)
+	require.Equal(t, 429, w.Code)
+	assert.Equal(t, middleware.DeniedCacheControl, w.Header().Get("Cache-Control"))
+	assert.Equal(t, middleware.DeniedVary, w.Header().Get("Vary"))
+}
+
+func TestRateLimit_KeyInContext(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(5, 60))
+	r := gin.New()
+	r.Use(ginmw.RateLimit(limiter, ginmw.KeyByHeader("X-API-Key")))
+	var got string
+	r.GET("/api/data", func(c *gin.Context) {
+		got, _ = middleware.KeyFromContext(c.Request.Context())
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.Header.Set("X-API-Key", "key-123")
+	r.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "key-123", got)
+}
+
+// clientIPRouter limits /api/data to one request per key and echoes the key.
+func clientIPRouter(keyFunc ginmw.KeyFunc, trusted []string) *gin.Engine {
+	r := gin.New()
+	if err := r.SetTrustedProxies(trusted); err != nil {
+		panic(err)
+	}
+	r.Use(ginmw.RateLimit(must(goratelimit.NewFixedWindow(1, 60)), keyFunc))
+	r.GET("/api/data", func(c *gin.Context) {
+		key, _ := middleware.KeyFromContext(c.Request.Context())
+		c.String(200, key)
+	})
+	return r
+}
+
+func serveFrom(r *gin.Engine, remoteAddr, xff string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/data", nil)
+	req.RemoteAddr = remoteAddr
+	if xff != "" {
+		req.Header.Set("X-Forwarded-For", xff)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestKeyByClientIP_TrustedProxy(t *testing.T) {
+	r := clientIPRouter(ginmw.KeyByClientIP, []string{"10.0.0.1"})
+
+	w := serveFrom(r, "10.0.0.1:1234", "203.0.113.7")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "203.0.113.7", w.Body.String())
+
+	// Another client behind the same proxy has its own budget.
+	w = serveFrom(r, "10.0.0.1:1234", "203.0.113.8")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "203.0.113.8", w.Body.String())
+}
+
+func TestKeyByClientIP_UntrustedSpoofIgnored(t *testing.T) {
+	r := clientIPRouter(ginmw.KeyByClientIP, []string{"10.0.0.1"})
+
+	w := serveFrom(r, "198.51.100.9:1234", "203.0.113.7")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "198.51.100.9", w.Body.String())
+
+	// A fresh spoofed address does not buy a fresh budget.
+	w = serveFrom(r, "198.51.100.9:1234", "203.0.113.99")
+	assert.Equal(t, 429, w.Code)
+}
+
+func TestKeyByClientIP_NoTrustedProxies(t *testing.T) {
+	r := clientIPRouter(ginmw.KeyByClientIP, nil)
+
+	w := serveFrom(r, "10.0.0.1:1234", "203.0.113.7")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "10.0.0.1", w.Body.String())
+}
+
+func TestKeyByIPNet(t *testing.T) {
+	r := clientIPRouter(ginmw.KeyByIPNet(24), []string{"10.0.0.1"})
+
+	w := serveFrom(r, "10.0.0.1:1234", "203.0.113.7")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "203.0.113.0/24", w.Body.String())
+
+	// Same /24: same budget.
+	w = serveFrom(r, "10.0.0.1:1234", "203.0.113.200")
+	assert.Equal(t, 429, w.Code)
+
+	w = serveFrom(r, "10.0.0.1:1234", "203.0.114.7")
+	assert.Equal(t, 200, w.Code)
+
+	w = serveFrom(r, "10.0.0.1:1234", "2001:db8:1:2:3:4:5:6")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "2001:db8:1:2::/64", w.Body.String())
+
+	// A spoofed header from an untrusted peer is still ignored.
+	w = serveFrom(r, "198.51.100.9:1234", "203.0.114.7")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "198.51.100.0/24", w.Body.String())
+}
+
+func TestKeyByIPNet_InvalidBits(t *testing.T) {
+	assert.Panics(t, func() { ginmw.KeyByIPNet(33) })
+}
@@ -24,6 +24,8 @@
 //	grpcmw.KeyByPeer           — remote peer address
 //	grpcmw.KeyByMetadata("x-api-key") — value from gRPC metadata
 //	grpcmw.KeyByMethod         — full method + peer (per-endpoint limiting)
+//	grpcmw.KeyByMethodAndMetadata("x-api-key") — full method + metadata value
+//	grpcmw.KeyByContextValue(principalKey{}) — principal set by an auth interceptor, else peer
 //
 // Full config:
 //
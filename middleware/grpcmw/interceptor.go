@@ -14,20 +14,27 @@ package grpcmw
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
 )
 
 // KeyFunc extracts the rate limiting key from a unary RPC context.
 type KeyFunc func(ctx context.Context, info *grpc.UnaryServerInfo) string
 
+// MessageKeyFunc extracts the rate limiting key from a unary RPC's decoded
+// request message, e.g. a tenant ID field.
+type MessageKeyFunc func(ctx context.Context, info *grpc.UnaryServerInfo, req any) string
+
 // StreamKeyFunc extracts the rate limiting key from a streaming RPC context.
 type StreamKeyFunc func(ctx context.Context, info *grpc.StreamServerInfo) string
 
@@ -40,9 +47,15 @@ type Config struct {
 	// Limiter is the rate limiter instance (required).
 	Limiter goratelimit.Limiter
 
-	// KeyFunc extracts the rate limit key for unary RPCs (required for unary).
+	// KeyFunc extracts the rate limit key for unary RPCs (required for unary
+	// unless MessageKeyFunc is set).
 	KeyFunc KeyFunc
 
+	// MessageKeyFunc, when set, extracts the unary key from the request
+	// message instead of KeyFunc. Streams are limited before any message is
+	// received, so it does not apply to them.
+	MessageKeyFunc MessageKeyFunc
+
 	// StreamKeyFunc extracts the rate limit key for streaming RPCs (required for stream).
 	StreamKeyFunc StreamKeyFunc
 
@@ -50,6 +63,18 @@ type Config struct {
 	// Default: codes.ResourceExhausted.
 	DeniedHandler DeniedHandler
 
+	// EmptyKeyPolicy controls how RPCs are handled when the key func returns "".
+	// Default: middleware.EmptyKeyShared.
+	EmptyKeyPolicy middleware.EmptyKeyPolicy
+
+	// EmptyKeyFallback resolves the unary key when KeyFunc returns "" and
+	// EmptyKeyPolicy is middleware.EmptyKeyFallback (required in that case).
+	EmptyKeyFallback KeyFunc
+
+	// StreamEmptyKeyFallback resolves the stream key when StreamKeyFunc returns ""
+	// and EmptyKeyPolicy is middleware.EmptyKeyFallback (required in that case).
+	StreamEmptyKeyFallback StreamKeyFunc
+
 	// ExcludeMethods are full method names (e.g. "/pkg.Service/Method")
 	// that bypass rate limiting.
 	ExcludeMethods map[string]bool
@@ -75,20 +100,40 @@ func UnaryServerInterceptorWithConfig(cfg Config) grpc.UnaryServerInterceptor {
 	if cfg.Limiter == nil {
 		panic("grpcmw: Limiter is required")
 	}
-	if cfg.KeyFunc == nil {
+	if cfg.KeyFunc == nil && cfg.MessageKeyFunc == nil {
 		panic("grpcmw: KeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == middleware.EmptyKeyFallback && cfg.EmptyKeyFallback == nil {
+		panic("grpcmw: EmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
 	if cfg.DeniedHandler == nil {
 		cfg.DeniedHandler = defaultDeniedHandler
 	}
 	sendHeaders := cfg.Headers == nil || *cfg.Headers
+	keyFunc := cfg.MessageKeyFunc
+	if keyFunc == nil {
+		keyFunc = func(ctx context.Context, info *grpc.UnaryServerInfo, _ any) string {
+			return cfg.KeyFunc(ctx, info)
+		}
+	}
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		if cfg.ExcludeMethods != nil && cfg.ExcludeMethods[info.FullMethod] {
 			return handler(ctx, req)
 		}
 
-		key := cfg.KeyFunc(ctx, info)
+		key := keyFunc(ctx, info, req)
+		if key == "" {
+			switch cfg.EmptyKeyPolicy {
+			case middleware.EmptyKeyAllow:
+				return handler(ctx, req)
+			case middleware.EmptyKeyDeny:
+				return nil, cfg.DeniedHandler(ctx, &goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+			case middleware.EmptyKeyFallback:
+				key = cfg.EmptyKeyFallback(ctx, info)
+			}
+		}
+		ctx = middleware.ContextWithKey(ctx, key)
 		result, err := cfg.Limiter.Allow(ctx, key)
 		if err != nil {
 			return handler(ctx, req)
@@ -125,6 +170,9 @@ func StreamServerInterceptorWithConfig(cfg Config) grpc.StreamServerInterceptor
 	if cfg.StreamKeyFunc == nil {
 		panic("grpcmw: StreamKeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == middleware.EmptyKeyFallback && cfg.StreamEmptyKeyFallback == nil {
+		panic("grpcmw: StreamEmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
 	if cfg.DeniedHandler == nil {
 		cfg.DeniedHandler = defaultDeniedHandler
 	}
@@ -138,6 +186,18 @@ func StreamServerInterceptorWithConfig(cfg Config) grpc.StreamServerInterceptor
 		}
 
 		key := cfg.StreamKeyFunc(ctx, info)
+		if key == "" {
+			switch cfg.EmptyKeyPolicy {
+			case middleware.EmptyKeyAllow:
+				return handler(srv, ss)
+			case middleware.EmptyKeyDeny:
+				return cfg.DeniedHandler(ctx, &goratelimit.Result{DenyReason: goratelimit.ReasonMissingKey})
+			case middleware.EmptyKeyFallback:
+				key = cfg.StreamEmptyKeyFallback(ctx, info)
+			}
+		}
+		ctx = middleware.ContextWithKey(ctx, key)
+		ss = &keyedStream{ServerStream: ss, ctx: ctx}
 		result, err := cfg.Limiter.Allow(ctx, key)
 		if err != nil {
 			return handler(srv, ss)
@@ -155,6 +215,16 @@ func StreamServerInterceptorWithConfig(cfg Config) grpc.StreamServerInterceptor
 	}
 }
 
+// keyedStream overrides Context so stream handlers see the resolved key.
+type keyedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *keyedStream) Context() context.Context {
+	return s.ctx
+}
+
 // ─── Built-in Key Extractors ─────────────────────────────────────────────────
 
 // KeyByPeer extracts the remote peer address as the rate limit key.
@@ -168,6 +238,7 @@ func StreamKeyByPeer(ctx context.Context, _ *grpc.StreamServerInfo) string {
 }
 
 // KeyByMetadata returns a KeyFunc that uses a value from incoming gRPC metadata.
+// A missing header gives an empty key, handled by Config.EmptyKeyPolicy.
 func KeyByMetadata(header string) KeyFunc {
 	return func(ctx context.Context, _ *grpc.UnaryServerInfo) string {
 		return metadataValue(ctx, header)
@@ -192,8 +263,77 @@ func StreamKeyByMethod(ctx context.Context, info *grpc.StreamServerInfo) string
 	return info.FullMethod + ":" + peerAddr(ctx)
 }
 
+// KeyByMethodAndMetadata returns a KeyFunc that uses "method:value" as the
+// key, where value is read from incoming gRPC metadata, e.g. giving each API
+// key its own budget on each RPC. A missing header gives an empty key, handled
+// by Config.EmptyKeyPolicy.
+func KeyByMethodAndMetadata(header string) KeyFunc {
+	return func(ctx context.Context, info *grpc.UnaryServerInfo) string {
+		return methodKey(info.FullMethod, metadataValue(ctx, header))
+	}
+}
+
+// StreamKeyByMethodAndMetadata is the stream equivalent of KeyByMethodAndMetadata.
+func StreamKeyByMethodAndMetadata(header string) StreamKeyFunc {
+	return func(ctx context.Context, info *grpc.StreamServerInfo) string {
+		return methodKey(info.FullMethod, metadataValue(ctx, header))
+	}
+}
+
+// KeyByClientCert uses the SHA-256 fingerprint of the peer's TLS leaf
+// certificate as the key (see middleware.CertFingerprint). Falls back to the
+// peer address when the connection carries no client certificate.
+func KeyByClientCert(ctx context.Context, _ *grpc.UnaryServerInfo) string {
+	return peerCertKey(ctx)
+}
+
+// StreamKeyByClientCert is the stream equivalent of KeyByClientCert.
+func StreamKeyByClientCert(ctx context.Context, _ *grpc.StreamServerInfo) string {
+	return peerCertKey(ctx)
+}
+
+// KeyByContextValue returns a KeyFunc that uses the value stored under key in
+// the RPC context, e.g. the principal an upstream auth interceptor attached,
+// so limits follow the caller's identity rather than its address. Chain the
+// rate limit interceptor after the auth one. Strings are used as is and other
+// values formatted with fmt; a missing or empty value gives an empty key,
+// handled by Config.EmptyKeyPolicy (set EmptyKeyFallback to KeyByPeer to key
+// anonymous calls by address).
+func KeyByContextValue(key any) KeyFunc {
+	return func(ctx context.Context, _ *grpc.UnaryServerInfo) string {
+		return contextValue(ctx, key)
+	}
+}
+
+// StreamKeyByContextValue is the stream equivalent of KeyByContextValue.
+func StreamKeyByContextValue(key any) StreamKeyFunc {
+	return func(ctx context.Context, _ *grpc.StreamServerInfo) string {
+		return contextValue(ctx, key)
+	}
+}
+
 // ─── Internals ───────────────────────────────────────────────────────────────
 
+func contextValue(ctx context.Context, key any) string {
+	switch val := ctx.Value(key).(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func peerCertKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			return middleware.CertFingerprint(info.State.PeerCertificates[0])
+		}
+	}
+	return peerAddr(ctx)
+}
+
 func peerAddr(ctx context.Context) string {
 	p, ok := peer.FromContext(ctx)
 	if ok && p.Addr != nil {
@@ -209,7 +349,16 @@ func metadataValue(ctx context.Context, header string) string {
 			return vals[0]
 		}
 	}
-	return "unknown"
+	return ""
+}
+
+// methodKey joins method and value, keeping the key empty when value is so
+// that a missing value reaches the EmptyKeyPolicy.
+func methodKey(method, value string) string {
+	if value == "" {
+		return ""
+	}
+	return method + ":" + value
 }
 
 func setRateLimitMetadata(ctx context.Context, result *goratelimit.Result) {
@@ -2,6 +2,13 @@ package grpcmw_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"net"
 	"testing"
 	"time"
@@ -10,11 +17,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
 	"github.com/krishna-kudari/ratelimit/middleware/grpcmw"
 
 	testgrpc "google.golang.org/grpc/interop/grpc_testing"
@@ -110,6 +120,73 @@ func TestUnaryServerInterceptor_DeniesExceedingLimit(t *testing.T) {
 	assert.Equal(t, codes.ResourceExhausted, st.Code())
 }
 
+func TestUnaryServerInterceptor_EmptyKeyPolicy(t *testing.T) {
+	emptyKey := func(context.Context, *grpc.UnaryServerInfo) string { return "" }
+
+	t.Run("deny", func(t *testing.T) {
+		limiter, err := goratelimit.NewFixedWindow(10, 60)
+		require.NoError(t, err)
+		var reason goratelimit.DenyReason
+		client, cleanup := startServer(t,
+			grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+				Limiter:        limiter,
+				KeyFunc:        emptyKey,
+				EmptyKeyPolicy: middleware.EmptyKeyDeny,
+				DeniedHandler: func(_ context.Context, result *goratelimit.Result) error {
+					reason = result.DenyReason
+					return status.Error(codes.ResourceExhausted, "denied")
+				},
+			})),
+		)
+		defer cleanup()
+
+		_, err = client.EmptyCall(context.Background(), &testgrpc.Empty{})
+		require.Error(t, err)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, goratelimit.ReasonMissingKey, reason)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		limiter, err := goratelimit.NewFixedWindow(1, 60)
+		require.NoError(t, err)
+		client, cleanup := startServer(t,
+			grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+				Limiter:        limiter,
+				KeyFunc:        emptyKey,
+				EmptyKeyPolicy: middleware.EmptyKeyAllow,
+			})),
+		)
+		defer cleanup()
+
+		for i := 0; i < 3; i++ {
+			_, err := client.EmptyCall(context.Background(), &testgrpc.Empty{})
+			require.NoError(t, err, "request %d", i+1)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		limiter, err := goratelimit.NewFixedWindow(1, 60)
+		require.NoError(t, err)
+		var fallbackCalled bool
+		client, cleanup := startServer(t,
+			grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+				Limiter:        limiter,
+				KeyFunc:        emptyKey,
+				EmptyKeyPolicy: middleware.EmptyKeyFallback,
+				EmptyKeyFallback: func(ctx context.Context, info *grpc.UnaryServerInfo) string {
+					fallbackCalled = true
+					return grpcmw.KeyByPeer(ctx, info)
+				},
+			})),
+		)
+		defer cleanup()
+
+		_, err = client.EmptyCall(context.Background(), &testgrpc.Empty{})
+		require.NoError(t, err)
+		assert.True(t, fallbackCalled)
+	})
+}
+
 func TestUnaryServerInterceptor_RateLimitHeaders(t *testing.T) {
 	limiter, err := goratelimit.NewFixedWindow(10, 60)
 	require.NoError(t, err)
@@ -255,6 +332,231 @@ func TestUnaryServerInterceptor_KeyByMethod(t *testing.T) {
 	require.NoError(t, err, "UnaryCall should be allowed (different method key)")
 }
 
+func TestUnaryServerInterceptor_KeyByMethodAndMetadata(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	client, cleanup := startServer(t,
+		grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptor(limiter, grpcmw.KeyByMethodAndMetadata("x-api-key"))),
+	)
+	defer cleanup()
+
+	ctxA := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-A")
+	ctxB := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-B")
+
+	// key-A on EmptyCall — use up its 1 allowed request
+	_, err = client.EmptyCall(ctxA, &testgrpc.Empty{})
+	require.NoError(t, err)
+	_, err = client.EmptyCall(ctxA, &testgrpc.Empty{})
+	require.Error(t, err, "key-A 2nd EmptyCall should be denied")
+
+	_, err = client.EmptyCall(ctxB, &testgrpc.Empty{})
+	require.NoError(t, err, "key-B should be allowed on the same method")
+
+	_, err = client.UnaryCall(ctxA, &testgrpc.SimpleRequest{})
+	require.NoError(t, err, "key-A should be allowed on a different method")
+}
+
+func TestUnaryServerInterceptor_MissingMetadataUsesEmptyKeyPolicy(t *testing.T) {
+	for name, keyFunc := range map[string]grpcmw.KeyFunc{
+		"KeyByMetadata":          grpcmw.KeyByMetadata("x-api-key"),
+		"KeyByMethodAndMetadata": grpcmw.KeyByMethodAndMetadata("x-api-key"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			limiter := mustLimiter(goratelimit.NewFixedWindow(10, 60))
+			var reason goratelimit.DenyReason
+			client, cleanup := startServer(t,
+				grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+					Limiter:        limiter,
+					KeyFunc:        keyFunc,
+					EmptyKeyPolicy: middleware.EmptyKeyDeny,
+					DeniedHandler: func(_ context.Context, result *goratelimit.Result) error {
+						reason = result.DenyReason
+						return status.Error(codes.ResourceExhausted, "denied")
+					},
+				})),
+			)
+			defer cleanup()
+
+			_, err := client.EmptyCall(context.Background(), &testgrpc.Empty{})
+			require.Error(t, err, "a call without the header should be denied")
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			assert.Equal(t, goratelimit.ReasonMissingKey, reason)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-A")
+			_, err = client.EmptyCall(ctx, &testgrpc.Empty{})
+			require.NoError(t, err, "a call with the header should be allowed")
+		})
+	}
+}
+
+func TestStreamKeyByMethodAndMetadata(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(1, 60))
+	stream := grpcmw.StreamServerInterceptor(limiter, grpcmw.StreamKeyByMethodAndMetadata("x-api-key"))
+	handler := func(any, grpc.ServerStream) error { return nil }
+
+	call := func(method, apiKey string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", apiKey))
+		return stream(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: method}, handler)
+	}
+
+	require.NoError(t, call("/svc/Watch", "key-A"))
+	require.Error(t, call("/svc/Watch", "key-A"), "key-A 2nd Watch should be denied")
+	require.NoError(t, call("/svc/Watch", "key-B"), "key-B should be allowed on the same method")
+	require.NoError(t, call("/svc/Tail", "key-A"), "key-A should be allowed on a different method")
+}
+
+// principalKey is the context key a test auth interceptor stores the
+// caller's identity under.
+type principalKey struct{}
+
+// authInterceptor stands in for an auth interceptor: it attaches the
+// "authorization" metadata value, if any, as the principal.
+func authInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get("authorization"); len(vals) > 0 {
+			ctx = context.WithValue(ctx, principalKey{}, vals[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
+func TestUnaryServerInterceptor_KeyByContextValue(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(1, 60))
+	var keys []string
+	recordKey := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key, _ := middleware.KeyFromContext(ctx)
+		keys = append(keys, key)
+		return handler(ctx, req)
+	}
+
+	client, cleanup := startServer(t,
+		grpc.ChainUnaryInterceptor(
+			authInterceptor,
+			grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+				Limiter:          limiter,
+				KeyFunc:          grpcmw.KeyByContextValue(principalKey{}),
+				EmptyKeyPolicy:   middleware.EmptyKeyFallback,
+				EmptyKeyFallback: grpcmw.KeyByPeer,
+			}),
+			recordKey,
+		),
+	)
+	defer cleanup()
+
+	as := func(principal string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", principal)
+	}
+
+	_, err := client.EmptyCall(as("alice"), &testgrpc.Empty{})
+	require.NoError(t, err)
+	_, err = client.EmptyCall(as("alice"), &testgrpc.Empty{})
+	require.Error(t, err, "alice's 2nd call should be denied")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.EmptyCall(as("bob"), &testgrpc.Empty{})
+	require.NoError(t, err, "bob has a separate budget on the same connection")
+
+	_, err = client.EmptyCall(context.Background(), &testgrpc.Empty{})
+	require.NoError(t, err, "an unauthenticated call falls back to the peer key")
+	_, err = client.EmptyCall(context.Background(), &testgrpc.Empty{})
+	require.Error(t, err, "unauthenticated calls share the peer's budget")
+
+	require.Len(t, keys, 3)
+	assert.Equal(t, []string{"alice", "bob"}, keys[:2])
+	assert.Contains(t, keys[2], "127.0.0.1:", "the fallback key is the peer address")
+}
+
+func TestStreamKeyByContextValue(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(1, 60))
+	stream := grpcmw.StreamServerInterceptor(limiter, grpcmw.StreamKeyByContextValue(principalKey{}))
+	handler := func(any, grpc.ServerStream) error { return nil }
+
+	call := func(principal any) error {
+		ctx := context.WithValue(context.Background(), principalKey{}, principal)
+		return stream(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}, handler)
+	}
+
+	require.NoError(t, call(42), "non-string principals are formatted")
+	require.Error(t, call("42"), "principal 42 has used its budget")
+	require.NoError(t, call("43"))
+}
+
+func TestKeyByContextValue_MissingValueIsEmpty(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+	assert.Empty(t, grpcmw.KeyByContextValue(principalKey{})(ctx, &grpc.UnaryServerInfo{}),
+		"a missing principal should be left to EmptyKeyPolicy")
+	assert.Empty(t, grpcmw.StreamKeyByContextValue(principalKey{})(context.WithValue(ctx, principalKey{}, ""), &grpc.StreamServerInfo{}),
+		"an empty principal should be left to EmptyKeyPolicy")
+}
+
+func TestUnaryServerInterceptor_MessageKeyFunc(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	client, cleanup := startServer(t,
+		grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptorWithConfig(grpcmw.Config{
+			Limiter: limiter,
+			MessageKeyFunc: func(_ context.Context, _ *grpc.UnaryServerInfo, req any) string {
+				if r, ok := req.(*testgrpc.SimpleRequest); ok {
+					return string(r.GetPayload().GetBody())
+				}
+				return ""
+			},
+		})),
+	)
+	defer cleanup()
+
+	ctx := context.Background()
+	tenant := func(id string) *testgrpc.SimpleRequest {
+		return &testgrpc.SimpleRequest{Payload: &testgrpc.Payload{Body: []byte(id)}}
+	}
+
+	_, err = client.UnaryCall(ctx, tenant("tenant-a"))
+	require.NoError(t, err)
+	_, err = client.UnaryCall(ctx, tenant("tenant-a"))
+	require.Error(t, err, "tenant-a 2nd call should be denied")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.UnaryCall(ctx, tenant("tenant-b"))
+	require.NoError(t, err, "tenant-b has its own budget")
+}
+
+func TestKeyByClientCert(t *testing.T) {
+	der := selfSignedCertDER(t)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	addr := &net.TCPAddr{IP: net.ParseIP("192.0.2.9"), Port: 5000}
+
+	withCert := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     addr,
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+	key := grpcmw.KeyByClientCert(withCert, &grpc.UnaryServerInfo{})
+	assert.Equal(t, middleware.CertFingerprint(cert), key)
+	assert.Equal(t, key, grpcmw.StreamKeyByClientCert(withCert, &grpc.StreamServerInfo{}),
+		"unary and stream extractors should agree")
+
+	noCert := peer.NewContext(context.Background(), &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{}})
+	assert.Equal(t, addr.String(), grpcmw.KeyByClientCert(noCert, &grpc.UnaryServerInfo{}),
+		"should fall back to peer address without a client cert")
+}
+
+func selfSignedCertDER(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
 func TestUnaryServerInterceptor_DifferentAlgorithms(t *testing.T) {
 	algorithms := []struct {
 		name    string
@@ -291,3 +593,35 @@ func mustLimiter(l goratelimit.Limiter, err error) goratelimit.Limiter {
 	}
 	return l
 }
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestInterceptors_KeyInContext(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(5, 60))
+
+	unary := grpcmw.UnaryServerInterceptor(limiter, grpcmw.KeyByMetadata("x-api-key"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "unary-key"))
+	var got string
+	_, err := unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/Unary"},
+		func(ctx context.Context, _ any) (any, error) {
+			got, _ = middleware.KeyFromContext(ctx)
+			return nil, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "unary-key", got)
+
+	stream := grpcmw.StreamServerInterceptor(limiter, grpcmw.StreamKeyByMetadata("x-api-key"))
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "stream-key"))
+	err = stream(nil, &contextStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/svc/Stream"},
+		func(_ any, ss grpc.ServerStream) error {
+			got, _ = middleware.KeyFromContext(ss.Context())
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "stream-key", got)
+}
@@ -0,0 +1,57 @@
+// Package pb defines RateLimitInfo, a protobuf representation of
+// goratelimit.Result, so gRPC services can return rate limit status in their
+// response messages rather than only in trailers.
+//
+// Import ratelimit.proto from your own .proto files and embed the message:
+//
+//	import "ratelimit.proto";
+//
+//	message GetWidgetResponse {
+//	  Widget widget = 1;
+//	  goratelimit.v1.RateLimitInfo rate_limit = 2;
+//	}
+//
+// Regenerate ratelimit.pb.go with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative ratelimit.proto
+package pb
+
+import (
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// ToProto converts result to a RateLimitInfo. ResetAt is truncated to Unix
+// seconds and RetryAfter to milliseconds. A nil result returns nil.
+func ToProto(result *goratelimit.Result) *RateLimitInfo {
+	if result == nil {
+		return nil
+	}
+	info := &RateLimitInfo{
+		Allowed:      result.Allowed,
+		Remaining:    result.Remaining,
+		Limit:        result.Limit,
+		RetryAfterMs: result.RetryAfter.Milliseconds(),
+		Rate:         result.Rate,
+	}
+	if !result.ResetAt.IsZero() {
+		info.ResetUnix = result.ResetAt.Unix()
+	}
+	return info
+}
+
+// FromProto converts info back to a Result. A nil info returns the zero Result.
+func FromProto(info *RateLimitInfo) goratelimit.Result {
+	result := goratelimit.Result{
+		Allowed:    info.GetAllowed(),
+		Remaining:  info.GetRemaining(),
+		Limit:      info.GetLimit(),
+		RetryAfter: time.Duration(info.GetRetryAfterMs()) * time.Millisecond,
+		Rate:       info.GetRate(),
+	}
+	if info.GetResetUnix() != 0 {
+		result.ResetAt = time.Unix(info.GetResetUnix(), 0)
+	}
+	return result
+}
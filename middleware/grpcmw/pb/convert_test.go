@@ -0,0 +1,69 @@
+package pb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware/grpcmw/pb"
+)
+
+func TestToProto_RoundTrip(t *testing.T) {
+	resetAt := time.Unix(1_700_000_123, 0)
+	tests := []struct {
+		name   string
+		result goratelimit.Result
+	}{
+		{"allowed", goratelimit.Result{Allowed: true, Remaining: 7, Limit: 10, ResetAt: resetAt, Rate: 5}},
+		{"denied", goratelimit.Result{Allowed: false, Remaining: 0, Limit: 10, ResetAt: resetAt, RetryAfter: 1500 * time.Millisecond}},
+		{"unlimited", goratelimit.Result{Allowed: true, Remaining: goratelimit.Unlimited, Limit: goratelimit.Unlimited}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := pb.ToProto(&tt.result)
+			assert.Equal(t, tt.result.Allowed, info.GetAllowed())
+			assert.Equal(t, tt.result.Remaining, info.GetRemaining())
+			assert.Equal(t, tt.result.Limit, info.GetLimit())
+			assert.Equal(t, tt.result.RetryAfter.Milliseconds(), info.GetRetryAfterMs())
+			if tt.result.ResetAt.IsZero() {
+				assert.Zero(t, info.GetResetUnix())
+			} else {
+				assert.Equal(t, tt.result.ResetAt.Unix(), info.GetResetUnix())
+			}
+
+			// Through the wire format and back.
+			b, err := proto.Marshal(info)
+			require.NoError(t, err)
+			var decoded pb.RateLimitInfo
+			require.NoError(t, proto.Unmarshal(b, &decoded))
+
+			got := pb.FromProto(&decoded)
+			assert.Equal(t, tt.result.Allowed, got.Allowed)
+			assert.Equal(t, tt.result.Remaining, got.Remaining)
+			assert.Equal(t, tt.result.Limit, got.Limit)
+			assert.Equal(t, tt.result.RetryAfter, got.RetryAfter)
+			assert.Equal(t, tt.result.Rate, got.Rate)
+			assert.True(t, tt.result.ResetAt.Equal(got.ResetAt), "ResetAt: want %v, got %v", tt.result.ResetAt, got.ResetAt)
+		})
+	}
+}
+
+func TestToProto_TruncatesSubUnitPrecision(t *testing.T) {
+	result := goratelimit.Result{
+		ResetAt:    time.Unix(1_700_000_000, 900_000_000),
+		RetryAfter: 1500*time.Millisecond + 999*time.Microsecond,
+	}
+	info := pb.ToProto(&result)
+	assert.Equal(t, int64(1_700_000_000), info.GetResetUnix())
+	assert.Equal(t, int64(1500), info.GetRetryAfterMs())
+}
+
+func TestToProto_Nil(t *testing.T) {
+	assert.Nil(t, pb.ToProto(nil))
+	assert.Equal(t, goratelimit.Result{}, pb.FromProto(nil))
+}
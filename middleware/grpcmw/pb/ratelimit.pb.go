@@ -0,0 +1,176 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: ratelimit.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RateLimitInfo is the wire form of goratelimit.Result, for services that
+// embed rate limit status in their response messages.
+type RateLimitInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the request was admitted.
+	Allowed bool `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	// Requests left in the current window or bucket.
+	Remaining int64 `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// Configured maximum for the key.
+	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// When the limit fully resets, in Unix seconds. 0 if unknown.
+	ResetUnix int64 `protobuf:"varint,4,opt,name=reset_unix,json=resetUnix,proto3" json:"reset_unix,omitempty"`
+	// How long to wait before retrying, in milliseconds. 0 when allowed.
+	RetryAfterMs int64 `protobuf:"varint,5,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	// Sustained requests per second for rate-based algorithms, else 0.
+	Rate          int64 `protobuf:"varint,6,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimitInfo) Reset() {
+	*x = RateLimitInfo{}
+	mi := &file_ratelimit_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimitInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimitInfo) ProtoMessage() {}
+
+func (x *RateLimitInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_ratelimit_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimitInfo.ProtoReflect.Descriptor instead.
+func (*RateLimitInfo) Descriptor() ([]byte, []int) {
+	return file_ratelimit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RateLimitInfo) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *RateLimitInfo) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *RateLimitInfo) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RateLimitInfo) GetResetUnix() int64 {
+	if x != nil {
+		return x.ResetUnix
+	}
+	return 0
+}
+
+func (x *RateLimitInfo) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *RateLimitInfo) GetRate() int64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+var File_ratelimit_proto protoreflect.FileDescriptor
+
+const file_ratelimit_proto_rawDesc = "" +
+	"\n" +
+	"\x0fratelimit.proto\x12\x0egoratelimit.v1\"\xb6\x01\n" +
+	"\rRateLimitInfo\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x03R\tremaining\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x1d\n" +
+	"\n" +
+	"reset_unix\x18\x04 \x01(\x03R\tresetUnix\x12$\n" +
+	"\x0eretry_after_ms\x18\x05 \x01(\x03R\fretryAfterMs\x12\x12\n" +
+	"\x04rate\x18\x06 \x01(\x03R\x04rateB=Z;github.com/krishna-kudari/ratelimit/middleware/grpcmw/pb;pbb\x06proto3"
+
+var (
+	file_ratelimit_proto_rawDescOnce sync.Once
+	file_ratelimit_proto_rawDescData []byte
+)
+
+func file_ratelimit_proto_rawDescGZIP() []byte {
+	file_ratelimit_proto_rawDescOnce.Do(func() {
+		file_ratelimit_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ratelimit_proto_rawDesc), len(file_ratelimit_proto_rawDesc)))
+	})
+	return file_ratelimit_proto_rawDescData
+}
+
+var file_ratelimit_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_ratelimit_proto_goTypes = []any{
+	(*RateLimitInfo)(nil), // 0: goratelimit.v1.RateLimitInfo
+}
+var file_ratelimit_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_ratelimit_proto_init() }
+func file_ratelimit_proto_init() {
+	if File_ratelimit_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ratelimit_proto_rawDesc), len(file_ratelimit_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_ratelimit_proto_goTypes,
+		DependencyIndexes: file_ratelimit_proto_depIdxs,
+		MessageInfos:      file_ratelimit_proto_msgTypes,
+	}.Build()
+	File_ratelimit_proto = out.File
+	file_ratelimit_proto_goTypes = nil
+	file_ratelimit_proto_depIdxs = nil
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// HeaderStyle selects which rate limit headers the middleware sends.
+type HeaderStyle int
+
+const (
+	// HeaderStyleLegacy sends X-RateLimit-Limit, X-RateLimit-Remaining and
+	// X-RateLimit-Reset (a Unix timestamp). This is the default.
+	HeaderStyleLegacy HeaderStyle = iota
+
+	// HeaderStyleDraft sends the IETF draft headers instead: RateLimit-Limit,
+	// RateLimit-Remaining, RateLimit-Reset (seconds until reset) and
+	// RateLimit-Policy.
+	HeaderStyleDraft
+
+	// HeaderStyleBoth sends the legacy and the draft headers.
+	HeaderStyleBoth
+)
+
+// ratePolicy renders d as a draft RateLimit-Policy value: "<limit>;w=<window>"
+// for window algorithms and "<rate>;w=1;burst=<limit>" for bucket
+// algorithms, with windows in seconds. It returns "" when the policy cannot
+// be stated, as for dynamic limits.
+func ratePolicy(d goratelimit.Description) string {
+	switch {
+	case d.Dynamic || d.Limit <= 0:
+		return ""
+	case d.Window >= time.Second:
+		return strconv.FormatInt(d.Limit, 10) + ";w=" + strconv.FormatInt(int64(d.Window/time.Second), 10)
+	case d.Rate > 0:
+		return strconv.FormatInt(d.Rate, 10) + ";w=1;burst=" + strconv.FormatInt(d.Limit, 10)
+	}
+	return ""
+}
+
+// draftReset returns the draft RateLimit-Reset value, the whole seconds until
+// the key's quota resets, and false if the result does not say.
+func draftReset(result *goratelimit.Result) (int64, bool) {
+	var d time.Duration
+	switch {
+	case !result.ResetAt.IsZero():
+		d = time.Until(result.ResetAt)
+	case result.RetryAfter > 0:
+		d = result.RetryAfter
+	default:
+		return 0, false
+	}
+	return max(0, int64((d+time.Second-1)/time.Second)), true
+}
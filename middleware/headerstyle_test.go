@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+var policyPattern = regexp.MustCompile(`^[0-9]+;w=[0-9]+(;burst=[0-9]+)?$`)
+
+func serveOnce(t *testing.T, cfg middleware.Config) *httptest.ResponseRecorder {
+	t.Helper()
+	cfg.KeyFunc = middleware.KeyByIP
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "7.7.7.7:1111"
+	middleware.RateLimitWithConfig(cfg)(okHandler()).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	return rr
+}
+
+func TestHeaderStyle_Draft_FixedWindowPolicy(t *testing.T) {
+	rr := serveOnce(t, middleware.Config{
+		Limiter:     mustLimiter(goratelimit.NewFixedWindow(100, 60)),
+		HeaderStyle: middleware.HeaderStyleDraft,
+	})
+
+	policy := rr.Header().Get("RateLimit-Policy")
+	assert.Regexp(t, policyPattern, policy)
+	assert.Equal(t, "100;w=60", policy)
+	assert.Equal(t, "100", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "99", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rr.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"), "draft style drops the legacy headers")
+}
+
+func TestHeaderStyle_Draft_TokenBucketPolicy(t *testing.T) {
+	rr := serveOnce(t, middleware.Config{
+		Limiter:     mustLimiter(goratelimit.NewTokenBucket(50, 5)),
+		HeaderStyle: middleware.HeaderStyleDraft,
+	})
+
+	policy := rr.Header().Get("RateLimit-Policy")
+	assert.Regexp(t, policyPattern, policy)
+	assert.Equal(t, "5;w=1;burst=50", policy)
+}
+
+func TestHeaderStyle_Both(t *testing.T) {
+	rr := serveOnce(t, middleware.Config{
+		Limiter:     mustLimiter(goratelimit.NewFixedWindow(10, 60)),
+		HeaderStyle: middleware.HeaderStyleBoth,
+	})
+
+	assert.Equal(t, "10", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "10", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "10;w=60", rr.Header().Get("RateLimit-Policy"))
+}
+
+func TestHeaderStyle_LegacyOmitsPolicy(t *testing.T) {
+	rr := serveOnce(t, middleware.Config{
+		Limiter: mustLimiter(goratelimit.NewFixedWindow(10, 60)),
+	})
+
+	assert.Equal(t, "10", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, rr.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, rr.Header().Get("RateLimit-Limit"))
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +18,10 @@ import (
 // The returned string identifies the caller (e.g. IP, API key, user ID).
 type KeyFunc func(r *http.Request) string
 
+// CostFunc returns how many units to charge a request, e.g. tokens derived
+// from its declared body size. It is passed to Limiter.AllowN.
+type CostFunc func(r *http.Request) int
+
 // ErrorHandler is called when the limiter returns an error.
 // Default behavior: 500 Internal Server Error.
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
@@ -32,14 +38,35 @@ type Config struct {
 	// KeyFunc extracts the rate limit key from the request (required).
 	KeyFunc KeyFunc
 
+	// Cost, when set, returns the units to charge each request with AllowN,
+	// e.g. CostByContentLength for upload bandwidth.
+	// Default: 1 per request.
+	Cost CostFunc
+
 	// ErrorHandler is called when the limiter returns an error.
-	// Default: responds with 500.
+	// Default: responds with 500, unless FallbackOnError is set.
 	ErrorHandler ErrorHandler
 
+	// FallbackOnError allows or denies requests when the limiter returns an
+	// error and ErrorHandler is nil, so a failing backend (errors wrapping
+	// goratelimit.ErrBackendUnavailable) does not turn into 500s.
+	// Ignored when ErrorHandler is set.
+	// Default: FallbackNone.
+	FallbackOnError ErrorFallback
+
 	// DeniedHandler is called when a request is denied.
-	// Default: responds with 429 and Retry-After header.
+	// Default: responds with 429, Retry-After, and Cache-Control: no-store
+	// (see SetDeniedCacheHeaders).
 	DeniedHandler DeniedHandler
 
+	// EmptyKeyPolicy controls how requests are handled when KeyFunc returns "".
+	// Default: EmptyKeyShared.
+	EmptyKeyPolicy EmptyKeyPolicy
+
+	// EmptyKeyFallback resolves the key when KeyFunc returns "" and
+	// EmptyKeyPolicy is EmptyKeyFallback (required in that case).
+	EmptyKeyFallback KeyFunc
+
 	// ExcludePaths are request paths that bypass rate limiting.
 	ExcludePaths map[string]bool
 
@@ -50,16 +77,107 @@ type Config struct {
 	Allowlist []string
 
 	// Headers controls whether X-RateLimit-* headers are set on responses.
+	// When the limiter resolves its limit per request (WithLimitFunc), this
+	// includes X-RateLimit-Limit-Policy: dynamic, telling clients that
+	// X-RateLimit-Limit may vary between requests.
 	// Default: true.
 	Headers *bool
 
+	// HeaderStyle selects the legacy X-RateLimit-* headers, the IETF draft
+	// RateLimit-* headers including RateLimit-Policy, or both. The policy is
+	// derived from the limiter's Describe output and omitted for dynamic
+	// limits. Ignored when Headers is false.
+	// Default: HeaderStyleLegacy.
+	HeaderStyle HeaderStyle
+
+	// ApplyShapingDelay, when true, holds a request the limiter admitted with
+	// a delay (RetryAfter on an allowed Result, as from a Shaping leaky
+	// bucket) for that delay before calling the handler, so the server does
+	// the pacing. If the request is canceled while waiting, the context error
+	// goes to ErrorHandler. When false, the delay is only advertised as
+	// X-RateLimit-Delay in milliseconds, unless Headers is false.
+	// Default: false.
+	ApplyShapingDelay bool
+
+	// DenyDelay, when positive, holds each request the limiter denies for
+	// this long before writing the rejection, so a brute-force client pays
+	// for every attempt (tarpitting). Each held request ties up a goroutine
+	// and a connection, so keep it short. If the request is canceled while
+	// held, the context error goes to ErrorHandler. Requests denied for a
+	// backend error or an empty key are not held.
+	// Default: 0 (deny at once).
+	DenyDelay time.Duration
+
+	// DenyDelayFunc, when set, returns the hold for each denied request in
+	// place of DenyDelay, e.g. PenaltyDenyDelay to lengthen it with a key's
+	// repeated violations.
+	DenyDelayFunc func(key string, result *goratelimit.Result) time.Duration
+
+	// ChargeOnStatus, when set, charges only requests whose response status
+	// it accepts, e.g. IsSuccessStatus to bill 2xx responses but not 304s
+	// or 401s. Each request still reserves its cost up front, so concurrent
+	// requests cannot overrun the limit; the reservation is refunded once
+	// the handler responds with a status ChargeOnStatus rejects. Limiter
+	// must be able to refund, as the in-memory window, Token Bucket and GCRA
+	// limiters can, also when wrapped by options such as WithDryRun; see
+	// goratelimit.CanRefund. A refund that fails leaves the request charged,
+	// and a request whose Result is Uncharged, e.g. under
+	// goratelimit.SetMode(goratelimit.ForceAllow), is never refunded.
+	// Default: nil (every admitted request is charged).
+	ChargeOnStatus func(status int) bool
+
 	// Message is the response body for denied requests.
 	// Default: "Too Many Requests".
 	Message string
 
+	// ResultBody, when true and DeniedHandler is nil, answers denied
+	// requests with JSONDeniedHandler instead of the default body, so
+	// clients that do not read headers still get the Result as JSON.
+	// StatusCode and StatusForReason still apply; Message does not.
+	// Default: false.
+	ResultBody bool
+
 	// StatusCode is the HTTP status code for denied requests.
 	// Default: 429.
 	StatusCode int
+
+	// StatusForReason overrides StatusCode per Result.DenyReason, e.g.
+	// 503 for ReasonBackendError and ReasonMaintenance so clients and SLA
+	// accounting can tell the server's failures from the client exceeding
+	// its limit. Reasons not in the map use StatusCode. Used only by the
+	// default DeniedHandler.
+	// Default: nil (StatusCode for every reason).
+	StatusForReason map[goratelimit.DenyReason]int
+
+	// ExposeAlgorithm, when true, sets X-RateLimit-Algorithm to the limiter's
+	// Describe().Algorithm. Omitted if the limiter does not implement
+	// goratelimit.Describer.
+	// Default: false.
+	ExposeAlgorithm *bool
+
+	// ComponentHeaders, when true and the limiter is a goratelimit.NewChain,
+	// adds X-RateLimit-Limit-<id> and X-RateLimit-Remaining-<id> for each
+	// chain link alongside the combined X-RateLimit-* headers, which reflect
+	// the most constraining link. Ignored when Headers is false.
+	// Default: false.
+	ComponentHeaders bool
+
+	// DocumentationURL, when set, is advertised on denied responses as
+	// `Link: <url>; rel="help"` so clients can discover the rate limit policy.
+	DocumentationURL string
+
+	// PenaltyBox, when set to the PenaltyBox wrapping Limiter, adds
+	// `X-RateLimit-Backoff: base=<s>; max=<s>; attempt=<n>` to denied
+	// responses so clients can back off exponentially. base and max are in
+	// seconds, and attempt counts the key's consecutive denials.
+	PenaltyBox *goratelimit.PenaltyBox
+
+	// SurrogateControl, when set, is called on denied requests and its
+	// return value is sent as Surrogate-Control, telling a CDN how long to
+	// serve the cached 429 at the edge, e.g. "max-age=" + the RetryAfter in
+	// seconds. Returning "" omits the header. Surrogate-Control is consumed
+	// by the CDN, so browsers still see Cache-Control: no-store.
+	SurrogateControl func(result *goratelimit.Result) string
 }
 
 // RateLimit creates HTTP middleware with default settings.
@@ -82,6 +200,11 @@ func RateLimit(limiter goratelimit.Limiter, keyFunc KeyFunc) func(http.Handler)
 }
 
 // RateLimitWithConfig creates HTTP middleware with full configuration control.
+// It runs the shared Core decision flow through a net/http Adapter.
+//
+// When several of these middlewares are stacked with the same Limiter and the
+// same key for a request, only the first charges the limiter; the others
+// reuse its Result.
 func RateLimitWithConfig(cfg Config) func(http.Handler) http.Handler {
 	if cfg.Limiter == nil {
 		panic("goratelimit/middleware: Limiter is required")
@@ -89,21 +212,25 @@ func RateLimitWithConfig(cfg Config) func(http.Handler) http.Handler {
 	if cfg.KeyFunc == nil {
 		panic("goratelimit/middleware: KeyFunc is required")
 	}
+	if cfg.EmptyKeyPolicy == EmptyKeyFallback && cfg.EmptyKeyFallback == nil {
+		panic("goratelimit/middleware: EmptyKeyFallback is required with EmptyKeyPolicy EmptyKeyFallback")
+	}
+	if cfg.DeniedHandler == nil && cfg.ResultBody {
+		cfg.DeniedHandler = jsonDeniedHandler(cfg.StatusCode, cfg.StatusForReason)
+	} else if cfg.DeniedHandler == nil {
+		cfg.DeniedHandler = defaultDeniedHandler(cfg.Message, cfg.StatusCode, cfg.StatusForReason)
+	}
 	if cfg.ErrorHandler == nil {
 		cfg.ErrorHandler = defaultErrorHandler
+	} else {
+		cfg.FallbackOnError = FallbackNone
 	}
-	if cfg.DeniedHandler == nil {
-		cfg.DeniedHandler = defaultDeniedHandler(cfg.Message, cfg.StatusCode)
-	}
-	sendHeaders := cfg.Headers == nil || *cfg.Headers
 
+	core := NewCore(cfg)
 	allowlistNets := ParseAllowlistCIDRs(cfg.Allowlist)
+	dedup := dedupable(cfg.Limiter)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if cfg.ExcludePaths != nil && cfg.ExcludePaths[r.URL.Path] {
-				next.ServeHTTP(w, r)
-				return
-			}
 			if cfg.BypassFunc != nil && cfg.BypassFunc(r) {
 				next.ServeHTTP(w, r)
 				return
@@ -112,29 +239,99 @@ func RateLimitWithConfig(cfg Config) func(http.Handler) http.Handler {
 				next.ServeHTTP(w, r)
 				return
 			}
-
-			key := cfg.KeyFunc(r)
-			result, err := cfg.Limiter.Allow(r.Context(), key)
-			if err != nil {
-				cfg.ErrorHandler(w, r, err)
-				return
+			if dedup {
+				_, r = withLedger(r)
 			}
-
-			if sendHeaders {
-				setRateLimitHeaders(w, &result)
+			a := &httpAdapter{cfg: &cfg, w: w, r: r, next: next}
+			if err := core.Run(r.Context(), a); err != nil {
+				cfg.ErrorHandler(w, a.r, err)
 			}
+		})
+	}
+}
 
-			if !result.Allowed {
-				if result.RetryAfter > 0 {
-					w.Header().Set("Retry-After", strconv.FormatInt(int64(result.RetryAfter.Seconds()+0.5), 10))
-				}
-				cfg.DeniedHandler(w, r, &result)
-				return
-			}
+// httpAdapter runs Core for one net/http request.
+type httpAdapter struct {
+	cfg  *Config
+	w    http.ResponseWriter
+	r    *http.Request
+	next http.Handler
+}
 
-			next.ServeHTTP(w, r)
-		})
+func (a *httpAdapter) Key() string {
+	key := a.cfg.KeyFunc(a.r)
+	if key == "" {
+		switch a.cfg.EmptyKeyPolicy {
+		case EmptyKeyAllow, EmptyKeyDeny:
+			return ""
+		case EmptyKeyFallback:
+			key = a.cfg.EmptyKeyFallback(a.r)
+		}
+	}
+	a.r = a.r.WithContext(ContextWithKey(a.r.Context(), key))
+	return key
+}
+
+func (a *httpAdapter) Path() string {
+	return a.r.URL.Path
+}
+
+func (a *httpAdapter) Cost() int {
+	if a.cfg.Cost == nil {
+		return 1
 	}
+	return a.cfg.Cost(a.r)
+}
+
+func (a *httpAdapter) SetHeader(key, value string) {
+	a.w.Header().Set(key, value)
+}
+
+func (a *httpAdapter) Deny(result *goratelimit.Result) error {
+	a.cfg.DeniedHandler(a.w, a.r, result)
+	return nil
+}
+
+func (a *httpAdapter) Next() error {
+	a.next.ServeHTTP(a.w, a.r)
+	return nil
+}
+
+func (a *httpAdapter) NextStatus() (int, error) {
+	sw := &statusWriter{ResponseWriter: a.w, status: http.StatusOK}
+	a.next.ServeHTTP(sw, a.r)
+	return sw.status, nil
+}
+
+// IsSuccessStatus reports whether status is 2xx. It suits
+// Config.ChargeOnStatus.
+func IsSuccessStatus(status int) bool {
+	return status >= 200 && status < 300
+}
+
+// statusWriter records the status written through it; a handler that never
+// calls WriteHeader responds with 200.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status >= 200 {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 // ─── Built-in Key Extractors ─────────────────────────────────────────────────
@@ -203,14 +400,21 @@ func KeyByPathAndIP(r *http.Request) string {
 	return r.URL.Path + ":" + KeyByIP(r)
 }
 
-// ─── Headers ─────────────────────────────────────────────────────────────────
-
-func setRateLimitHeaders(w http.ResponseWriter, result *goratelimit.Result) {
-	w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
-	w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
-	if !result.ResetAt.IsZero() {
-		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+// KeyByClientCert uses the SHA-256 fingerprint of the TLS client's leaf
+// certificate as the rate limit key, for per-client limits on mTLS APIs.
+// Falls back to KeyByIP when the request carries no client certificate.
+func KeyByClientCert(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return CertFingerprint(r.TLS.PeerCertificates[0])
 	}
+	return KeyByIP(r)
+}
+
+// CertFingerprint returns the hex-encoded SHA-256 of cert's raw DER bytes.
+// It is stable for a given certificate across connections.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
 }
 
 // ─── Default Handlers ────────────────────────────────────────────────────────
@@ -219,7 +423,7 @@ func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, _ error) {
 	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 }
 
-func defaultDeniedHandler(message string, statusCode int) DeniedHandler {
+func defaultDeniedHandler(message string, statusCode int, statusForReason map[goratelimit.DenyReason]int) DeniedHandler {
 	if message == "" {
 		message = "rate limit exceeded"
 	}
@@ -236,11 +440,43 @@ func defaultDeniedHandler(message string, statusCode int) DeniedHandler {
 			RetryAfter: retryAfter,
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
+		SetDeniedCacheHeaders(w.Header())
+		w.WriteHeader(deniedStatus(statusCode, statusForReason, result))
 		_ = json.NewEncoder(w).Encode(body)
 	}
 }
 
+// JSONDeniedHandler returns a DeniedHandler that responds with statusCode,
+// 429 if zero, and the Result encoded by goratelimit.Result.MarshalJSON,
+// e.g. {"allowed":false,"limit":100,"remaining":0,"reset":1700000000,
+// "retryAfter":12,"reason":"over_limit"}, plus scope naming the binding
+// link of a chain. Like the default handler it sets Cache-Control: no-store.
+// Set Config.ResultBody to use it as the default with Config.StatusCode.
+func JSONDeniedHandler(statusCode int) DeniedHandler {
+	return jsonDeniedHandler(statusCode, nil)
+}
+
+func jsonDeniedHandler(statusCode int, statusForReason map[goratelimit.DenyReason]int) DeniedHandler {
+	if statusCode == 0 {
+		statusCode = http.StatusTooManyRequests
+	}
+	return func(w http.ResponseWriter, _ *http.Request, result *goratelimit.Result) {
+		w.Header().Set("Content-Type", "application/json")
+		SetDeniedCacheHeaders(w.Header())
+		w.WriteHeader(deniedStatus(statusCode, statusForReason, result))
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// deniedStatus picks the status for result from statusForReason, falling
+// back to statusCode.
+func deniedStatus(statusCode int, statusForReason map[goratelimit.DenyReason]int, result *goratelimit.Result) int {
+	if status, ok := statusForReason[result.DenyReason]; ok && status != 0 {
+		return status
+	}
+	return statusCode
+}
+
 type deniedBody struct {
 	Error      string `json:"error"`
 	Limit      int64  `json:"limit"`
@@ -3,11 +3,14 @@ package middleware_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -397,3 +400,934 @@ func mustLimiter(l goratelimit.Limiter, err error) goratelimit.Limiter {
 	}
 	return l
 }
+
+func TestRateLimit_DocumentationURL_LinkHeader(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:          limiter,
+		KeyFunc:          middleware.KeyByIP,
+		DocumentationURL: "https://docs.example/rate-limits",
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.6:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Link"), "Link header should only be set on denial")
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.6:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, `<https://docs.example/rate-limits>; rel="help"`, rr.Header().Get("Link"))
+}
+
+func TestRateLimit_DocumentationURL_UnsetOmitsLink(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler())
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "6.6.6.7:1111"
+		handler.ServeHTTP(rr, req)
+		if i == 1 {
+			require.Equal(t, http.StatusTooManyRequests, rr.Code)
+			assert.Empty(t, rr.Header().Get("Link"))
+		}
+	}
+}
+
+func TestRateLimit_BackoffHeader(t *testing.T) {
+	clock := goratelimit.NewFakeClock()
+	inner, err := goratelimit.NewTokenBucket(1, 1, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	pb := goratelimit.NewPenaltyBox(goratelimit.PenaltyConfig{Clock: clock})
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:    pb.Wrap(inner),
+		KeyFunc:    middleware.KeyByIP,
+		PenaltyBox: pb,
+	})(okHandler())
+	serve := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "6.6.6.8:1111"
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		rr := serve()
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-RateLimit-Backoff"), "backoff header should only be set on denial")
+
+		rr = serve()
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, fmt.Sprintf("base=1; max=32; attempt=%d", attempt), rr.Header().Get("X-RateLimit-Backoff"))
+
+		retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		clock.Advance(time.Duration(retryAfter) * time.Second)
+	}
+}
+
+func TestRateLimit_DynamicLimitHeaders(t *testing.T) {
+	plans := map[string]int64{"free": 2, "pro": 5}
+	limiter, err := goratelimit.NewFixedWindow(1, 60, goratelimit.WithLimitFunc(
+		func(_ context.Context, key string) int64 { return plans[key] }))
+	require.NoError(t, err)
+	handler := middleware.RateLimit(limiter, middleware.KeyByHeader("X-API-Key"))(okHandler())
+
+	for _, plan := range []string{"free", "pro", "other"} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", plan)
+		handler.ServeHTTP(rr, req)
+
+		want := plans[plan]
+		if want == 0 {
+			want = 1 // construction-time default
+		}
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, strconv.FormatInt(want, 10), rr.Header().Get("X-RateLimit-Limit"), plan)
+		assert.Equal(t, "dynamic", rr.Header().Get("X-RateLimit-Limit-Policy"), plan)
+	}
+}
+
+func TestRateLimit_StaticLimitOmitsPolicyHeader(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(5, 60)
+	require.NoError(t, err)
+	handler := middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "5", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit-Policy"))
+}
+
+func TestRateLimit_ExposeAlgorithm(t *testing.T) {
+	limiter, err := goratelimit.NewTokenBucket(5, 1)
+	require.NoError(t, err)
+
+	expose := true
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:         limiter,
+		KeyFunc:         middleware.KeyByIP,
+		ExposeAlgorithm: &expose,
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "7.7.7.7:1111"
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "token_bucket", rr.Header().Get("X-RateLimit-Algorithm"))
+}
+
+func TestRateLimit_ExposeAlgorithm_DefaultOff(t *testing.T) {
+	limiter, err := goratelimit.NewTokenBucket(5, 1)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "7.7.7.8:1111"
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Algorithm"))
+}
+
+// plainLimiter implements goratelimit.Limiter without goratelimit.Describer.
+type plainLimiter struct{}
+
+func (plainLimiter) Allow(context.Context, string) (goratelimit.Result, error) {
+	return goratelimit.Result{Allowed: true, Limit: 1, Remaining: 1}, nil
+}
+
+func (plainLimiter) AllowN(context.Context, string, int) (goratelimit.Result, error) {
+	return goratelimit.Result{Allowed: true, Limit: 1, Remaining: 1}, nil
+}
+
+func (plainLimiter) Reset(context.Context, string) error { return nil }
+
+func TestRateLimit_ExposeAlgorithm_NoDescriber(t *testing.T) {
+	expose := true
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:         plainLimiter{},
+		KeyFunc:         middleware.KeyByIP,
+		ExposeAlgorithm: &expose,
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "7.7.7.9:1111"
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Algorithm"), "header should be omitted without Describer")
+}
+
+func TestRateLimit_EmptyKeyPolicy(t *testing.T) {
+	newHandler := func(policy middleware.EmptyKeyPolicy) http.Handler {
+		limiter, err := goratelimit.NewFixedWindow(1, 60)
+		require.NoError(t, err)
+		return middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:          limiter,
+			KeyFunc:          middleware.KeyByHeader("X-API-Key"),
+			EmptyKeyPolicy:   policy,
+			EmptyKeyFallback: middleware.KeyByIP,
+		})(okHandler())
+	}
+	serve := func(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("shared by default", func(t *testing.T) {
+		h := newHandler(middleware.EmptyKeyShared)
+		assert.Equal(t, http.StatusOK, serve(h, "8.8.8.1:1").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(h, "8.8.8.2:1").Code,
+			"different clients with empty keys share one bucket")
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		h := newHandler(middleware.EmptyKeyDeny)
+		rr := serve(h, "8.8.8.1:1")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"), "limiter should not be consulted")
+	})
+
+	t.Run("deny maps through StatusForReason", func(t *testing.T) {
+		limiter, err := goratelimit.NewFixedWindow(1, 60)
+		require.NoError(t, err)
+		h := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:         limiter,
+			KeyFunc:         middleware.KeyByHeader("X-API-Key"),
+			EmptyKeyPolicy:  middleware.EmptyKeyDeny,
+			StatusForReason: map[goratelimit.DenyReason]int{goratelimit.ReasonMissingKey: http.StatusUnauthorized},
+		})(okHandler())
+		assert.Equal(t, http.StatusUnauthorized, serve(h, "8.8.8.1:1").Code)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		h := newHandler(middleware.EmptyKeyAllow)
+		for i := 0; i < 3; i++ {
+			rr := serve(h, "8.8.8.1:1")
+			assert.Equal(t, http.StatusOK, rr.Code, "request %d", i+1)
+			assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		h := newHandler(middleware.EmptyKeyFallback)
+		assert.Equal(t, http.StatusOK, serve(h, "8.8.8.1:1").Code)
+		assert.Equal(t, http.StatusOK, serve(h, "8.8.8.2:1").Code,
+			"fallback key separates clients")
+		assert.Equal(t, http.StatusTooManyRequests, serve(h, "8.8.8.1:1").Code)
+	})
+}
+
+func TestRateLimit_EmptyKeyFallback_Required(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+	assert.Panics(t, func() {
+		middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:        limiter,
+			KeyFunc:        middleware.KeyByIP,
+			EmptyKeyPolicy: middleware.EmptyKeyFallback,
+		})
+	})
+}
+
+func TestRateLimit_DeniedResponseNotCacheable(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Cache-Control"), "allowed responses keep the handler's caching")
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "*", rr.Header().Get("Vary"))
+}
+
+func TestRateLimit_CustomDeniedHandler_OverridesCacheHeaders(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: limiter,
+		KeyFunc: middleware.KeyByIP,
+		DeniedHandler: func(w http.ResponseWriter, _ *http.Request, _ *goratelimit.Result) {
+			w.Header().Set("Cache-Control", "private, max-age=1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+	})(okHandler())
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "6.6.6.9:1111"
+		handler.ServeHTTP(rr, req)
+		if i == 1 {
+			require.Equal(t, http.StatusTooManyRequests, rr.Code)
+			assert.Equal(t, "private, max-age=1", rr.Header().Get("Cache-Control"))
+			assert.Empty(t, rr.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestRateLimit_StackedSameLimiterChargesOnce(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(3, 60)
+	require.NoError(t, err)
+
+	global := middleware.RateLimit(limiter, middleware.KeyByIP)
+	perRoute := middleware.RateLimit(limiter, middleware.KeyByIP)
+	handler := global(perRoute(okHandler()))
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "7.7.7.7:1111"
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "request %d should consume one token, not two", i+1)
+		assert.Equal(t, strconv.Itoa(3-i-1), rr.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "7.7.7.7:1111"
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+}
+
+func TestRateLimit_StackedDifferentKeysChargeEach(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(4, 60)
+	require.NoError(t, err)
+
+	byIP := middleware.RateLimit(limiter, middleware.KeyByIP)
+	byPath := middleware.RateLimit(limiter, middleware.KeyByPathAndIP)
+	handler := byIP(byPath(okHandler()))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/a", nil)
+	req.RemoteAddr = "7.7.7.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	res, err := limiter.Allow(context.Background(), "7.7.7.8")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Remaining, "IP key charged once by the outer middleware")
+	res, err = limiter.Allow(context.Background(), "/a:7.7.7.8")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Remaining, "path key charged once by the inner middleware")
+}
+
+func TestRateLimit_SeparateRequestsChargedSeparately(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(10, 60)
+	require.NoError(t, err)
+	handler := middleware.RateLimit(limiter, middleware.KeyByIP)(middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler()))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "7.7.7.9:1111"
+		handler.ServeHTTP(rr, req)
+	}
+	res, err := limiter.Allow(context.Background(), "7.7.7.9")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), res.Remaining, "each request charges once")
+}
+
+func TestRateLimit_ComponentHeaders(t *testing.T) {
+	perIP, err := goratelimit.NewFixedWindow(10, 60)
+	require.NoError(t, err)
+	perUser, err := goratelimit.NewFixedWindow(3, 60)
+	require.NoError(t, err)
+	limiter := goratelimit.NewChain(
+		goratelimit.ChainLink{ID: "ip", Limiter: perIP},
+		goratelimit.ChainLink{ID: "user", Limiter: perUser, Key: func(_ context.Context, _ string) string { return "alice" }},
+	)
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:          limiter,
+		KeyFunc:          middleware.KeyByIP,
+		ComponentHeaders: true,
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "8.8.8.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	assert.Equal(t, "10", rr.Header().Get("X-RateLimit-Limit-ip"))
+	assert.Equal(t, "9", rr.Header().Get("X-RateLimit-Remaining-ip"))
+	assert.Equal(t, "3", rr.Header().Get("X-RateLimit-Limit-user"))
+	assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Remaining-user"))
+	assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Remaining"), "combined Remaining is the minimum")
+	assert.Equal(t, "3", rr.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestRateLimit_ComponentHeaders_DefaultOff(t *testing.T) {
+	perIP, err := goratelimit.NewFixedWindow(10, 60)
+	require.NoError(t, err)
+	limiter := goratelimit.NewChain(goratelimit.ChainLink{ID: "ip", Limiter: perIP})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "8.8.8.9:1111"
+	middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler()).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit-ip"))
+}
+
+func TestRateLimit_KeyInContext(t *testing.T) {
+	limiter := mustLimiter(goratelimit.NewFixedWindow(5, 60))
+	var got string
+	var ok bool
+	handler := middleware.RateLimit(limiter, middleware.KeyByPathAndIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = middleware.KeyFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.RemoteAddr = "7.7.7.7:1111"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.True(t, ok)
+	assert.Equal(t, "/api:7.7.7.7", got)
+
+	_, ok = middleware.KeyFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestRateLimit_SurrogateControl(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter: limiter,
+		KeyFunc: middleware.KeyByIP,
+		SurrogateControl: func(result *goratelimit.Result) string {
+			return fmt.Sprintf("max-age=%d", int64(result.RetryAfter.Seconds()+0.5))
+		},
+	})(okHandler())
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Surrogate-Control"), "Surrogate-Control should only be set on denial")
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "6.6.6.8:1111"
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "max-age=60", rr.Header().Get("Surrogate-Control"))
+	assert.Equal(t, middleware.DeniedCacheControl, rr.Header().Get("Cache-Control"), "browsers still must not cache")
+}
+
+func TestRateLimit_SurrogateControl_EmptyOmitsHeader(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:          limiter,
+		KeyFunc:          middleware.KeyByIP,
+		SurrogateControl: func(*goratelimit.Result) string { return "" },
+	})(okHandler())
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "6.6.6.9:1111"
+		handler.ServeHTTP(rr, req)
+		if i == 1 {
+			require.Equal(t, http.StatusTooManyRequests, rr.Code)
+			_, ok := rr.Header()["Surrogate-Control"]
+			assert.False(t, ok)
+		}
+	}
+}
+
+func TestRateLimit_HeadersAcrossAlgorithms(t *testing.T) {
+	now := time.Unix(1_700_000_040, 0) // on a minute boundary
+	algorithms := []struct {
+		name  string
+		build func(clock goratelimit.Clock) (goratelimit.Limiter, error)
+		// resets are X-RateLimit-Reset, in seconds after now, for three
+		// allowed requests and then the denied one.
+		resets     []int64
+		retryAfter string
+	}{
+		{"FixedWindow", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(3, 60, goratelimit.WithClock(c))
+		}, []int64{60, 60, 60, 60}, "60"},
+		{"SlidingWindow", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindow(3, 60, goratelimit.WithClock(c))
+		}, []int64{60, 60, 60, 60}, "60"},
+		{"SlidingWindowCounter", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindowCounter(3, 60, goratelimit.WithClock(c))
+		}, []int64{120, 120, 120, 60}, "60"},
+		{"TokenBucket", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(3, 1, goratelimit.WithClock(c))
+		}, []int64{1, 2, 3, 1}, "1"},
+		{"LeakyBucket", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(3, 1, goratelimit.Policing, goratelimit.WithClock(c))
+		}, []int64{1, 2, 3, 1}, "1"},
+		{"GCRA", func(c goratelimit.Clock) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 3, goratelimit.WithClock(c))
+		}, []int64{1, 2, 3, 1}, "1"},
+	}
+
+	for _, alg := range algorithms {
+		t.Run(alg.name, func(t *testing.T) {
+			limiter := mustLimiter(alg.build(goratelimit.NewFakeClockAt(now)))
+			handler := middleware.RateLimit(limiter, middleware.KeyByIP)(okHandler())
+
+			for i, reset := range alg.resets {
+				rr := httptest.NewRecorder()
+				req := httptest.NewRequest("GET", "/", nil)
+				req.RemoteAddr = "9.9.9.9:1111"
+				handler.ServeHTTP(rr, req)
+
+				h := rr.Header()
+				assert.Equal(t, "3", h.Get("X-RateLimit-Limit"), "request %d", i+1)
+				assert.Equal(t, strconv.FormatInt(now.Unix()+reset, 10), h.Get("X-RateLimit-Reset"), "request %d", i+1)
+				if i < 3 {
+					require.Equal(t, http.StatusOK, rr.Code, "request %d should be allowed", i+1)
+					assert.Equal(t, strconv.Itoa(2-i), h.Get("X-RateLimit-Remaining"))
+					assert.Empty(t, h.Get("Retry-After"))
+					continue
+				}
+				require.Equal(t, http.StatusTooManyRequests, rr.Code, "request %d should be denied", i+1)
+				assert.Equal(t, "0", h.Get("X-RateLimit-Remaining"))
+				assert.Equal(t, alg.retryAfter, h.Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRateLimit_ShapingDelay(t *testing.T) {
+	// Shaping at 10/s spaces admitted requests 100ms apart.
+	shaping := func() goratelimit.Limiter {
+		return mustLimiter(goratelimit.NewLeakyBucket(5, 10, goratelimit.Shaping))
+	}
+	serve := func(handler http.Handler, ctx context.Context) (*httptest.ResponseRecorder, time.Duration) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		req.RemoteAddr = "9.9.9.9:1111"
+		start := time.Now()
+		handler.ServeHTTP(rr, req)
+		return rr, time.Since(start)
+	}
+
+	t.Run("advertised by default", func(t *testing.T) {
+		handler := middleware.RateLimit(shaping(), middleware.KeyByIP)(okHandler())
+
+		rr, _ := serve(handler, context.Background())
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-RateLimit-Delay"), "the first request is not delayed")
+
+		rr, elapsed := serve(handler, context.Background())
+		assert.Equal(t, http.StatusOK, rr.Code)
+		delay, err := strconv.Atoi(rr.Header().Get("X-RateLimit-Delay"))
+		require.NoError(t, err)
+		assert.InDelta(t, 100, delay, 5)
+		assert.Less(t, elapsed, 50*time.Millisecond, "the server does not wait")
+	})
+
+	t.Run("applied server-side", func(t *testing.T) {
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:           shaping(),
+			KeyFunc:           middleware.KeyByIP,
+			ApplyShapingDelay: true,
+		})(okHandler())
+
+		serve(handler, context.Background())
+		rr, elapsed := serve(handler, context.Background())
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("X-RateLimit-Delay"))
+		assert.GreaterOrEqual(t, elapsed, 80*time.Millisecond)
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		var handlerErr error
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:           shaping(),
+			KeyFunc:           middleware.KeyByIP,
+			ApplyShapingDelay: true,
+			ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+				handlerErr = err
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		})(okHandler())
+
+		serve(handler, context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		rr, elapsed := serve(handler, ctx)
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		assert.ErrorIs(t, handlerErr, context.DeadlineExceeded)
+		assert.Less(t, elapsed, 80*time.Millisecond)
+	})
+}
+
+func TestJSONDeniedHandler_BodyMatchesResult(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		statusCode int
+		want       int
+	}{
+		{"default", 0, http.StatusTooManyRequests},
+		{"configured", http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got goratelimit.Result
+			jsonHandler := middleware.JSONDeniedHandler(tc.statusCode)
+			handler := middleware.RateLimitWithConfig(middleware.Config{
+				Limiter: limiter,
+				KeyFunc: middleware.KeyByIP,
+				DeniedHandler: func(w http.ResponseWriter, r *http.Request, result *goratelimit.Result) {
+					got = *result
+					jsonHandler(w, r, result)
+				},
+			})(okHandler())
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "100.0.0.2:1111"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.want, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			var body struct {
+				Allowed    bool   `json:"allowed"`
+				Limit      int64  `json:"limit"`
+				Remaining  int64  `json:"remaining"`
+				Reset      int64  `json:"reset"`
+				RetryAfter int64  `json:"retryAfter"`
+				Reason     string `json:"reason"`
+			}
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+			assert.False(t, body.Allowed)
+			assert.Equal(t, got.Limit, body.Limit)
+			assert.Equal(t, got.Remaining, body.Remaining)
+			assert.Equal(t, got.ResetAt.Unix(), body.Reset)
+			assert.Equal(t, int64(got.RetryAfter.Seconds()+0.5), body.RetryAfter)
+			assert.Positive(t, body.RetryAfter)
+			assert.Equal(t, "over_limit", body.Reason)
+			require.NoError(t, limiter.Reset(context.Background(), "100.0.0.2"))
+		})
+	}
+}
+
+func TestRateLimit_ResultBody(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:    limiter,
+		KeyFunc:    middleware.KeyByIP,
+		ResultBody: true,
+		StatusCode: http.StatusServiceUnavailable,
+	})(okHandler())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "100.0.0.3:1111"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.Equal(t, float64(1), body["limit"])
+	assert.Equal(t, float64(0), body["remaining"])
+	assert.Contains(t, body, "retryAfter")
+	assert.NotContains(t, body, "error", "ResultBody replaces the default body")
+}
+
+func TestRateLimit_ChargeOnStatus(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(2, 60)
+	require.NoError(t, err)
+
+	status := http.StatusNotModified
+	handler := middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:        limiter,
+		KeyFunc:        middleware.KeyByIP,
+		ChargeOnStatus: middleware.IsSuccessStatus,
+	})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func() int {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "100.0.0.4:1111"
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Non-success responses are refunded and never use up the quota.
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusNotModified, serve(), "request %d", i+1)
+	}
+	status = http.StatusUnauthorized
+	require.Equal(t, http.StatusUnauthorized, serve())
+
+	// Success responses are charged.
+	status = http.StatusOK
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve())
+}
+
+func TestRateLimit_ChargeOnStatus_ReservesWhileHandlerRuns(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+
+	var inner int
+	var handler http.Handler
+	handler = middleware.RateLimitWithConfig(middleware.Config{
+		Limiter:        limiter,
+		KeyFunc:        middleware.KeyByIP,
+		ChargeOnStatus: middleware.IsSuccessStatus,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A second request arriving while the first is in flight is
+		// denied: the first holds the only unit.
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = r.RemoteAddr
+		if inner++; inner == 1 {
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "100.0.0.5:1111"
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestRateLimit_ChargeOnStatus_SkipsUnchargedRequests(t *testing.T) {
+	newHandler := func(limiter goratelimit.Limiter, status *int) http.Handler {
+		return middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:        limiter,
+			KeyFunc:        middleware.KeyByIP,
+			ChargeOnStatus: middleware.IsSuccessStatus,
+		})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(*status)
+		}))
+	}
+	serve := func(handler http.Handler) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "100.0.0.6:1111"
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("ForceAllow", func(t *testing.T) {
+		limiter, err := goratelimit.NewFixedWindow(2, 60)
+		require.NoError(t, err)
+		status := http.StatusOK
+		handler := newHandler(limiter, &status)
+		require.Equal(t, http.StatusOK, serve(handler).Code)
+		require.Equal(t, http.StatusOK, serve(handler).Code)
+
+		goratelimit.SetMode(goratelimit.ForceAllow)
+		t.Cleanup(func() { goratelimit.SetMode(goratelimit.Normal) })
+		status = http.StatusUnauthorized
+		for i := 0; i < 5; i++ {
+			require.Equal(t, http.StatusUnauthorized, serve(handler).Code)
+		}
+		goratelimit.SetMode(goratelimit.Normal)
+
+		status = http.StatusOK
+		assert.Equal(t, http.StatusTooManyRequests, serve(handler).Code,
+			"requests admitted by ForceAllow took nothing to refund")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		var wouldDeny int
+		limiter, err := goratelimit.NewFixedWindow(2, 60, goratelimit.WithDryRun(true),
+			goratelimit.WithDryRunLogFunc(func(string, *goratelimit.Result) { wouldDeny++ }))
+		require.NoError(t, err)
+		status := http.StatusOK
+		handler := newHandler(limiter, &status)
+		require.Equal(t, http.StatusOK, serve(handler).Code)
+		require.Equal(t, http.StatusOK, serve(handler).Code)
+
+		status = http.StatusUnauthorized
+		for i := 0; i < 5; i++ {
+			require.Equal(t, http.StatusUnauthorized, serve(handler).Code)
+		}
+		require.Equal(t, 5, wouldDeny)
+
+		status = http.StatusOK
+		assert.Equal(t, http.StatusOK, serve(handler).Code)
+		assert.Equal(t, 6, wouldDeny, "requests the limiter denied took nothing to refund")
+	})
+}
+
+func TestRateLimit_ChargeOnStatus_RequiresRefunder(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	build := func(limiter goratelimit.Limiter) {
+		middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:        limiter,
+			KeyFunc:        middleware.KeyByIP,
+			ChargeOnStatus: middleware.IsSuccessStatus,
+		})
+	}
+
+	assert.Panics(t, func() { build(mustLimiter(goratelimit.NewLeakyBucket(5, 1, goratelimit.Policing))) })
+	assert.Panics(t, func() {
+		build(mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithRedis(client), goratelimit.WithDryRun(true))))
+	}, "a wrapper forwarding Refund to a Redis limiter cannot refund")
+	assert.NotPanics(t, func() {
+		build(mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithDryRun(true))))
+	}, "a wrapped in-memory limiter can refund")
+}
+
+func TestRateLimit_DenyDelay(t *testing.T) {
+	serve := func(handler http.Handler, ctx context.Context) (*httptest.ResponseRecorder, time.Duration) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		req.RemoteAddr = "9.9.9.10:1111"
+		start := time.Now()
+		handler.ServeHTTP(rr, req)
+		return rr, time.Since(start)
+	}
+
+	t.Run("denied requests are held", func(t *testing.T) {
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:   mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+			KeyFunc:   middleware.KeyByIP,
+			DenyDelay: 100 * time.Millisecond,
+		})(okHandler())
+
+		rr, elapsed := serve(handler, context.Background())
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Less(t, elapsed, 50*time.Millisecond, "allowed requests are not held")
+
+		rr, elapsed = serve(handler, context.Background())
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+	})
+
+	t.Run("canceled while held", func(t *testing.T) {
+		var handlerErr error
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:   mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+			KeyFunc:   middleware.KeyByIP,
+			DenyDelay: time.Minute,
+			ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+				handlerErr = err
+				w.WriteHeader(499)
+			},
+		})(okHandler())
+
+		serve(handler, context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		rr, elapsed := serve(handler, ctx)
+		assert.Equal(t, 499, rr.Code)
+		assert.ErrorIs(t, handlerErr, context.DeadlineExceeded)
+		assert.Less(t, elapsed, time.Second)
+	})
+
+	t.Run("func overrides the fixed delay", func(t *testing.T) {
+		var gotKey string
+		handler := middleware.RateLimitWithConfig(middleware.Config{
+			Limiter:   mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+			KeyFunc:   middleware.KeyByIP,
+			DenyDelay: time.Minute,
+			DenyDelayFunc: func(key string, result *goratelimit.Result) time.Duration {
+				gotKey = key
+				assert.False(t, result.Allowed)
+				return 0
+			},
+		})(okHandler())
+
+		serve(handler, context.Background())
+		rr, elapsed := serve(handler, context.Background())
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Less(t, elapsed, 50*time.Millisecond)
+		assert.Equal(t, "9.9.9.10", gotKey)
+	})
+}
+
+func TestPenaltyDenyDelay(t *testing.T) {
+	ctx := context.Background()
+	clock := goratelimit.NewFakeClock()
+	pb := goratelimit.NewPenaltyBox(goratelimit.PenaltyConfig{Clock: clock})
+	limiter := pb.Wrap(mustLimiter(goratelimit.NewTokenBucket(1, 1, goratelimit.WithClock(clock))))
+	delay := middleware.PenaltyDenyDelay(pb, 100*time.Millisecond, 300*time.Millisecond)
+
+	assert.Equal(t, 100*time.Millisecond, delay("k", nil), "unpenalized keys get base")
+	for _, want := range []time.Duration{100, 200, 300, 300} {
+		_, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		result, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+		assert.Equal(t, want*time.Millisecond, delay("k", &result))
+		clock.Advance(result.RetryAfter)
+	}
+}
+
+func TestRateLimit_Balance(t *testing.T) {
+	ctx := context.Background()
+	balances, err := goratelimit.NewBalance()
+	require.NoError(t, err)
+	handler := middleware.RateLimit(balances, middleware.KeyByIP)(okHandler())
+	serve := func() int {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "9.9.9.11:1111"
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	_, err = balances.AddBalance(ctx, "9.9.9.11", 2)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve(), "balance exhausted")
+
+	_, err = balances.AddBalance(ctx, "9.9.9.11", 1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(), "served again after a top-up")
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// Cache headers set on denied responses by the default DeniedHandlers of the
+// net/http, Gin, Echo, and Fiber middleware. A 429 is specific to the
+// client's rate limit key, so a shared cache (CDN, reverse proxy) must never
+// store it or serve it to another client. Vary is "*" because the key is
+// usually derived from something other than request headers, such as the
+// client IP.
+const (
+	DeniedCacheControl = "no-store"
+	DeniedVary         = "*"
+)
+
+// SetDeniedCacheHeaders sets DeniedCacheControl and DeniedVary on h. Custom
+// DeniedHandlers can call it to keep the default caching behavior; handlers
+// that don't call it are free to set their own.
+func SetDeniedCacheHeaders(h http.Header) {
+	h.Set("Cache-Control", DeniedCacheControl)
+	h.Set("Vary", DeniedVary)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// KeyByHeaderWithMap creates HTTP middleware that selects a limiter by the
+// value of header, e.g. a country code injected by a CDN, and applies it per
+// client IP. Requests whose header value has no entry in limiters, including
+// requests without the header, use defaultLimiter; pass nil to skip rate
+// limiting for them.
+//
+//	mw := middleware.KeyByHeaderWithMap("CF-IPCountry", map[string]goratelimit.Limiter{
+//		"DE": eu, "FR": eu, "US": us,
+//	}, fallback)
+//
+// Header values are matched verbatim. Limiters shared between entries, like
+// eu above, share one budget per client. For a different key or the full
+// Config, use RateLimitByVersion with VersionByHeader(header).
+func KeyByHeaderWithMap(header string, limiters map[string]goratelimit.Limiter, defaultLimiter goratelimit.Limiter) func(http.Handler) http.Handler {
+	return RateLimitByVersion(VersionConfig{
+		Config:   Config{Limiter: defaultLimiter, KeyFunc: KeyByIP},
+		Version:  VersionByHeader(header),
+		Limiters: limiters,
+	})
+}
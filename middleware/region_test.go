@@ -0,0 +1,64 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+func TestKeyByHeaderWithMap_RoutesByCountry(t *testing.T) {
+	eu := mustLimiter(goratelimit.NewFixedWindow(2, 60))
+	us := mustLimiter(goratelimit.NewFixedWindow(4, 60))
+	fallback := mustLimiter(goratelimit.NewFixedWindow(1, 60))
+	handler := middleware.KeyByHeaderWithMap("CF-IPCountry", map[string]goratelimit.Limiter{
+		"DE": eu, "FR": eu, "US": us,
+	}, fallback)(okHandler())
+
+	allowed := func(country string, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			if country != "" {
+				req.Header.Set("CF-IPCountry", country)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code == http.StatusOK {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Equal(t, 4, allowed("US", 6))
+	assert.Equal(t, 1, allowed("DE", 1))
+	assert.Equal(t, 1, allowed("FR", 3), "DE and FR share the EU limiter")
+	assert.Equal(t, 1, allowed("BR", 3), "unknown regions use the default limiter")
+	assert.Equal(t, 0, allowed("", 1), "a missing header shares the default budget")
+}
+
+func TestKeyByHeaderWithMap_PerClientWithinRegion(t *testing.T) {
+	handler := middleware.KeyByHeaderWithMap("CF-IPCountry", map[string]goratelimit.Limiter{
+		"US": mustLimiter(goratelimit.NewFixedWindow(1, 60)),
+	}, nil)(okHandler())
+
+	serve := func(ip, country string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("CF-IPCountry", country)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("1.1.1.1", "US").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve("1.1.1.1", "US").Code)
+	assert.Equal(t, http.StatusOK, serve("2.2.2.2", "US").Code, "each client has its own budget")
+	assert.Equal(t, http.StatusOK, serve("1.1.1.1", "JP").Code, "nil default skips limiting")
+	assert.Equal(t, http.StatusOK, serve("1.1.1.1", "JP").Code)
+}
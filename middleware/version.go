@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// VersionFunc extracts the API version from a request, e.g. "v1".
+// It returns "" when the request carries no recognizable version.
+type VersionFunc func(r *http.Request) string
+
+// VersionConfig configures RateLimitByVersion.
+type VersionConfig struct {
+	// Config holds the settings shared by every version. Its Limiter is used
+	// for requests whose version has no entry in Limiters; leave it nil to
+	// skip rate limiting for unknown versions.
+	Config
+
+	// Version extracts the API version from the request.
+	// Default: VersionByPathPrefix.
+	Version VersionFunc
+
+	// Limiters maps a version, as returned by Version, to its limiter.
+	// Each limiter keeps its own budget, e.g. a tighter one for "v1".
+	Limiters map[string]goratelimit.Limiter
+}
+
+// RateLimitByVersion creates HTTP middleware that selects a limiter by API
+// version, so legacy and current clients can get different quotas.
+//
+//	mux.Handle("/", middleware.RateLimitByVersion(middleware.VersionConfig{
+//		Config:   middleware.Config{Limiter: defaultLimiter, KeyFunc: middleware.KeyByAPIKey},
+//		Limiters: map[string]goratelimit.Limiter{"v1": legacy, "v2": current},
+//	})(handler))
+//
+// Every version shares the rest of cfg.Config (KeyFunc, headers, handlers).
+func RateLimitByVersion(cfg VersionConfig) func(http.Handler) http.Handler {
+	if cfg.KeyFunc == nil {
+		panic("goratelimit/middleware: KeyFunc is required")
+	}
+	for version, limiter := range cfg.Limiters {
+		if limiter == nil {
+			panic("goratelimit/middleware: nil Limiter for version " + version)
+		}
+	}
+	if cfg.Version == nil {
+		cfg.Version = VersionByPathPrefix
+	}
+
+	return func(next http.Handler) http.Handler {
+		handlers := make(map[string]http.Handler, len(cfg.Limiters))
+		for version, limiter := range cfg.Limiters {
+			c := cfg.Config
+			c.Limiter = limiter
+			handlers[version] = RateLimitWithConfig(c)(next)
+		}
+		fallback := next
+		if cfg.Limiter != nil {
+			fallback = RateLimitWithConfig(cfg.Config)(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h, ok := handlers[cfg.Version(r)]; ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			fallback.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ─── Built-in Version Extractors ─────────────────────────────────────────────
+
+// VersionByPathPrefix returns the first path segment when it looks like a
+// version, e.g. "v2" for "/v2/users". Otherwise it returns "".
+func VersionByPathPrefix(r *http.Request) string {
+	segment := strings.TrimPrefix(r.URL.Path, "/")
+	if i := strings.IndexByte(segment, '/'); i >= 0 {
+		segment = segment[:i]
+	}
+	if isVersion(segment) {
+		return segment
+	}
+	return ""
+}
+
+// VersionByAccept reads the version from the Accept header, either from a
+// version parameter ("application/json; version=2") or a vendor media type
+// suffix ("application/vnd.example.v2+json"). It returns e.g. "v2", or ""
+// when neither is present.
+func VersionByAccept(r *http.Request) string {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if v := params["version"]; v != "" {
+			if !strings.HasPrefix(v, "v") {
+				v = "v" + v
+			}
+			if isVersion(v) {
+				return v
+			}
+		}
+		if i := strings.IndexByte(mediaType, '+'); i >= 0 {
+			mediaType = mediaType[:i]
+		}
+		if i := strings.LastIndexByte(mediaType, '.'); i >= 0 && isVersion(mediaType[i+1:]) {
+			return mediaType[i+1:]
+		}
+	}
+	return ""
+}
+
+// VersionByHeader returns a VersionFunc that uses the given header's value
+// verbatim, e.g. "X-API-Version".
+func VersionByHeader(header string) VersionFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(header)
+	}
+}
+
+// isVersion reports whether s has the form "v" followed by digits.
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
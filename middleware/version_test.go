@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+func TestRateLimitByVersion_IndependentBudgets(t *testing.T) {
+	v1 := mustLimiter(goratelimit.NewFixedWindow(1, 60))
+	v2 := mustLimiter(goratelimit.NewFixedWindow(3, 60))
+	fallback := mustLimiter(goratelimit.NewFixedWindow(2, 60))
+	handler := middleware.RateLimitByVersion(middleware.VersionConfig{
+		Config:   middleware.Config{Limiter: fallback, KeyFunc: middleware.KeyByIP},
+		Limiters: map[string]goratelimit.Limiter{"v1": v1, "v2": v2},
+	})(okHandler())
+
+	allowed := func(path string, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+			if rec.Code == http.StatusOK {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Equal(t, 1, allowed("/v1/users", 5), "v1 gets its own, smaller budget")
+	assert.Equal(t, 3, allowed("/v2/users", 5), "v2 is unaffected by v1 usage")
+	assert.Equal(t, 2, allowed("/v3/users", 5), "unknown versions use the default limiter")
+	assert.Equal(t, 0, allowed("/health", 1), "unversioned paths share the default budget")
+}
+
+func TestRateLimitByVersion_HeadersReflectVersionLimiter(t *testing.T) {
+	handler := middleware.RateLimitByVersion(middleware.VersionConfig{
+		Config:   middleware.Config{KeyFunc: middleware.KeyByIP},
+		Version:  middleware.VersionByAccept,
+		Limiters: map[string]goratelimit.Limiter{"v1": mustLimiter(goratelimit.NewFixedWindow(5, 60))},
+	})(okHandler())
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Accept", "application/vnd.example.v1+json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("X-RateLimit-Limit"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"), "nil default should skip limiting")
+}
+
+func TestVersionExtractors(t *testing.T) {
+	req := func(path, accept string) *http.Request {
+		r := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		return r
+	}
+
+	assert.Equal(t, "v1", middleware.VersionByPathPrefix(req("/v1/users", "")))
+	assert.Equal(t, "v12", middleware.VersionByPathPrefix(req("/v12", "")))
+	assert.Equal(t, "", middleware.VersionByPathPrefix(req("/users/v1", "")))
+	assert.Equal(t, "", middleware.VersionByPathPrefix(req("/video/1", "")))
+
+	assert.Equal(t, "v2", middleware.VersionByAccept(req("/", "application/vnd.example.v2+json")))
+	assert.Equal(t, "v3", middleware.VersionByAccept(req("/", "application/json; version=3")))
+	assert.Equal(t, "v1", middleware.VersionByAccept(req("/", "text/html, application/json;version=v1")))
+	assert.Equal(t, "", middleware.VersionByAccept(req("/", "application/json")))
+
+	r := req("/", "")
+	r.Header.Set("X-API-Version", "2024-01")
+	assert.Equal(t, "2024-01", middleware.VersionByHeader("X-API-Version")(r))
+}
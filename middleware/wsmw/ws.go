@@ -0,0 +1,188 @@
+// Package wsmw rate limits WebSocket endpoints: the upgrade handshake as a
+// normal HTTP request, and then every message the client sends on the
+// connection, which per-request HTTP limits never see.
+//
+// Separated from the middleware package so that importing the HTTP middleware
+// does not pull in github.com/gorilla/websocket.
+//
+// Usage:
+//
+//	ws := wsmw.WSLimit(limiter, middleware.KeyByIP)
+//	mux.Handle("/ws", ws.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    c, err := upgrader.Upgrade(w, r, nil)
+//	    if err != nil {
+//	        return
+//	    }
+//	    conn := ws.Conn(r, c)
+//	    defer conn.Close()
+//	    for {
+//	        _, msg, err := conn.ReadMessage() // closed with 1008 once over the limit
+//	        if err != nil {
+//	            return
+//	        }
+//	        ...
+//	    }
+//	})))
+package wsmw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+)
+
+// ErrMessageLimit is returned by a Conn's reads once an inbound message was
+// denied and the connection closed.
+var ErrMessageLimit = errors.New("goratelimit/wsmw: message rate limit exceeded")
+
+// DefaultCloseText is the reason sent with the policy violation close frame.
+const DefaultCloseText = "rate limit exceeded"
+
+// closeTimeout bounds writing the close frame to a client that is not reading.
+const closeTimeout = time.Second
+
+// Config holds the WebSocket rate limit configuration.
+type Config struct {
+	// Limiter is charged one unit per upgrade handshake (required).
+	Limiter goratelimit.Limiter
+
+	// MessageLimiter is charged one unit per inbound message.
+	// Default: Limiter, so handshakes and messages share a budget.
+	MessageLimiter goratelimit.Limiter
+
+	// KeyFunc extracts the rate limit key from the upgrade request
+	// (required). Messages are charged to the same key.
+	KeyFunc middleware.KeyFunc
+
+	// Handshake configures the handshake middleware beyond Limiter and
+	// KeyFunc, which are taken from this Config.
+	// Default: the defaults of middleware.RateLimitWithConfig.
+	Handshake middleware.Config
+
+	// CloseText is the reason sent in the close frame when a message is
+	// denied.
+	// Default: DefaultCloseText.
+	CloseText string
+}
+
+// Limiter rate limits one WebSocket endpoint. Use Handler for the upgrade
+// request and Conn for the connection it upgrades to.
+type Limiter struct {
+	cfg       Config
+	handshake func(http.Handler) http.Handler
+}
+
+// WSLimit creates a Limiter charging limiter for handshakes and messages
+// alike, keyed by keyFunc.
+func WSLimit(limiter goratelimit.Limiter, keyFunc middleware.KeyFunc) *Limiter {
+	return WSLimitWithConfig(Config{
+		Limiter: limiter,
+		KeyFunc: keyFunc,
+	})
+}
+
+// WSLimitWithConfig creates a Limiter with full configuration control.
+func WSLimitWithConfig(cfg Config) *Limiter {
+	if cfg.Limiter == nil {
+		panic("goratelimit/wsmw: Limiter is required")
+	}
+	if cfg.KeyFunc == nil {
+		panic("goratelimit/wsmw: KeyFunc is required")
+	}
+	if cfg.MessageLimiter == nil {
+		cfg.MessageLimiter = cfg.Limiter
+	}
+	if cfg.CloseText == "" {
+		cfg.CloseText = DefaultCloseText
+	}
+	handshake := cfg.Handshake
+	handshake.Limiter = cfg.Limiter
+	handshake.KeyFunc = cfg.KeyFunc
+	return &Limiter{cfg: cfg, handshake: middleware.RateLimitWithConfig(handshake)}
+}
+
+// Handler rate limits the upgrade handshake like any HTTP request, answering
+// a denied one with 429 before it is upgraded.
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return l.handshake(next)
+}
+
+// Conn wraps c, upgraded from r, so that every message read from it is
+// charged to r's key. r's context is used for the charges, so use the Conn
+// only while the handler serving r runs.
+func (l *Limiter) Conn(r *http.Request, c *websocket.Conn) *Conn {
+	key, ok := middleware.KeyFromContext(r.Context())
+	if !ok {
+		key = l.cfg.KeyFunc(r)
+	}
+	return &Conn{Conn: c, ctx: r.Context(), key: key, cfg: &l.cfg}
+}
+
+// Conn is a *websocket.Conn whose reads are rate limited. NextReader,
+// ReadMessage and ReadJSON charge one unit per message before returning it.
+// When a message is denied, Conn sends a close frame with
+// websocket.ClosePolicyViolation (1008), closes the connection and returns
+// ErrMessageLimit. A limiter error is returned as is, leaving the connection
+// open.
+type Conn struct {
+	*websocket.Conn
+	ctx    context.Context
+	key    string
+	cfg    *Config
+	denied bool
+}
+
+// NextReader returns the next message, once it has been charged.
+func (c *Conn) NextReader() (messageType int, r io.Reader, err error) {
+	if c.denied {
+		return 0, nil, ErrMessageLimit
+	}
+	messageType, r, err = c.Conn.NextReader()
+	if err != nil {
+		return messageType, r, err
+	}
+	res, err := c.cfg.MessageLimiter.Allow(c.ctx, c.key)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !res.Allowed {
+		c.denied = true
+		msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, c.cfg.CloseText)
+		_ = c.Conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout))
+		_ = c.Conn.Close()
+		return 0, nil, ErrMessageLimit
+	}
+	return messageType, r, nil
+}
+
+// ReadMessage is websocket.Conn.ReadMessage, charging the message first.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	messageType, r, err := c.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	p, err = io.ReadAll(r)
+	return messageType, p, err
+}
+
+// ReadJSON is websocket.Conn.ReadJSON, charging the message first.
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, r, err := c.NextReader()
+	if err != nil {
+		return err
+	}
+	err = json.NewDecoder(r).Decode(v)
+	if err == io.EOF {
+		// One value is expected in the message.
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
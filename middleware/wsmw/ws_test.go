@@ -0,0 +1,128 @@
+package wsmw_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+	"github.com/krishna-kudari/ratelimit/middleware/wsmw"
+)
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// echoServer serves an echo endpoint behind ws and reports the error that
+// ended each connection's read loop on readErr.
+func echoServer(t *testing.T, ws *wsmw.Limiter) (url string, readErr <-chan error) {
+	t.Helper()
+	errs := make(chan error, 10)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(ws.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := ws.Conn(r, c)
+		defer conn.Close()
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := conn.WriteMessage(mt, msg); err != nil {
+				errs <- err
+				return
+			}
+		}
+	})))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), errs
+}
+
+func TestConn_ClosesFloodAfterQuota(t *testing.T) {
+	ws := wsmw.WSLimitWithConfig(wsmw.Config{
+		Limiter:        must(goratelimit.NewFixedWindow(10, 60)),
+		MessageLimiter: must(goratelimit.NewFixedWindow(5, 60)),
+		KeyFunc:        middleware.KeyByIP,
+	})
+	url, readErr := echoServer(t, ws)
+
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// Flood: the first 5 messages are echoed, the 6th closes the connection.
+	for i := 0; i < 20; i++ {
+		if err := c.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+			break
+		}
+	}
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
+	echoed := 0
+	for {
+		_, _, err = c.ReadMessage()
+		if err != nil {
+			break
+		}
+		echoed++
+	}
+	assert.Equal(t, 5, echoed)
+	var closeErr *websocket.CloseError
+	require.True(t, errors.As(err, &closeErr), "want a close frame, got %v", err)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, wsmw.DefaultCloseText, closeErr.Text)
+	assert.ErrorIs(t, <-readErr, wsmw.ErrMessageLimit)
+}
+
+func TestHandler_LimitsHandshake(t *testing.T) {
+	limiter := must(goratelimit.NewFixedWindow(100, 60))
+	ws := wsmw.WSLimitWithConfig(wsmw.Config{
+		Limiter:        must(goratelimit.NewFixedWindow(1, 60)),
+		MessageLimiter: limiter,
+		KeyFunc:        middleware.KeyByIP,
+	})
+	url, _ := echoServer(t, ws)
+
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestWSLimit_SharesBudget(t *testing.T) {
+	ws := wsmw.WSLimit(must(goratelimit.NewFixedWindow(3, 60)), middleware.KeyByIP)
+	url, _ := echoServer(t, ws)
+
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	// The handshake took one unit, leaving two messages.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte("hi")))
+	}
+	for i := 0; i < 2; i++ {
+		_, _, err := c.ReadMessage()
+		require.NoError(t, err, "message %d", i+1)
+	}
+	_, _, err = c.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+}
@@ -0,0 +1,219 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewMinInterval creates a limiter that admits at most one request per key
+// every interval: a request is allowed if at least interval has passed since
+// the key's last allowed request, and denied otherwise with RetryAfter set to
+// the exact time left, rather than rounded up to a second. It behaves like
+// GCRA with a burst of one, for spacing out webhooks and similar callers.
+//
+// interval must be at least a millisecond, the precision of the Redis
+// backend. AllowN with n > 1 is denied with ReasonCostTooLarge, and
+// WithLimitFunc and SetLimit do not apply. Pass WithRedis for distributed
+// mode; omit for in-memory.
+func NewMinInterval(interval time.Duration, opts ...Option) (Limiter, error) {
+	if interval < time.Millisecond {
+		return nil, validationErr("interval must be at least 1ms",
+			"Use a positive duration, e.g. NewMinInterval(5*time.Second).")
+	}
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewMinInterval", false, false); err != nil {
+		return nil, err
+	}
+
+	if o.RedisClient != nil {
+		return wrapOptions(&minIntervalRedis{
+			redis:    o.RedisClient,
+			interval: interval.Milliseconds(),
+			opts:     o,
+		}, o), nil
+	}
+	return wrapOptions(&minIntervalMemory{
+		last:     make(map[string]time.Time),
+		interval: interval,
+		opts:     o,
+	}, o), nil
+}
+
+// minIntervalResult describes a decision for a key last allowed at last.
+func minIntervalResult(allowed bool, last time.Time, interval, retryAfter time.Duration) Result {
+	return Result{
+		Allowed:    allowed,
+		DenyReason: denyReason(allowed),
+		Remaining:  0,
+		Limit:      1,
+		ResetAt:    last.Add(interval),
+		RetryAfter: retryAfter,
+	}
+}
+
+// ─── In-Memory ───────────────────────────────────────────────────────────────
+
+type minIntervalMemory struct {
+	mu       sync.Mutex
+	last     map[string]time.Time // last allowed request per key
+	interval time.Duration
+	opts     *Options
+}
+
+func (m *minIntervalMemory) Allow(ctx context.Context, key string) (Result, error) {
+	return m.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (m *minIntervalMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, 1); ok {
+		return res, nil
+	}
+	if res, ok := costTooLarge(n, 1, 0); ok {
+		return res, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.opts.now()
+	last, ok := m.last[key]
+	if ok {
+		if elapsed := now.Sub(last); elapsed < m.interval {
+			return minIntervalResult(false, last, m.interval, m.interval-elapsed), nil
+		}
+	}
+	m.last[key] = now
+	return minIntervalResult(true, now, m.interval, 0), nil
+}
+
+func (m *minIntervalMemory) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.last, key)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *minIntervalMemory) ResetMany(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		delete(m.last, key)
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *minIntervalMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	_, ok := m.last[key]
+	delete(m.last, key)
+	m.mu.Unlock()
+	return ok, nil
+}
+
+func (m *minIntervalMemory) Describe() Description {
+	return Description{Algorithm: "min_interval", Limit: 1, Window: m.interval}
+}
+
+// ─── Redis ────────────────────────────────────────────────────────────────────
+
+// minIntervalScript stores the last allowed time in milliseconds and returns
+// { allowed, last_ms, retry_after_ms }. The key expires once the interval has
+// passed, since it no longer affects decisions. An empty now means read it
+// from Redis TIME.
+var minIntervalScript = redis.NewScript(`
+local key = KEYS[1]
+local interval = tonumber(ARGV[1])
+local now = tonumber(ARGV[2])
+local expire = tonumber(ARGV[3])
+
+if not now then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+end
+
+local last = tonumber(redis.call('GET', key))
+if last and now - last < interval then
+  return { 0, last, interval - (now - last) }
+end
+
+if expire > 0 then
+  redis.call('SET', key, now, 'PX', math.ceil(interval * expire))
+else
+  redis.call('SET', key, now)
+end
+return { 1, now, 0 }
+`)
+
+type minIntervalRedis struct {
+	redis    redis.UniversalClient
+	interval int64 // milliseconds
+	opts     *Options
+}
+
+func (m *minIntervalRedis) Allow(ctx context.Context, key string) (Result, error) {
+	return m.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (m *minIntervalRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	return m.queueAllowN(ctx, m.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (m *minIntervalRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, 1); ok {
+		return decided(res)
+	}
+	if res, ok := costTooLarge(n, 1, 0); ok {
+		return decided(res)
+	}
+	keys := []string{m.opts.FormatKey(key)}
+	args := []interface{}{
+		m.interval,
+		scriptNow(m.opts, m.opts.now().UnixMilli()),
+		m.opts.expireArg(),
+	}
+	cmd := runScript(ctx, minIntervalScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := m.opts.scriptResult(ctx, cmd, m.redis, minIntervalScript, keys, args)
+		if err != nil {
+			if m.opts.FailOpen {
+				m.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: 0, Limit: 1}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: 1, DenyReason: ReasonBackendError}, redisErr(err, m.opts)
+		}
+
+		interval := time.Duration(m.interval) * time.Millisecond
+		return minIntervalResult(result[0] == 1, time.UnixMilli(result[1]), interval,
+			time.Duration(result[2])*time.Millisecond), nil
+	}
+}
+
+func (m *minIntervalRedis) redisClient() redis.UniversalClient {
+	return m.redis
+}
+
+func (m *minIntervalRedis) Reset(ctx context.Context, key string) error {
+	return m.redis.Del(ctx, m.opts.FormatKey(key)).Err()
+}
+
+func (m *minIntervalRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = m.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, m.redis, fullKeys)
+}
+
+func (m *minIntervalRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := m.redis.Del(ctx, m.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+func (m *minIntervalRedis) Describe() Description {
+	return Description{Algorithm: "min_interval", Limit: 1, Window: time.Duration(m.interval) * time.Millisecond}
+}
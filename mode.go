@@ -0,0 +1,68 @@
+package goratelimit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Mode is a process-wide override for every limiter in this package.
+type Mode int32
+
+const (
+	// Normal applies each limiter's algorithm. This is the default.
+	Normal Mode = iota
+
+	// ForceAllow admits every request without touching limiter state,
+	// disabling rate limiting (e.g. when the backend is misbehaving).
+	ForceAllow
+
+	// ForceDeny rejects every request without touching limiter state,
+	// shedding load during an incident.
+	ForceDeny
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ForceAllow:
+		return "force_allow"
+	case ForceDeny:
+		return "force_deny"
+	default:
+		return "normal"
+	}
+}
+
+var globalMode atomic.Int32
+
+// SetMode switches all limiters to m. It takes effect on the next Allow or
+// AllowN call of every limiter in the process and is safe for concurrent use.
+// Wrappers still apply on top: a DryRun limiter allows under ForceDeny.
+func SetMode(m Mode) {
+	globalMode.Store(int32(m))
+}
+
+// CurrentMode returns the mode set by SetMode.
+func CurrentMode() Mode {
+	return Mode(globalMode.Load())
+}
+
+// forcedResult returns the synthetic result for a call bypassed with
+// WithContextBypass or for the current mode, or false under Normal. limit is
+// reported as the Result's Limit.
+func forcedResult(ctx context.Context, limit int64) (Result, bool) {
+	if contextBypassed(ctx) {
+		return Result{Allowed: true, Remaining: limit, Limit: limit, Uncharged: true}, true
+	}
+	switch Mode(globalMode.Load()) {
+	case ForceAllow:
+		return Result{Allowed: true, Remaining: limit, Limit: limit, Uncharged: true}, true
+	case ForceDeny:
+		return Result{Allowed: false, Remaining: 0, Limit: limit, DenyReason: ReasonMaintenance, Uncharged: true}, true
+	}
+	return Result{}, false
+}
+
+// unlimitedResult is the result for a key whose limit is Unlimited.
+func unlimitedResult() Result {
+	return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited, Uncharged: true}
+}
@@ -0,0 +1,81 @@
+package goratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMode_ForceDenyAndForceAllow(t *testing.T) {
+	t.Cleanup(func() { SetMode(Normal) })
+	ctx := context.Background()
+
+	limiters := map[string]Limiter{
+		"fixed_window":           must(NewFixedWindow(2, 60)),
+		"sliding_window":         must(NewSlidingWindow(2, 60)),
+		"sliding_window_counter": must(NewSlidingWindowCounter(2, 60)),
+		"token_bucket":           must(NewTokenBucket(2, 1)),
+		"leaky_bucket":           must(NewLeakyBucket(2, 1, Policing)),
+		"gcra":                   must(NewGCRA(1, 2)),
+		"cms":                    must(NewCMS(2, 60, 0.01, 0.001)),
+		"concurrency":            must(NewConcurrency(2, time.Minute)),
+	}
+
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			SetMode(ForceDeny)
+			for i := 0; i < 3; i++ {
+				res, err := l.Allow(ctx, "key")
+				require.NoError(t, err)
+				assert.False(t, res.Allowed, "ForceDeny should deny despite unused budget")
+				assert.Equal(t, int64(2), res.Limit)
+			}
+
+			SetMode(ForceAllow)
+			for i := 0; i < 5; i++ {
+				res, err := l.AllowN(ctx, "key", 2)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed, "ForceAllow should allow beyond the limit")
+				assert.True(t, res.Uncharged)
+			}
+
+			// Forced decisions never touched state, so the full budget remains.
+			SetMode(Normal)
+			for i := 0; i < 2; i++ {
+				res, err := l.Allow(ctx, "key")
+				require.NoError(t, err)
+				assert.True(t, res.Allowed, "request %d should be allowed in Normal mode", i+1)
+			}
+			res, err := l.Allow(ctx, "key")
+			require.NoError(t, err)
+			assert.False(t, res.Allowed, "Normal mode should enforce the limit again")
+		})
+	}
+}
+
+func TestSetMode_Concurrent(t *testing.T) {
+	t.Cleanup(func() { SetMode(Normal) })
+	l := must(NewFixedWindow(1000000, 60))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				_, _ = l.Allow(ctx, "key")
+			}
+		}()
+	}
+	for _, m := range []Mode{ForceDeny, ForceAllow, Normal, ForceDeny} {
+		SetMode(m)
+	}
+	wg.Wait()
+	assert.Equal(t, ForceDeny, CurrentMode())
+	assert.Equal(t, "force_deny", CurrentMode().String())
+}
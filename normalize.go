@@ -0,0 +1,87 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// WithKeyNormalizer rewrites every key before it is used, so variants such as
+// "User@Example.com " and "user@example.com" share one bucket. It applies to
+// Allow, AllowN, Reset and the other per-key methods, before the key prefix,
+// hash tag and shard suffix are added. LimitFunc and OnLimitExceeded see the
+// normalized key. Default: keys are used as given. Ignored by NewConcurrency.
+func WithKeyNormalizer(fn func(key string) string) Option {
+	return func(o *Options) { o.KeyNormalizer = fn }
+}
+
+// LowerTrimNormalizer lowercases key and trims surrounding whitespace, for
+// keys such as email addresses. Use it with WithKeyNormalizer.
+func LowerTrimNormalizer(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// normalizedLimiter applies Options.KeyNormalizer before the inner limiter
+// sees a key.
+type normalizedLimiter struct {
+	inner     Limiter
+	normalize func(string) string
+}
+
+func (l *normalizedLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.inner.Allow(ctx, l.normalize(key))
+}
+
+func (l *normalizedLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	return l.inner.AllowN(ctx, l.normalize(key), n)
+}
+
+func (l *normalizedLimiter) Reset(ctx context.Context, key string) error {
+	return l.inner.Reset(ctx, l.normalize(key))
+}
+
+func (l *normalizedLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	normalized := make([]string, len(keys))
+	for i, key := range keys {
+		normalized[i] = l.normalize(key)
+	}
+	return ResetMany(ctx, l.inner, normalized...)
+}
+
+func (l *normalizedLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	return ResetExisted(ctx, l.inner, l.normalize(key))
+}
+
+func (l *normalizedLimiter) Describe() Description {
+	return Describe(l.inner)
+}
+
+func (l *normalizedLimiter) SetLimit(limit int64) error {
+	return SetLimit(l.inner, limit)
+}
+
+func (l *normalizedLimiter) Inspect() []KeyState {
+	states, _ := Inspect(l.inner)
+	return states
+}
+
+// Unwrap returns the wrapped limiter.
+func (l *normalizedLimiter) Unwrap() Limiter {
+	return l.inner
+}
+
+func (l *normalizedLimiter) Refund(ctx context.Context, key string, n int) error {
+	return Refund(ctx, l.inner, l.normalize(key), n)
+}
+
+func (l *normalizedLimiter) ProjectN(ctx context.Context, key string, n int) (time.Time, error) {
+	return ProjectN(ctx, l.inner, l.normalize(key), n)
+}
+
+func (l *normalizedLimiter) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	normalized := make(map[string]StateSnapshot, len(states))
+	for key, s := range states {
+		normalized[l.normalize(key)] = s
+	}
+	return Seed(ctx, l.inner, normalized)
+}
@@ -0,0 +1,56 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyNormalizer_SharesBucket(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(3, 60, WithKeyNormalizer(LowerTrimNormalizer)))
+
+	for _, key := range []string{"User@Example.com ", "user@example.com", "  USER@example.COM"} {
+		res, err := l.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, key)
+	}
+	res, err := l.Allow(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "all variants share one bucket")
+
+	states, ok := Inspect(l)
+	require.True(t, ok)
+	require.Len(t, states, 1)
+	assert.Equal(t, "user@example.com", states[0].Key)
+}
+
+func TestKeyNormalizer_ResetEitherForm(t *testing.T) {
+	ctx := context.Background()
+	for _, resetKey := range []string{"User@Example.com ", "user@example.com"} {
+		l := must(NewFixedWindow(1, 60, WithKeyNormalizer(LowerTrimNormalizer)))
+		_, err := l.Allow(ctx, "user@example.com")
+		require.NoError(t, err)
+
+		require.NoError(t, l.Reset(ctx, resetKey))
+		res, err := l.Allow(ctx, "User@Example.com ")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "Reset(%q) should clear the shared bucket", resetKey)
+
+		existed, err := ResetExisted(ctx, l, resetKey)
+		require.NoError(t, err)
+		assert.True(t, existed)
+	}
+}
+
+func TestKeyNormalizer_DefaultKeepsKeys(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(1, 60))
+	_, err := l.Allow(ctx, "User@Example.com")
+	require.NoError(t, err)
+	res, err := l.Allow(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "without a normalizer keys are distinct")
+}
@@ -0,0 +1,254 @@
+package goratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// PenaltyConfig configures a PenaltyBox. Zero values select the defaults.
+type PenaltyConfig struct {
+	// Multiplier is the factor applied per consecutive denial. The first
+	// denial is returned unchanged, the second is multiplied by Multiplier,
+	// the third by Multiplier², and so on. Default 2.
+	Multiplier float64
+
+	// MaxMultiplier caps the escalating factor. Default 32.
+	MaxMultiplier float64
+
+	// QuietPeriod is how long a key must go without a denial, measured from
+	// the end of its last cooldown, before its penalty decays back to normal.
+	// Default 1 minute.
+	QuietPeriod time.Duration
+
+	// Clock provides the current time. If nil, time.Now is used.
+	Clock Clock
+}
+
+// PenaltyBox escalates the cooldown for clients that keep hitting the limit.
+// It tracks consecutive denials per key in local memory; each denial
+// multiplies the returned RetryAfter by an escalating factor, and requests
+// arriving before the escalated RetryAfter has elapsed are denied without
+// consulting the wrapped limiter. The penalty resets once a key has gone
+// QuietPeriod past its last cooldown without another denial.
+//
+//	pb := goratelimit.NewPenaltyBox(goratelimit.PenaltyConfig{QuietPeriod: 5 * time.Minute})
+//	limiter := pb.Wrap(gcra)
+//
+// To also tighten the limit while a key is penalized, pass LimitFunc to the
+// wrapped limiter's constructor:
+//
+//	gcra, _ := goratelimit.NewGCRA(10, 20, goratelimit.WithLimitFunc(pb.LimitFunc(20)))
+//	limiter := pb.Wrap(gcra)
+type PenaltyBox struct {
+	mu     sync.Mutex
+	states map[string]*penaltyState
+	cfg    PenaltyConfig
+}
+
+type penaltyState struct {
+	strikes      int
+	limit        int64
+	base         time.Duration
+	blockedUntil time.Time
+}
+
+// Backoff describes a key's escalating penalty as exponential backoff
+// parameters a client can follow.
+type Backoff struct {
+	// Base is the cooldown before escalation: the wrapped limiter's
+	// RetryAfter on the latest denial.
+	Base time.Duration
+
+	// Max is the longest cooldown the penalty can reach, Base × MaxMultiplier.
+	Max time.Duration
+
+	// Attempt counts the key's consecutive denials, starting at 1. Requests
+	// rejected during a cooldown do not count.
+	Attempt int
+}
+
+// NewPenaltyBox creates a PenaltyBox. Penalty state is per process; with
+// several instances each tracks the denials it observes.
+func NewPenaltyBox(cfg PenaltyConfig) *PenaltyBox {
+	if cfg.Multiplier <= 1 {
+		cfg.Multiplier = 2
+	}
+	if cfg.MaxMultiplier < 1 {
+		cfg.MaxMultiplier = 32
+	}
+	if cfg.QuietPeriod <= 0 {
+		cfg.QuietPeriod = time.Minute
+	}
+	return &PenaltyBox{states: make(map[string]*penaltyState), cfg: cfg}
+}
+
+// Wrap returns a Limiter that applies the penalty box to inner. Limiters
+// wrapped by the same PenaltyBox share penalty state per key.
+func (pb *PenaltyBox) Wrap(inner Limiter) Limiter {
+	return &penaltyLimiter{inner: inner, box: pb}
+}
+
+// LimitFunc returns a function for WithLimitFunc that divides base by the
+// key's current penalty factor (never below 1). Unpenalized keys get base.
+func (pb *PenaltyBox) LimitFunc(base int64) func(ctx context.Context, key string) int64 {
+	return func(_ context.Context, key string) int64 {
+		pb.mu.Lock()
+		factor := pb.factorLocked(key, pb.now())
+		pb.mu.Unlock()
+		if factor <= 1 {
+			return base
+		}
+		limit := int64(float64(base) / factor)
+		if limit < 1 {
+			limit = 1
+		}
+		return limit
+	}
+}
+
+// Factor returns the current penalty factor for key; 1 means no penalty.
+func (pb *PenaltyBox) Factor(key string) float64 {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.factorLocked(key, pb.now())
+}
+
+// Backoff returns the current penalty for key as backoff parameters. ok is
+// false when the key is not penalized.
+func (pb *PenaltyBox) Backoff(key string) (b Backoff, ok bool) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	st := pb.stateLocked(key, pb.now())
+	if st == nil {
+		return Backoff{}, false
+	}
+	return Backoff{
+		Base:    st.base,
+		Max:     time.Duration(float64(st.base) * pb.cfg.MaxMultiplier),
+		Attempt: st.strikes,
+	}, true
+}
+
+// Clear removes the penalty for keys.
+func (pb *PenaltyBox) Clear(keys ...string) {
+	pb.mu.Lock()
+	for _, key := range keys {
+		delete(pb.states, key)
+	}
+	pb.mu.Unlock()
+}
+
+func (pb *PenaltyBox) now() time.Time {
+	if pb.cfg.Clock != nil {
+		return pb.cfg.Clock.Now()
+	}
+	return time.Now()
+}
+
+// stateLocked returns the live state for key, dropping it if the quiet
+// period has elapsed since the key's last cooldown ended.
+func (pb *PenaltyBox) stateLocked(key string, now time.Time) *penaltyState {
+	st, ok := pb.states[key]
+	if !ok {
+		return nil
+	}
+	if now.Sub(st.blockedUntil) >= pb.cfg.QuietPeriod {
+		delete(pb.states, key)
+		return nil
+	}
+	return st
+}
+
+func (pb *PenaltyBox) factorLocked(key string, now time.Time) float64 {
+	st := pb.stateLocked(key, now)
+	if st == nil {
+		return 1
+	}
+	return pb.factorFor(st.strikes)
+}
+
+func (pb *PenaltyBox) factorFor(strikes int) float64 {
+	if strikes <= 1 {
+		return 1
+	}
+	return math.Min(math.Pow(pb.cfg.Multiplier, float64(strikes-1)), pb.cfg.MaxMultiplier)
+}
+
+// ─── Internals ───────────────────────────────────────────────────────────────
+
+type penaltyLimiter struct {
+	inner Limiter
+	box   *PenaltyBox
+}
+
+func (p *penaltyLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return p.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (p *penaltyLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	pb := p.box
+	now := pb.now()
+
+	pb.mu.Lock()
+	if st := pb.stateLocked(key, now); st != nil && now.Before(st.blockedUntil) {
+		retryAfter := st.blockedUntil.Sub(now)
+		pb.mu.Unlock()
+		return Result{
+			Allowed:    false,
+			DenyReason: ReasonOverLimit,
+			Remaining:  0,
+			Limit:      st.limit,
+			ResetAt:    now.Add(retryAfter),
+			RetryAfter: retryAfter,
+		}, nil
+	}
+	pb.mu.Unlock()
+
+	res, err := p.inner.AllowN(ctx, key, n)
+	if err != nil || res.Allowed {
+		return res, err
+	}
+
+	pb.mu.Lock()
+	st := pb.stateLocked(key, now)
+	if st == nil {
+		st = &penaltyState{}
+		pb.states[key] = st
+	}
+	st.strikes++
+	st.limit = res.Limit
+	st.base = res.RetryAfter
+	res.RetryAfter = time.Duration(float64(res.RetryAfter) * pb.factorFor(st.strikes))
+	if res.RetryAfter > 0 {
+		res.ResetAt = now.Add(res.RetryAfter)
+	}
+	st.blockedUntil = now.Add(res.RetryAfter)
+	pb.mu.Unlock()
+
+	return res, nil
+}
+
+func (p *penaltyLimiter) Reset(ctx context.Context, key string) error {
+	p.box.Clear(key)
+	return p.inner.Reset(ctx, key)
+}
+
+func (p *penaltyLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	p.box.Clear(keys...)
+	return ResetMany(ctx, p.inner, keys...)
+}
+
+func (p *penaltyLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	p.box.Clear(key)
+	return ResetExisted(ctx, p.inner, key)
+}
+
+func (p *penaltyLimiter) Describe() Description {
+	return Describe(p.inner)
+}
+
+func (p *penaltyLimiter) SetLimit(limit int64) error {
+	return SetLimit(p.inner, limit)
+}
@@ -0,0 +1,151 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// denyAfterFirst exhausts the single token for key and returns the denial.
+func denyAfterFirst(t *testing.T, l Limiter, key string) Result {
+	t.Helper()
+	ctx := context.Background()
+	res, err := l.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "first request should be allowed")
+	res, err = l.Allow(ctx, key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed, "second request should be denied")
+	return res
+}
+
+func TestPenaltyBox_EscalatesRetryAfter(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewTokenBucket(1, 1, WithClock(clock))
+	require.NoError(t, err)
+	pb := NewPenaltyBox(PenaltyConfig{QuietPeriod: 10 * time.Second, Clock: clock})
+	l := pb.Wrap(inner)
+
+	var prev time.Duration
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		res := denyAfterFirst(t, l, "abuser")
+		assert.Equal(t, want, res.RetryAfter, "denial %d", i+1)
+		assert.Greater(t, res.RetryAfter, prev, "denial %d should increase RetryAfter", i+1)
+		prev = res.RetryAfter
+		clock.Advance(res.RetryAfter)
+	}
+	assert.Equal(t, float64(8), pb.Factor("abuser"))
+	assert.Equal(t, float64(1), pb.Factor("other"), "penalties are per key")
+}
+
+func TestPenaltyBox_BlocksUntilEscalatedRetryAfter(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	inner, err := NewTokenBucket(1, 1, WithClock(clock))
+	require.NoError(t, err)
+	l := NewPenaltyBox(PenaltyConfig{Clock: clock}).Wrap(inner)
+
+	denyAfterFirst(t, l, "abuser")
+	clock.Advance(time.Second)
+	res := denyAfterFirst(t, l, "abuser")
+	require.Equal(t, 2*time.Second, res.RetryAfter)
+
+	// The bucket refills after 1s, but the penalty holds for 2s.
+	clock.Advance(time.Second)
+	res, err = l.Allow(ctx, "abuser")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "penalized key should stay blocked")
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, int64(1), res.Limit)
+
+	clock.Advance(time.Second)
+	res, err = l.Allow(ctx, "abuser")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "request after the penalty should be allowed")
+}
+
+func TestPenaltyBox_QuietPeriodResetsPenalty(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewTokenBucket(1, 1, WithClock(clock))
+	require.NoError(t, err)
+	pb := NewPenaltyBox(PenaltyConfig{QuietPeriod: 10 * time.Second, Clock: clock})
+	l := pb.Wrap(inner)
+
+	for i := 0; i < 3; i++ {
+		res := denyAfterFirst(t, l, "abuser")
+		clock.Advance(res.RetryAfter)
+	}
+	require.Equal(t, float64(4), pb.Factor("abuser"))
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, float64(1), pb.Factor("abuser"), "penalty should decay after quiet period")
+	res := denyAfterFirst(t, l, "abuser")
+	assert.Equal(t, time.Second, res.RetryAfter, "first denial after decay should be unpenalized")
+}
+
+func TestPenaltyBox_LimitFuncTightensLimit(t *testing.T) {
+	clock := NewFakeClock()
+	pb := NewPenaltyBox(PenaltyConfig{Clock: clock})
+	inner, err := NewFixedWindow(10, 60, WithClock(clock), WithLimitFunc(pb.LimitFunc(10)))
+	require.NoError(t, err)
+	l := pb.Wrap(inner)
+	ctx := context.Background()
+
+	limit := pb.LimitFunc(10)
+	assert.Equal(t, int64(10), limit(ctx, "abuser"))
+
+	for i := 0; i < 2; i++ {
+		for {
+			res, err := l.Allow(ctx, "abuser")
+			require.NoError(t, err)
+			if !res.Allowed {
+				clock.Advance(res.RetryAfter)
+				break
+			}
+		}
+	}
+	assert.Equal(t, int64(5), limit(ctx, "abuser"), "limit should be divided by the penalty factor")
+}
+
+func TestPenaltyBox_ResetClearsPenalty(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	inner, err := NewTokenBucket(1, 1, WithClock(clock))
+	require.NoError(t, err)
+	pb := NewPenaltyBox(PenaltyConfig{Clock: clock})
+	l := pb.Wrap(inner)
+
+	denyAfterFirst(t, l, "abuser")
+	clock.Advance(time.Second)
+	denyAfterFirst(t, l, "abuser")
+	require.Equal(t, float64(2), pb.Factor("abuser"))
+
+	require.NoError(t, l.Reset(ctx, "abuser"))
+	assert.Equal(t, float64(1), pb.Factor("abuser"))
+	res, err := l.Allow(ctx, "abuser")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, Describe(inner), Describe(l))
+}
+
+func TestPenaltyBox_Backoff(t *testing.T) {
+	clock := NewFakeClock()
+	inner, err := NewTokenBucket(1, 1, WithClock(clock))
+	require.NoError(t, err)
+	pb := NewPenaltyBox(PenaltyConfig{MaxMultiplier: 8, Clock: clock})
+	l := pb.Wrap(inner)
+
+	_, ok := pb.Backoff("abuser")
+	assert.False(t, ok, "unpenalized key has no backoff")
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		res := denyAfterFirst(t, l, "abuser")
+		b, ok := pb.Backoff("abuser")
+		require.True(t, ok)
+		assert.Equal(t, Backoff{Base: time.Second, Max: 8 * time.Second, Attempt: attempt}, b)
+		clock.Advance(res.RetryAfter)
+	}
+}
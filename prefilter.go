@@ -32,7 +32,7 @@ func NewPreFilter(local, precise Limiter) Limiter {
 }
 
 func (p *preFilter) Allow(ctx context.Context, key string) (Result, error) {
-	return p.AllowN(ctx, key, 1)
+	return p.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (p *preFilter) AllowN(ctx context.Context, key string, n int) (Result, error) {
@@ -51,3 +51,18 @@ func (p *preFilter) Reset(ctx context.Context, key string) error {
 	_ = p.local.Reset(ctx, key)
 	return p.precise.Reset(ctx, key)
 }
+
+func (p *preFilter) ResetMany(ctx context.Context, keys ...string) error {
+	_ = ResetMany(ctx, p.local, keys...)
+	return ResetMany(ctx, p.precise, keys...)
+}
+
+func (p *preFilter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	_ = p.local.Reset(ctx, key)
+	return ResetExisted(ctx, p.precise, key)
+}
+
+// Describe reports the precise limiter, whose result is authoritative.
+func (p *preFilter) Describe() Description {
+	return Describe(p.precise)
+}
@@ -0,0 +1,32 @@
+package goratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Projector is implemented by limiters that can project the effect of an
+// AllowN without making it. Both GCRA backends implement it. The dry run,
+// OnLimitExceeded, hot key, allow list, block list, key normalizer and
+// metrics wrappers forward to the wrapped limiter.
+type Projector interface {
+	// ProjectN returns the earliest time a single request for key would be
+	// allowed after AllowN(ctx, key, n) were called now, without changing
+	// the key's state. If that AllowN would be denied it changes nothing,
+	// and ProjectN reports when one request is next allowed as things are.
+	// n may be 0 to ask about the next request alone.
+	ProjectN(ctx context.Context, key string, n int) (time.Time, error)
+}
+
+// ProjectN returns when a request for key is next allowed by l after n more
+// are sent now; see Projector. It returns an error wrapping
+// ErrInvalidParameter if l does not implement Projector.
+func ProjectN(ctx context.Context, l Limiter, key string, n int) (time.Time, error) {
+	p, ok := l.(Projector)
+	if !ok {
+		return time.Time{}, validationErr(fmt.Sprintf("%T does not support ProjectN", l),
+			"Use a GCRA limiter.")
+	}
+	return p.ProjectN(ctx, key, n)
+}
@@ -0,0 +1,88 @@
+package goratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkProjection asserts that after AllowN(n) a single request is denied
+// just before the projected time and allowed at it.
+func checkProjection(t *testing.T, l Limiter, clock *FakeClock, key string, n int) {
+	t.Helper()
+	ctx := context.Background()
+	next, err := ProjectN(ctx, l, key, n)
+	require.NoError(t, err)
+	again, err := ProjectN(ctx, l, key, n)
+	require.NoError(t, err)
+	require.Equal(t, next, again, "ProjectN must not change state")
+
+	_, err = l.AllowN(ctx, key, n)
+	require.NoError(t, err)
+	if wait := next.Sub(clock.Now()); wait > 0 {
+		clock.Advance(wait - time.Nanosecond)
+		res, err := l.Allow(ctx, key)
+		require.NoError(t, err)
+		require.False(t, res.Allowed, "allowed %v before the projected time", time.Nanosecond)
+		clock.Advance(time.Nanosecond)
+	} else {
+		require.Equal(t, clock.Now(), next)
+	}
+	res, err := l.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "denied at the projected time")
+}
+
+func TestGCRA_ProjectNMatchesAllowN(t *testing.T) {
+	clock := NewFakeClock()
+	l := must(NewGCRA(10, 5, WithClock(clock)))
+	for _, n := range []int{0, 1, 3, 5, 2, 1, 4} {
+		checkProjection(t, l, clock, "k", n)
+	}
+
+	// Fresh key: 5 now uses the whole burst, so the next waits one interval.
+	next, err := ProjectN(context.Background(), l, "fresh", 5)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(100*time.Millisecond), next)
+
+	// n the AllowN would deny changes nothing.
+	next, err = ProjectN(context.Background(), l, "fresh", 6)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now(), next)
+}
+
+func TestProjectN_ThroughWrappersAndUnsupported(t *testing.T) {
+	clock := NewFakeClock()
+	l := must(NewGCRA(1, 2, WithClock(clock), WithDryRun(true), WithKeyNormalizer(strings.ToLower), WithAllowList("vip")))
+	_, err := l.AllowN(context.Background(), "k", 2)
+	require.NoError(t, err)
+
+	next, err := ProjectN(context.Background(), l, "K", 0)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(time.Second), next, "normalized key sees k's state")
+
+	next, err = ProjectN(context.Background(), l, "vip", 100)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now(), next, "exempt keys are always allowed")
+
+	_, err = ProjectN(context.Background(), must(NewTokenBucket(1, 1)), "k", 1)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+func TestParseGCRATAT(t *testing.T) {
+	for stored, want := range map[string]int64{
+		"":                     0,
+		"1700000000:000000042": 1700000000*int64(time.Second) + 42,
+		"1700000000000000.5":   1700000000000000500,
+	} {
+		got, err := parseGCRATAT(stored)
+		require.NoError(t, err, stored)
+		assert.Equal(t, want, got, stored)
+	}
+	_, err := parseGCRATAT("garbage")
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+// Package ratelimittest provides Limiter test doubles for code that consumes
+// goratelimit, such as HTTP handlers and middleware.
+//
+//	fake := ratelimittest.NewFakeLimiter(
+//	    ratelimittest.Allowed(1),
+//	    ratelimittest.Denied(30*time.Second),
+//	)
+//	handler := middleware.RateLimit(fake, middleware.KeyByIP)(app)
+//	// first request → 200, every later request → 429
+//	assert.Equal(t, 2, fake.CallCount())
+package ratelimittest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// Step is one scripted response of a FakeLimiter.
+type Step struct {
+	Result goratelimit.Result
+	Err    error
+}
+
+// Allowed returns a Step that admits the request with remaining requests left.
+func Allowed(remaining int64) Step {
+	return Step{Result: goratelimit.Result{Allowed: true, Remaining: remaining}}
+}
+
+// Denied returns a Step that rejects the request with the given RetryAfter.
+func Denied(retryAfter time.Duration) Step {
+	return Step{Result: goratelimit.Result{Allowed: false, RetryAfter: retryAfter}}
+}
+
+// Fail returns a Step that makes AllowN return err, as a backend outage would.
+func Fail(err error) Step {
+	return Step{Err: err}
+}
+
+// Call records one Allow or AllowN invocation.
+type Call struct {
+	Key string
+	N   int
+}
+
+// FakeLimiter is a goratelimit.Limiter that replays a scripted sequence of
+// Steps and records every call. Once the script is exhausted the last Step
+// repeats; an empty script allows every request. Safe for concurrent use.
+type FakeLimiter struct {
+	// Limit is reported as Result.Limit when a Step leaves Limit zero.
+	Limit int64
+
+	mu       sync.Mutex
+	steps    []Step
+	next     int
+	err      error
+	resetErr error
+	calls    []Call
+	resets   []string
+}
+
+// NewFakeLimiter returns a FakeLimiter that replays steps in order.
+func NewFakeLimiter(steps ...Step) *FakeLimiter {
+	return &FakeLimiter{steps: steps}
+}
+
+// AlwaysAllow returns a FakeLimiter that admits every request as unlimited.
+func AlwaysAllow() *FakeLimiter {
+	return NewFakeLimiter(Step{Result: goratelimit.Result{
+		Allowed:   true,
+		Remaining: goratelimit.Unlimited,
+		Limit:     goratelimit.Unlimited,
+	}})
+}
+
+// AlwaysDeny returns a FakeLimiter that rejects every request with retryAfter.
+func AlwaysDeny(retryAfter time.Duration) *FakeLimiter {
+	return NewFakeLimiter(Denied(retryAfter))
+}
+
+// Allow is AllowN with n = 1.
+func (f *FakeLimiter) Allow(ctx context.Context, key string) (goratelimit.Result, error) {
+	return f.AllowN(ctx, key, goratelimit.CostFromContext(ctx))
+}
+
+// AllowN records the call and returns the next scripted Step.
+func (f *FakeLimiter) AllowN(_ context.Context, key string, n int) (goratelimit.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Key: key, N: n})
+	if f.err != nil {
+		return goratelimit.Result{}, f.err
+	}
+
+	step := Step{Result: goratelimit.Result{Allowed: true}}
+	if len(f.steps) > 0 {
+		i := f.next
+		if i >= len(f.steps) {
+			i = len(f.steps) - 1
+		} else {
+			f.next++
+		}
+		step = f.steps[i]
+	}
+	if step.Err != nil {
+		return goratelimit.Result{}, step.Err
+	}
+	result := step.Result
+	if result.Limit == 0 {
+		result.Limit = f.Limit
+	}
+	if !result.Allowed && result.RetryAfter > 0 && result.ResetAt.IsZero() {
+		result.ResetAt = time.Now().Add(result.RetryAfter)
+	}
+	return result, nil
+}
+
+// Reset records key and returns the error set by SetResetError.
+func (f *FakeLimiter) Reset(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resets = append(f.resets, key)
+	return f.resetErr
+}
+
+// Describe reports the "fake" algorithm and Limit.
+func (f *FakeLimiter) Describe() goratelimit.Description {
+	return goratelimit.Description{Algorithm: "fake", Limit: f.Limit}
+}
+
+// SetError makes every later AllowN fail with err, overriding the script,
+// until cleared with SetError(nil).
+func (f *FakeLimiter) SetError(err error) {
+	f.mu.Lock()
+	f.err = err
+	f.mu.Unlock()
+}
+
+// SetResetError sets the error returned by Reset.
+func (f *FakeLimiter) SetResetError(err error) {
+	f.mu.Lock()
+	f.resetErr = err
+	f.mu.Unlock()
+}
+
+// Calls returns a copy of the recorded Allow/AllowN calls in order.
+func (f *FakeLimiter) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallCount returns the number of Allow/AllowN calls.
+func (f *FakeLimiter) CallCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+// Resets returns a copy of the keys passed to Reset, in order.
+func (f *FakeLimiter) Resets() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.resets...)
+}
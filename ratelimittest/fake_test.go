@@ -0,0 +1,124 @@
+package ratelimittest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/middleware"
+	"github.com/krishna-kudari/ratelimit/ratelimittest"
+)
+
+var _ goratelimit.Limiter = (*ratelimittest.FakeLimiter)(nil)
+
+func TestFakeLimiter_ScriptedSequence(t *testing.T) {
+	ctx := context.Background()
+	fake := ratelimittest.NewFakeLimiter(
+		ratelimittest.Allowed(1),
+		ratelimittest.Allowed(0),
+		ratelimittest.Denied(30*time.Second),
+	)
+	fake.Limit = 2
+
+	res, err := fake.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining)
+	assert.Equal(t, int64(2), res.Limit)
+
+	res, err = fake.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(0), res.Remaining)
+
+	// The last step repeats once the script is exhausted.
+	for i := 0; i < 2; i++ {
+		res, err = fake.Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 30*time.Second, res.RetryAfter)
+		assert.False(t, res.ResetAt.IsZero())
+	}
+}
+
+func TestFakeLimiter_EmptyScriptAllows(t *testing.T) {
+	res, err := ratelimittest.NewFakeLimiter().Allow(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestFakeLimiter_ErrorInjection(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("backend down")
+
+	fake := ratelimittest.NewFakeLimiter(ratelimittest.Allowed(5), ratelimittest.Fail(errDown), ratelimittest.Allowed(4))
+	_, err := fake.Allow(ctx, "a")
+	require.NoError(t, err)
+	_, err = fake.Allow(ctx, "a")
+	assert.ErrorIs(t, err, errDown, "scripted Fail step should surface its error")
+	_, err = fake.Allow(ctx, "a")
+	require.NoError(t, err)
+
+	fake.SetError(errDown)
+	_, err = fake.Allow(ctx, "a")
+	assert.ErrorIs(t, err, errDown, "SetError should override the script")
+	fake.SetError(nil)
+	_, err = fake.Allow(ctx, "a")
+	assert.NoError(t, err)
+
+	fake.SetResetError(errDown)
+	assert.ErrorIs(t, fake.Reset(ctx, "a"), errDown)
+}
+
+func TestFakeLimiter_RecordsCalls(t *testing.T) {
+	ctx := context.Background()
+	fake := ratelimittest.AlwaysAllow()
+
+	_, _ = fake.Allow(ctx, "a")
+	_, _ = fake.AllowN(ctx, "b", 3)
+	_ = fake.Reset(ctx, "a")
+
+	assert.Equal(t, 2, fake.CallCount())
+	assert.Equal(t, []ratelimittest.Call{{Key: "a", N: 1}, {Key: "b", N: 3}}, fake.Calls())
+	assert.Equal(t, []string{"a"}, fake.Resets())
+}
+
+func TestAlwaysAllowAndAlwaysDeny(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		res, err := ratelimittest.AlwaysAllow().Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+
+		res, err = ratelimittest.AlwaysDeny(time.Second).Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, time.Second, res.RetryAfter)
+	}
+}
+
+func TestFakeLimiter_DrivesMiddleware(t *testing.T) {
+	fake := ratelimittest.NewFakeLimiter(ratelimittest.Allowed(0), ratelimittest.Denied(10*time.Second))
+	fake.Limit = 1
+	handler := middleware.RateLimit(fake, middleware.KeyByIP)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	codes := make([]int, 0, 2)
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.1.1.1:1234"
+		handler.ServeHTTP(rr, req)
+		codes = append(codes, rr.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, "10.1.1.1", fake.Calls()[0].Key)
+}
@@ -0,0 +1,89 @@
+package goratelimit
+
+import "fmt"
+
+// DenyReason explains why a Result was denied.
+type DenyReason uint8
+
+const (
+	// ReasonNone is set on allowed results.
+	ReasonNone DenyReason = iota
+
+	// ReasonOverLimit means the key exhausted its limit.
+	ReasonOverLimit
+
+	// ReasonBackendError means the backend failed and the limiter is
+	// configured to fail closed (WithFailOpen(false)).
+	ReasonBackendError
+
+	// ReasonMaintenance means the request was shed administratively: the
+	// process is in ForceDeny mode or the limiter is draining.
+	ReasonMaintenance
+
+	// ReasonMaxDelay means admitting the request would require waiting longer
+	// than the caller allows. Built-in limiters do not set it; it is for
+	// wrappers that cap shaping or queueing delay.
+	ReasonMaxDelay
+
+	// ReasonCostTooLarge means AllowN asked for more than the limiter's
+	// maximum (capacity, burst or per-window limit), so waiting cannot help.
+	// RetryAfter is zero and the key's state is left untouched.
+	ReasonCostTooLarge
+
+	// ReasonBlocked means the key is on the limiter's block list (see
+	// WithBlockList) and was denied without consulting the backend.
+	ReasonBlocked
+
+	// ReasonMissingKey means the middleware could not derive a key for the
+	// request and its EmptyKeyPolicy is EmptyKeyDeny. The limiter was not
+	// consulted.
+	ReasonMissingKey
+)
+
+func (r DenyReason) String() string {
+	switch r {
+	case ReasonOverLimit:
+		return "over_limit"
+	case ReasonBackendError:
+		return "backend_error"
+	case ReasonMaintenance:
+		return "maintenance"
+	case ReasonMaxDelay:
+		return "max_delay"
+	case ReasonCostTooLarge:
+		return "cost_too_large"
+	case ReasonBlocked:
+		return "blocked"
+	case ReasonMissingKey:
+		return "missing_key"
+	default:
+		return "none"
+	}
+}
+
+// denyReason returns ReasonOverLimit for a denied backend decision.
+func denyReason(allowed bool) DenyReason {
+	if allowed {
+		return ReasonNone
+	}
+	return ReasonOverLimit
+}
+
+// costTooLarge returns the denial for an AllowN cost of n against limit, or
+// false if n can be admitted at some point.
+func costTooLarge(n int, limit, rate int64) (Result, bool) {
+	if int64(n) <= limit {
+		return Result{}, false
+	}
+	return Result{Allowed: false, DenyReason: ReasonCostTooLarge, Limit: limit, Rate: rate}, true
+}
+
+// nonPositiveCost returns the error for an AllowN cost of n, if n is not
+// positive, for limiters where such a cost would not be a no-op.
+func nonPositiveCost(n int) error {
+	if n > 0 {
+		return nil
+	}
+	return validationErr(fmt.Sprintf("cost %d is not positive", n),
+		"Charge at least one unit, e.g. AllowN(ctx, key, 1).")
+}
@@ -0,0 +1,103 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenyReason_OverLimit(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(1, 60))
+
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, ReasonNone, res.DenyReason)
+
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, ReasonOverLimit, res.DenyReason)
+}
+
+func TestDenyReason_BackendErrorWhenFailClosed(t *testing.T) {
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 50 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	defer client.Close()
+	onDenied := WithOnLimitExceeded(func(context.Context, string, *Result) {})
+
+	for name, opts := range map[string][]Option{
+		"plain":             {WithRedis(client), WithFailOpen(false)},
+		"on limit exceeded": {WithRedis(client), WithFailOpen(false), onDenied},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := must(NewGCRA(10, 10, opts...)).Allow(context.Background(), "k")
+			require.Error(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, ReasonBackendError, res.DenyReason)
+		})
+	}
+}
+
+func TestDenyReason_MaintenanceUnderForceDeny(t *testing.T) {
+	SetMode(ForceDeny)
+	defer SetMode(Normal)
+
+	res, err := must(NewTokenBucket(10, 1)).Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, ReasonMaintenance, res.DenyReason)
+}
+
+func TestDenyReason_ClearedByDryRun(t *testing.T) {
+	ctx := context.Background()
+	l := must(NewFixedWindow(1, 60, WithDryRun(true), WithDryRunLogFunc(func(string, *Result) {})))
+	_, _ = l.Allow(ctx, "k")
+
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, ReasonNone, res.DenyReason)
+}
+
+func TestDenyReason_String(t *testing.T) {
+	assert.Equal(t, "none", ReasonNone.String())
+	assert.Equal(t, "over_limit", ReasonOverLimit.String())
+	assert.Equal(t, "backend_error", ReasonBackendError.String())
+	assert.Equal(t, "maintenance", ReasonMaintenance.String())
+	assert.Equal(t, "max_delay", ReasonMaxDelay.String())
+	assert.Equal(t, "cost_too_large", ReasonCostTooLarge.String())
+	assert.Equal(t, "blocked", ReasonBlocked.String())
+	assert.Equal(t, "missing_key", ReasonMissingKey.String())
+}
+
+func TestDenyReason_CostTooLarge(t *testing.T) {
+	ctx := context.Background()
+	for name, l := range map[string]Limiter{
+		"token bucket": must(NewTokenBucket(10, 1)),
+		"gcra":         must(NewGCRA(1, 10)),
+		"fixed window": must(NewFixedWindow(10, 60)),
+		"leaky bucket": must(NewLeakyBucket(10, 1, Policing)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := l.AllowN(ctx, "k", 11)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, ReasonCostTooLarge, res.DenyReason)
+			assert.Zero(t, res.RetryAfter, "waiting can never admit this cost")
+			assert.Equal(t, int64(10), res.Limit)
+
+			// State is untouched: the full limit is still available.
+			res, err = l.AllowN(ctx, "k", 10)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
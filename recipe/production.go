@@ -0,0 +1,44 @@
+// Package recipe assembles common limiter stacks from the root package and
+// its wrapper packages. It lives outside the root package because cache and
+// metrics both import goratelimit.
+package recipe
+
+import (
+	"fmt"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/cache"
+	"github.com/krishna-kudari/ratelimit/metrics"
+)
+
+// Production builds the usual production stack:
+//
+//	metrics.Wrap → cache.New → builder.Build() (e.g. Redis-backed GCRA)
+//
+// The LocalCache sits directly on the backend so cache hits skip Redis, and
+// the collector sits outermost so every decision is counted, whether the
+// cache or the backend served it. Metrics are labelled with the backend's
+// Describe().Algorithm. A nil collector skips metrics.
+//
+//	limiter, err := recipe.Production(
+//	    goratelimit.NewBuilder().GCRA(1000, 50).Redis(client),
+//	    []cache.CacheOption{cache.WithTTL(50 * time.Millisecond)},
+//	    metrics.NewCollector(),
+//	)
+//
+// The cache's eviction goroutine runs for the life of the process, so build
+// the stack once at startup.
+func Production(builder *goratelimit.Builder, cacheOpts []cache.CacheOption, collector *metrics.Collector) (goratelimit.Limiter, error) {
+	if builder == nil {
+		return nil, fmt.Errorf("goratelimit/recipe: builder is required: %w", goratelimit.ErrInvalidParameter)
+	}
+	backend, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	limiter := goratelimit.Limiter(cache.New(backend, cacheOpts...))
+	if collector != nil {
+		limiter = metrics.Wrap(limiter, goratelimit.Describe(backend).Algorithm, collector)
+	}
+	return limiter, nil
+}
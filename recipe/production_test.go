@@ -0,0 +1,91 @@
+package recipe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+	"github.com/krishna-kudari/ratelimit/cache"
+	"github.com/krishna-kudari/ratelimit/metrics"
+	"github.com/krishna-kudari/ratelimit/recipe"
+)
+
+func TestProduction_FullStack(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	// LimitFunc runs once per backend AllowN, so it counts Redis round trips.
+	var backendCalls atomic.Int64
+	countCalls := func(context.Context, string) int64 {
+		backendCalls.Add(1)
+		return 0
+	}
+	builder := goratelimit.NewBuilder().
+		GCRA(1000, 100).
+		Redis(client).
+		KeyPrefix(fmt.Sprintf("test_recipe_%d", time.Now().UnixNano())).
+		LimitFunc(countCalls)
+	reg := prometheus.NewRegistry()
+
+	limiter, err := recipe.Production(builder,
+		[]cache.CacheOption{cache.WithTTL(time.Minute)},
+		metrics.NewCollector(metrics.WithRegistry(reg)))
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		res, err := limiter.Allow(ctx, "user:1")
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	assert.Equal(t, int64(1), backendCalls.Load(), "cache hits should not reach Redis")
+	assert.Equal(t, 50.0, requestsTotal(t, reg, "gcra", "allowed"), "metrics should count cached decisions")
+}
+
+func TestProduction_NilCollectorSkipsMetrics(t *testing.T) {
+	limiter, err := recipe.Production(goratelimit.NewBuilder().FixedWindow(1, time.Minute), nil, nil)
+	require.NoError(t, err)
+	res, err := limiter.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestProduction_Errors(t *testing.T) {
+	_, err := recipe.Production(nil, nil, nil)
+	assert.True(t, errors.Is(err, goratelimit.ErrInvalidParameter))
+
+	_, err = recipe.Production(goratelimit.NewBuilder(), nil, nil)
+	assert.True(t, errors.Is(err, goratelimit.ErrUnknownAlgorithm))
+}
+
+func requestsTotal(t *testing.T, reg *prometheus.Registry, algorithm, decision string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, mf := range families {
+		if mf.GetName() != "ratelimit_requests_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			labels := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+			if labels["algorithm"] == algorithm && labels["decision"] == decision {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
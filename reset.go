@@ -0,0 +1,63 @@
+package goratelimit
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BulkResetter is implemented by limiters that can clear many keys at once.
+// All built-in algorithms implement it: in-memory limiters delete under a
+// single lock acquisition and Redis limiters batch deletes into one pipeline.
+type BulkResetter interface {
+	ResetMany(ctx context.Context, keys ...string) error
+}
+
+// ResetMany clears rate limit state for all keys. It uses l's ResetMany when
+// l implements BulkResetter and falls back to calling Reset per key otherwise.
+func ResetMany(ctx context.Context, l Limiter, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if b, ok := l.(BulkResetter); ok {
+		return b.ResetMany(ctx, keys...)
+	}
+	for _, key := range keys {
+		if err := l.Reset(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExistenceResetter is implemented by limiters that can report whether a
+// reset removed any state. All built-in algorithms implement it.
+type ExistenceResetter interface {
+	ResetExisted(ctx context.Context, key string) (bool, error)
+}
+
+// ResetExisted clears rate limit state for key and reports whether any state
+// was present. In-memory limiters check map membership and Redis limiters use
+// the count returned by DEL. If l does not implement ExistenceResetter, Reset
+// is called and ResetExisted reports false.
+func ResetExisted(ctx context.Context, l Limiter, key string) (bool, error) {
+	if e, ok := l.(ExistenceResetter); ok {
+		return e.ResetExisted(ctx, key)
+	}
+	return false, l.Reset(ctx, key)
+}
+
+// delPipelined deletes keys in one round-trip. Each key gets its own DEL
+// rather than one multi-key DEL so Redis Cluster can route keys that live
+// in different slots.
+func delPipelined(ctx context.Context, client redis.UniversalClient, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,71 @@
+package goratelimit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MarshalJSON encodes r for clients, e.g. in a 429 body:
+//
+//	{"allowed":false,"limit":100,"remaining":0,"reset":1700000000,
+//	 "retryAfter":12,"reason":"over_limit","scope":"user"}
+//
+// reset is ResetAt in Unix seconds and retryAfter is RetryAfter rounded to
+// whole seconds, matching the X-RateLimit-Reset and Retry-After headers.
+// reset, retryAfter, rate, reason and scope are omitted when zero. scope is
+// the ID of the binding link or key of a NewChain or AllowAll result, and
+// components lists every one checked. The encoding is meant for clients;
+// Result has no matching UnmarshalJSON.
+func (r Result) MarshalJSON() ([]byte, error) {
+	body := resultJSON{
+		Allowed:    r.Allowed,
+		Limit:      r.Limit,
+		Remaining:  r.Remaining,
+		RetryAfter: roundSeconds(r.RetryAfter),
+		Rate:       r.Rate,
+		Scope:      r.scope(),
+		Components: r.Components,
+	}
+	if !r.ResetAt.IsZero() {
+		body.Reset = r.ResetAt.Unix()
+	}
+	if r.DenyReason != ReasonNone {
+		body.Reason = r.DenyReason.String()
+	}
+	return json.Marshal(body)
+}
+
+type resultJSON struct {
+	Allowed    bool              `json:"allowed"`
+	Limit      int64             `json:"limit"`
+	Remaining  int64             `json:"remaining"`
+	Reset      int64             `json:"reset,omitempty"`
+	RetryAfter int64             `json:"retryAfter,omitempty"`
+	Rate       int64             `json:"rate,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Scope      string            `json:"scope,omitempty"`
+	Components []ComponentResult `json:"components,omitempty"`
+}
+
+// scope returns the ID of the chain link r came from: the last link checked
+// on denial, otherwise the earliest with r's Remaining. It is "" for results
+// without Components.
+func (r Result) scope() string {
+	if len(r.Components) == 0 {
+		return ""
+	}
+	if !r.Allowed {
+		return r.Components[len(r.Components)-1].ID
+	}
+	for _, c := range r.Components {
+		if c.Remaining == r.Remaining {
+			return c.ID
+		}
+	}
+	return ""
+}
+
+// roundSeconds returns d in whole seconds, rounded to nearest.
+func roundSeconds(d time.Duration) int64 {
+	return int64(d.Seconds() + 0.5)
+}
@@ -0,0 +1,62 @@
+package goratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResult_MarshalJSON(t *testing.T) {
+	reset := time.Unix(1700000000, 0)
+	data, err := json.Marshal(Result{
+		Remaining:  0,
+		Limit:      100,
+		ResetAt:    reset,
+		RetryAfter: 11600 * time.Millisecond,
+		DenyReason: ReasonOverLimit,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"allowed":false,"limit":100,"remaining":0,"reset":1700000000,
+		"retryAfter":12,"reason":"over_limit"}`, string(data))
+
+	data, err = json.Marshal(Result{Allowed: true, Remaining: 4, Limit: 5})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"allowed":true,"limit":5,"remaining":4}`, string(data), "zero fields are omitted")
+}
+
+func TestResult_MarshalJSON_ChainScope(t *testing.T) {
+	l := NewChain(
+		ChainLink{ID: "ip", Limiter: must(NewFixedWindow(10, 60))},
+		ChainLink{ID: "user", Limiter: must(NewFixedWindow(1, 60))},
+	)
+	ctx := context.Background()
+
+	res, err := l.Allow(ctx, "k")
+	require.NoError(t, err)
+	var body struct {
+		Scope      string            `json:"scope"`
+		Components []ComponentResult `json:"components"`
+	}
+	data, err := json.Marshal(res)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, "user", body.Scope, "allowed: the link with the lowest remaining")
+	assert.Equal(t, res.Components, body.Components)
+
+	l = NewChain(
+		ChainLink{ID: "ip", Limiter: must(NewFixedWindow(1, 60))},
+		ChainLink{ID: "user", Limiter: must(NewFixedWindow(10, 60))},
+	)
+	_, _ = l.Allow(ctx, "k")
+	res, err = l.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	data, err = json.Marshal(res)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, "ip", body.Scope, "denied: the denying link")
+}
@@ -0,0 +1,76 @@
+package goratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithBackendRetry makes Redis limiters retry a backend operation that fails
+// with a network error, such as a dropped connection, up to attempts more
+// times, waiting backoff before each retry. Only when the last attempt fails
+// does the WithFailOpen policy apply. Errors Redis itself replies with, like
+// a script error, are not retried, nor is a canceled context.
+//
+// A retry re-sends the operation, so a request whose reply was lost after
+// Redis ran it may be counted twice. Keep attempts small: every retry adds
+// latency to the request while the backend is down.
+// Default: no retries. In-memory limiters ignore it.
+func WithBackendRetry(attempts int, backoff time.Duration) Option {
+	return func(o *Options) {
+		o.BackendRetries = max(attempts, 0)
+		o.BackendRetryBackoff = max(backoff, 0)
+	}
+}
+
+// retryableErr reports whether err is a network failure worth retrying,
+// rather than a reply from Redis or the caller giving up.
+func retryableErr(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var replyErr redis.Error
+	if errors.As(err, &replyErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryBackend re-runs op per WithBackendRetry while it fails with a
+// retryable error, given the value and error of a first attempt.
+func retryBackend[T any](ctx context.Context, o *Options, v T, err error, op func() (T, error)) (T, error) {
+	for i := 0; i < o.BackendRetries && retryableErr(err); i++ {
+		if o.BackendRetryBackoff > 0 {
+			t := time.NewTimer(o.BackendRetryBackoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return v, err
+			case <-t.C:
+			}
+		}
+		v, err = op()
+	}
+	return v, err
+}
+
+// callBackend runs op, retrying it per WithBackendRetry.
+func callBackend[T any](ctx context.Context, o *Options, op func() (T, error)) (T, error) {
+	v, err := op()
+	return retryBackend(ctx, o, v, err, op)
+}
+
+// scriptResult decodes cmd, a run of script, re-running it on client per
+// WithBackendRetry if it failed with a network error. cmd may have come from
+// a pipeline; retries are sent on their own.
+func (o *Options) scriptResult(ctx context.Context, cmd *redis.Cmd, client redis.Scripter, script *redis.Script, keys []string, args []interface{}) ([]int64, error) {
+	result, err := cmd.Int64Slice()
+	return retryBackend(ctx, o, result, err, func() ([]int64, error) {
+		return script.Run(ctx, client, keys, args...).Int64Slice()
+	})
+}
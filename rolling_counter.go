@@ -0,0 +1,361 @@
+package goratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRollingCounter creates a limiter admitting at most maxRequests per key
+// in any rolling window, counted in buckets of bucketSize, e.g. "100 actions
+// in any 5 minutes" with one-minute buckets. It keeps one counter per bucket
+// and sums the last window/bucketSize of them, so old requests age out a
+// whole bucket at a time: exact at bucket granularity, unlike the weighted
+// estimate of the Sliding Window Counter, and far cheaper than the Sliding
+// Window log.
+//
+// Buckets are aligned to multiples of bucketSize since the Unix epoch.
+// window must be a whole number of buckets, and bucketSize at least a
+// millisecond. Each check reads every bucket in the window, so keep
+// window/bucketSize small, e.g. at most 60.
+//
+// Pass WithRedis for distributed mode, which stores one key per bucket
+// ("prefix:key:<bucket index>") expiring once it leaves the window; enable
+// WithHashTag on Redis Cluster. Omit it for in-memory.
+func NewRollingCounter(maxRequests int64, window, bucketSize time.Duration, opts ...Option) (Limiter, error) {
+	if maxRequests <= 0 {
+		return nil, validationErr("maxRequests must be positive",
+			"Use a positive integer, e.g. NewRollingCounter(100, 5*time.Minute, time.Minute).")
+	}
+	if bucketSize < time.Millisecond || window < bucketSize || window%bucketSize != 0 {
+		return nil, validationErr("window must be a whole number of buckets of at least 1ms",
+			"Use e.g. NewRollingCounter(100, 5*time.Minute, time.Minute).")
+	}
+	o := applyOptions(opts)
+	if err := o.checkStrict("NewRollingCounter", false, false); err != nil {
+		return nil, err
+	}
+	maxRequests = o.perShard(maxRequests)
+
+	r := rollingWindow{bucket: bucketSize, buckets: int64(window / bucketSize)}
+	if o.RedisClient != nil {
+		return wrapOptions(&rollingCounterRedis{
+			redis:         o.RedisClient,
+			baseLimit:     newBaseLimit(maxRequests),
+			rollingWindow: r,
+			opts:          o,
+		}, o), nil
+	}
+	return wrapOptions(&rollingCounterMemory{
+		states:        make(map[string]*rollingCounterState),
+		baseLimit:     newBaseLimit(maxRequests),
+		rollingWindow: r,
+		opts:          o,
+	}, o), nil
+}
+
+// rollingWindow is the bucket layout shared by both backends.
+type rollingWindow struct {
+	bucket  time.Duration
+	buckets int64 // buckets per window
+}
+
+// index returns the index of the epoch-aligned bucket containing t.
+func (w rollingWindow) index(t time.Time) int64 {
+	return t.UnixNano() / int64(w.bucket)
+}
+
+// expiry returns when the bucket at index leaves the window.
+func (w rollingWindow) expiry(index int64) time.Time {
+	return time.Unix(0, (index+w.buckets)*int64(w.bucket))
+}
+
+// result describes a decision over counts, the window's bucket counts oldest
+// first after any charge, the first being the bucket at index oldest. A
+// denied request may be retried once enough of the oldest buckets have
+// expired to fit cost.
+func (w rollingWindow) result(allowed bool, counts []int64, oldest, limit, cost int64, now time.Time) Result {
+	var sum int64
+	for _, c := range counts {
+		sum += c
+	}
+	r := Result{
+		Allowed:    allowed,
+		DenyReason: denyReason(allowed),
+		Remaining:  max(0, limit-sum),
+		Limit:      limit,
+	}
+	if allowed {
+		for i := len(counts) - 1; i >= 0; i-- {
+			if counts[i] > 0 {
+				r.ResetAt = w.expiry(oldest + int64(i))
+				break
+			}
+		}
+		return r
+	}
+	need := sum + cost - limit
+	for i, c := range counts {
+		if need -= c; need <= 0 {
+			r.ResetAt = w.expiry(oldest + int64(i))
+			r.RetryAfter = r.ResetAt.Sub(now)
+			break
+		}
+	}
+	return r
+}
+
+func (w rollingWindow) describe(limit int64, dynamic bool) Description {
+	return Description{Algorithm: "rolling_counter", Limit: limit, Window: w.bucket * time.Duration(w.buckets), Dynamic: dynamic}
+}
+
+// ─── In-Memory ───────────────────────────────────────────────────────────────
+
+type rollingCounterState struct {
+	counts []int64 // per-bucket counts, the bucket at index i in slot i%len
+	newest int64   // index of the newest bucket counted
+}
+
+type rollingCounterMemory struct {
+	mu     sync.Mutex
+	states map[string]*rollingCounterState
+	*baseLimit
+	rollingWindow
+	opts *Options
+}
+
+func (r *rollingCounterMemory) Allow(ctx context.Context, key string) (Result, error) {
+	return r.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (r *rollingCounterMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, r.limit()); ok {
+		return res, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	maxReq, unlimited := r.opts.resolveLimit(ctx, key, r.limit())
+	if unlimited {
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
+	}
+
+	now := r.opts.now()
+	current := r.index(now)
+	state := r.advance(key, current)
+	oldest := current - r.buckets + 1
+	counts := make([]int64, r.buckets)
+	var sum int64
+	for i := range counts {
+		counts[i] = state.counts[r.slot(oldest+int64(i))]
+		sum += counts[i]
+	}
+
+	cost := int64(n)
+	allowed := sum+cost <= maxReq
+	if allowed {
+		state.counts[r.slot(current)] += cost
+		counts[len(counts)-1] += cost
+	}
+	return r.result(allowed, counts, oldest, maxReq, cost, now), nil
+}
+
+// advance returns key's state with the buckets between its newest one and
+// current cleared for reuse.
+func (r *rollingCounterMemory) advance(key string, current int64) *rollingCounterState {
+	state, ok := r.states[key]
+	if !ok {
+		state = &rollingCounterState{counts: make([]int64, r.buckets), newest: current}
+		r.states[key] = state
+	}
+	if current <= state.newest {
+		return state
+	}
+	if current-state.newest >= r.buckets {
+		clear(state.counts)
+	} else {
+		for i := state.newest + 1; i <= current; i++ {
+			state.counts[r.slot(i)] = 0
+		}
+	}
+	state.newest = current
+	return state
+}
+
+// slot returns the position of the bucket at index in a state's counts.
+func (r *rollingCounterMemory) slot(index int64) int64 {
+	return (index%r.buckets + r.buckets) % r.buckets
+}
+
+func (r *rollingCounterMemory) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	delete(r.states, key)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *rollingCounterMemory) ResetMany(_ context.Context, keys ...string) error {
+	r.mu.Lock()
+	for _, key := range keys {
+		delete(r.states, key)
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *rollingCounterMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	r.mu.Lock()
+	_, ok := r.states[key]
+	delete(r.states, key)
+	r.mu.Unlock()
+	return ok, nil
+}
+
+func (r *rollingCounterMemory) Describe() Description {
+	return r.describe(r.limit(), r.opts.LimitFunc != nil)
+}
+
+// ─── Redis ────────────────────────────────────────────────────────────────────
+
+// rollingCounterScript sums the bucket keys of a window, oldest first, and
+// charges the newest one if cost fits. The newest bucket's key expires when
+// it leaves the window. It returns { allowed, counts... }, the counts after
+// any charge.
+var rollingCounterScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local cost = tonumber(ARGV[2])
+local ttl_ms = tonumber(ARGV[3])
+local expire = tonumber(ARGV[4])
+
+local counts = redis.call('MGET', unpack(KEYS))
+local sum = 0
+for i = 1, #counts do
+  counts[i] = tonumber(counts[i]) or 0
+  sum = sum + counts[i]
+end
+
+local allowed = 0
+if sum + cost <= limit then
+  allowed = 1
+  local newest = redis.call('INCRBY', KEYS[#KEYS], cost)
+  counts[#counts] = newest
+  if expire > 0 and newest == cost then
+    redis.call('PEXPIRE', KEYS[#KEYS], math.ceil(ttl_ms * expire))
+  end
+end
+
+table.insert(counts, 1, allowed)
+return counts
+`)
+
+type rollingCounterRedis struct {
+	redis redis.UniversalClient
+	*baseLimit
+	rollingWindow
+	opts *Options
+}
+
+func (r *rollingCounterRedis) Allow(ctx context.Context, key string) (Result, error) {
+	return r.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (r *rollingCounterRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	return r.queueAllowN(ctx, r.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (r *rollingCounterRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, r.limit()); ok {
+		return decided(res)
+	}
+	maxReq, unlimited := r.opts.resolveLimit(ctx, key, r.limit())
+	if unlimited {
+		return decided(unlimitedResult())
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return decided(res)
+	}
+	now := r.opts.now()
+	current := r.index(now)
+	oldest := current - r.buckets + 1
+	keys := r.bucketKeys(key, oldest, r.buckets)
+	args := []interface{}{
+		maxReq,
+		n,
+		r.expiry(current).Sub(now).Milliseconds() + 1,
+		r.opts.expireArg(),
+	}
+	cmd := runScript(ctx, rollingCounterScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := r.opts.scriptResult(ctx, cmd, r.redis, rollingCounterScript, keys, args)
+		if err != nil {
+			if r.opts.FailOpen {
+				r.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: maxReq - 1, Limit: maxReq}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: maxReq, DenyReason: ReasonBackendError}, redisErr(err, r.opts)
+		}
+		return r.result(result[0] == 1, result[1:], oldest, maxReq, int64(n), now), nil
+	}
+}
+
+// bucketKeys returns the keys of count buckets of key from oldest on.
+func (r *rollingCounterRedis) bucketKeys(key string, oldest, count int64) []string {
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = r.opts.FormatKeySuffix(key, strconv.FormatInt(oldest+int64(i), 10))
+	}
+	return keys
+}
+
+// windowKeys returns the bucket keys a reset must clear for each key: the
+// current window's, and the next bucket's for a node whose clock runs
+// slightly ahead, as the Sliding Window Counter does.
+func (r *rollingCounterRedis) windowKeys(keys ...string) []string {
+	oldest := r.index(r.opts.now()) - r.buckets + 1
+	all := make([]string, 0, len(keys)*int(r.buckets+1))
+	for _, key := range keys {
+		all = append(all, r.bucketKeys(key, oldest, r.buckets+1)...)
+	}
+	return all
+}
+
+func (r *rollingCounterRedis) redisClient() redis.UniversalClient {
+	return r.redis
+}
+
+func (r *rollingCounterRedis) Reset(ctx context.Context, key string) error {
+	return delPipelined(ctx, r.redis, r.windowKeys(key))
+}
+
+func (r *rollingCounterRedis) ResetMany(ctx context.Context, keys ...string) error {
+	return delPipelined(ctx, r.redis, r.windowKeys(keys...))
+}
+
+func (r *rollingCounterRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	pipe := r.redis.Pipeline()
+	var dels []*redis.IntCmd
+	for _, k := range r.windowKeys(key) {
+		dels = append(dels, pipe.Del(ctx, k))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	for _, del := range dels {
+		if del.Val() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *rollingCounterRedis) Describe() Description {
+	return r.describe(r.limit(), r.opts.LimitFunc != nil)
+}
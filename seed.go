@@ -0,0 +1,63 @@
+package goratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StateSnapshot is the state Seed writes for one key.
+type StateSnapshot struct {
+	// Remaining is what the key can consume right after seeding, from 0 to
+	// the construction-time limit. Dynamic limits from LimitFunc are not
+	// applied.
+	Remaining int64
+}
+
+// Seeder is implemented by limiters that can write the state of many keys at
+// once, e.g. to migrate from another rate limiter or to pre-warm after a
+// flush. The Redis backends of Token Bucket, Leaky Bucket, GCRA, Fixed Window
+// and Sliding Window Counter implement it. The dry run, OnLimitExceeded, hot
+// key, allow list, block list, key normalizer and metrics wrappers forward to
+// the wrapped limiter.
+type Seeder interface {
+	// Seed overwrites the state of every key in states as of now, in one
+	// pipeline, with the TTLs AllowN would give them. Other keys are left
+	// alone. The writes are not atomic: a concurrent AllowN may land on
+	// either side of them. Seed writes nothing and returns an error wrapping
+	// ErrInvalidParameter if a Remaining is out of range.
+	Seed(ctx context.Context, states map[string]StateSnapshot) error
+}
+
+// Seed writes states to l; see Seeder. It returns an error wrapping
+// ErrInvalidParameter if l does not implement Seeder.
+func Seed(ctx context.Context, l Limiter, states map[string]StateSnapshot) error {
+	s, ok := l.(Seeder)
+	if !ok {
+		return validationErr(fmt.Sprintf("%T does not support Seed", l),
+			"Use the Redis backend of Token Bucket, Leaky Bucket, GCRA, Fixed Window or Sliding Window Counter.")
+	}
+	return s.Seed(ctx, states)
+}
+
+// checkSeed validates every Remaining in states against limit.
+func checkSeed(states map[string]StateSnapshot, limit int64) error {
+	for key, s := range states {
+		if s.Remaining < 0 || s.Remaining > limit {
+			return validationErr(fmt.Sprintf("Remaining %d for key %q is outside [0, %d]", s.Remaining, key, limit),
+				"Seed each key with at most the limit the limiter was built with.")
+		}
+	}
+	return nil
+}
+
+// seedTTL returns the TTL for a seeded key that AllowN would keep for ttl,
+// applying NoExpire and TTLJitter. Zero means no expiry.
+func (o *Options) seedTTL(ttl time.Duration) time.Duration {
+	return time.Duration(float64(ttl) * o.expireArg())
+}
+
+// ceilDiv returns a/b rounded up, for positive a and b.
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,66 @@
+package goratelimit
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// LimitSetter is implemented by limiters whose base limit (maxRequests,
+// capacity, burst or maxInFlight) can be changed at runtime. All built-in
+// algorithms implement it, as do the wrappers in this module that forward
+// to a single limiter.
+//
+// The new limit applies to subsequent calls. Existing per-key state is kept
+// and clamped the same way as when a LimitFunc changes the limit: lowering
+// the limit below a key's current usage denies it until usage drains, and
+// raising it makes the extra room available immediately. Token Bucket keeps
+// each key's token count instead, capped at the new capacity, so a raise
+// fills in at the refill rate. Rates and windows are unchanged. A LimitFunc,
+// when set, still takes precedence.
+type LimitSetter interface {
+	SetLimit(limit int64) error
+}
+
+// SetLimit changes l's base limit at runtime. It returns an error wrapping
+// ErrInvalidParameter if l does not implement LimitSetter or limit is out of
+// range.
+func SetLimit(l Limiter, limit int64) error {
+	s, ok := l.(LimitSetter)
+	if !ok {
+		return validationErr(fmt.Sprintf("%T does not support SetLimit", l),
+			"Use a built-in limiter, or WithLimitFunc to resolve limits per request.")
+	}
+	return s.SetLimit(limit)
+}
+
+// baseLimit holds a limiter's construction-time limit and implements
+// SetLimit for it. Algorithms embed it and read the limit with limit().
+type baseLimit struct {
+	v atomic.Int64
+}
+
+func newBaseLimit(limit int64) *baseLimit {
+	b := &baseLimit{}
+	b.v.Store(limit)
+	return b
+}
+
+func (b *baseLimit) limit() int64 {
+	return b.v.Load()
+}
+
+func (b *baseLimit) SetLimit(limit int64) error {
+	if err := checkLimit(limit); err != nil {
+		return err
+	}
+	b.v.Store(limit)
+	return nil
+}
+
+// checkLimit rejects a base limit that a constructor would reject.
+func checkLimit(limit int64) error {
+	if limit <= 0 {
+		return validationErr("limit must be positive", "Use a positive integer, e.g. SetLimit(200).")
+	}
+	return checkRateBounds(1, limit)
+}
@@ -0,0 +1,91 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func allowedCount(t *testing.T, l Limiter, key string, attempts int) int {
+	t.Helper()
+	count := 0
+	for i := 0; i < attempts; i++ {
+		res, err := l.Allow(context.Background(), key)
+		require.NoError(t, err)
+		if res.Allowed {
+			count++
+		}
+	}
+	return count
+}
+
+func setLimitLimiters(clock *FakeClock) map[string]Limiter {
+	return map[string]Limiter{
+		"fixed window":           must(NewFixedWindow(5, 60, WithClock(clock))),
+		"sliding window":         must(NewSlidingWindow(5, 60, WithClock(clock))),
+		"sliding window counter": must(NewSlidingWindowCounter(5, 60, WithClock(clock))),
+		"token bucket":           must(NewTokenBucket(5, 1, WithClock(clock))),
+		"leaky bucket":           must(NewLeakyBucket(5, 1, Policing, WithClock(clock))),
+		"gcra":                   must(NewGCRA(1, 5, WithClock(clock))),
+		"cms":                    must(NewCMS(5, 60, 0.01, 0.001, WithClock(clock))),
+		"wrapped":                must(NewFixedWindow(5, 60, WithClock(clock), WithOnLimitExceeded(func(context.Context, string, *Result) {}))),
+	}
+}
+
+func TestSetLimit_Raise(t *testing.T) {
+	for name, l := range setLimitLimiters(NewFakeClock()) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, 5, allowedCount(t, l, "k", 8))
+
+			require.NoError(t, SetLimit(l, 8))
+			assert.Equal(t, int64(8), Describe(l).Limit)
+			want := 3 // existing usage counts against the raised limit
+			if name == "token bucket" {
+				want = 0 // tokens are kept and refill toward the new capacity
+			}
+			assert.Equal(t, want, allowedCount(t, l, "k", 8))
+			assert.Equal(t, 8, allowedCount(t, l, "fresh", 10))
+		})
+	}
+}
+
+func TestSetLimit_Lower(t *testing.T) {
+	for name, l := range setLimitLimiters(NewFakeClock()) {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, 3, allowedCount(t, l, "k", 3))
+
+			require.NoError(t, SetLimit(l, 2))
+			want := 0 // usage above the lowered limit is denied
+			if name == "token bucket" {
+				want = 2 // the 2 tokens left fit the lowered capacity
+			}
+			assert.Equal(t, want, allowedCount(t, l, "k", 3))
+			assert.Equal(t, 2, allowedCount(t, l, "fresh", 3))
+
+			res, err := l.Allow(context.Background(), "other")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), res.Limit)
+		})
+	}
+}
+
+func TestSetLimit_ShardedSplitsLimit(t *testing.T) {
+	l := must(NewFixedWindow(8, 60, WithKeyShards(4)))
+	require.NoError(t, SetLimit(l, 16))
+	assert.Equal(t, int64(16), Describe(l).Limit)
+	assert.Equal(t, 16, allowedCount(t, l, "k", 20))
+}
+
+func TestSetLimit_Invalid(t *testing.T) {
+	l := must(NewTokenBucket(5, 1))
+	assert.ErrorIs(t, SetLimit(l, 0), ErrInvalidParameter)
+	assert.ErrorIs(t, SetLimit(l, MaxBurst+1), ErrInvalidParameter)
+	assert.Equal(t, int64(5), Describe(l).Limit, "a rejected limit leaves the old one in place")
+
+	g := must(NewGCRA(1, 5))
+	assert.ErrorIs(t, SetLimit(g, MaxBurst), ErrInvalidParameter, "GCRA burst must fit its span")
+
+	assert.ErrorIs(t, SetLimit(NewChain(ChainLink{ID: "a", Limiter: l}, ChainLink{ID: "b", Limiter: g}), 10), ErrInvalidParameter, "chains have no single limit")
+}
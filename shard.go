@@ -0,0 +1,81 @@
+package goratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+// shardedLimiter rotates each request for a logical key over shards
+// physical keys. The wrapped limiter was built with per-shard limits.
+type shardedLimiter struct {
+	inner  Limiter
+	shards int
+	next   atomic.Uint64
+}
+
+func shardKey(key string, shard int) string {
+	return key + "#" + strconv.Itoa(shard)
+}
+
+func (s *shardedLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return s.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (s *shardedLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	shard := int(s.next.Add(1) % uint64(s.shards))
+	res, err := s.inner.AllowN(ctx, shardKey(key, shard), n)
+	if res.Limit > 0 {
+		res.Limit *= int64(s.shards)
+		res.Remaining *= int64(s.shards)
+	}
+	return res, err
+}
+
+func (s *shardedLimiter) Reset(ctx context.Context, key string) error {
+	return ResetMany(ctx, s.inner, s.shardKeys(key)...)
+}
+
+func (s *shardedLimiter) ResetMany(ctx context.Context, keys ...string) error {
+	all := make([]string, 0, len(keys)*s.shards)
+	for _, key := range keys {
+		all = append(all, s.shardKeys(key)...)
+	}
+	return ResetMany(ctx, s.inner, all...)
+}
+
+// ResetExisted reports true if any shard held state for key.
+func (s *shardedLimiter) ResetExisted(ctx context.Context, key string) (bool, error) {
+	existed := false
+	for _, k := range s.shardKeys(key) {
+		ok, err := ResetExisted(ctx, s.inner, k)
+		if err != nil {
+			return existed, err
+		}
+		existed = existed || ok
+	}
+	return existed, nil
+}
+
+func (s *shardedLimiter) Describe() Description {
+	d := Describe(s.inner)
+	d.Limit *= int64(s.shards)
+	d.Rate *= int64(s.shards)
+	return d
+}
+
+// SetLimit splits limit across shards the way the constructor does.
+func (s *shardedLimiter) SetLimit(limit int64) error {
+	if err := checkLimit(limit); err != nil {
+		return err
+	}
+	return SetLimit(s.inner, max(1, limit/int64(s.shards)))
+}
+
+func (s *shardedLimiter) shardKeys(key string) []string {
+	keys := make([]string, s.shards)
+	for i := range keys {
+		keys[i] = shardKey(key, i)
+	}
+	return keys
+}
@@ -2,7 +2,6 @@ package goratelimit
 
 import (
 	"context"
-	"fmt"
 	"math/rand"
 	"sync"
 	"time"
@@ -22,18 +21,22 @@ func NewSlidingWindow(maxRequests, windowSeconds int64, opts ...Option) (Limiter
 			"Use positive integers, e.g. NewSlidingWindow(10, 60).")
 	}
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewSlidingWindow", false, false); err != nil {
+		return nil, err
+	}
+	maxRequests = o.perShard(maxRequests)
 
 	if o.RedisClient != nil {
 		return wrapOptions(&slidingWindowRedis{
 			redis:         o.RedisClient,
-			maxRequests:   maxRequests,
+			baseLimit:     newBaseLimit(maxRequests),
 			windowSeconds: windowSeconds,
 			opts:          o,
 		}, o), nil
 	}
 	return wrapOptions(&slidingWindowMemory{
 		states:        make(map[string]*slidingWindowState),
-		maxRequests:   maxRequests,
+		baseLimit:     newBaseLimit(maxRequests),
 		windowSeconds: windowSeconds,
 		opts:          o,
 	}, o), nil
@@ -46,24 +49,30 @@ type slidingWindowState struct {
 }
 
 type slidingWindowMemory struct {
-	mu            sync.Mutex
-	states        map[string]*slidingWindowState
-	maxRequests   int64
+	mu     sync.Mutex
+	states map[string]*slidingWindowState
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (s *slidingWindowMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return s.AllowN(ctx, key, 1)
+	return s.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (s *slidingWindowMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, s.limit()); ok {
+		return res, nil
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.maxRequests)
+	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
 	}
 
 	state, ok := s.states[key]
@@ -92,23 +101,23 @@ func (s *slidingWindowMemory) AllowN(ctx context.Context, key string, n int) (Re
 			Allowed:   true,
 			Remaining: remaining,
 			Limit:     maxReq,
+			ResetAt:   now.Add(windowDuration),
 		}, nil
 	}
 
+	// The batch fits once enough of the oldest timestamps expire to free n
+	// slots, i.e. when the one at index len+n-maxReq-1 does.
 	var retryAfter time.Duration
-	if len(state.timestamps) > 0 {
-		oldest := state.timestamps[0]
-		expiresAt := oldest.Add(windowDuration)
-		retryAfter = time.Until(expiresAt)
-		if retryAfter < 0 {
-			retryAfter = 0
-		}
+	if i := int64(len(state.timestamps)) + cost - maxReq - 1; i >= 0 && i < int64(len(state.timestamps)) {
+		retryAfter = max(0, state.timestamps[i].Add(windowDuration).Sub(now))
 	}
 
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      maxReq,
+		ResetAt:    now.Add(retryAfter),
 		RetryAfter: retryAfter,
 	}, nil
 }
@@ -120,76 +129,179 @@ func (s *slidingWindowMemory) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+func (s *slidingWindowMemory) ResetMany(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.states, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *slidingWindowMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	_, ok := s.states[key]
+	delete(s.states, key)
+	s.mu.Unlock()
+	return ok, nil
+}
+
+func (s *slidingWindowMemory) Describe() Description {
+	return Description{Algorithm: "sliding_window", Limit: s.limit(), Window: time.Duration(s.windowSeconds) * time.Second, Dynamic: s.opts.LimitFunc != nil}
+}
+
+func (s *slidingWindowMemory) Inspect() []KeyState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.opts.now()
+	windowDuration := time.Duration(s.windowSeconds) * time.Second
+	states := make([]KeyState, 0, len(s.states))
+	for key, state := range s.states {
+		var count int64
+		for _, ts := range state.timestamps {
+			if now.Sub(ts) <= windowDuration {
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		states = append(states, KeyState{
+			Key:       key,
+			Remaining: max(0, s.limit()-count),
+			ResetAt:   state.timestamps[len(state.timestamps)-1].Add(windowDuration),
+		})
+	}
+	return states
+}
+
+// Refund drops the key's n most recent timestamps.
+func (s *slidingWindowMemory) Refund(_ context.Context, key string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[key]; ok {
+		state.timestamps = state.timestamps[:max(0, len(state.timestamps)-n)]
+	}
+	return nil
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
+// slidingWindowScript takes now in milliseconds; an empty now means read it
+// from Redis TIME. Members are "now:nonce:i" so concurrent callers never
+// collide. The ZSET is trimmed to the newest max_requests members, the most
+// the decision ever needs, so a lowered limit cannot leave a key holding more.
+// A denied batch retries once the member whose expiry frees enough slots for
+// it has expired. It returns { allowed, remaining, retry_after_ms }.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local max_requests = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local nonce = ARGV[5]
+local expire = tonumber(ARGV[6])
+
+if not now then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+end
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window_ms)
+local count = redis.call('ZCARD', key)
+if count > max_requests then
+  redis.call('ZREMRANGEBYRANK', key, 0, count - max_requests - 1)
+  count = max_requests
+end
+
+if count + cost <= max_requests then
+  for i = 1, cost do
+    redis.call('ZADD', key, now, now .. ':' .. nonce .. ':' .. i)
+  end
+  if expire > 0 then
+    redis.call('PEXPIRE', key, math.ceil(window_ms * expire))
+  end
+  return { 1, max_requests - count - cost, 0 }
+end
+
+local retry_after = window_ms
+local freeing = count + cost - max_requests - 1
+local oldest = redis.call('ZRANGE', key, freeing, freeing, 'WITHSCORES')
+if #oldest > 0 then
+  local retry = tonumber(oldest[2]) + window_ms - now
+  if retry > 0 and retry <= window_ms then
+    retry_after = retry
+  end
+end
+return { 0, 0, retry_after }
+`)
+
 type slidingWindowRedis struct {
-	redis         redis.UniversalClient
-	maxRequests   int64
+	redis redis.UniversalClient
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (s *slidingWindowRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return s.AllowN(ctx, key, 1)
+	return s.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (s *slidingWindowRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.maxRequests)
+	return s.queueAllowN(ctx, s.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (s *slidingWindowRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, s.limit()); ok {
+		return decided(res)
+	}
+	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return decided(unlimitedResult())
 	}
-	fullKey := s.opts.FormatKey(key)
-	now := s.opts.now().UnixMilli()
-	windowStart := now - s.windowSeconds*1000
-
-	// Remove expired entries
-	err := s.redis.ZRemRangeByScore(ctx, fullKey, "0", fmt.Sprintf("%d", windowStart)).Err()
-	if err != nil {
-		return s.failResult(err, maxReq)
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return decided(res)
 	}
+	fullKey := s.opts.FormatKey(key)
+	now := s.opts.now()
 
-	count, err := s.redis.ZCard(ctx, fullKey).Result()
-	if err != nil {
-		return s.failResult(err, maxReq)
+	keys := []string{fullKey}
+	args := []interface{}{
+		maxReq,
+		s.windowSeconds * 1000,
+		scriptNow(s.opts, now.UnixMilli()),
+		n,
+		rand.Int63(),
+		s.opts.expireArg(),
 	}
-
-	cost := int64(n)
-	if count+cost <= maxReq {
-		pipe := s.redis.Pipeline()
-		for i := 0; i < n; i++ {
-			member := fmt.Sprintf("%d:%d", now, rand.Int63())
-			pipe.ZAdd(ctx, fullKey, redis.Z{Score: float64(now), Member: member})
-		}
-		pipe.Expire(ctx, fullKey, time.Duration(s.windowSeconds)*time.Second)
-		if _, err := pipe.Exec(ctx); err != nil {
+	cmd := runScript(ctx, slidingWindowScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := s.opts.scriptResult(ctx, cmd, s.redis, slidingWindowScript, keys, args)
+		if err != nil {
 			return s.failResult(err, maxReq)
 		}
-		remaining := maxReq - count - cost
-		return Result{
-			Allowed:   true,
-			Remaining: remaining,
-			Limit:     maxReq,
-		}, nil
-	}
 
-	// Denied — compute retryAfter from oldest entry
-	retryAfter := time.Duration(s.windowSeconds) * time.Second
-	oldest, err := s.redis.ZRangeWithScores(ctx, fullKey, 0, 0).Result()
-	if err == nil && len(oldest) > 0 {
-		oldestMs := int64(oldest[0].Score)
-		expiresAt := oldestMs + s.windowSeconds*1000
-		retryMs := expiresAt - now
-		if retryMs > 0 && retryMs <= s.windowSeconds*1000 {
-			retryAfter = time.Duration(retryMs) * time.Millisecond
+		allowed := result[0] == 1
+		r := Result{
+			Allowed:    allowed,
+			DenyReason: denyReason(allowed),
+			Remaining:  result[1],
+			Limit:      maxReq,
+			ResetAt:    now.Add(time.Duration(s.windowSeconds) * time.Second),
+		}
+		if !allowed {
+			r.RetryAfter = time.Duration(result[2]) * time.Millisecond
+			r.ResetAt = now.Add(r.RetryAfter)
 		}
+		return r, nil
 	}
+}
 
-	return Result{
-		Allowed:    false,
-		Remaining:  0,
-		Limit:      maxReq,
-		RetryAfter: retryAfter,
-	}, nil
+func (s *slidingWindowRedis) redisClient() redis.UniversalClient {
+	return s.redis
 }
 
 func (s *slidingWindowRedis) Reset(ctx context.Context, key string) error {
@@ -197,9 +309,27 @@ func (s *slidingWindowRedis) Reset(ctx context.Context, key string) error {
 	return s.redis.Del(ctx, fullKey).Err()
 }
 
+func (s *slidingWindowRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = s.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, s.redis, fullKeys)
+}
+
+func (s *slidingWindowRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := s.redis.Del(ctx, s.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+func (s *slidingWindowRedis) Describe() Description {
+	return Description{Algorithm: "sliding_window", Limit: s.limit(), Window: time.Duration(s.windowSeconds) * time.Second, Dynamic: s.opts.LimitFunc != nil}
+}
+
 func (s *slidingWindowRedis) failResult(err error, limit int64) (Result, error) {
 	if s.opts.FailOpen {
+		s.opts.failOpen(err)
 		return Result{Allowed: true, Remaining: limit - 1, Limit: limit}, nil
 	}
-	return Result{Allowed: false, Remaining: 0, Limit: limit}, redisErr(err, s.opts)
+	return Result{Allowed: false, Remaining: 0, Limit: limit, DenyReason: ReasonBackendError}, redisErr(err, s.opts)
 }
@@ -11,6 +11,19 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// EstimateRounding selects how the Sliding Window Counter rounds the weighted
+// previous-window count before comparing the estimate to the limit.
+type EstimateRounding string
+
+const (
+	// Conservative ceils the estimate so the limiter never over-grants, at the
+	// cost of occasionally admitting one request fewer near window boundaries.
+	Conservative EstimateRounding = "conservative"
+	// Permissive floors the estimate, which may admit slightly more than the
+	// nominal rate near window boundaries.
+	Permissive EstimateRounding = "permissive"
+)
+
 // NewSlidingWindowCounter creates a Sliding Window Counter rate limiter.
 // This uses the weighted-counter approximation (~1% error) with O(1) memory per key.
 // maxRequests is the maximum requests allowed per window.
@@ -22,18 +35,22 @@ func NewSlidingWindowCounter(maxRequests, windowSeconds int64, opts ...Option) (
 			"Use positive integers, e.g. NewSlidingWindowCounter(10, 60).")
 	}
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewSlidingWindowCounter", false, false); err != nil {
+		return nil, err
+	}
+	maxRequests = o.perShard(maxRequests)
 
 	if o.RedisClient != nil {
 		return wrapOptions(&slidingWindowCounterRedis{
 			redis:         o.RedisClient,
-			maxRequests:   maxRequests,
+			baseLimit:     newBaseLimit(maxRequests),
 			windowSeconds: windowSeconds,
 			opts:          o,
 		}, o), nil
 	}
 	return wrapOptions(&slidingWindowCounterMemory{
 		states:        make(map[string]*slidingWindowCounterState),
-		maxRequests:   maxRequests,
+		baseLimit:     newBaseLimit(maxRequests),
 		windowSeconds: windowSeconds,
 		opts:          o,
 	}, o), nil
@@ -48,24 +65,30 @@ type slidingWindowCounterState struct {
 }
 
 type slidingWindowCounterMemory struct {
-	mu            sync.Mutex
-	states        map[string]*slidingWindowCounterState
-	maxRequests   int64
+	mu     sync.Mutex
+	states map[string]*slidingWindowCounterState
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (s *slidingWindowCounterMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return s.AllowN(ctx, key, 1)
+	return s.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (s *slidingWindowCounterMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, s.limit()); ok {
+		return res, nil
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.maxRequests)
+	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
 	}
 
 	state, ok := s.states[key]
@@ -84,7 +107,7 @@ func (s *slidingWindowCounterMemory) AllowN(ctx context.Context, key string, n i
 	}
 
 	elapsedFraction := now.Sub(state.windowStart).Seconds() / float64(s.windowSeconds)
-	prevWeight := float64(state.previousCount) * (1 - elapsedFraction)
+	prevWeight := roundEstimate(float64(state.previousCount)*(1-elapsedFraction), s.opts.EstimateRounding)
 	estimatedCount := prevWeight + float64(state.currentCount)
 
 	cost := float64(n)
@@ -92,10 +115,12 @@ func (s *slidingWindowCounterMemory) AllowN(ctx context.Context, key string, n i
 		state.currentCount += int64(n)
 		newEstimate := prevWeight + float64(state.currentCount)
 		remaining := int64(math.Max(0, math.Floor(float64(maxReq)-newEstimate)))
+		// Back to full once the current window has slid out, as in Inspect.
 		return Result{
 			Allowed:   true,
 			Remaining: remaining,
 			Limit:     maxReq,
+			ResetAt:   state.windowStart.Add(2 * windowDuration),
 		}, nil
 	}
 
@@ -105,8 +130,10 @@ func (s *slidingWindowCounterMemory) AllowN(ctx context.Context, key string, n i
 	}
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      maxReq,
+		ResetAt:    now.Add(retryAfter),
 		RetryAfter: retryAfter,
 	}, nil
 }
@@ -118,23 +145,90 @@ func (s *slidingWindowCounterMemory) Reset(ctx context.Context, key string) erro
 	return nil
 }
 
+func (s *slidingWindowCounterMemory) ResetMany(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.states, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *slidingWindowCounterMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	_, ok := s.states[key]
+	delete(s.states, key)
+	s.mu.Unlock()
+	return ok, nil
+}
+
+func (s *slidingWindowCounterMemory) Describe() Description {
+	return Description{Algorithm: "sliding_window_counter", Limit: s.limit(), Window: time.Duration(s.windowSeconds) * time.Second, Dynamic: s.opts.LimitFunc != nil}
+}
+
+func (s *slidingWindowCounterMemory) Inspect() []KeyState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.opts.now()
+	windowDuration := time.Duration(s.windowSeconds) * time.Second
+	states := make([]KeyState, 0, len(s.states))
+	for key, state := range s.states {
+		windowStart, previous, current := state.windowStart, state.previousCount, state.currentCount
+		for now.Sub(windowStart) >= windowDuration && (previous > 0 || current > 0) {
+			windowStart, previous, current = windowStart.Add(windowDuration), current, 0
+		}
+		if previous == 0 && current == 0 {
+			continue
+		}
+		elapsedFraction := now.Sub(windowStart).Seconds() / float64(s.windowSeconds)
+		estimate := roundEstimate(float64(previous)*(1-elapsedFraction), s.opts.EstimateRounding) + float64(current)
+		// The estimate is back to zero once the last counted window has
+		// fully slid out.
+		resetAt := windowStart.Add(windowDuration)
+		if current > 0 {
+			resetAt = resetAt.Add(windowDuration)
+		}
+		states = append(states, KeyState{
+			Key:       key,
+			Remaining: int64(math.Max(0, math.Floor(float64(s.limit())-estimate))),
+			ResetAt:   resetAt,
+		})
+	}
+	return states
+}
+
+func (s *slidingWindowCounterMemory) Refund(_ context.Context, key string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[key]; ok {
+		state.currentCount = max(0, state.currentCount-int64(n))
+	}
+	return nil
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
 type slidingWindowCounterRedis struct {
-	redis         redis.UniversalClient
-	maxRequests   int64
+	redis redis.UniversalClient
+	*baseLimit
 	windowSeconds int64
 	opts          *Options
 }
 
 func (s *slidingWindowCounterRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return s.AllowN(ctx, key, 1)
+	return s.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (s *slidingWindowCounterRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.maxRequests)
+	if res, ok := forcedResult(ctx, s.limit()); ok {
+		return res, nil
+	}
+	maxReq, unlimited := s.opts.resolveLimit(ctx, key, s.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, maxReq, 0); ok {
+		return res, nil
 	}
 	now := s.opts.now().Unix()
 	currentWindow := now / s.windowSeconds
@@ -144,14 +238,14 @@ func (s *slidingWindowCounterRedis) AllowN(ctx context.Context, key string, n in
 	currentKey := s.opts.FormatKeySuffix(key, fmt.Sprintf("%d", currentWindow))
 	previousKey := s.opts.FormatKeySuffix(key, fmt.Sprintf("%d", previousWindow))
 
-	prevStr, err := s.redis.Get(ctx, previousKey).Result()
+	prevStr, err := s.get(ctx, previousKey)
 	if err != nil && err != redis.Nil {
 		return s.failResult(err, maxReq)
 	}
 	prevCount, _ := strconv.ParseFloat(prevStr, 64)
-	weightedPrev := prevCount * (1 - elapsed)
+	weightedPrev := roundEstimate(prevCount*(1-elapsed), s.opts.EstimateRounding)
 
-	currStr, err := s.redis.Get(ctx, currentKey).Result()
+	currStr, err := s.get(ctx, currentKey)
 	if err != nil && err != redis.Nil {
 		return s.failResult(err, maxReq)
 	}
@@ -170,42 +264,127 @@ func (s *slidingWindowCounterRedis) AllowN(ctx context.Context, key string, n in
 		}
 		return Result{
 			Allowed:    false,
+			DenyReason: ReasonOverLimit,
 			Remaining:  0,
 			Limit:      maxReq,
+			ResetAt:    time.Unix(now+retryAfter, 0),
 			RetryAfter: time.Duration(retryAfter) * time.Second,
 		}, nil
 	}
 
-	newCount, err := s.redis.IncrBy(ctx, currentKey, int64(n)).Result()
+	newCount, err := callBackend(ctx, s.opts, func() (int64, error) {
+		return s.redis.IncrBy(ctx, currentKey, int64(n)).Result()
+	})
 	if err != nil {
 		return s.failResult(err, maxReq)
 	}
-	if newCount == int64(n) {
-		s.redis.Expire(ctx, currentKey, time.Duration(s.windowSeconds*2)*time.Second)
+	if expire := s.opts.expireArg(); newCount == int64(n) && expire > 0 {
+		s.redis.Expire(ctx, currentKey, time.Duration(float64(s.windowSeconds*2)*expire*float64(time.Second)))
 	}
 
 	newEstimate := weightedPrev + float64(newCount)
 	remaining := int64(math.Max(0, math.Floor(float64(maxReq)-newEstimate)))
 
+	// The current window's count stops weighing on the estimate once the
+	// window after it has ended.
 	return Result{
 		Allowed:   true,
 		Remaining: remaining,
 		Limit:     maxReq,
+		ResetAt:   time.Unix((currentWindow+2)*s.windowSeconds, 0),
 	}, nil
 }
 
 func (s *slidingWindowCounterRedis) Reset(ctx context.Context, key string) error {
-	now := s.opts.now().Unix()
-	currentWindow := now / s.windowSeconds
-	previousWindow := currentWindow - 1
-	currentKey := s.opts.FormatKeySuffix(key, fmt.Sprintf("%d", currentWindow))
-	previousKey := s.opts.FormatKeySuffix(key, fmt.Sprintf("%d", previousWindow))
-	return s.redis.Del(ctx, currentKey, previousKey).Err()
+	return delPipelined(ctx, s.redis, s.windowKeys(key))
+}
+
+func (s *slidingWindowCounterRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, 0, 3*len(keys))
+	for _, key := range keys {
+		fullKeys = append(fullKeys, s.windowKeys(key)...)
+	}
+	return delPipelined(ctx, s.redis, fullKeys)
+}
+
+func (s *slidingWindowCounterRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	pipe := s.redis.Pipeline()
+	var dels []*redis.IntCmd
+	for _, k := range s.windowKeys(key) {
+		dels = append(dels, pipe.Del(ctx, k))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	for _, del := range dels {
+		if del.Val() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// windowKeys returns the counter keys a reset must clear: the previous,
+// current and next windows. AllowN reads the previous and current windows,
+// so the neighbours on both sides cover a window boundary passing between a
+// request and the reset, or a node whose clock runs slightly ahead.
+func (s *slidingWindowCounterRedis) windowKeys(key string) []string {
+	currentWindow := s.opts.now().Unix() / s.windowSeconds
+	return []string{
+		s.opts.FormatKeySuffix(key, strconv.FormatInt(currentWindow-1, 10)),
+		s.opts.FormatKeySuffix(key, strconv.FormatInt(currentWindow, 10)),
+		s.opts.FormatKeySuffix(key, strconv.FormatInt(currentWindow+1, 10)),
+	}
+}
+
+// Seed writes each key's count for the current window and clears the
+// previous one, so the estimate starts at exactly limit-Remaining.
+func (s *slidingWindowCounterRedis) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	maxReq := s.limit()
+	if err := checkSeed(states, maxReq); err != nil {
+		return err
+	}
+	currentWindow := s.opts.now().Unix() / s.windowSeconds
+	ttl := s.opts.seedTTL(time.Duration(s.windowSeconds*2) * time.Second)
+	pipe := s.redis.Pipeline()
+	for key, st := range states {
+		pipe.Del(ctx, s.opts.FormatKeySuffix(key, strconv.FormatInt(currentWindow-1, 10)))
+		pipe.Set(ctx, s.opts.FormatKeySuffix(key, strconv.FormatInt(currentWindow, 10)), maxReq-st.Remaining, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *slidingWindowCounterRedis) Describe() Description {
+	return Description{Algorithm: "sliding_window_counter", Limit: s.limit(), Window: time.Duration(s.windowSeconds) * time.Second, Dynamic: s.opts.LimitFunc != nil}
+}
+
+// get reads a window's count, retrying per WithBackendRetry.
+func (s *slidingWindowCounterRedis) get(ctx context.Context, key string) (string, error) {
+	return callBackend(ctx, s.opts, func() (string, error) {
+		return s.redis.Get(ctx, key).Result()
+	})
 }
 
 func (s *slidingWindowCounterRedis) failResult(err error, limit int64) (Result, error) {
 	if s.opts.FailOpen {
+		s.opts.failOpen(err)
 		return Result{Allowed: true, Remaining: limit - 1, Limit: limit}, nil
 	}
-	return Result{Allowed: false, Remaining: 0, Limit: limit}, redisErr(err, s.opts)
+	return Result{Allowed: false, Remaining: 0, Limit: limit, DenyReason: ReasonBackendError}, redisErr(err, s.opts)
+}
+
+// ─── Internals ───────────────────────────────────────────────────────────────
+
+// roundEstimate applies r to a weighted count. The small epsilon keeps float
+// noise (e.g. 2.0000000000000004) from ceiling to the next integer.
+func roundEstimate(weighted float64, r EstimateRounding) float64 {
+	const epsilon = 1e-9
+	switch r {
+	case Conservative:
+		return math.Ceil(weighted - epsilon)
+	case Permissive:
+		return math.Floor(weighted + epsilon)
+	}
+	return weighted
 }
@@ -0,0 +1,143 @@
+package goratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// FromStdRate adapts a golang.org/x/time/rate Limiter to the Limiter
+// interface, so teams standardized on x/time/rate can use this package's
+// middleware, metrics and cache wrappers.
+//
+//	limiter := goratelimit.FromStdRate(rate.NewLimiter(rate.Every(100*time.Millisecond), 20))
+//
+// A rate.Limiter is not keyed: every key shares its one bucket. Use
+// FromStdRatePerKey for a bucket per key. AllowN maps to rate.Limiter.AllowN;
+// Result.Limit is its burst, Remaining the whole tokens left, and RetryAfter
+// the time until n tokens are available. Reset is a no-op, since a
+// rate.Limiter cannot be refilled. Only WithClock is read from opts.
+func FromStdRate(limiter *rate.Limiter, opts ...Option) Limiter {
+	return &stdRateLimiter{limiter: limiter, opts: applyOptions(opts)}
+}
+
+type stdRateLimiter struct {
+	limiter *rate.Limiter
+	opts    *Options
+}
+
+func (s *stdRateLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return s.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (s *stdRateLimiter) AllowN(ctx context.Context, _ string, n int) (Result, error) {
+	return stdRateAllowN(ctx, s.limiter, s.opts.now(), n), nil
+}
+
+func (s *stdRateLimiter) Reset(_ context.Context, _ string) error {
+	return nil
+}
+
+func (s *stdRateLimiter) Describe() Description {
+	return Description{Algorithm: "token_bucket", Limit: int64(s.limiter.Burst()), Rate: stdRatePerSecond(s.limiter.Limit())}
+}
+
+// FromStdRatePerKey returns a Limiter that keeps a separate
+// rate.NewLimiter(r, burst) per key, created on first use. Reset drops the
+// key's limiter. Keys are kept until reset, so prefer the built-in
+// NewTokenBucket, which behaves the same, for unbounded key spaces. Only
+// WithClock is read from opts.
+func FromStdRatePerKey(r rate.Limit, burst int, opts ...Option) Limiter {
+	return &stdRateKeyed{
+		limiters: make(map[string]*rate.Limiter),
+		limit:    r,
+		burst:    burst,
+		opts:     applyOptions(opts),
+	}
+}
+
+type stdRateKeyed struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	limit    rate.Limit
+	burst    int
+	opts     *Options
+}
+
+func (s *stdRateKeyed) Allow(ctx context.Context, key string) (Result, error) {
+	return s.AllowN(ctx, key, CostFromContext(ctx))
+}
+
+func (s *stdRateKeyed) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	s.mu.Lock()
+	limiter, ok := s.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(s.limit, s.burst)
+		s.limiters[key] = limiter
+	}
+	s.mu.Unlock()
+	return stdRateAllowN(ctx, limiter, s.opts.now(), n), nil
+}
+
+func (s *stdRateKeyed) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.limiters, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *stdRateKeyed) Describe() Description {
+	return Description{Algorithm: "token_bucket", Limit: int64(s.burst), Rate: stdRatePerSecond(s.limit)}
+}
+
+// stdRatePerSecond reports limit as whole requests per second, or zero for
+// rates under one per second and rate.Inf.
+func stdRatePerSecond(limit rate.Limit) int64 {
+	if limit < 1 || limit == rate.Inf {
+		return 0
+	}
+	return int64(limit)
+}
+
+// stdRateAllowN calls limiter.AllowN at now and describes the outcome as a
+// Result.
+func stdRateAllowN(ctx context.Context, limiter *rate.Limiter, now time.Time, n int) Result {
+	limit := limiter.Limit()
+	burst := int64(limiter.Burst())
+	if res, ok := forcedResult(ctx, burst); ok {
+		return res
+	}
+	if limit == rate.Inf {
+		return unlimitedResult()
+	}
+	perSecond := stdRatePerSecond(limit)
+	if res, ok := costTooLarge(n, burst, perSecond); ok {
+		return res
+	}
+
+	if limiter.AllowN(now, n) {
+		return Result{
+			Allowed:   true,
+			Remaining: int64(math.Max(0, math.Floor(limiter.TokensAt(now)))),
+			Limit:     burst,
+			Rate:      perSecond,
+		}
+	}
+
+	var retryAfter time.Duration
+	if limit > 0 {
+		deficit := float64(n) - limiter.TokensAt(now)
+		retryAfter = time.Duration(math.Ceil(deficit / float64(limit) * float64(time.Second)))
+	}
+	return Result{
+		Allowed:    false,
+		DenyReason: ReasonOverLimit,
+		Remaining:  0,
+		Limit:      burst,
+		Rate:       perSecond,
+		RetryAfter: retryAfter,
+	}
+}
@@ -0,0 +1,70 @@
+package goratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestFromStdRate_SurfacesLimiterState(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	std := rate.NewLimiter(2, 3)
+	l := FromStdRate(std, WithClock(clock))
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, int64(3-i-1), res.Remaining)
+		assert.Equal(t, int64(3), res.Limit)
+		assert.Equal(t, int64(2), res.Rate)
+	}
+
+	res, err := l.Allow(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "keys share the one std bucket")
+	assert.Equal(t, ReasonOverLimit, res.DenyReason)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	clock.Advance(500 * time.Millisecond)
+	res, err = l.Allow(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = l.AllowN(ctx, "a", 4)
+	require.NoError(t, err)
+	assert.Equal(t, ReasonCostTooLarge, res.DenyReason)
+	assert.Equal(t, "token_bucket", Describe(l).Algorithm)
+}
+
+func TestFromStdRate_Inf(t *testing.T) {
+	res, err := FromStdRate(rate.NewLimiter(rate.Inf, 0)).Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, Unlimited, res.Remaining)
+}
+
+func TestFromStdRatePerKey(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock()
+	l := FromStdRatePerKey(1, 2, WithClock(clock))
+
+	for _, key := range []string{"a", "a", "b"} {
+		res, err := l.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, key)
+	}
+	res, err := l.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	require.NoError(t, l.Reset(ctx, "a"))
+	res, err = l.Allow(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "Reset should give the key a fresh limiter")
+}
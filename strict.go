@@ -0,0 +1,66 @@
+package goratelimit
+
+// WithStrictMode makes constructors reject configuration they would
+// otherwise accept with a silent loss, returning an error wrapping
+// ErrInvalidParameter instead:
+//
+//   - both a Store and a Redis client, where the Redis client wins;
+//   - WithRedis for an in-memory-only algorithm (NewCMS,
+//     NewApproxFixedWindow), which would ignore it;
+//   - an option that wraps the limiter (WithKeyShards, WithHotKeyDetector,
+//     WithOnLimitExceeded, WithDryRun, WithAllowList, WithBlockList and
+//     WithKeyNormalizer) for NewConcurrency or NewBalance, which return
+//     the bare limiter so their Acquire, Release and AddBalance stay
+//     reachable, and so ignore it;
+//   - a Builder window that is not a whole number of seconds, which would
+//     be truncated.
+//
+// Non-positive limits, rates and windows are rejected in either mode.
+// Default: false, accepting the configurations above as before.
+func WithStrictMode(strict bool) Option {
+	return func(o *Options) { o.Strict = strict }
+}
+
+// checkStrict returns the error strict mode gives o, if any, for a
+// constructor named by ctor. memoryOnly marks algorithms without a Redis
+// backend, and unwrapped ones that skip wrapOptions.
+func (o *Options) checkStrict(ctor string, memoryOnly, unwrapped bool) error {
+	if !o.Strict {
+		return nil
+	}
+	switch {
+	case o.Store != nil && o.RedisClient != nil:
+		return validationErr("both Redis and Store are set",
+			"Configure a single backend: pass WithRedis or WithStore, not both.")
+	case memoryOnly && o.RedisClient != nil:
+		return validationErr(ctor+" is in-memory only but WithRedis is set",
+			"Drop WithRedis, or use an algorithm with a Redis backend.")
+	case unwrapped && o.wrapperOption() != "":
+		opt := o.wrapperOption()
+		return validationErr(ctor+" does not support "+opt,
+			"Drop "+opt+".")
+	}
+	return nil
+}
+
+// wrapperOption names the first option set in o that wrapOptions applies,
+// or returns "" if there is none.
+func (o *Options) wrapperOption() string {
+	switch {
+	case o.KeyShards > 1:
+		return "WithKeyShards"
+	case o.OnHotKey != nil && o.HotKeyThreshold > 0 && o.HotKeyWindow > 0:
+		return "WithHotKeyDetector"
+	case o.OnLimitExceeded != nil:
+		return "WithOnLimitExceeded"
+	case o.DryRun:
+		return "WithDryRun"
+	case o.AllowListFunc != nil:
+		return "WithAllowList"
+	case o.BlockListFunc != nil:
+		return "WithBlockList"
+	case o.KeyNormalizer != nil:
+		return "WithKeyNormalizer"
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package goratelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/krishna-kudari/ratelimit/store/memory"
+)
+
+func TestStrictMode_RejectsLossyConfig(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+
+	tests := []struct {
+		name  string
+		build func(opts ...Option) error
+		want  string
+	}{
+		{"redis and store", func(opts ...Option) error {
+			_, err := NewTokenBucket(10, 1, append(opts, WithRedis(client), WithStore(memory.New()))...)
+			return err
+		}, "both Redis and Store are set"},
+		{"cms with redis", func(opts ...Option) error {
+			_, err := NewCMS(10, 60, 0.01, 0.01, append(opts, WithRedis(client))...)
+			return err
+		}, "NewCMS is in-memory only"},
+		{"approx fixed window with redis", func(opts ...Option) error {
+			_, err := NewApproxFixedWindow(10, 60, 64, 2, append(opts, WithRedis(client))...)
+			return err
+		}, "NewApproxFixedWindow is in-memory only"},
+		{"concurrency with key shards", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithKeyShards(4))...)
+			return err
+		}, "NewConcurrency does not support WithKeyShards"},
+		{"concurrency with dry run", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithDryRun(true))...)
+			return err
+		}, "NewConcurrency does not support WithDryRun"},
+		{"concurrency with allow list", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithAllowList("admin"))...)
+			return err
+		}, "NewConcurrency does not support WithAllowList"},
+		{"concurrency with block list", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithBlockList("abuser"))...)
+			return err
+		}, "NewConcurrency does not support WithBlockList"},
+		{"concurrency with key normalizer", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithKeyNormalizer(strings.ToLower))...)
+			return err
+		}, "NewConcurrency does not support WithKeyNormalizer"},
+		{"concurrency with hot key detector", func(opts ...Option) error {
+			_, err := NewConcurrency(10, time.Second, append(opts, WithHotKeyDetector(5, time.Second, func(string) {}))...)
+			return err
+		}, "NewConcurrency does not support WithHotKeyDetector"},
+		{"balance with dry run", func(opts ...Option) error {
+			_, err := NewBalance(append(opts, WithDryRun(true))...)
+			return err
+		}, "NewBalance does not support WithDryRun"},
+		{"builder sub-second window", func(opts ...Option) error {
+			b := NewBuilder().FixedWindow(10, 1500*time.Millisecond)
+			b.opts = append(b.opts, opts...)
+			_, err := b.Build()
+			return err
+		}, "window 1.5s is not a whole number of seconds"},
+		{"builder cms sub-second window", func(opts ...Option) error {
+			b := NewBuilder().CMS(10, 2500*time.Millisecond, 0.01, 0.01)
+			b.opts = append(b.opts, opts...)
+			_, err := b.Build()
+			return err
+		}, "window 2.5s is not a whole number of seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.build(), "lenient by default")
+			assert.NoError(t, tt.build(WithStrictMode(false)))
+
+			err := tt.build(WithStrictMode(true))
+			assert.ErrorIs(t, err, ErrInvalidParameter)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestStrictMode_BuilderWholeSecondWindow(t *testing.T) {
+	l, err := NewBuilder().SlidingWindowCounter(10, 2*time.Second).StrictMode(true).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, Describe(l).Window)
+}
+
+// TestStrictMode_NonPositiveParams checks that every constructor rejects
+// non-positive parameters on the Redis backend, in either mode.
+func TestStrictMode_NonPositiveParams(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+
+	constructors := map[string]func(opts ...Option) error{
+		"fixed window": func(opts ...Option) error { _, err := NewFixedWindow(0, 60, opts...); return err },
+		"sliding window": func(opts ...Option) error {
+			_, err := NewSlidingWindow(10, -1, opts...)
+			return err
+		},
+		"sliding window counter": func(opts ...Option) error {
+			_, err := NewSlidingWindowCounter(-5, 60, opts...)
+			return err
+		},
+		"token bucket": func(opts ...Option) error { _, err := NewTokenBucket(10, 0, opts...); return err },
+		"leaky bucket": func(opts ...Option) error {
+			_, err := NewLeakyBucket(0, 1, Policing, opts...)
+			return err
+		},
+		"gcra":        func(opts ...Option) error { _, err := NewGCRA(-1, 10, opts...); return err },
+		"concurrency": func(opts ...Option) error { _, err := NewConcurrency(0, time.Second, opts...); return err },
+		"min interval": func(opts ...Option) error {
+			_, err := NewMinInterval(0, opts...)
+			return err
+		},
+	}
+	for name, build := range constructors {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, build(WithRedis(client)), ErrInvalidParameter)
+			assert.ErrorIs(t, build(WithRedis(client), WithStrictMode(true)), ErrInvalidParameter)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestNewApproxFixedWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxRequests   int64
+		windowSeconds int64
+		width, depth  int
+		expectError   bool
+	}{
+		{"valid parameters", 100, 60, 1024, 4, false},
+		{"zero maxRequests", 0, 60, 1024, 4, true},
+		{"zero windowSeconds", 100, 0, 1024, 4, true},
+		{"zero width", 100, 60, 0, 4, true},
+		{"negative depth", 100, 60, 1024, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := goratelimit.NewApproxFixedWindow(tt.maxRequests, tt.windowSeconds, tt.width, tt.depth)
+			if tt.expectError {
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+				assert.Nil(t, limiter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "approx_fixed_window", goratelimit.Describe(limiter).Algorithm)
+		})
+	}
+}
+
+func TestApproxFixedWindow_HeavyHitterLimited(t *testing.T) {
+	ctx := context.Background()
+	clock := goratelimit.NewFakeClock()
+	limiter, err := goratelimit.NewApproxFixedWindow(10, 60, 1024, 4, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		res, err := limiter.Allow(ctx, "attacker")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+1)
+		assert.Equal(t, int64(10-i-1), res.Remaining)
+	}
+	res, err := limiter.Allow(ctx, "attacker")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 60*time.Second, res.RetryAfter)
+
+	clock.Advance(60 * time.Second)
+	res, err = limiter.Allow(ctx, "attacker")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "the sketch clears at the window boundary")
+}
+
+func TestApproxFixedWindow_LightKeysRarelyThrottled(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := goratelimit.NewApproxFixedWindow(5, 60, 16384, 4)
+	require.NoError(t, err)
+
+	// A few heavy hitters saturate their cells.
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 5; j++ {
+			_, err := limiter.Allow(ctx, fmt.Sprintf("heavy-%d", i))
+			require.NoError(t, err)
+		}
+	}
+
+	const lightKeys = 2000
+	denied := 0
+	for i := 0; i < lightKeys; i++ {
+		key := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		for j := 0; j < 2; j++ {
+			res, err := limiter.Allow(ctx, key)
+			require.NoError(t, err)
+			if !res.Allowed {
+				denied++
+			}
+		}
+	}
+	assert.Less(t, float64(denied)/(2*lightKeys), 0.01, "false throttling rate should be under 1%")
+}
+
+func TestApproxFixedWindow_BoundedMemory(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := goratelimit.NewApproxFixedWindow(100, 60, 4096, 4)
+	require.NoError(t, err)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < 200_000; i++ {
+		_, err := limiter.Allow(ctx, fmt.Sprintf("key-%d", i))
+		require.NoError(t, err)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	growth := int64(after.HeapAlloc) - int64(before.HeapAlloc)
+	assert.Less(t, growth, int64(1<<20), "heap should not grow with distinct keys")
+	runtime.KeepAlive(limiter)
+}
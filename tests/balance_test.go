@@ -0,0 +1,147 @@
+package goratelimit_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// assertBalance drains a key's balance, checks that it stays exhausted, and
+// that a top-up lets requests through again.
+func assertBalance(t *testing.T, limiter goratelimit.BalanceLimiter) {
+	t.Helper()
+	ctx := context.Background()
+
+	result, err := limiter.Allow(ctx, "acct")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "no balance before the first top-up")
+	assert.Equal(t, goratelimit.ReasonOverLimit, result.DenyReason)
+
+	balance, err := limiter.AddBalance(ctx, "acct", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), balance)
+
+	result, err = limiter.AllowN(ctx, "acct", 3)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(2), result.Remaining)
+	assert.Equal(t, int64(5), result.Limit, "Limit is the balance before the charge")
+
+	result, err = limiter.AllowN(ctx, "acct", 3)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "a charge may not overdraw the balance")
+	assert.Equal(t, int64(2), result.Remaining, "a denied charge takes nothing")
+	assert.Zero(t, result.RetryAfter, "balances do not refill")
+
+	for _, want := range []int64{1, 0} {
+		result, err = limiter.Allow(ctx, "acct")
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		assert.Equal(t, want, result.Remaining)
+	}
+	for i := 0; i < 3; i++ {
+		result, err = limiter.Allow(ctx, "acct")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed, "exhausted balance denies request %d", i)
+		assert.Equal(t, int64(0), result.Remaining)
+	}
+
+	balance, err = limiter.AddBalance(ctx, "acct", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), balance)
+	result, err = limiter.Allow(ctx, "acct")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "allowed again after a top-up")
+	assert.Equal(t, int64(1), result.Remaining)
+
+	balance, err = limiter.AddBalance(ctx, "other", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), balance, "keys have separate balances")
+
+	_, err = limiter.AddBalance(ctx, "acct", 0)
+	assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+	for _, n := range []int{0, -3} {
+		_, err = limiter.AllowN(ctx, "other", n)
+		assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter, "cost %d", n)
+	}
+	balance, err = limiter.AddBalance(ctx, "other", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), balance, "a non-positive cost neither charges nor credits")
+
+	require.NoError(t, limiter.Reset(ctx, "acct"))
+	result, err = limiter.Allow(ctx, "acct")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "Reset drops the balance to zero")
+}
+
+func TestBalance_Memory(t *testing.T) {
+	limiter, err := goratelimit.NewBalance()
+	require.NoError(t, err)
+	assertBalance(t, limiter)
+	assert.Equal(t, "balance", goratelimit.Describe(limiter).Algorithm)
+}
+
+func TestBalance_Redis(t *testing.T) {
+	mr, client := miniredisClient(t)
+	limiter, err := goratelimit.NewBalance(goratelimit.WithRedis(client))
+	require.NoError(t, err)
+	assertBalance(t, limiter)
+
+	stored, err := mr.Get("ratelimit:other")
+	require.NoError(t, err)
+	assert.Equal(t, "2", stored)
+	assert.Zero(t, mr.TTL("ratelimit:other"), "balances never expire")
+}
+
+// lostReplyHook runs the first failures commands a client sends, then fails
+// them with err as if the reply had been lost on the way back.
+type lostReplyHook struct {
+	failures int64
+	err      error
+	calls    atomic.Int64
+}
+
+func (h *lostReplyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *lostReplyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if h.calls.Add(1) <= h.failures {
+			cmd.SetErr(h.err)
+			return h.err
+		}
+		return err
+	}
+}
+
+func (h *lostReplyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestBalance_Redis_ChargeNotRetried(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	limiter, err := goratelimit.NewBalance(goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(3, 0), goratelimit.WithFailOpen(false))
+	require.NoError(t, err)
+	_, err = limiter.AddBalance(ctx, "acct", 10)
+	require.NoError(t, err)
+	_, err = limiter.Allow(ctx, "acct") // loads the script
+	require.NoError(t, err)
+
+	client.AddHook(&lostReplyHook{failures: 1, err: connReset})
+	_, err = limiter.AllowN(ctx, "acct", 4)
+	assert.ErrorIs(t, err, goratelimit.ErrBackendUnavailable)
+
+	result, err := limiter.Allow(ctx, "acct")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Limit, "the lost charge was applied once, not retried")
+}
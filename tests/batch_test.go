@@ -0,0 +1,154 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// roundTrips counts the commands and pipelines a client sends.
+type roundTrips struct{ n atomic.Int64 }
+
+func (h *roundTrips) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *roundTrips) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *roundTrips) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmds)
+	}
+}
+
+func mustLimiter(l goratelimit.Limiter, err error) goratelimit.Limiter {
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func TestBatchCheck_MixedAlgorithmsOneRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	require.NoError(t, client.Ping(ctx).Err(), "open the connection before counting")
+	trips := &roundTrips{}
+	client.AddHook(trips)
+
+	perIP, err := goratelimit.NewTokenBucket(2, 1, goratelimit.WithRedis(client), goratelimit.WithKeyPrefix("ip"))
+	require.NoError(t, err)
+	perOrg, err := goratelimit.NewFixedWindow(3, 60, goratelimit.WithRedis(client), goratelimit.WithKeyPrefix("org"))
+	require.NoError(t, err)
+	checks := []goratelimit.Check{
+		{Limiter: perIP, Key: "10.0.0.1"},
+		{Limiter: perOrg, Key: "acme"},
+	}
+
+	for i := 0; i < 2; i++ {
+		before := trips.n.Load()
+		results, err := goratelimit.BatchCheck(ctx, checks)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), trips.n.Load()-before, "one pipeline for both algorithms")
+		require.Len(t, results, 2)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, int64(2), results[0].Limit, "token bucket result first")
+		assert.Equal(t, int64(1-i), results[0].Remaining)
+		assert.True(t, results[1].Allowed)
+		assert.Equal(t, int64(3), results[1].Limit, "fixed window result second")
+		assert.Equal(t, int64(2-i), results[1].Remaining)
+	}
+
+	results, err := goratelimit.BatchCheck(ctx, checks)
+	require.NoError(t, err)
+	assert.False(t, results[0].Allowed, "per-IP bucket is empty")
+	assert.Equal(t, goratelimit.ReasonOverLimit, results[0].DenyReason)
+	assert.True(t, results[1].Allowed, "checks are charged independently")
+	assert.Equal(t, int64(0), results[1].Remaining)
+}
+
+func TestBatchCheck_MatchesAllowN(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	opts := []goratelimit.Option{goratelimit.WithRedis(client)}
+	build := []func(prefix string) goratelimit.Limiter{
+		func(p string) goratelimit.Limiter {
+			return mustLimiter(goratelimit.NewGCRA(1, 3, append(opts, goratelimit.WithKeyPrefix(p))...))
+		},
+		func(p string) goratelimit.Limiter {
+			return mustLimiter(goratelimit.NewSlidingWindow(3, 60, append(opts, goratelimit.WithKeyPrefix(p))...))
+		},
+		func(p string) goratelimit.Limiter {
+			return mustLimiter(goratelimit.NewLeakyBucket(3, 1, goratelimit.Policing, append(opts, goratelimit.WithKeyPrefix(p))...))
+		},
+		func(p string) goratelimit.Limiter {
+			return mustLimiter(goratelimit.NewSlidingWindowCounter(3, 60, append(opts, goratelimit.WithKeyPrefix(p))...))
+		},
+	}
+	batched := make([]goratelimit.Check, len(build))
+	direct := make([]goratelimit.Limiter, len(build))
+	for i, b := range build {
+		batched[i] = goratelimit.Check{Limiter: b(fmt.Sprintf("batch%d", i)), Key: "k", N: 2}
+		direct[i] = b(fmt.Sprintf("direct%d", i))
+	}
+
+	for round := 0; round < 2; round++ {
+		results, err := goratelimit.BatchCheck(ctx, batched)
+		require.NoError(t, err)
+		for i, l := range direct {
+			want, err := l.AllowN(ctx, "k", 2)
+			require.NoError(t, err)
+			assert.Equal(t, want.Allowed, results[i].Allowed, "round %d check %d", round, i)
+			assert.Equal(t, want.Remaining, results[i].Remaining, "round %d check %d", round, i)
+		}
+	}
+}
+
+func TestBatchCheck_MixedClientsAndMemory(t *testing.T) {
+	ctx := context.Background()
+	_, a := miniredisClient(t)
+	_, b := miniredisClient(t)
+	require.NoError(t, a.Ping(ctx).Err())
+	require.NoError(t, b.Ping(ctx).Err())
+	trips := &roundTrips{}
+	a.AddHook(trips)
+	b.AddHook(trips)
+
+	checks := []goratelimit.Check{
+		{Limiter: mustLimiter(goratelimit.NewGCRA(10, 5, goratelimit.WithRedis(a))), Key: "k"},
+		{Limiter: mustLimiter(goratelimit.NewTokenBucket(5, 1)), Key: "k"},
+		{Limiter: mustLimiter(goratelimit.NewFixedWindow(5, 60, goratelimit.WithRedis(b))), Key: "k"},
+	}
+	results, err := goratelimit.BatchCheck(ctx, checks)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), trips.n.Load(), "one pipeline per client")
+	for i, res := range results {
+		assert.True(t, res.Allowed, "check %d", i)
+		assert.Equal(t, int64(4), res.Remaining, "check %d", i)
+	}
+}
+
+func TestBatchCheck_FailClosedError(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0", MaxRetries: -1})
+	defer client.Close()
+	l := mustLimiter(goratelimit.NewTokenBucket(5, 1, goratelimit.WithRedis(client), goratelimit.WithFailOpen(false)))
+
+	results, err := goratelimit.BatchCheck(context.Background(), []goratelimit.Check{{Limiter: l, Key: "k"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `check 0 (key "k")`)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Allowed)
+	assert.Equal(t, goratelimit.ReasonBackendError, results[0].DenyReason)
+}
@@ -40,6 +40,7 @@ func TestNewCMS(t *testing.T) {
 			limiter, err := goratelimit.NewCMS(tt.limit, tt.windowSeconds, tt.epsilon, tt.delta)
 			if tt.expectError {
 				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 				assert.Contains(t, err.Error(), tt.errorSubstring)
 				assert.Nil(t, limiter)
 			} else {
@@ -0,0 +1,186 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestNewConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxInFlight int64
+		leaseTTL    time.Duration
+		expectError bool
+	}{
+		{"valid parameters", 5, time.Second, false},
+		{"zero max in flight", 0, time.Second, true},
+		{"negative max in flight", -1, time.Second, true},
+		{"zero lease", 5, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := goratelimit.NewConcurrency(tt.maxInFlight, tt.leaseTTL)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+				assert.Nil(t, limiter)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, limiter)
+			}
+		})
+	}
+}
+
+func TestConcurrency_InMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("acquire up to limit then deny", func(t *testing.T) {
+		limiter, err := goratelimit.NewConcurrency(2, time.Minute)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			res, lease, err := limiter.Acquire(ctx, "worker")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "acquire %d should be allowed", i+1)
+			assert.NotNil(t, lease)
+			assert.Equal(t, int64(1-i), res.Remaining)
+		}
+
+		res, lease, err := limiter.Acquire(ctx, "worker")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed, "third acquire should be denied")
+		assert.Nil(t, lease)
+		assert.Positive(t, res.RetryAfter)
+	})
+
+	t.Run("release frees slot", func(t *testing.T) {
+		limiter, err := goratelimit.NewConcurrency(1, time.Minute)
+		require.NoError(t, err)
+
+		res, lease, err := limiter.Acquire(ctx, "worker")
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+
+		res, _, err = limiter.Acquire(ctx, "worker")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+
+		require.NoError(t, limiter.Release(ctx, lease))
+
+		res, _, err = limiter.Acquire(ctx, "worker")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "slot should be free after release")
+	})
+
+	t.Run("expired lease frees slot", func(t *testing.T) {
+		clock := goratelimit.NewFakeClock()
+		limiter, err := goratelimit.NewConcurrency(1, 5*time.Second, goratelimit.WithClock(clock))
+		require.NoError(t, err)
+
+		res, _, err := limiter.Acquire(ctx, "crashy")
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+
+		res, _, err = limiter.Acquire(ctx, "crashy")
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, 5*time.Second, res.RetryAfter)
+
+		clock.Advance(5 * time.Second)
+
+		res, _, err = limiter.Acquire(ctx, "crashy")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "expired lease should no longer hold the slot")
+	})
+
+	t.Run("allowN holds n slots", func(t *testing.T) {
+		limiter, err := goratelimit.NewConcurrency(3, time.Minute)
+		require.NoError(t, err)
+
+		res, err := limiter.AllowN(ctx, "batch", 2)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, int64(1), res.Remaining)
+
+		res, err = limiter.AllowN(ctx, "batch", 2)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+	})
+
+	t.Run("reset clears leases", func(t *testing.T) {
+		limiter, err := goratelimit.NewConcurrency(1, time.Minute)
+		require.NoError(t, err)
+
+		_, err = limiter.Allow(ctx, "worker")
+		require.NoError(t, err)
+		require.NoError(t, limiter.Reset(ctx, "worker"))
+
+		res, err := limiter.Allow(ctx, "worker")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	})
+}
+
+func TestConcurrency_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	t.Run("acquire, release, and lease expiry", func(t *testing.T) {
+		key := fmt.Sprintf("test-concurrency-%d", time.Now().UnixNano())
+		clock := goratelimit.NewFakeClockAt(time.Now())
+		limiter, err := goratelimit.NewConcurrency(2, 10*time.Second,
+			goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+		require.NoError(t, err)
+		defer limiter.Reset(ctx, key)
+
+		res, first, err := limiter.Acquire(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+
+		res, _, err = limiter.Acquire(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+
+		res, _, err = limiter.Acquire(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed, "third acquire should be denied")
+
+		require.NoError(t, limiter.Release(ctx, first))
+		res, _, err = limiter.Acquire(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "released slot should be reusable")
+
+		// Nobody releases the remaining two leases; they expire on their own.
+		clock.Advance(11 * time.Second)
+		for i := 0; i < 2; i++ {
+			res, _, err = limiter.Acquire(ctx, key)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "acquire %d after expiry should be allowed", i+1)
+		}
+	})
+}
+
+func TestConcurrency_Redis_NonPositiveCost(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	limiter, err := goratelimit.NewConcurrency(2, time.Minute, goratelimit.WithRedis(client))
+	require.NoError(t, err)
+
+	for _, n := range []int{0, -1} {
+		_, err := limiter.AllowN(ctx, "k", n)
+		assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter, "n=%d", n)
+	}
+}
@@ -0,0 +1,38 @@
+package goratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestTokenBucketWithDailyCap_Redis_RetriesAfterDayUsedUp(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	clock := goratelimit.NewFakeClockAt(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	limiter, err := goratelimit.NewTokenBucketWithDailyCap(5, 1, 3,
+		goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	midnight := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		res, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+1)
+	}
+	// The Redis bucket cannot refund, but after the first daily denial
+	// retries check the day first and never reach it.
+	for i := 0; i < 10; i++ {
+		res, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.False(t, res.Allowed, "retry %d", i+1)
+		last := res.Components[len(res.Components)-1]
+		assert.Equal(t, goratelimit.DailyCapDailyLink, last.ID, "retry %d", i+1)
+		assert.Equal(t, midnight.Sub(clock.Now()), res.RetryAfter, "retry %d", i+1)
+	}
+}
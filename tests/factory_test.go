@@ -0,0 +1,54 @@
+package goratelimit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestFactory_SharesRedisAndPrefix(t *testing.T) {
+	ctx := context.Background()
+	mr, client := miniredisClient(t)
+	f := goratelimit.NewFactory(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix("gw"))
+
+	fw, err := f.FixedWindow(5, 60)
+	require.NoError(t, err)
+	tb, err := f.TokenBucket(10, 1)
+	require.NoError(t, err)
+
+	_, err = fw.Allow(ctx, "login:alice")
+	require.NoError(t, err)
+	_, err = tb.Allow(ctx, "search:alice")
+	require.NoError(t, err)
+
+	keys := mr.Keys()
+	require.Len(t, keys, 2, "both limiters write to the shared Redis")
+	for _, key := range keys {
+		assert.True(t, strings.HasPrefix(key, "gw:"), "%s lacks the shared prefix", key)
+	}
+	assert.Equal(t, "fixed_window", goratelimit.Describe(fw).Algorithm)
+	assert.Equal(t, "token_bucket", goratelimit.Describe(tb).Algorithm)
+}
+
+func TestFactory_PerLimiterOptionsOverride(t *testing.T) {
+	ctx := context.Background()
+	mr, client := miniredisClient(t)
+	f := goratelimit.NewFactory(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix("gw"))
+
+	g, err := f.GCRA(10, 5, goratelimit.WithKeyPrefix("gcra"))
+	require.NoError(t, err)
+	_, err = g.Allow(ctx, "k")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"gcra:k"}, mr.Keys())
+}
+
+func TestFactory_ValidatesArguments(t *testing.T) {
+	_, err := goratelimit.NewFactory().SlidingWindowCounter(0, 60)
+	assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+}
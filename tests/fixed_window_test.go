@@ -33,6 +33,7 @@ func TestNewFixedWindow(t *testing.T) {
 			limiter, err := goratelimit.NewFixedWindow(tt.maxRequests, tt.windowSeconds)
 			if tt.expectError {
 				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 				assert.Contains(t, err.Error(), tt.errorSubstring)
 				assert.Nil(t, limiter)
 			} else {
@@ -172,3 +173,177 @@ func TestFixedWindow_Allow_Redis(t *testing.T) {
 		assert.True(t, res2.Allowed, "user2 should not be rate limited")
 	})
 }
+
+func TestFixedWindow_Redis_ResetAtAlignedToBoundary(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	windowStart := time.Unix(1_759_999_980, 0) // a multiple of 60s
+	boundary := windowStart.Add(time.Minute)
+	for _, offset := range []time.Duration{0, 13500 * time.Millisecond, 59999 * time.Millisecond} {
+		t.Run(offset.String(), func(t *testing.T) {
+			clock := goratelimit.NewFakeClockAt(windowStart.Add(offset))
+			prefix := fmt.Sprintf("test-fw-aligned-%d", time.Now().UnixNano())
+			limiter, err := goratelimit.NewFixedWindow(1, 60,
+				goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix), goratelimit.WithClock(clock))
+			require.NoError(t, err)
+			defer limiter.Reset(ctx, "k")
+
+			res, err := limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			assert.True(t, boundary.Equal(res.ResetAt), "ResetAt %v should be the boundary %v", res.ResetAt, boundary)
+
+			res, err = limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.False(t, res.Allowed)
+			assert.True(t, boundary.Equal(res.ResetAt))
+			assert.Equal(t, boundary.Sub(clock.Now()), res.RetryAfter)
+
+			clock.Advance(boundary.Sub(clock.Now()))
+			res, err = limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "a new window starts at the boundary")
+			assert.True(t, boundary.Add(time.Minute).Equal(res.ResetAt))
+		})
+	}
+}
+
+// assertCarryover drives NewFixedWindow(10, 60, WithCarryover(3)) through
+// windows with light, full and no use, starting at a window boundary.
+func assertCarryover(t *testing.T, clock *goratelimit.FakeClock, limiter goratelimit.Limiter) {
+	t.Helper()
+	ctx := context.Background()
+	useAll := func(want int64) {
+		t.Helper()
+		for i := int64(0); i < want; i++ {
+			res, err := limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.True(t, res.Allowed, "request %d of %d should be allowed", i+1, want)
+			require.Equal(t, want, res.Limit)
+			require.Equal(t, want-i-1, res.Remaining)
+		}
+		res, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		require.False(t, res.Allowed, "request %d should be denied", want+1)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+	}
+
+	clock.Advance(time.Minute)
+	useAll(13) // 8 unused, capped at 3
+
+	clock.Advance(time.Minute)
+	useAll(10) // the previous window was over its base limit
+
+	clock.Advance(time.Minute)
+	res, err := limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), res.Limit)
+
+	clock.Advance(2 * time.Minute)
+	res, err = limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), res.Limit, "credit does not skip an idle window")
+}
+
+func TestFixedWindow_Carryover(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_759_999_980, 0))
+	limiter, err := goratelimit.NewFixedWindow(10, 60,
+		goratelimit.WithCarryover(3), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertCarryover(t, clock, limiter)
+}
+
+func TestFixedWindow_Redis_Carryover(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_759_999_980, 0))
+	limiter, err := goratelimit.NewFixedWindow(10, 60,
+		goratelimit.WithRedis(client),
+		goratelimit.WithKeyPrefix(fmt.Sprintf("test-fw-carry-%d", time.Now().UnixNano())),
+		goratelimit.WithCarryover(3),
+		goratelimit.WithClock(clock),
+	)
+	require.NoError(t, err)
+	assertCarryover(t, clock, limiter)
+}
+
+// assertRemainingAfterExceededCost checks that a cost too large for what is
+// left of the window is denied without hiding that headroom.
+func assertRemainingAfterExceededCost(t *testing.T, limiter goratelimit.Limiter) {
+	t.Helper()
+	ctx := context.Background()
+
+	res, err := limiter.AllowN(ctx, "k", 8)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	assert.Equal(t, int64(2), res.Remaining)
+
+	res, err = limiter.AllowN(ctx, "k", 5)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	assert.Equal(t, goratelimit.ReasonOverLimit, res.DenyReason)
+	assert.Equal(t, int64(2), res.Remaining, "headroom too small for the cost is still reported")
+
+	res, err = limiter.AllowN(ctx, "k", 2)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "the denied cost took nothing")
+	assert.Equal(t, int64(0), res.Remaining)
+}
+
+func TestFixedWindow_RemainingAfterExceededCost(t *testing.T) {
+	limiter, err := goratelimit.NewFixedWindow(10, 60)
+	require.NoError(t, err)
+	assertRemainingAfterExceededCost(t, limiter)
+}
+
+func TestFixedWindow_Redis_RemainingAfterExceededCost(t *testing.T) {
+	_, client := miniredisClient(t)
+	limiter, err := goratelimit.NewFixedWindow(10, 60, goratelimit.WithRedis(client))
+	require.NoError(t, err)
+	assertRemainingAfterExceededCost(t, limiter)
+}
+
+// assertResetDropsCarryover checks that Reset also forgets the previous
+// window's unused requests under WithCarryover(3).
+func assertResetDropsCarryover(t *testing.T, clock *goratelimit.FakeClock, limiter goratelimit.Limiter) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	require.NoError(t, limiter.Reset(ctx, "k"))
+	res, err := limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), res.Limit, "no carryover credit after Reset")
+	assert.Equal(t, int64(9), res.Remaining)
+}
+
+func TestFixedWindow_Carryover_Reset(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_759_999_980, 0))
+	limiter, err := goratelimit.NewFixedWindow(10, 60,
+		goratelimit.WithCarryover(3), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertResetDropsCarryover(t, clock, limiter)
+}
+
+func TestFixedWindow_Redis_Carryover_Reset(t *testing.T) {
+	_, client := miniredisClient(t)
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_759_999_980, 0))
+	limiter, err := goratelimit.NewFixedWindow(10, 60,
+		goratelimit.WithRedis(client), goratelimit.WithCarryover(3), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertResetDropsCarryover(t, clock, limiter)
+}
@@ -28,6 +28,14 @@ func TestNewGCRA(t *testing.T) {
 		{"negative burst", 10, -1, true, "must be positive"},
 		{"burst equals rate", 10, 10, false, ""},
 		{"burst greater than rate", 10, 30, false, ""},
+		{"large but reasonable", 1_000_000, 1_000_000, false, ""},
+		{"rate above MaxRate", goratelimit.MaxRate + 1, 10, true, "exceeds MaxRate"},
+		{"high rate dividing 1e9", 500_000_000, 10, false, ""},
+		{"high rate within rounding tolerance", 1_000_500, 10, false, ""},
+		{"high rate rounded past tolerance", 600_000_000, 10, true, "whole-nanosecond interval"},
+		{"rate just past rounding tolerance", 1_000_001, 10, true, "whole-nanosecond interval"},
+		{"burst above MaxBurst", 10, goratelimit.MaxBurst + 1, true, "exceeds MaxBurst"},
+		{"burst window overflows", 1, 1 << 40, true, "too large for rate"},
 	}
 
 	for _, tt := range tests {
@@ -35,6 +43,7 @@ func TestNewGCRA(t *testing.T) {
 			limiter, err := goratelimit.NewGCRA(tt.rate, tt.burst)
 			if tt.expectError {
 				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 				assert.Contains(t, err.Error(), tt.errorSubstring)
 				assert.Nil(t, limiter)
 			} else {
@@ -414,3 +423,154 @@ func TestGCRA_Redis_Reset(t *testing.T) {
 		}
 	})
 }
+
+func TestGCRA_ResultRate(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := goratelimit.NewGCRA(10, 25)
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(ctx, "rate-user")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(25), result.Limit, "Limit should be the burst")
+	assert.Equal(t, int64(10), result.Rate, "Rate should be the sustained rate")
+
+	result, err = limiter.AllowN(ctx, "rate-user", 100)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(25), result.Limit)
+	assert.Equal(t, int64(10), result.Rate, "Rate should be reported on denial too")
+}
+
+// assertHighRate drives a limiter refilling at 1e6/s with a burst of 1000 on
+// a clock at a realistic Unix time, where float64 seconds lose sub-µs detail.
+func assertHighRate(t *testing.T, clock *goratelimit.FakeClock, limiter goratelimit.Limiter) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 0; i < 1000; i++ {
+		res, err := limiter.Allow(ctx, "hot")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d should be allowed", i+1)
+		require.Equal(t, int64(999-i), res.Remaining, "request %d remaining", i+1)
+	}
+	res, err := limiter.Allow(ctx, "hot")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "burst exhausted")
+
+	clock.Advance(time.Microsecond)
+	res, err = limiter.Allow(ctx, "hot")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "one request refills per microsecond")
+	assert.Equal(t, int64(0), res.Remaining)
+
+	clock.Advance(500 * time.Microsecond)
+	res, err = limiter.Allow(ctx, "hot")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(499), res.Remaining)
+}
+
+func TestGCRA_HighRate(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 0))
+	limiter, err := goratelimit.NewGCRA(1_000_000, 1000, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertHighRate(t, clock, limiter)
+}
+
+func TestGCRA_Redis_HighRate(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 0))
+	limiter, err := goratelimit.NewGCRA(1_000_000, 1000,
+		goratelimit.WithRedis(client),
+		goratelimit.WithKeyPrefix(fmt.Sprintf("test-gcra-highrate-%d", time.Now().UnixNano())),
+		goratelimit.WithClock(clock),
+	)
+	require.NoError(t, err)
+	defer limiter.Reset(ctx, "hot")
+	assertHighRate(t, clock, limiter)
+}
+
+// assertRemainingSequence pins NewGCRA(10, 3): each allowed call reports how
+// many further calls will succeed, so the last allowed call reports 0.
+func assertRemainingSequence(t *testing.T, limiter goratelimit.Limiter, key string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, want := range []int64{2, 1, 0} {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request with remaining %d should be allowed", want)
+		assert.Equal(t, want, res.Remaining)
+	}
+	res, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "4th request should be rejected")
+	assert.Equal(t, int64(0), res.Remaining)
+}
+
+func TestGCRA_RemainingSequence(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 123_456_789))
+	limiter, err := goratelimit.NewGCRA(10, 3, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertRemainingSequence(t, limiter, "seq")
+}
+
+func TestGCRA_Redis_RemainingSequence(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	// A rate of 3/s has an emission interval that is not a whole number of
+	// microseconds, which the script used to round into an off-by-one.
+	for _, rate := range []int64{10, 3} {
+		clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 123_456_789))
+		limiter, err := goratelimit.NewGCRA(rate, 3,
+			goratelimit.WithRedis(client),
+			goratelimit.WithKeyPrefix(fmt.Sprintf("test-gcra-seq-%d-%d", rate, time.Now().UnixNano())),
+			goratelimit.WithClock(clock),
+		)
+		require.NoError(t, err)
+		assertRemainingSequence(t, limiter, "seq")
+		require.NoError(t, limiter.Reset(ctx, "seq"))
+	}
+}
+
+func TestGCRA_Redis_ProjectN(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	clock := goratelimit.NewFakeClockAt(time.Now())
+	limiter, err := goratelimit.NewGCRA(10, 5, goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	key := fmt.Sprintf("test-gcra-project-%d", time.Now().UnixNano())
+	defer limiter.Reset(ctx, key)
+
+	for _, n := range []int{1, 4, 2, 3} {
+		next, err := goratelimit.ProjectN(ctx, limiter, key, n)
+		require.NoError(t, err)
+		_, err = limiter.AllowN(ctx, key, n)
+		require.NoError(t, err)
+
+		if wait := next.Sub(clock.Now()); wait > 0 {
+			clock.Advance(wait - time.Nanosecond)
+			res, err := limiter.Allow(ctx, key)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed, "n=%d: allowed before the projected time", n)
+			clock.Advance(time.Nanosecond)
+		}
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "n=%d: denied at the projected time", n)
+	}
+}
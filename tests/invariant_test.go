@@ -0,0 +1,92 @@
+package goratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// TestResult_ResetAtRetryAfterInvariant checks, for every algorithm on both
+// backends, that a denied result has RetryAfter > 0 and ResetAt within a
+// second of now+RetryAfter, and that an allowed one has RetryAfter == 0
+// unless it is being shaped.
+func TestResult_ResetAtRetryAfterInvariant(t *testing.T) {
+	constructors := []struct {
+		name    string
+		shaping bool
+		build   func(opts ...goratelimit.Option) (goratelimit.Limiter, error)
+	}{
+		{"fixed_window", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(5, 10, opts...)
+		}},
+		{"sliding_window", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindow(5, 10, opts...)
+		}},
+		{"sliding_window_counter", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindowCounter(5, 10, opts...)
+		}},
+		{"token_bucket", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(5, 1, opts...)
+		}},
+		{"gcra", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 5, opts...)
+		}},
+		{"leaky_bucket_policing", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(5, 1, goratelimit.Policing, opts...)
+		}},
+		{"leaky_bucket_shaping", true, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(5, 1, goratelimit.Shaping, opts...)
+		}},
+		{"min_interval", false, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewMinInterval(2*time.Second, opts...)
+		}},
+	}
+	backends := []struct {
+		name string
+		opts func(t *testing.T) []goratelimit.Option
+	}{
+		{"memory", func(*testing.T) []goratelimit.Option { return nil }},
+		{"redis", func(t *testing.T) []goratelimit.Option {
+			mr := miniredis.RunT(t)
+			return []goratelimit.Option{goratelimit.WithRedis(redis.NewClient(&redis.Options{Addr: mr.Addr()}))}
+		}},
+	}
+
+	ctx := context.Background()
+	for _, b := range backends {
+		for _, c := range constructors {
+			t.Run(b.name+"/"+c.name, func(t *testing.T) {
+				clock := goratelimit.NewFakeClockAt(time.Unix(1_700_000_000, 250_000_000))
+				l, err := c.build(append(b.opts(t), goratelimit.WithClock(clock))...)
+				require.NoError(t, err)
+
+				denied := 0
+				for i := 0; i < 30; i++ {
+					now := clock.Now()
+					res, err := l.Allow(ctx, "invariant")
+					require.NoError(t, err)
+					if res.Allowed {
+						if !c.shaping {
+							assert.Zero(t, res.RetryAfter, "request %d", i)
+						}
+					} else {
+						denied++
+						require.Positive(t, res.RetryAfter, "request %d", i)
+						want := now.Add(res.RetryAfter)
+						assert.WithinDuration(t, want, res.ResetAt, time.Second,
+							"request %d: ResetAt %v, now+RetryAfter %v", i, res.ResetAt, want)
+					}
+					clock.Advance(100 * time.Millisecond)
+				}
+				assert.Positive(t, denied, "no request was denied")
+			})
+		}
+	}
+}
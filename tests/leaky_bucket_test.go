@@ -36,6 +36,7 @@ func TestNewLeakyBucket(t *testing.T) {
 			if tt.expectError {
 				require.Error(t, err)
 				if tt.errorSubstring != "" {
+					assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 					assert.Contains(t, err.Error(), tt.errorSubstring)
 				}
 				assert.Nil(t, limiter, "expected limiter to be nil on error")
@@ -217,7 +218,8 @@ func TestLeakyBucket_Allow_Shaping(t *testing.T) {
 		require.NoError(t, err)
 		assert.False(t, result.Allowed, "4th request should be rejected")
 		assert.Zero(t, result.Remaining, "remaining should be 0 when rejected")
-		assert.Zero(t, result.RetryAfter, "retryAfter should be 0 when rejected")
+		assert.Positive(t, result.RetryAfter, "retryAfter should be set when rejected")
+		assert.LessOrEqual(t, result.RetryAfter, time.Second/60+time.Millisecond, "one slot leaks in 1/60s")
 	})
 
 	t.Run("delays requests based on queue depth", func(t *testing.T) {
@@ -438,20 +440,23 @@ func TestLeakyBucket_Redis_Allow_Shaping(t *testing.T) {
 	})
 
 	t.Run("rejects requests when queue is full", func(t *testing.T) {
-		limiter := newRedisLeakyBucket(t, 3, 60, goratelimit.Shaping)
-		userID := fmt.Sprintf("test-leaky-shaping-user-2-%d", time.Now().UnixNano())
+		_, client := miniredisClient(t)
+		clock := goratelimit.NewFakeClockAt(time.Unix(1_700_000_000, 0))
+		limiter, err := goratelimit.NewLeakyBucket(3, 60, goratelimit.Shaping,
+			goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+		require.NoError(t, err)
 
 		for i := 0; i < 3; i++ {
-			result, err := limiter.Allow(ctx, userID)
+			result, err := limiter.Allow(ctx, "user")
 			require.NoError(t, err)
 			assert.True(t, result.Allowed, "request %d should be allowed", i+1)
 		}
 
-		result, err := limiter.Allow(ctx, userID)
+		result, err := limiter.Allow(ctx, "user")
 		require.NoError(t, err)
 		assert.False(t, result.Allowed, "4th request should be rejected")
 		assert.Zero(t, result.Remaining, "remaining should be 0")
-		assert.Zero(t, result.RetryAfter, "retryAfter should be 0 for shaping mode when rejected")
+		assert.Equal(t, 17*time.Millisecond, result.RetryAfter, "one slot leaks in 1/60s, rounded up")
 	})
 
 	t.Run("delays requests based on queue depth", func(t *testing.T) {
@@ -506,3 +511,207 @@ func TestLeakyBucket_Redis_Allow_Shaping(t *testing.T) {
 		t.Skip("requires Redis mocking to test fail-open behavior")
 	})
 }
+
+func TestLeakyBucket_ResultRate(t *testing.T) {
+	ctx := context.Background()
+	for _, mode := range []goratelimit.LeakyBucketMode{goratelimit.Policing, goratelimit.Shaping} {
+		t.Run(string(mode), func(t *testing.T) {
+			limiter, err := goratelimit.NewLeakyBucket(10, 2, mode)
+			require.NoError(t, err)
+
+			result, err := limiter.Allow(ctx, "rate-user")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, int64(10), result.Limit, "Limit should be the bucket capacity")
+			assert.Equal(t, int64(2), result.Rate, "Rate should be the leak rate")
+
+			result, err = limiter.AllowN(ctx, "rate-user", 50)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, int64(10), result.Limit)
+			assert.Equal(t, int64(2), result.Rate, "Rate should be reported on denial too")
+		})
+	}
+}
+
+// shapingBatchCases exercise AllowN at the capacity boundary of a capacity-5
+// shaping queue. A batch that does not fit must be denied as a whole and must
+// not reserve any queue slots.
+var shapingBatchCases = []struct {
+	name    string
+	batches []int
+	allowed []bool
+}{
+	{"3 then 3", []int{3, 3}, []bool{true, false}},
+	{"5 then 1", []int{5, 1}, []bool{true, false}},
+	{"2, 2, then 2", []int{2, 2, 2}, []bool{true, true, false}},
+	{"4 then 1 fills exactly", []int{4, 1}, []bool{true, true}},
+	{"6 exceeds capacity", []int{6}, []bool{false}},
+}
+
+func assertShapingBatches(t *testing.T, limiter goratelimit.Limiter, key string, batches []int, allowed []bool) {
+	t.Helper()
+	ctx := context.Background()
+	queued := 0
+	for i, n := range batches {
+		result, err := limiter.AllowN(ctx, key, n)
+		require.NoError(t, err)
+		require.Equal(t, allowed[i], result.Allowed, "batch %d (n=%d)", i+1, n)
+		if result.Allowed {
+			queued += n
+			assert.Equal(t, int64(5-queued), result.Remaining, "batch %d (n=%d) remaining", i+1, n)
+		}
+	}
+
+	// Denied batches must not have reserved slots: the leftover capacity is
+	// still admissible in one batch, and one more request is not.
+	if free := 5 - queued; free > 0 {
+		result, err := limiter.AllowN(ctx, key, free)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "remaining %d slots should still be free", free)
+		assert.Equal(t, int64(0), result.Remaining)
+	}
+	result, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "queue should be exactly full")
+}
+
+func TestLeakyBucket_Shaping_AllowN_Atomic(t *testing.T) {
+	for _, tt := range shapingBatchCases {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := goratelimit.NewFakeClock()
+			limiter, err := goratelimit.NewLeakyBucket(5, 1, goratelimit.Shaping, goratelimit.WithClock(clock))
+			require.NoError(t, err)
+			assertShapingBatches(t, limiter, "batch", tt.batches, tt.allowed)
+		})
+	}
+}
+
+func TestLeakyBucket_Redis_Shaping_AllowN_Atomic(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	for _, tt := range shapingBatchCases {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := goratelimit.NewFakeClockAt(time.Now())
+			limiter, err := goratelimit.NewLeakyBucket(5, 1, goratelimit.Shaping,
+				goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+			require.NoError(t, err)
+			key := fmt.Sprintf("test-leaky-shaping-batch-%d", time.Now().UnixNano())
+			defer limiter.Reset(context.Background(), key)
+			assertShapingBatches(t, limiter, key, tt.batches, tt.allowed)
+		})
+	}
+}
+
+// assertPolicingRetryAfter checks that a denial's RetryAfter is the real time
+// until the bucket leaks enough to admit the request, to the millisecond. The
+// limiter must leak 4/s; its capacity starts at 10 and is later lowered
+// through a LimitFunc to overfill the bucket.
+func assertPolicingRetryAfter(t *testing.T, newLimiter func(opts ...goratelimit.Option) (goratelimit.Limiter, error)) {
+	t.Helper()
+	ctx := context.Background()
+	clock := goratelimit.NewFakeClock()
+	capacity := int64(10)
+	limiter, err := newLimiter(goratelimit.WithClock(clock),
+		goratelimit.WithLimitFunc(func(context.Context, string) int64 { return capacity }))
+	require.NoError(t, err)
+
+	deniedAfter := func(n int) time.Duration {
+		t.Helper()
+		result, err := limiter.AllowN(ctx, "k", n)
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+		return result.RetryAfter
+	}
+	allowedAfter := func(wait time.Duration, n int) {
+		t.Helper()
+		clock.Advance(wait)
+		result, err := limiter.AllowN(ctx, "k", n)
+		require.NoError(t, err)
+		require.True(t, result.Allowed, "request should fit after waiting %v", wait)
+	}
+
+	result, err := limiter.AllowN(ctx, "k", 10)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// Level 9.6 after 100ms: 2 more fit once it drops to 8, 0.4s later.
+	clock.Advance(100 * time.Millisecond)
+	retry := deniedAfter(2)
+	assert.InDelta(t, 400*time.Millisecond, retry, float64(time.Millisecond))
+	allowedAfter(retry, 2)
+
+	// Shrinking capacity to 4 leaves the full bucket 6 over; one more
+	// request needs the level at 3, 7/4 = 1.75s away.
+	capacity = 4
+	retry = deniedAfter(1)
+	assert.InDelta(t, 1750*time.Millisecond, retry, float64(time.Millisecond))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, retry, deniedAfter(1), "repeated denials should not move the wait")
+	}
+	allowedAfter(retry, 1)
+}
+
+func TestLeakyBucket_Policing_RetryAfterReflectsLevel(t *testing.T) {
+	assertPolicingRetryAfter(t, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewLeakyBucket(10, 4, goratelimit.Policing, opts...)
+	})
+}
+
+func TestLeakyBucket_Redis_Policing_RetryAfterReflectsLevel(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+	prefix := fmt.Sprintf("test_lb_retry_%d", time.Now().UnixNano())
+	assertPolicingRetryAfter(t, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		opts = append(opts, goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix))
+		return goratelimit.NewLeakyBucket(10, 4, goratelimit.Policing, opts...)
+	})
+}
+
+// TestLeakyBucket_Redis_Policing_AllowN checks multi-token batches against a
+// capacity-5 bucket leaking 1/s: a batch of 3 fits, a second one does not fit
+// the 2 left, and it is admitted once enough has leaked to fill the bucket
+// exactly. The clock starts at a realistic Unix time, where the script's
+// float seconds lose sub-microsecond precision.
+func TestLeakyBucket_Redis_Policing_AllowN(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_700_000_000, 123_456_789))
+	limiter, err := goratelimit.NewLeakyBucket(5, 1, goratelimit.Policing,
+		goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+
+	result, err := limiter.AllowN(ctx, "k", 3)
+	require.NoError(t, err)
+	require.True(t, result.Allowed, "first batch of 3 should fit")
+	assert.Equal(t, int64(2), result.Remaining)
+	assert.Zero(t, result.RetryAfter)
+
+	result, err = limiter.AllowN(ctx, "k", 3)
+	require.NoError(t, err)
+	require.False(t, result.Allowed, "second batch of 3 should not fit the 2 left")
+	assert.Equal(t, int64(2), result.Remaining, "a denied batch takes nothing")
+	assert.Equal(t, time.Second, result.RetryAfter, "one token must leak first")
+
+	clock.Advance(500 * time.Millisecond)
+	result, err = limiter.AllowN(ctx, "k", 3)
+	require.NoError(t, err)
+	require.False(t, result.Allowed, "only half a token has leaked")
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	clock.Advance(result.RetryAfter)
+	result, err = limiter.AllowN(ctx, "k", 3)
+	require.NoError(t, err)
+	require.True(t, result.Allowed, "batch should fit exactly after the leak")
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Zero(t, result.RetryAfter)
+
+	result, err = limiter.AllowN(ctx, "k", 2)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "the bucket is full")
+	assert.Equal(t, 2*time.Second, result.RetryAfter)
+}
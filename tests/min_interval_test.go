@@ -0,0 +1,131 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestNewMinInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    time.Duration
+		expectError bool
+	}{
+		{"valid interval", time.Second, false},
+		{"one millisecond", time.Millisecond, false},
+		{"zero interval", 0, true},
+		{"sub-millisecond interval", time.Microsecond, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := goratelimit.NewMinInterval(tt.interval)
+			if tt.expectError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+				assert.Nil(t, limiter)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, limiter)
+			}
+		})
+	}
+}
+
+// assertMinIntervalSpacing checks that limiter, built with a 2s interval on
+// clock, admits one request per interval and reports the exact time left.
+func assertMinIntervalSpacing(t *testing.T, limiter goratelimit.Limiter, clock *goratelimit.FakeClock, key string) {
+	t.Helper()
+	ctx := context.Background()
+
+	res, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.True(t, res.Allowed, "first request should be allowed")
+	assert.Equal(t, int64(1), res.Limit)
+
+	clock.Advance(750 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "request inside the interval should be denied")
+	assert.Equal(t, goratelimit.ReasonOverLimit, res.DenyReason)
+	assert.Equal(t, 1250*time.Millisecond, res.RetryAfter)
+
+	clock.Advance(1249 * time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "request 1ms early should be denied")
+	assert.Equal(t, time.Millisecond, res.RetryAfter)
+
+	clock.Advance(time.Millisecond)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "request after the interval should be allowed")
+
+	// The interval restarts from the last allowed request, not the denials.
+	clock.Advance(time.Second)
+	res, err = limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	res, err = limiter.Allow(ctx, "other-"+key)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "keys should be spaced independently")
+}
+
+func TestMinInterval_InMemory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("spacing and retry after", func(t *testing.T) {
+		clock := goratelimit.NewFakeClockAt(time.Now())
+		limiter, err := goratelimit.NewMinInterval(2*time.Second, goratelimit.WithClock(clock))
+		require.NoError(t, err)
+		assertMinIntervalSpacing(t, limiter, clock, "webhook")
+	})
+
+	t.Run("cost above one is rejected", func(t *testing.T) {
+		limiter, err := goratelimit.NewMinInterval(time.Second)
+		require.NoError(t, err)
+		res, err := limiter.AllowN(ctx, "webhook", 2)
+		require.NoError(t, err)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, goratelimit.ReasonCostTooLarge, res.DenyReason)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		limiter, err := goratelimit.NewMinInterval(time.Minute)
+		require.NoError(t, err)
+		res, _ := limiter.Allow(ctx, "webhook")
+		require.True(t, res.Allowed)
+		res, _ = limiter.Allow(ctx, "webhook")
+		require.False(t, res.Allowed)
+
+		require.NoError(t, limiter.Reset(ctx, "webhook"))
+		res, _ = limiter.Allow(ctx, "webhook")
+		assert.True(t, res.Allowed, "reset should clear the spacing")
+	})
+}
+
+func TestMinInterval_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test-mininterval-%d", time.Now().UnixNano())
+	clock := goratelimit.NewFakeClockAt(time.Now())
+	limiter, err := goratelimit.NewMinInterval(2*time.Second,
+		goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	defer goratelimit.ResetMany(ctx, limiter, "webhook", "other-webhook")
+	assertMinIntervalSpacing(t, limiter, clock, "webhook")
+}
@@ -0,0 +1,52 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestNoExpire_Redis(t *testing.T) {
+	ctx := context.Background()
+
+	constructors := resetManyConstructors()
+	constructors["leaky_bucket_policing"] = func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewLeakyBucket(1, 1, goratelimit.Policing, opts...)
+	}
+	constructors["leaky_bucket_shaping"] = func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewLeakyBucket(1, 1, goratelimit.Shaping, opts...)
+	}
+
+	for name, newLimiter := range constructors {
+		for _, noExpire := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/no_expire=%v", name, noExpire), func(t *testing.T) {
+				_, client := miniredisClient(t)
+				limiter, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithNoExpire(noExpire))
+				require.NoError(t, err)
+
+				res, err := limiter.Allow(ctx, "k")
+				require.NoError(t, err)
+				require.True(t, res.Allowed)
+
+				keys, err := client.Keys(ctx, "*").Result()
+				require.NoError(t, err)
+				require.NotEmpty(t, keys)
+				for _, key := range keys {
+					ttl, err := client.PTTL(ctx, key).Result()
+					require.NoError(t, err)
+					if noExpire {
+						assert.Equal(t, time.Duration(-1), ttl, "%s should have no TTL", key)
+					} else {
+						assert.Greater(t, ttl, time.Duration(0), "%s should have a TTL", key)
+					}
+				}
+			})
+		}
+	}
+}
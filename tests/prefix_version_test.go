@@ -0,0 +1,54 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestPrefixVersion_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	for name, newLimiter := range resetManyConstructors() {
+		t.Run(name, func(t *testing.T) {
+			prefix := fmt.Sprintf("test_prefix_version_%d", time.Now().UnixNano())
+			defer func() { _, _ = goratelimit.FlushRedis(ctx, client, 0, goratelimit.WithKeyPrefix(prefix)) }()
+			v1, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix), goratelimit.WithPrefixVersion("v1"))
+			require.NoError(t, err)
+			v2, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix), goratelimit.WithPrefixVersion("v2"))
+			require.NoError(t, err)
+
+			res, err := v1.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			res, err = v1.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.False(t, res.Allowed, "v1 should be exhausted")
+
+			res, err = v2.Allow(ctx, "k")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "v1 state must be invisible under v2")
+
+			v1Keys, err := client.Keys(ctx, prefix+":v1:*").Result()
+			require.NoError(t, err)
+			v2Keys, err := client.Keys(ctx, prefix+":v2:*").Result()
+			require.NoError(t, err)
+			assert.NotEmpty(t, v1Keys)
+			assert.NotEmpty(t, v2Keys)
+			for _, key := range v1Keys {
+				assert.NotContains(t, v2Keys, key)
+			}
+		})
+	}
+}
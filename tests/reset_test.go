@@ -0,0 +1,221 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// resetManyConstructors builds one limiter per algorithm, each allowing a
+// single request per key so exhaustion and reset are easy to observe.
+func resetManyConstructors() map[string]func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+	return map[string]func(opts ...goratelimit.Option) (goratelimit.Limiter, error){
+		"fixed_window": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(1, 60, opts...)
+		},
+		"sliding_window": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindow(1, 60, opts...)
+		},
+		"sliding_window_counter": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindowCounter(1, 60, opts...)
+		},
+		"token_bucket": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(1, 1, opts...)
+		},
+		"gcra": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 1, opts...)
+		},
+		"concurrency": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewConcurrency(1, time.Minute, opts...)
+		},
+	}
+}
+
+func assertResetMany(t *testing.T, limiter goratelimit.Limiter, keys []string, other string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, key := range append(keys, other) {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "first request for %s should be allowed", key)
+		res, err = limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.False(t, res.Allowed, "second request for %s should be denied", key)
+	}
+
+	require.NoError(t, goratelimit.ResetMany(ctx, limiter, keys...))
+
+	for _, key := range keys {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "%s should be cleared by ResetMany", key)
+	}
+	res, err := limiter.Allow(ctx, other)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "%s should be untouched by ResetMany", other)
+}
+
+func TestResetMany_InMemory(t *testing.T) {
+	for name, newLimiter := range resetManyConstructors() {
+		t.Run(name, func(t *testing.T) {
+			limiter, err := newLimiter()
+			require.NoError(t, err)
+			_, ok := limiter.(goratelimit.BulkResetter)
+			assert.True(t, ok, "limiter should implement BulkResetter")
+			assertResetMany(t, limiter, []string{"a", "b", "c"}, "other")
+		})
+	}
+}
+
+func TestResetMany_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	for _, hashTag := range []bool{false, true} {
+		for name, newLimiter := range resetManyConstructors() {
+			t.Run(fmt.Sprintf("%s/hashtag=%v", name, hashTag), func(t *testing.T) {
+				prefix := fmt.Sprintf("test-resetmany-%d", time.Now().UnixNano())
+				opts := []goratelimit.Option{goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix)}
+				if hashTag {
+					opts = append(opts, goratelimit.WithHashTag())
+				}
+				limiter, err := newLimiter(opts...)
+				require.NoError(t, err)
+
+				keys := []string{"a", "b", "c"}
+				defer goratelimit.ResetMany(ctx, limiter, append(keys, "other")...)
+				assertResetMany(t, limiter, keys, "other")
+			})
+		}
+	}
+}
+
+func TestResetMany_FallsBackToReset(t *testing.T) {
+	ctx := context.Background()
+	inner, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+	// Embedding only the Limiter interface hides the BulkResetter method.
+	limiter := struct{ goratelimit.Limiter }{inner}
+
+	assertResetMany(t, limiter, []string{"a", "b"}, "other")
+	require.NoError(t, goratelimit.ResetMany(ctx, limiter))
+}
+
+func assertResetExisted(t *testing.T, limiter goratelimit.Limiter) {
+	t.Helper()
+	ctx := context.Background()
+
+	existed, err := goratelimit.ResetExisted(ctx, limiter, "absent")
+	require.NoError(t, err)
+	assert.False(t, existed, "absent key should report no state")
+
+	res, err := limiter.Allow(ctx, "present")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	existed, err = goratelimit.ResetExisted(ctx, limiter, "present")
+	require.NoError(t, err)
+	assert.True(t, existed, "present key should report state")
+
+	existed, err = goratelimit.ResetExisted(ctx, limiter, "present")
+	require.NoError(t, err)
+	assert.False(t, existed, "second reset should find nothing")
+}
+
+func TestResetExisted_InMemory(t *testing.T) {
+	for name, newLimiter := range resetManyConstructors() {
+		t.Run(name, func(t *testing.T) {
+			limiter, err := newLimiter()
+			require.NoError(t, err)
+			_, ok := limiter.(goratelimit.ExistenceResetter)
+			assert.True(t, ok, "limiter should implement ExistenceResetter")
+			assertResetExisted(t, limiter)
+		})
+	}
+}
+
+func TestResetExisted_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	for name, newLimiter := range resetManyConstructors() {
+		t.Run(name, func(t *testing.T) {
+			prefix := fmt.Sprintf("test-resetexisted-%d", time.Now().UnixNano())
+			limiter, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix))
+			require.NoError(t, err)
+			assertResetExisted(t, limiter)
+		})
+	}
+}
+
+func TestResetExisted_FallsBackToReset(t *testing.T) {
+	ctx := context.Background()
+	inner, err := goratelimit.NewFixedWindow(1, 60)
+	require.NoError(t, err)
+	limiter := struct{ goratelimit.Limiter }{inner}
+
+	denied := func() bool {
+		res, err := limiter.Allow(ctx, "k")
+		require.NoError(t, err)
+		return !res.Allowed
+	}
+	denied()
+	require.True(t, denied())
+
+	existed, err := goratelimit.ResetExisted(ctx, limiter, "k")
+	require.NoError(t, err)
+	assert.False(t, existed, "fallback cannot tell whether state existed")
+	assert.False(t, denied(), "fallback should still reset the key")
+}
+
+func TestReset_ConcurrentWithAllow_InMemory(t *testing.T) {
+	ctx := context.Background()
+	for name, newLimiter := range resetManyConstructors() {
+		t.Run(name, func(t *testing.T) {
+			limiter, err := newLimiter()
+			require.NoError(t, err)
+
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 200; i++ {
+						if (g+i)%4 == 0 {
+							assert.NoError(t, limiter.Reset(ctx, "k"))
+						} else {
+							_, err := limiter.Allow(ctx, "k")
+							assert.NoError(t, err)
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+
+			// Whatever the interleaving, the last Reset wins: no Allow that
+			// raced with an earlier Reset leaves state behind it.
+			require.NoError(t, limiter.Reset(ctx, "k"))
+			existed, err := goratelimit.ResetExisted(ctx, limiter, "k")
+			require.NoError(t, err)
+			assert.False(t, existed, "no state should survive the final Reset")
+			res, err := limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "key should start fresh after Reset")
+		})
+	}
+}
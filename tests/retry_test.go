@@ -0,0 +1,187 @@
+package goratelimit_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// replyErr mimics an error reply from Redis, such as a script error.
+type replyErr string
+
+func (e replyErr) Error() string { return string(e) }
+func (replyErr) RedisError()     {}
+
+// flakyHook fails the first failures commands or pipelines a client sends
+// with err, then lets the rest through.
+type flakyHook struct {
+	failures int64
+	err      error
+	calls    atomic.Int64
+}
+
+func (h *flakyHook) fail() error {
+	if h.calls.Add(1) <= h.failures {
+		return h.err
+	}
+	return nil
+}
+
+func (h *flakyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *flakyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.fail(); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *flakyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.fail(); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
+
+var connReset = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+// flakyClient returns a miniredis client whose first failures commands fail
+// with err.
+func flakyClient(t *testing.T, failures int64, err error) (*redis.Client, *flakyHook) {
+	t.Helper()
+	_, client := miniredisClient(t)
+	require.NoError(t, client.Ping(context.Background()).Err(), "open the connection before failing")
+	hook := &flakyHook{failures: failures, err: err}
+	client.AddHook(hook)
+	return client, hook
+}
+
+func TestBackendRetry_RecoversWithoutFailingOpen(t *testing.T) {
+	constructors := map[string]func(opts ...goratelimit.Option) (goratelimit.Limiter, error){
+		"token bucket": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(5, 1, opts...)
+		},
+		"gcra": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 5, opts...)
+		},
+		"fixed window": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(5, 60, opts...)
+		},
+		"sliding window": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindow(5, 60, opts...)
+		},
+		"sliding window counter": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindowCounter(5, 60, opts...)
+		},
+		"leaky bucket": func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(5, 1, goratelimit.Policing, opts...)
+		},
+	}
+	for name, newLimiter := range constructors {
+		t.Run(name, func(t *testing.T) {
+			client, hook := flakyClient(t, 1, connReset)
+			var failedOpen atomic.Int64
+			limiter, err := newLimiter(goratelimit.WithRedis(client),
+				goratelimit.WithBackendRetry(1, time.Millisecond),
+				goratelimit.WithOnFailOpen(func(error) { failedOpen.Add(1) }))
+			require.NoError(t, err)
+
+			result, err := limiter.Allow(context.Background(), "k")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, int64(4), result.Remaining, "the retry reached Redis")
+			assert.Zero(t, failedOpen.Load(), "the retry succeeded, so the limiter did not fail open")
+			assert.Greater(t, hook.calls.Load(), int64(1))
+
+			result, err = limiter.Allow(context.Background(), "k")
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), result.Remaining, "the failed attempt was not counted")
+		})
+	}
+}
+
+func TestBackendRetry_MinInterval(t *testing.T) {
+	client, _ := flakyClient(t, 1, connReset)
+	limiter, err := goratelimit.NewMinInterval(time.Minute, goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(1, 0), goratelimit.WithFailOpen(false))
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	result, err = limiter.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "the retried request was recorded")
+}
+
+func TestBackendRetry_BatchCheck(t *testing.T) {
+	client, _ := flakyClient(t, 1, connReset)
+	limiter, err := goratelimit.NewTokenBucket(5, 1, goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(1, 0), goratelimit.WithFailOpen(false))
+	require.NoError(t, err)
+
+	results, err := goratelimit.BatchCheck(context.Background(), []goratelimit.Check{{Limiter: limiter, Key: "k"}})
+	require.NoError(t, err, "the failed pipeline is retried on its own")
+	assert.True(t, results[0].Allowed)
+	assert.Equal(t, int64(4), results[0].Remaining)
+}
+
+func TestBackendRetry_ExhaustedFailsOpen(t *testing.T) {
+	client, hook := flakyClient(t, 3, connReset)
+	var failedOpen atomic.Int64
+	limiter, err := goratelimit.NewTokenBucket(5, 1, goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(2, 0),
+		goratelimit.WithOnFailOpen(func(error) { failedOpen.Add(1) }))
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(context.Background(), "k")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(1), failedOpen.Load())
+	assert.Equal(t, int64(3), hook.calls.Load(), "one attempt and two retries")
+}
+
+func TestBackendRetry_SkipsReplyErrors(t *testing.T) {
+	client, hook := flakyClient(t, 1, replyErr("ERR Error running script"))
+	limiter, err := goratelimit.NewGCRA(1, 5, goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(3, 0), goratelimit.WithFailOpen(false))
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(context.Background(), "k")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, goratelimit.ErrBackendUnavailable)
+	assert.Equal(t, goratelimit.ReasonBackendError, result.DenyReason)
+	assert.Equal(t, int64(1), hook.calls.Load(), "a reply from Redis is not retried")
+}
+
+func TestBackendRetry_StopsOnCanceledContext(t *testing.T) {
+	client, hook := flakyClient(t, 1, connReset)
+	limiter, err := goratelimit.NewTokenBucket(5, 1, goratelimit.WithRedis(client),
+		goratelimit.WithBackendRetry(1, time.Hour), goratelimit.WithFailOpen(false))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Allow(ctx, "k")
+	require.Error(t, err, "the backoff gave up with the context")
+	assert.Equal(t, int64(1), hook.calls.Load())
+}
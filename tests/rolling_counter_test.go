@@ -0,0 +1,122 @@
+package goratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestNewRollingCounter_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		max         int64
+		window, bkt time.Duration
+	}{
+		{"zero limit", 0, 5 * time.Minute, time.Minute},
+		{"partial bucket", 10, 90 * time.Second, time.Minute},
+		{"bucket larger than window", 10, time.Minute, 5 * time.Minute},
+		{"sub-millisecond bucket", 10, time.Millisecond, time.Microsecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := goratelimit.NewRollingCounter(tt.max, tt.window, tt.bkt)
+			assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+		})
+	}
+
+	l, err := goratelimit.NewRollingCounter(100, 5*time.Minute, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "rolling counter: 100 per 300s", goratelimit.Describe(l).String())
+}
+
+// assertRollingCounter spreads requests over the one-minute buckets of a
+// limiter allowing 5 requests in any 5 minutes, and checks that each bucket
+// stops counting exactly when it leaves the window.
+func assertRollingCounter(t *testing.T, newLimiter func(opts ...goratelimit.Option) (goratelimit.Limiter, error)) {
+	t.Helper()
+	ctx := context.Background()
+	minute := time.Unix(1_700_000_040, 0) // a minute boundary
+	clock := goratelimit.NewFakeClockAt(minute.Add(10 * time.Second))
+	limiter, err := newLimiter(goratelimit.WithClock(clock))
+	require.NoError(t, err)
+
+	allow := func(n int, wantRemaining int64) {
+		t.Helper()
+		result, err := limiter.AllowN(ctx, "k", n)
+		require.NoError(t, err)
+		require.True(t, result.Allowed, "at %v", clock.Now().Sub(minute))
+		assert.Equal(t, wantRemaining, result.Remaining, "at %v", clock.Now().Sub(minute))
+	}
+	deny := func(n int, wantRetry time.Duration) {
+		t.Helper()
+		result, err := limiter.AllowN(ctx, "k", n)
+		require.NoError(t, err)
+		require.False(t, result.Allowed, "at %v", clock.Now().Sub(minute))
+		assert.Equal(t, goratelimit.ReasonOverLimit, result.DenyReason)
+		assert.Equal(t, wantRetry, result.RetryAfter, "at %v", clock.Now().Sub(minute))
+		assert.Equal(t, clock.Now().Add(wantRetry), result.ResetAt)
+	}
+
+	allow(2, 3) // minute 0
+	clock.Advance(time.Minute)
+	allow(1, 2) // minute 1
+	clock.Advance(2 * time.Minute)
+	allow(2, 0) // minute 3
+
+	// Minute 0's 2 requests leave the window at minute 5.
+	deny(1, time.Minute+50*time.Second)
+	clock.Advance(time.Minute + 50*time.Second - time.Millisecond)
+	deny(1, time.Millisecond)
+	clock.Advance(time.Millisecond) // minute 5
+	allow(2, 0)
+
+	// 3 more need minute 1's request and minute 3's 2 to leave, at minute 8.
+	deny(1, time.Minute)
+	deny(3, 3*time.Minute)
+	clock.Advance(3 * time.Minute) // minute 8
+	allow(3, 0)
+
+	// At minute 10 minute 5's 2 have left too, so only minute 8's 3 count.
+	clock.Advance(2 * time.Minute)
+	allow(1, 1)
+}
+
+func TestRollingCounter_AgesOutBuckets(t *testing.T) {
+	assertRollingCounter(t, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewRollingCounter(5, 5*time.Minute, time.Minute, opts...)
+	})
+}
+
+func TestRollingCounter_Redis_AgesOutBuckets(t *testing.T) {
+	_, client := miniredisClient(t)
+	assertRollingCounter(t, func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewRollingCounter(5, 5*time.Minute, time.Minute, append(opts, goratelimit.WithRedis(client))...)
+	})
+}
+
+func TestRollingCounter_Redis_KeysExpireWithTheirBucket(t *testing.T) {
+	ctx := context.Background()
+	mr, client := miniredisClient(t)
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_700_000_040, 0).Add(15 * time.Second))
+	limiter, err := goratelimit.NewRollingCounter(5, 5*time.Minute, time.Minute,
+		goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	assert.Equal(t, time.Unix(1_700_000_040, 0).Add(5*time.Minute), result.ResetAt, "the bucket leaves the window 5 minutes after it starts")
+
+	keys := mr.Keys()
+	require.Len(t, keys, 1, "only the current bucket is written")
+	ttl := mr.TTL(keys[0])
+	assert.InDelta(t, 4*time.Minute+45*time.Second, ttl, float64(10*time.Millisecond))
+
+	require.NoError(t, limiter.Reset(ctx, "k"))
+	assert.Empty(t, mr.Keys())
+}
@@ -0,0 +1,142 @@
+package goratelimit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func miniredisClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestSeed_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	clock := goratelimit.NewFakeClock()
+	limiter, err := goratelimit.NewTokenBucket(10, 1, goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+
+	require.NoError(t, goratelimit.Seed(ctx, limiter, map[string]goratelimit.StateSnapshot{
+		"user:1": {Remaining: 5},
+		"user:2": {Remaining: 0},
+	}))
+
+	res, err := limiter.Allow(ctx, "user:1")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(4), res.Remaining, "seeded with 5 tokens")
+	for i := 0; i < 4; i++ {
+		res, err = limiter.Allow(ctx, "user:1")
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d", i+2)
+	}
+	res, err = limiter.Allow(ctx, "user:1")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "the 6th request exceeds the seeded tokens")
+
+	res, err = limiter.Allow(ctx, "user:2")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "seeded empty")
+
+	// Refill continues from the seeded state.
+	clock.Advance(2 * time.Second)
+	res, err = limiter.Allow(ctx, "user:2")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining)
+}
+
+func TestSeed_Algorithms(t *testing.T) {
+	constructors := []struct {
+		name  string
+		build func(opts ...goratelimit.Option) (goratelimit.Limiter, error)
+	}{
+		{"fixed_window", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(10, 60, opts...)
+		}},
+		{"sliding_window_counter", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindowCounter(10, 60, opts...)
+		}},
+		{"token_bucket", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(10, 1, opts...)
+		}},
+		{"gcra", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 10, opts...)
+		}},
+		{"leaky_bucket_policing", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(10, 1, goratelimit.Policing, opts...)
+		}},
+		{"leaky_bucket_shaping", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(10, 1, goratelimit.Shaping, opts...)
+		}},
+	}
+	ctx := context.Background()
+	for _, c := range constructors {
+		t.Run(c.name, func(t *testing.T) {
+			mr, client := miniredisClient(t)
+			clock := goratelimit.NewFakeClockAt(time.Unix(1_700_000_010, 0))
+			limiter, err := c.build(goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+			require.NoError(t, err)
+
+			// Overwrites existing state.
+			_, err = limiter.AllowN(ctx, "k", 10)
+			require.NoError(t, err)
+			require.NoError(t, goratelimit.Seed(ctx, limiter, map[string]goratelimit.StateSnapshot{"k": {Remaining: 3}}))
+			for _, key := range mr.Keys() {
+				assert.Positive(t, mr.TTL(key), "%s has no TTL", key)
+			}
+
+			res, err := limiter.Allow(ctx, "k")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			assert.Equal(t, int64(2), res.Remaining)
+			res, err = limiter.AllowN(ctx, "k", 3)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed, "only 2 left")
+		})
+	}
+}
+
+func TestSeed_RejectsOutOfRange(t *testing.T) {
+	mr, client := miniredisClient(t)
+	limiter, err := goratelimit.NewGCRA(1, 10, goratelimit.WithRedis(client))
+	require.NoError(t, err)
+
+	err = goratelimit.Seed(context.Background(), limiter, map[string]goratelimit.StateSnapshot{
+		"ok":  {Remaining: 5},
+		"bad": {Remaining: 11},
+	})
+	assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+	assert.Empty(t, mr.Keys(), "nothing is written")
+}
+
+func TestSeed_Unsupported(t *testing.T) {
+	limiter, err := goratelimit.NewTokenBucket(10, 1)
+	require.NoError(t, err)
+	err = goratelimit.Seed(context.Background(), limiter, map[string]goratelimit.StateSnapshot{"k": {Remaining: 1}})
+	assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
+}
+
+func TestSeed_ForwardedThroughNormalizer(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	limiter, err := goratelimit.NewFixedWindow(10, 60,
+		goratelimit.WithRedis(client), goratelimit.WithKeyNormalizer(strings.ToLower))
+	require.NoError(t, err)
+
+	require.NoError(t, goratelimit.Seed(ctx, limiter, map[string]goratelimit.StateSnapshot{"User:1": {Remaining: 1}}))
+	res, err := limiter.Allow(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Remaining)
+}
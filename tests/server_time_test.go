@@ -0,0 +1,67 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+func TestServerTime_SkewedClientsShareKey(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		limiter func(opts ...goratelimit.Option) (goratelimit.Limiter, error)
+	}{
+		{"token_bucket", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(5, 1, opts...)
+		}},
+		{"gcra", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 5, opts...)
+		}},
+		{"leaky_bucket_policing", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(5, 1, goratelimit.Policing, opts...)
+		}},
+		{"leaky_bucket_shaping", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewLeakyBucket(5, 1, goratelimit.Shaping, opts...)
+		}},
+		{"sliding_window", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewSlidingWindow(5, 60, opts...)
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, serverTime := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s/server_time=%v", tt.name, serverTime), func(t *testing.T) {
+				prefix := fmt.Sprintf("test_server_time_%d", time.Now().UnixNano())
+				behind, ahead := goratelimit.NewFakeClock(), goratelimit.NewFakeClock()
+				ahead.Advance(time.Hour)
+
+				node := func(clock goratelimit.Clock) goratelimit.Limiter {
+					l, err := tt.limiter(goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix),
+						goratelimit.WithClock(clock), goratelimit.WithServerTime(serverTime))
+					require.NoError(t, err)
+					return l
+				}
+				a, b := node(behind), node(ahead)
+
+				assert.Equal(t, 5, countAllowed(t, a, "k", 5))
+				if serverTime {
+					assert.Equal(t, 0, countAllowed(t, b, "k", 5), "skewed node should see the exhausted key")
+				} else {
+					assert.Equal(t, 5, countAllowed(t, b, "k", 5), "client clocks an hour apart should disagree")
+				}
+			})
+		}
+	}
+}
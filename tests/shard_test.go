@@ -0,0 +1,120 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// countAllowed sends attempts requests for key and returns how many pass.
+func countAllowed(t *testing.T, limiter goratelimit.Limiter, key string, attempts int) int {
+	t.Helper()
+	allowed := 0
+	for i := 0; i < attempts; i++ {
+		res, err := limiter.Allow(context.Background(), key)
+		require.NoError(t, err)
+		if res.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestKeyShards_DistributesAcrossShards(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]int{}
+	record := func(_ context.Context, key string) int64 {
+		mu.Lock()
+		seen[key]++
+		mu.Unlock()
+		return 0 // keep the construction-time limit
+	}
+
+	limiter, err := goratelimit.NewFixedWindow(100, 60,
+		goratelimit.WithKeyShards(4), goratelimit.WithLimitFunc(record))
+	require.NoError(t, err)
+
+	countAllowed(t, limiter, "user:1", 40)
+	assert.Equal(t, map[string]int{"user:1#0": 10, "user:1#1": 10, "user:1#2": 10, "user:1#3": 10}, seen)
+}
+
+func TestKeyShards_AggregateApproximatesGlobalLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter func(opts ...goratelimit.Option) (goratelimit.Limiter, error)
+		want    int
+		delta   float64
+	}{
+		{"fixed_window", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(100, 60, opts...)
+		}, 100, 0},
+		{"token_bucket", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewTokenBucket(100, 1, opts...)
+		}, 100, 0},
+		{"gcra", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewGCRA(1, 100, opts...)
+		}, 100, 0},
+		// 10/4 rounds down to 2 per shard: 8 in aggregate.
+		{"indivisible limit rounds down per shard", func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+			return goratelimit.NewFixedWindow(10, 60, opts...)
+		}, 10, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := tt.limiter(goratelimit.WithKeyShards(4))
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, countAllowed(t, limiter, "k", 3*tt.want), tt.delta)
+		})
+	}
+}
+
+func TestKeyShards_ResultAndDescribeUseGlobalUnits(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := goratelimit.NewFixedWindow(100, 60, goratelimit.WithKeyShards(4))
+	require.NoError(t, err)
+
+	res, err := limiter.Allow(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), res.Limit)
+	assert.Equal(t, int64(96), res.Remaining, "24 left on the shard, scaled by 4")
+	assert.Equal(t, int64(100), goratelimit.Describe(limiter).Limit)
+
+	countAllowed(t, limiter, "k", 200)
+	require.NoError(t, limiter.Reset(ctx, "k"))
+	assert.Equal(t, 100, countAllowed(t, limiter, "k", 200), "Reset should clear every shard")
+}
+
+func TestKeyShards_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	prefix := fmt.Sprintf("test-shards-%d", time.Now().UnixNano())
+	limiter, err := goratelimit.NewGCRA(10, 40,
+		goratelimit.WithRedis(client), goratelimit.WithKeyPrefix(prefix),
+		goratelimit.WithHashTag(), goratelimit.WithKeyShards(4))
+	require.NoError(t, err)
+	defer limiter.Reset(ctx, "hot")
+
+	assert.Equal(t, 40, countAllowed(t, limiter, "hot", 80))
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("%s:{hot#%d}", prefix, i)
+		n, err := client.Exists(ctx, key).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n, "shard key %s should exist", key)
+	}
+
+	existed, err := goratelimit.ResetExisted(ctx, limiter, "hot")
+	require.NoError(t, err)
+	assert.True(t, existed)
+}
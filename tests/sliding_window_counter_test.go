@@ -34,6 +34,7 @@ func TestNewSlidingWindowCounter(t *testing.T) {
 			if tt.expectError {
 				require.Error(t, err)
 				if tt.errorSubstring != "" {
+					assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 					assert.Contains(t, err.Error(), tt.errorSubstring)
 				}
 				assert.Nil(t, limiter, "expected limiter to be nil on error")
@@ -274,3 +275,122 @@ func TestSlidingWindowCounter_Allow_Redis(t *testing.T) {
 		assert.GreaterOrEqual(t, allowedCount, 1, "should allow at least 1 request")
 	})
 }
+
+// countBoundaryAdmits fills one 4s window with 10 requests, advances 5s so the
+// previous window is weighted at 0.75 (7.5 requests), and counts how many
+// more requests the limiter admits.
+func countBoundaryAdmits(t *testing.T, limiter goratelimit.Limiter, clock *goratelimit.FakeClock, key string) int {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "fill request %d should be allowed", i+1)
+	}
+	clock.Advance(5 * time.Second)
+
+	admitted := 0
+	for i := 0; i < 10; i++ {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		if !res.Allowed {
+			break
+		}
+		admitted++
+	}
+	return admitted
+}
+
+func TestSlidingWindowCounter_EstimateRounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []goratelimit.Option
+		admitted int
+	}{
+		// 7.5 + 2 = 9.5 ≤ 10; a third would exceed the nominal rate.
+		{"default", nil, 2},
+		// ceil(7.5) = 8: never more than 10 within the sliding window.
+		{"conservative", []goratelimit.Option{goratelimit.WithEstimateRounding(goratelimit.Conservative)}, 2},
+		// floor(7.5) = 7: admits a third request, 10.5 against a limit of 10.
+		{"permissive", []goratelimit.Option{goratelimit.WithEstimateRounding(goratelimit.Permissive)}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := goratelimit.NewFakeClockAt(time.Unix(1_000_000, 0))
+			limiter, err := goratelimit.NewSlidingWindowCounter(10, 4, append(tt.opts, goratelimit.WithClock(clock))...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.admitted, countBoundaryAdmits(t, limiter, clock, "user"))
+		})
+	}
+}
+
+func TestSlidingWindowCounter_EstimateRounding_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	tests := []struct {
+		rounding goratelimit.EstimateRounding
+		admitted int
+	}{
+		{goratelimit.Conservative, 2},
+		{goratelimit.Permissive, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.rounding), func(t *testing.T) {
+			// Align to a window boundary: the Redis path buckets by Unix seconds.
+			clock := goratelimit.NewFakeClockAt(time.Unix(1_000_000, 0))
+			limiter, err := goratelimit.NewSlidingWindowCounter(10, 4,
+				goratelimit.WithRedis(client), goratelimit.WithClock(clock),
+				goratelimit.WithEstimateRounding(tt.rounding))
+			require.NoError(t, err)
+			key := fmt.Sprintf("test-counter-rounding-%s-%d", tt.rounding, time.Now().UnixNano())
+			defer limiter.Reset(ctx, key)
+			assert.Equal(t, tt.admitted, countBoundaryAdmits(t, limiter, clock, key))
+		})
+	}
+}
+
+func TestSlidingWindowCounter_ResetAcrossBoundary_Redis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	// Two nodes share the keys; the writer's clock is 2s ahead of the
+	// resetter's and has already crossed into the next 60s window.
+	boundary := time.Unix(1_000_020, 0) // a multiple of 60
+	writerClock := goratelimit.NewFakeClockAt(boundary.Add(time.Second))
+	resetterClock := goratelimit.NewFakeClockAt(boundary.Add(-time.Second))
+	writer, err := goratelimit.NewSlidingWindowCounter(3, 60,
+		goratelimit.WithRedis(client), goratelimit.WithClock(writerClock))
+	require.NoError(t, err)
+	resetter, err := goratelimit.NewSlidingWindowCounter(3, 60,
+		goratelimit.WithRedis(client), goratelimit.WithClock(resetterClock))
+	require.NoError(t, err)
+	key := fmt.Sprintf("test-counter-reset-boundary-%d", time.Now().UnixNano())
+	defer writer.Reset(ctx, key)
+
+	for i := 0; i < 3; i++ {
+		res, err := writer.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+	res, err := writer.Allow(ctx, key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	existed, err := goratelimit.ResetExisted(ctx, resetter, key)
+	require.NoError(t, err)
+	assert.True(t, existed, "the next window's counter should be found")
+
+	res, err = writer.Allow(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(2), res.Remaining, "reset should leave a full fresh limit")
+}
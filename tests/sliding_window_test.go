@@ -33,6 +33,7 @@ func TestNewSlidingWindow(t *testing.T) {
 			limiter, err := goratelimit.NewSlidingWindow(tt.maxRequests, tt.windowSeconds)
 			if tt.expectError {
 				require.Error(t, err)
+				assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 				assert.Contains(t, err.Error(), tt.errorSubstring)
 				assert.Nil(t, limiter)
 			} else {
@@ -216,3 +217,80 @@ func TestSlidingWindow_Allow_Redis(t *testing.T) {
 		assert.True(t, res2.Allowed, "user2 should not be rate limited")
 	})
 }
+
+func TestSlidingWindow_Redis_CapsMembers(t *testing.T) {
+	ctx := context.Background()
+	_, client := miniredisClient(t)
+	limiter, err := goratelimit.NewSlidingWindow(10, 60, goratelimit.WithRedis(client))
+	require.NoError(t, err)
+	fullKey := "ratelimit:flood"
+
+	for i := 0; i < 100; i++ {
+		_, err := limiter.AllowN(ctx, "flood", 1+i%3)
+		require.NoError(t, err)
+		card, err := client.ZCard(ctx, fullKey).Result()
+		require.NoError(t, err)
+		require.LessOrEqual(t, card, int64(10), "ZSET must never exceed maxRequests")
+	}
+
+	require.NoError(t, goratelimit.SetLimit(limiter, 4))
+	res, err := limiter.Allow(ctx, "flood")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	card, err := client.ZCard(ctx, fullKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), card, "a lowered limit trims the oldest members")
+}
+
+// assertBatchRetryAfter fills a 5-per-minute limiter with one request a
+// second, then checks that AllowN(3) waits for the third oldest request to
+// expire rather than the oldest.
+func assertBatchRetryAfter(t *testing.T, limiter goratelimit.Limiter, clock *goratelimit.FakeClock, key string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		res, err := limiter.Allow(ctx, key)
+		require.NoError(t, err)
+		require.True(t, res.Allowed, "request %d should be allowed", i+1)
+		clock.Advance(time.Second)
+	}
+	clock.Advance(5 * time.Second) // now 10s after the first request
+
+	res, err := limiter.Allow(ctx, key)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	assert.Equal(t, 50*time.Second, res.RetryAfter, "one slot frees when the oldest request expires")
+
+	res, err = limiter.AllowN(ctx, key, 3)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	assert.Equal(t, 52*time.Second, res.RetryAfter, "three slots free when the third oldest request expires")
+
+	clock.Advance(res.RetryAfter + time.Millisecond)
+	res, err = limiter.AllowN(ctx, key, 3)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "the batch should fit after RetryAfter")
+}
+
+func TestSlidingWindow_BatchRetryAfter(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Now())
+	limiter, err := goratelimit.NewSlidingWindow(5, 60, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertBatchRetryAfter(t, limiter, clock, "batch")
+}
+
+func TestSlidingWindow_Redis_BatchRetryAfter(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	key := fmt.Sprintf("test-sliding-batch-%d", time.Now().UnixNano())
+	clock := goratelimit.NewFakeClockAt(time.Now())
+	limiter, err := goratelimit.NewSlidingWindow(5, 60, goratelimit.WithRedis(client), goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	defer limiter.Reset(ctx, key)
+	assertBatchRetryAfter(t, limiter, clock, key)
+}
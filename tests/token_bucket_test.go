@@ -26,6 +26,9 @@ func TestNewTokenBucket(t *testing.T) {
 		{name: "negative max capacity", maxCapacity: -1, refillRate: 60, expectError: true, errorSubstring: "must be positive"},
 		{name: "zero refill rate", maxCapacity: 10, refillRate: 0, expectError: true, errorSubstring: "must be positive"},
 		{name: "negative refill rate", maxCapacity: 10, refillRate: -1, expectError: true, errorSubstring: "must be positive"},
+		{name: "large but reasonable", maxCapacity: 1_000_000, refillRate: 1_000_000, expectError: false},
+		{name: "refill rate above MaxRate", maxCapacity: 10, refillRate: goratelimit.MaxRate + 1, expectError: true, errorSubstring: "exceeds MaxRate"},
+		{name: "capacity above MaxBurst", maxCapacity: goratelimit.MaxBurst + 1, refillRate: 10, expectError: true, errorSubstring: "exceeds MaxBurst"},
 	}
 
 	for _, tt := range tests {
@@ -34,6 +37,7 @@ func TestNewTokenBucket(t *testing.T) {
 			if tt.expectError {
 				require.Error(t, err)
 				if tt.errorSubstring != "" {
+					assert.ErrorIs(t, err, goratelimit.ErrInvalidParameter)
 					assert.Contains(t, err.Error(), tt.errorSubstring)
 				}
 				assert.Nil(t, limiter, "expected limiter to be nil on error")
@@ -284,3 +288,46 @@ func TestTokenBucket_Allow_Redis(t *testing.T) {
 		t.Skip("requires Redis mocking to test fail-open behavior")
 	})
 }
+
+func TestTokenBucket_ResultRate(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := goratelimit.NewTokenBucket(20, 5)
+	require.NoError(t, err)
+
+	result, err := limiter.Allow(ctx, "rate-user")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(20), result.Limit, "Limit should be the bucket capacity")
+	assert.Equal(t, int64(5), result.Rate, "Rate should be the refill rate")
+
+	result, err = limiter.AllowN(ctx, "rate-user", 100)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(20), result.Limit)
+	assert.Equal(t, int64(5), result.Rate, "Rate should be reported on denial too")
+}
+
+func TestTokenBucket_HighRate(t *testing.T) {
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 0))
+	limiter, err := goratelimit.NewTokenBucket(1000, 1_000_000, goratelimit.WithClock(clock))
+	require.NoError(t, err)
+	assertHighRate(t, clock, limiter)
+}
+
+func TestTokenBucket_Redis_HighRate(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis not available: %v", err)
+	}
+
+	clock := goratelimit.NewFakeClockAt(time.Unix(1_760_000_000, 0))
+	limiter, err := goratelimit.NewTokenBucket(1000, 1_000_000,
+		goratelimit.WithRedis(client),
+		goratelimit.WithKeyPrefix(fmt.Sprintf("test-tb-highrate-%d", time.Now().UnixNano())),
+		goratelimit.WithClock(clock),
+	)
+	require.NoError(t, err)
+	defer limiter.Reset(ctx, "hot")
+	assertHighRate(t, clock, limiter)
+}
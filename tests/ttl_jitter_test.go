@@ -0,0 +1,92 @@
+package goratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goratelimit "github.com/krishna-kudari/ratelimit"
+)
+
+// keyTTLs charges n keys once each and returns their TTLs.
+func keyTTLs(t *testing.T, client *redis.Client, l goratelimit.Limiter, prefix string, n int) []time.Duration {
+	t.Helper()
+	ctx := context.Background()
+	ttls := make([]time.Duration, 0, n)
+	for i := 0; i < n; i++ {
+		_, err := l.Allow(ctx, fmt.Sprintf("k%d", i))
+		require.NoError(t, err)
+	}
+	keys, err := client.Keys(ctx, prefix+":*").Result()
+	require.NoError(t, err)
+	require.Len(t, keys, n)
+	for _, key := range keys {
+		ttl, err := client.PTTL(ctx, key).Result()
+		require.NoError(t, err)
+		require.Positive(t, ttl, key)
+		ttls = append(ttls, ttl)
+	}
+	return ttls
+}
+
+func TestTTLJitter_Miniredis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	constructors := resetManyConstructors()
+	constructors["leaky_bucket_policing"] = func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewLeakyBucket(1, 1, goratelimit.Policing, opts...)
+	}
+	constructors["leaky_bucket_shaping"] = func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewLeakyBucket(1, 1, goratelimit.Shaping, opts...)
+	}
+	constructors["min_interval"] = func(opts ...goratelimit.Option) (goratelimit.Limiter, error) {
+		return goratelimit.NewMinInterval(time.Minute, opts...)
+	}
+
+	const keys, jitter = 40, 0.5
+	clock := goratelimit.NewFakeClockAt(time.Date(2026, 1, 1, 0, 0, 10, 0, time.UTC))
+	for name, newLimiter := range constructors {
+		t.Run(name, func(t *testing.T) {
+			plain, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithClock(clock),
+				goratelimit.WithKeyPrefix(name+"_plain"))
+			require.NoError(t, err)
+			uniform := keyTTLs(t, client, plain, name+"_plain", keys)
+			base := uniform[0]
+			for _, ttl := range uniform {
+				assert.Equal(t, base, ttl, "TTLs without jitter are uniform")
+			}
+
+			jittered, err := newLimiter(goratelimit.WithRedis(client), goratelimit.WithClock(clock),
+				goratelimit.WithKeyPrefix(name+"_jitter"), goratelimit.WithTTLJitter(jitter))
+			require.NoError(t, err)
+			distinct := make(map[time.Duration]bool)
+			for _, ttl := range keyTTLs(t, client, jittered, name+"_jitter", keys) {
+				distinct[ttl] = true
+				assert.GreaterOrEqual(t, ttl, base, "jitter never shortens a TTL")
+				assert.LessOrEqual(t, ttl, time.Duration(float64(base)*(1+jitter))+time.Millisecond)
+			}
+			assert.Greater(t, len(distinct), keys/2, "TTLs vary within the jitter band")
+		})
+	}
+
+	t.Run("sliding_window_counter", func(t *testing.T) {
+		l, err := goratelimit.NewSlidingWindowCounter(1, 60, goratelimit.WithRedis(client),
+			goratelimit.WithClock(clock), goratelimit.WithKeyPrefix("swc_jitter"), goratelimit.WithTTLJitter(jitter))
+		require.NoError(t, err)
+		distinct := make(map[time.Duration]bool)
+		for _, ttl := range keyTTLs(t, client, l, "swc_jitter", keys) {
+			distinct[ttl] = true
+			assert.GreaterOrEqual(t, ttl, 120*time.Second)
+			assert.LessOrEqual(t, ttl, 180*time.Second)
+		}
+		assert.Greater(t, len(distinct), keys/2)
+	})
+}
@@ -12,25 +12,34 @@ import (
 // NewTokenBucket creates a Token Bucket rate limiter.
 // capacity is the maximum number of tokens (burst size).
 // refillRate is the number of tokens added per second.
+// refillRate may be at most MaxRate and capacity at most MaxBurst.
 // Pass WithRedis for distributed mode; omit for in-memory.
 func NewTokenBucket(capacity, refillRate int64, opts ...Option) (Limiter, error) {
 	if capacity <= 0 || refillRate <= 0 {
 		return nil, validationErr("capacity and refillRate must be positive",
 			"Use positive integers, e.g. NewTokenBucket(10, 5).")
 	}
+	if err := checkRateBounds(refillRate, capacity); err != nil {
+		return nil, err
+	}
 	o := applyOptions(opts)
+	if err := o.checkStrict("NewTokenBucket", false, false); err != nil {
+		return nil, err
+	}
+	capacity = o.perShard(capacity)
+	refillRate = o.perShard(refillRate)
 
 	if o.RedisClient != nil {
 		return wrapOptions(&tokenBucketRedis{
 			redis:      o.RedisClient,
-			capacity:   capacity,
+			baseLimit:  newBaseLimit(capacity),
 			refillRate: refillRate,
 			opts:       o,
 		}, o), nil
 	}
 	return wrapOptions(&tokenBucketMemory{
 		states:     make(map[string]*tokenBucketState),
-		capacity:   capacity,
+		baseLimit:  newBaseLimit(capacity),
 		refillRate: refillRate,
 		opts:       o,
 	}, o), nil
@@ -44,24 +53,30 @@ type tokenBucketState struct {
 }
 
 type tokenBucketMemory struct {
-	mu         sync.Mutex
-	states     map[string]*tokenBucketState
-	capacity   int64
+	mu     sync.Mutex
+	states map[string]*tokenBucketState
+	*baseLimit
 	refillRate int64
 	opts       *Options
 }
 
 func (t *tokenBucketMemory) Allow(ctx context.Context, key string) (Result, error) {
-	return t.AllowN(ctx, key, 1)
+	return t.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (t *tokenBucketMemory) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if res, ok := forcedResult(ctx, t.limit()); ok {
+		return res, nil
+	}
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	cap, unlimited := t.opts.resolveLimit(ctx, key, t.capacity)
+	cap, unlimited := t.opts.resolveLimit(ctx, key, t.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return unlimitedResult(), nil
+	}
+	if res, ok := costTooLarge(n, cap, t.refillRate); ok {
+		return res, nil
 	}
 
 	state, ok := t.states[key]
@@ -74,8 +89,9 @@ func (t *tokenBucketMemory) AllowN(ctx context.Context, key string, n int) (Resu
 	}
 
 	now := t.opts.now()
-	elapsed := now.Sub(state.lastRefill).Seconds()
-	state.tokens = math.Min(float64(cap), state.tokens+elapsed*float64(t.refillRate))
+	elapsed := now.Sub(state.lastRefill)
+	refill := float64(elapsed) * float64(t.refillRate) / float64(time.Second)
+	state.tokens = math.Min(float64(cap), state.tokens+refill)
 	state.lastRefill = now
 
 	cost := float64(n)
@@ -86,6 +102,8 @@ func (t *tokenBucketMemory) AllowN(ctx context.Context, key string, n int) (Resu
 			Allowed:   true,
 			Remaining: remaining,
 			Limit:     cap,
+			Rate:      t.refillRate,
+			ResetAt:   untilFull(now, float64(cap)-state.tokens, float64(t.refillRate)),
 		}, nil
 	}
 
@@ -93,8 +111,11 @@ func (t *tokenBucketMemory) AllowN(ctx context.Context, key string, n int) (Resu
 	retryAfter := time.Duration(math.Ceil(deficit/float64(t.refillRate)) * float64(time.Second))
 	return Result{
 		Allowed:    false,
+		DenyReason: ReasonOverLimit,
 		Remaining:  0,
 		Limit:      cap,
+		Rate:       t.refillRate,
+		ResetAt:    now.Add(retryAfter),
 		RetryAfter: retryAfter,
 	}, nil
 }
@@ -106,14 +127,81 @@ func (t *tokenBucketMemory) Reset(ctx context.Context, key string) error {
 	return nil
 }
 
+func (t *tokenBucketMemory) ResetMany(_ context.Context, keys ...string) error {
+	t.mu.Lock()
+	for _, key := range keys {
+		delete(t.states, key)
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *tokenBucketMemory) ResetExisted(_ context.Context, key string) (bool, error) {
+	t.mu.Lock()
+	_, ok := t.states[key]
+	delete(t.states, key)
+	t.mu.Unlock()
+	return ok, nil
+}
+
+func (t *tokenBucketMemory) Describe() Description {
+	return Description{Algorithm: "token_bucket", Limit: t.limit(), Rate: t.refillRate, Dynamic: t.opts.LimitFunc != nil}
+}
+
+func (t *tokenBucketMemory) Inspect() []KeyState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.opts.now()
+	capacity := float64(t.limit())
+	states := make([]KeyState, 0, len(t.states))
+	for key, state := range t.states {
+		refill := float64(now.Sub(state.lastRefill)) * float64(t.refillRate) / float64(time.Second)
+		tokens := math.Min(capacity, state.tokens+refill)
+		states = append(states, KeyState{
+			Key:       key,
+			Remaining: int64(math.Floor(tokens)),
+			ResetAt:   untilFull(now, capacity-tokens, float64(t.refillRate)),
+		})
+	}
+	return states
+}
+
+func (t *tokenBucketMemory) Refund(ctx context.Context, key string, n int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.states[key]
+	if !ok {
+		return nil
+	}
+	capacity, unlimited := t.opts.resolveLimit(ctx, key, t.limit())
+	if !unlimited {
+		state.tokens = math.Min(float64(capacity), state.tokens+float64(n))
+	}
+	return nil
+}
+
 // ─── Redis ────────────────────────────────────────────────────────────────────
 
-var tokenBucketScript = redis.NewScript(`
+// tokenBucketScript takes now in integer microseconds, which stays exact as a
+// Lua double, and stores state with %.17g because tostring keeps only 14
+// significant digits. An empty now means read it from Redis TIME.
+var tokenBucketScript = redis.NewScript(tokenBucketLua)
+
+// tokenBucketLua is tokenBucketScript's source, which tests also run through
+// a Lua interpreter without Redis.
+const tokenBucketLua = `
 local key = KEYS[1]
 local max_tokens = tonumber(ARGV[1])
 local refill_rate = tonumber(ARGV[2])
 local now = tonumber(ARGV[3])
 local cost = tonumber(ARGV[4])
+local expire = tonumber(ARGV[5])
+
+if not now then
+  redis.replicate_commands()
+  local t = redis.call('TIME')
+  now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+end
 
 local data = redis.call('HGETALL', key)
 local tokens = max_tokens
@@ -129,7 +217,7 @@ if #data > 0 then
 end
 
 local elapsed = now - last_refill
-tokens = math.min(max_tokens, tokens + elapsed * refill_rate)
+tokens = math.min(max_tokens, tokens + elapsed * refill_rate / 1000000)
 
 local allowed = 0
 local remaining = math.floor(tokens)
@@ -144,57 +232,129 @@ else
   retry_after = math.ceil(deficit / refill_rate)
 end
 
-redis.call('HSET', key, 'tokens', tostring(tokens), 'last_refill', tostring(now))
-redis.call('EXPIRE', key, math.ceil(max_tokens / refill_rate) + 1)
+redis.call('HSET', key, 'tokens', string.format('%.17g', tokens), 'last_refill', string.format('%.17g', now))
+if expire > 0 then
+  redis.call('PEXPIRE', key, math.ceil((math.ceil(max_tokens / refill_rate) + 1) * 1000 * expire))
+end
 
 return { allowed, remaining, retry_after }
-`)
+`
 
 type tokenBucketRedis struct {
-	redis      redis.UniversalClient
-	capacity   int64
+	redis redis.UniversalClient
+	*baseLimit
 	refillRate int64
 	opts       *Options
 }
 
 func (t *tokenBucketRedis) Allow(ctx context.Context, key string) (Result, error) {
-	return t.AllowN(ctx, key, 1)
+	return t.AllowN(ctx, key, CostFromContext(ctx))
 }
 
 func (t *tokenBucketRedis) AllowN(ctx context.Context, key string, n int) (Result, error) {
-	cap, unlimited := t.opts.resolveLimit(ctx, key, t.capacity)
+	return t.queueAllowN(ctx, t.redis, key, n)()
+}
+
+// queueAllowN runs AllowN's script on sc, which may be a pipeline, and
+// returns a function decoding its reply once sc has run it.
+func (t *tokenBucketRedis) queueAllowN(ctx context.Context, sc redis.Scripter, key string, n int) func() (Result, error) {
+	if res, ok := forcedResult(ctx, t.limit()); ok {
+		return decided(res)
+	}
+	cap, unlimited := t.opts.resolveLimit(ctx, key, t.limit())
 	if unlimited {
-		return Result{Allowed: true, Remaining: Unlimited, Limit: Unlimited}, nil
+		return decided(unlimitedResult())
+	}
+	if res, ok := costTooLarge(n, cap, t.refillRate); ok {
+		return decided(res)
 	}
 	fullKey := t.opts.FormatKey(key)
-	now := float64(t.opts.now().UnixNano()) / 1e9
+	now := t.opts.now()
 
-	result, err := tokenBucketScript.Run(ctx, t.redis, []string{fullKey},
+	keys := []string{fullKey}
+	args := []interface{}{
 		cap,
 		t.refillRate,
-		now,
+		scriptNow(t.opts, now.UnixMicro()),
 		n,
-	).Int64Slice()
-	if err != nil {
-		if t.opts.FailOpen {
-			return Result{Allowed: true, Remaining: cap - 1, Limit: cap}, nil
-		}
-		return Result{Allowed: false, Remaining: 0, Limit: cap}, redisErr(err, t.opts)
+		t.opts.expireArg(),
 	}
+	cmd := runScript(ctx, tokenBucketScript, sc, keys, args...)
+	return func() (Result, error) {
+		result, err := t.opts.scriptResult(ctx, cmd, t.redis, tokenBucketScript, keys, args)
+		if err != nil {
+			if t.opts.FailOpen {
+				t.opts.failOpen(err)
+				return Result{Allowed: true, Remaining: cap - 1, Limit: cap, Rate: t.refillRate}, nil
+			}
+			return Result{Allowed: false, Remaining: 0, Limit: cap, Rate: t.refillRate, DenyReason: ReasonBackendError}, redisErr(err, t.opts)
+		}
 
-	allowed := result[0] == 1
-	remaining := result[1]
-	retryAfterSec := result[2]
+		allowed := result[0] == 1
+		remaining := result[1]
+		retryAfter := time.Duration(result[2]) * time.Second
 
-	return Result{
-		Allowed:    allowed,
-		Remaining:  remaining,
-		Limit:      cap,
-		RetryAfter: time.Duration(retryAfterSec) * time.Second,
-	}, nil
+		// Remaining is rounded down, so an allowed ResetAt may be up to one
+		// token's refill late.
+		resetAt := untilFull(now, float64(cap-remaining), float64(t.refillRate))
+		if !allowed {
+			resetAt = now.Add(retryAfter)
+		}
+		return Result{
+			Allowed:    allowed,
+			DenyReason: denyReason(allowed),
+			Remaining:  remaining,
+			Limit:      cap,
+			Rate:       t.refillRate,
+			ResetAt:    resetAt,
+			RetryAfter: retryAfter,
+		}, nil
+	}
+}
+
+func (t *tokenBucketRedis) redisClient() redis.UniversalClient {
+	return t.redis
 }
 
 func (t *tokenBucketRedis) Reset(ctx context.Context, key string) error {
 	fullKey := t.opts.FormatKey(key)
 	return t.redis.Del(ctx, fullKey).Err()
 }
+
+func (t *tokenBucketRedis) ResetMany(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = t.opts.FormatKey(key)
+	}
+	return delPipelined(ctx, t.redis, fullKeys)
+}
+
+func (t *tokenBucketRedis) ResetExisted(ctx context.Context, key string) (bool, error) {
+	n, err := t.redis.Del(ctx, t.opts.FormatKey(key)).Result()
+	return n > 0, err
+}
+
+// Seed writes each key's tokens as of now, in the script's format.
+func (t *tokenBucketRedis) Seed(ctx context.Context, states map[string]StateSnapshot) error {
+	capacity := t.limit()
+	if err := checkSeed(states, capacity); err != nil {
+		return err
+	}
+	now := t.opts.now().UnixMicro()
+	ttl := t.opts.seedTTL(time.Duration(ceilDiv(capacity, t.refillRate)+1) * time.Second)
+	pipe := t.redis.Pipeline()
+	for key, s := range states {
+		fullKey := t.opts.FormatKey(key)
+		pipe.Del(ctx, fullKey)
+		pipe.HSet(ctx, fullKey, "tokens", s.Remaining, "last_refill", now)
+		if ttl > 0 {
+			pipe.PExpire(ctx, fullKey, ttl)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (t *tokenBucketRedis) Describe() Description {
+	return Description{Algorithm: "token_bucket", Limit: t.limit(), Rate: t.refillRate, Dynamic: t.opts.LimitFunc != nil}
+}